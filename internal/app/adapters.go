@@ -1,10 +1,17 @@
 package app
 
 import (
+	"crypto/rand"
+	"encoding/hex"
 	"fmt"
+	"os"
+	"strings"
 	"time"
 
 	"github.com/nleiva/chatgbt/pkg/backend"
+	"github.com/nleiva/chatgbt/pkg/notify"
+	"github.com/nleiva/chatgbt/pkg/store"
+	"github.com/nleiva/chatgbt/pkg/tools"
 )
 
 // MetricsLoggerAdapter adapts backend.MetricsLogger to implement the app.Logger interface
@@ -48,29 +55,124 @@ func DefaultSessionManagerConfig(llmConfig backend.LLMConfig, budgetConfig backe
 	return SessionManagerConfig{
 		LLMConfig:      llmConfig,
 		BudgetConfig:   budgetConfig,
-		MaxTokens:      6000,
-		KeepRecent:     3,
-		SummaryEnabled: true,
+		MaxTokens:      genericConversationDefaults.MaxTokens,
+		KeepRecent:     genericConversationDefaults.KeepRecent,
+		SummaryEnabled: genericConversationDefaults.SummaryEnabled,
 	}
 }
 
+// SessionOptions holds optional session settings shared by every mode
+type SessionOptions struct {
+	FewShot          map[string][]backend.FewShotExample // Few-shot examples keyed by conversation type
+	Notifier         notify.Notifier                     // Receives budget and provider failure alerts; nil disables them
+	FailureThreshold int                                 // Consecutive provider failures before alerting
+	Tools            *tools.Registry                     // Tools the model may call; nil disables tool calling
+	Classifier       Classifier                          // Prompt classifier; nil uses keyword matching
+	Language         string                              // Initial UI language; empty uses i18n.Default
+	AutoLanguage     bool                                // Follow the language the user writes in
+	MaxMessageLength int                                 // Longest user message in characters; 0 for no limit
+	ConversationType string                              // Conversation type of CLI and TUI sessions; empty uses DefaultConversationType
+	MaxDailyCost     float64                             // Combined USD spend per UTC day of a session manager's sessions; 0 for no limit
+	TenantDailyCost  float64                             // USD spend per UTC day of each tenant without its own limit; 0 for none
+	Router           *Router                             // Sends messages matching its rules to other models; nil disables routing
+	FollowUps        bool                                // Suggest follow-up questions after each reply
+	AutoContinue     bool                                // Continue replies cut off at the token limit
+	MaxQueued        int                                 // Requests that may wait for a session's reply in progress; 0 rejects them
+	EndUser          string                              // Person using CLI and TUI sessions, hashed before it is sent; empty sends none
+	InjectionGuard   backend.InjectionGuard              // Handling of instruction-like content in tool results; empty passes it through
+	Embedder         backend.Embedder                    // Embeddings for conversation types with keep_relevant; nil disables relevance pruning
+	Store            store.Store                         // Where conversations are saved; nil uses files in store.DefaultDir
+
+	// Conversations overrides the built-in defaults of the conversation types it lists
+	Conversations map[string]ConversationDefaults
+}
+
 // NewChatSessionWithDefaults creates a new chat session with default configuration
-func NewChatSessionWithDefaults(id, conversationType, systemPrompt string, llmConfig backend.LLMConfig, budgetConfig backend.TokenBudgetConfig) (*ChatSession, error) {
+func NewChatSessionWithDefaults(id, conversationType, systemPrompt string, llmConfig backend.LLMConfig, budgetConfig backend.TokenBudgetConfig, opts SessionOptions) (*ChatSession, error) {
 	config := SessionConfig{
 		ID:               id,
 		ConversationType: conversationType,
 		SystemPrompt:     systemPrompt,
 		LLMConfig:        llmConfig,
 		BudgetConfig:     budgetConfig,
-		MaxTokens:        6000,
-		KeepRecent:       3,
-		SummaryEnabled:   true,
+		FewShot:          opts.FewShot,
+		Notifier:         opts.Notifier,
+		FailureThreshold: opts.FailureThreshold,
+		Tools:            opts.Tools,
+		Classifier:       opts.Classifier,
+		Language:         opts.Language,
+		AutoLanguage:     opts.AutoLanguage,
+		MaxMessageLength: opts.MaxMessageLength,
+		Router:           opts.Router,
+		FollowUps:        opts.FollowUps,
+		AutoContinue:     opts.AutoContinue,
+		MaxQueued:        opts.MaxQueued,
+		EndUser:          opts.EndUser,
+		InjectionGuard:   opts.InjectionGuard,
+		Embedder:         opts.Embedder,
+		Store:            opts.Store,
 	}
+	opts.conversationDefaults(conversationType).apply(&config)
 
 	return NewChatSession(config)
 }
 
-// GenerateSessionID creates a unique session ID based on the mode and current time
+// GenerateSessionID creates a unique session ID based on the mode and current time.
+// A random suffix keeps IDs unique when several sessions are created within a second.
 func GenerateSessionID(mode string) string {
-	return fmt.Sprintf("%s_%d", mode, time.Now().Unix())
+	suffix := make([]byte, 4)
+	rand.Read(suffix)
+	return fmt.Sprintf("%s_%d_%s", mode, time.Now().Unix(), hex.EncodeToString(suffix))
+}
+
+// ConfigForModel derives the LLM configuration for a specific provider and model.
+// When the provider differs from the base configuration, the base URL and key don't
+// apply, so the key is read from <PROVIDER>_API_KEY instead, and providers with a
+// default model of their own (e.g. groq) start from it.
+func ConfigForModel(base backend.LLMConfig, provider backend.ProviderName, model string) backend.LLMConfig {
+	cfg := base
+	if provider != "" && provider != base.Provider {
+		cfg.Provider = provider
+		cfg.URL = ""
+		cfg.APIKey = os.Getenv(strings.ToUpper(string(provider)) + "_API_KEY")
+		if cfg.APIKey == "" && provider == backend.ProviderNameHuggingFace {
+			cfg.APIKey = os.Getenv("HF_TOKEN")
+		}
+		if model := backend.DefaultModelFor(provider); model != "" {
+			cfg.Model = model
+		}
+	}
+	if model != "" {
+		cfg.Model = model
+	}
+	return cfg
+}
+
+// ParseModelSpec parses a "provider:model" or plain "model" specification
+// into an LLM configuration derived from base
+func ParseModelSpec(base backend.LLMConfig, spec string) backend.LLMConfig {
+	provider, model, found := strings.Cut(strings.TrimSpace(spec), ":")
+	if !found {
+		return ConfigForModel(base, "", provider)
+	}
+	return ConfigForModel(base, backend.ProviderName(provider), model)
+}
+
+// InferModelSpec is ParseModelSpec for lists of models from different vendors: a plain
+// model name of a well-known family (e.g. "claude-sonnet-4-20250514" or "grok-3") goes
+// to the provider that serves it instead of the base provider
+func InferModelSpec(base backend.LLMConfig, spec string) backend.LLMConfig {
+	spec = strings.TrimSpace(spec)
+	if !strings.Contains(spec, ":") {
+		if provider := backend.ProviderForModel(spec); provider != "" {
+			return ConfigForModel(base, provider, spec)
+		}
+	}
+	return ParseModelSpec(base, spec)
+}
+
+// presetCostPerToken returns the price of provider's preset, or 0 when it has none
+func presetCostPerToken(provider backend.ProviderName) float64 {
+	preset, _ := backend.LookupPreset(provider)
+	return preset.CostPerToken
 }
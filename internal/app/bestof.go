@@ -0,0 +1,195 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nleiva/chatgbt/pkg/backend"
+)
+
+// Best-of-N selection methods
+const (
+	SelectVote  = "vote"  // Majority vote on the final answers (self-consistency)
+	SelectJudge = "judge" // The model picks the best sample
+)
+
+// bestOfTemperature makes the samples differ enough for a vote to be meaningful
+const bestOfTemperature = 0.8
+
+// judgePrompt instructs the model that picks the best of several samples
+const judgePrompt = "You are judging candidate answers to the same question. Pick the one that is most " +
+	"correct, complete, and clear. Reply with the number of the best answer only."
+
+var (
+	// answerMarker finds an explicitly stated final answer, e.g. "Final answer: 42"
+	answerMarker = regexp.MustCompile(`(?i)(?:final answer|answer)\s*(?:is|:)\s*(.+)`)
+	// numberPattern finds numbers such as "-3", "1,250", or "0.75"
+	numberPattern = regexp.MustCompile(`-?\d[\d,]*(?:\.\d+)?`)
+)
+
+// BestOfResult is the answer chosen among several samples
+type BestOfResult struct {
+	Answer  string
+	Samples int            // Samples that succeeded
+	Votes   int            // Samples agreeing with Answer; 0 when a judge picked it
+	Judged  bool           // Answer was picked by the model rather than by majority vote
+	Usage   *backend.Usage // Summed over the samples and the judge
+}
+
+// BestOf samples n answers to query at a raised temperature and picks one, by majority
+// vote on the final answers or by asking the model to judge. A vote in which no two
+// samples agree is settled by the judge. Every request is logged to logger.
+func BestOf(ctx context.Context, client LLMClient, logger Logger, query string, n int, selection string) (*BestOfResult, error) {
+	promptType := ClassifyPrompt(query)
+	temperature := bestOfTemperature
+
+	type sample struct {
+		resp         *backend.ChatCompletionResponse
+		responseTime time.Duration
+		err          error
+	}
+	samples := make([]sample, n)
+	var wg sync.WaitGroup
+	for i := range samples {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			start := time.Now()
+			samples[i].resp, samples[i].err = client.CreateCompletion(ctx, &backend.ChatCompletionRequest{
+				Messages:    []backend.Message{{Role: backend.RoleUser, Content: query}},
+				Temperature: &temperature,
+			})
+			samples[i].responseTime = time.Since(start)
+		}()
+	}
+	wg.Wait()
+
+	result := &BestOfResult{}
+	var answers []string
+	var lastErr error
+	for _, sample := range samples {
+		logCompletion(logger, client, sample.resp, sample.responseTime, sample.err, promptType)
+		if sample.err != nil {
+			lastErr = sample.err
+			continue
+		}
+		result.Usage = addUsage(result.Usage, sample.resp.Usage)
+		if len(sample.resp.Choices) > 0 {
+			answers = append(answers, sample.resp.Choices[0].Message.Content)
+		}
+	}
+	if len(answers) == 0 {
+		if lastErr == nil {
+			lastErr = fmt.Errorf("no sample returned an answer")
+		}
+		return nil, fmt.Errorf("all %d samples failed: %w", n, lastErr)
+	}
+	result.Samples = len(answers)
+
+	if selection != SelectJudge {
+		best, votes := majorityAnswer(answers, promptType)
+		if votes > 1 || len(answers) == 1 {
+			result.Answer, result.Votes = answers[best], votes
+			return result, nil
+		}
+	}
+
+	best, usage, err := judgeAnswers(ctx, client, logger, query, answers)
+	if err != nil {
+		return nil, fmt.Errorf("failed to judge the samples: %w", err)
+	}
+	result.Usage = addUsage(result.Usage, usage)
+	result.Answer, result.Judged = answers[best], true
+	return result, nil
+}
+
+// majorityAnswer returns the index of the first sample whose final answer most samples
+// share, and how many do
+func majorityAnswer(answers []string, promptType string) (best, votes int) {
+	counts := make(map[string]int)
+	keys := make([]string, len(answers))
+	for i, answer := range answers {
+		keys[i] = finalAnswer(answer, promptType)
+		counts[keys[i]]++
+	}
+	for i, key := range keys {
+		if counts[key] > votes {
+			best, votes = i, counts[key]
+		}
+	}
+	return best, votes
+}
+
+// finalAnswer reduces a reply to the answer it concludes with, so that samples that
+// reason differently but agree are counted together: an explicitly marked answer, the
+// last number of a problem_solving or very short reply, or else the whole reply with
+// case and spacing normalized
+func finalAnswer(reply, promptType string) string {
+	if matches := answerMarker.FindAllStringSubmatch(reply, -1); len(matches) > 0 {
+		reply = matches[len(matches)-1][1]
+	}
+	if promptType == "problem_solving" || len(reply) < 40 {
+		if numbers := numberPattern.FindAllString(reply, -1); len(numbers) > 0 {
+			last := strings.ReplaceAll(numbers[len(numbers)-1], ",", "")
+			if value, err := strconv.ParseFloat(last, 64); err == nil {
+				return strconv.FormatFloat(value, 'g', -1, 64)
+			}
+		}
+	}
+	return strings.Join(strings.Fields(strings.ToLower(strings.TrimRight(reply, ". \n"))), " ")
+}
+
+// judgeAnswers asks the model which answer is best and returns its index. An
+// unparseable verdict picks the first answer.
+func judgeAnswers(ctx context.Context, client LLMClient, logger Logger, query string, answers []string) (int, *backend.Usage, error) {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Question:\n%s\n", query)
+	for i, answer := range answers {
+		fmt.Fprintf(&b, "\nAnswer %d:\n%s\n", i+1, answer)
+	}
+
+	start := time.Now()
+	resp, err := client.CreateCompletion(ctx, &backend.ChatCompletionRequest{
+		Messages: []backend.Message{
+			{Role: backend.RoleSystem, Content: judgePrompt},
+			{Role: backend.RoleUser, Content: b.String()},
+		},
+	})
+	logCompletion(logger, client, resp, time.Since(start), err, "best_of_judge")
+	if err != nil {
+		return 0, nil, err
+	}
+
+	best := 0
+	if len(resp.Choices) > 0 {
+		if number := numberPattern.FindString(resp.Choices[0].Message.Content); number != "" {
+			if n, err := strconv.Atoi(number); err == nil && n >= 1 && n <= len(answers) {
+				best = n - 1
+			}
+		}
+	}
+	return best, resp.Usage, nil
+}
+
+// logCompletion records a single request as an interaction
+func logCompletion(logger Logger, client LLMClient, resp *backend.ChatCompletionResponse, responseTime time.Duration, err error, promptType string) {
+	var usage *backend.Usage
+	if err == nil {
+		usage = resp.Usage
+	}
+	provider, model := clientModelInfo(client, resp)
+	logger.LogInteraction(backend.InteractionLog{
+		Usage:        usage,
+		ResponseTime: responseTime,
+		Success:      err == nil,
+		ErrorType:    getErrorType(err),
+		PromptType:   promptType,
+		Provider:     provider,
+		Model:        model,
+	})
+}
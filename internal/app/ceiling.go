@@ -0,0 +1,109 @@
+package app
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/nleiva/chatgbt/pkg/notify"
+)
+
+// DailyCostExceededError is returned instead of a completion once the sessions sharing
+// a CostCeiling have spent its limit for the day
+type DailyCostExceededError struct {
+	Spent float64
+	Limit float64
+}
+
+func (e *DailyCostExceededError) Error() string {
+	return fmt.Sprintf("daily cost limit of $%.2f reached ($%.4f spent); try again tomorrow (UTC)", e.Limit, e.Spent)
+}
+
+// CostCeiling caps the combined estimated spend of many sessions per UTC day. A nil
+// CostCeiling has no limit.
+type CostCeiling struct {
+	mutex    sync.Mutex
+	limit    float64
+	day      string // UTC date that spent refers to
+	spent    float64
+	alerted  bool
+	notifier notify.Notifier // Told once per day when the limit is reached; may be nil
+	parent   *CostCeiling    // Also checked and charged, such as the global ceiling; nil for none
+}
+
+// NewCostCeiling creates a ceiling of limit dollars per UTC day
+func NewCostCeiling(limit float64, notifier notify.Notifier) *CostCeiling {
+	return &CostCeiling{limit: limit, notifier: notifier}
+}
+
+// Nested creates a ceiling of limit dollars per UTC day for part of the sessions c
+// covers, such as a tenant's. Spend against it also counts toward c, and it refuses
+// requests once either is reached. c may be nil.
+func (c *CostCeiling) Nested(limit float64, notifier notify.Notifier) *CostCeiling {
+	return &CostCeiling{limit: limit, notifier: notifier, parent: c}
+}
+
+// Check returns a *DailyCostExceededError once today's spend has reached the limit,
+// or that of the ceiling it is nested in
+func (c *CostCeiling) Check() error {
+	if c == nil {
+		return nil
+	}
+
+	c.mutex.Lock()
+	c.rollover()
+	spent := c.spent
+	c.mutex.Unlock()
+	if spent >= c.limit {
+		return &DailyCostExceededError{Spent: spent, Limit: c.limit}
+	}
+	return c.parent.Check()
+}
+
+// Add records spend by sessionID, also in the ceiling it is nested in, and alerts the
+// first time the day's limit is reached
+func (c *CostCeiling) Add(sessionID string, cost float64) {
+	if c == nil || cost <= 0 {
+		return
+	}
+	c.parent.Add(sessionID, cost)
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.rollover()
+	c.spent += cost
+	if c.spent < c.limit || c.alerted {
+		return
+	}
+
+	c.alerted = true
+	if c.notifier != nil {
+		c.notifier.Notify(notify.Event{
+			Type:      notify.EventDailyCostLimit,
+			Timestamp: time.Now(),
+			SessionID: sessionID,
+			Cost:      c.spent,
+			Message:   fmt.Sprintf("Daily cost limit of $%.2f reached ($%.4f spent); new requests are refused until tomorrow (UTC)", c.limit, c.spent),
+		})
+	}
+}
+
+// Spent returns today's spend and the daily limit
+func (c *CostCeiling) Spent() (spent, limit float64) {
+	if c == nil {
+		return 0, 0
+	}
+
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.rollover()
+	return c.spent, c.limit
+}
+
+// rollover starts a new day's count. Callers must hold the mutex.
+func (c *CostCeiling) rollover() {
+	today := time.Now().UTC().Format(time.DateOnly)
+	if c.day != today {
+		c.day, c.spent, c.alerted = today, 0, false
+	}
+}
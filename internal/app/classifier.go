@@ -1,6 +1,192 @@
 package app
 
-import "strings"
+import (
+	"context"
+	"errors"
+	"log"
+	"math"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nleiva/chatgbt/pkg/backend"
+)
+
+// Classifier assigns a user prompt to a category for metrics tracking
+type Classifier interface {
+	Classify(ctx context.Context, input string) string
+}
+
+// KeywordClassifier classifies prompts with English keyword matching. It needs no network
+// access, so it is the default and the fallback for other classifiers.
+type KeywordClassifier struct{}
+
+// Classify implements Classifier
+func (KeywordClassifier) Classify(_ context.Context, input string) string {
+	return ClassifyPrompt(input)
+}
+
+const (
+	// embeddingTimeout keeps classification from delaying the chat request noticeably
+	embeddingTimeout = 3 * time.Second
+	// embeddingRetryDelay is how long the classifier stays on the fallback after a failure
+	embeddingRetryDelay = 5 * time.Minute
+)
+
+// promptCategories holds example prompts for each category. Examples in several languages
+// let the embedding classifier recognize prompts the English keywords miss.
+var promptCategories = map[string][]string{
+	"code_help": {
+		"Why does my Go program panic with a nil pointer dereference?",
+		"Fix this Python function that raises a TypeError",
+		"¿Por qué no compila mi programa en Java?",
+		"Mon script JavaScript renvoie undefined, pourquoi ?",
+	},
+	"explanation": {
+		"Explain how photosynthesis works",
+		"What is the difference between a virus and a bacterium?",
+		"¿Qué significa la inflación en economía?",
+		"Erkläre mir die Relativitätstheorie einfach",
+	},
+	"creative": {
+		"Write a short poem about the ocean",
+		"Draft a cover letter for a marketing position",
+		"Escribe un cuento corto sobre un dragón",
+		"Écris une chanson sur l'été",
+	},
+	"analysis": {
+		"Compare the pros and cons of remote work",
+		"Critique the argument in this essay",
+		"Analiza las causas de la Revolución Francesa",
+		"Bewerte diese Geschäftsstrategie",
+	},
+	"problem_solving": {
+		"Solve 3x + 5 = 20",
+		"Calculate the area of a circle with radius 4",
+		"Resuelve esta ecuación cuadrática paso a paso",
+		"Combien font 15 % de 240 ?",
+	},
+	"language": {
+		"Translate this sentence into French",
+		"Correct the grammar in this paragraph",
+		"Traduce este texto al inglés",
+		"Korrigiere bitte meine Rechtschreibung",
+	},
+	"general": {
+		"Hello, how are you?",
+		"Thanks for your help!",
+		"Hola, ¿qué tal?",
+		"Recommend a good book for the weekend",
+	},
+}
+
+// EmbeddingClassifier picks the category whose example prompts are most similar to the
+// input in embedding space. When the embeddings API is unreachable it uses the fallback
+// classifier and retries after a delay, so offline use keeps working.
+type EmbeddingClassifier struct {
+	embedder backend.Embedder
+	fallback Classifier
+
+	mutex      sync.Mutex // Guards the fields below
+	categories []string
+	prototypes [][]float64 // One vector per example, parallel to categories
+	retryAt    time.Time
+}
+
+// NewEmbeddingClassifier creates a classifier backed by embedder, falling back to keywords
+func NewEmbeddingClassifier(embedder backend.Embedder) *EmbeddingClassifier {
+	return &EmbeddingClassifier{embedder: embedder, fallback: KeywordClassifier{}}
+}
+
+// Classify implements Classifier
+func (c *EmbeddingClassifier) Classify(ctx context.Context, input string) string {
+	if strings.TrimSpace(input) == "" {
+		return "general"
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, embeddingTimeout)
+	defer cancel()
+
+	categories, prototypes, err := c.loadPrototypes(ctx)
+	if err != nil {
+		return c.fallback.Classify(ctx, input)
+	}
+
+	vectors, err := c.embedder.Embed(ctx, []string{input})
+	if err != nil {
+		c.fail(err)
+		return c.fallback.Classify(ctx, input)
+	}
+
+	best, bestScore := "general", math.Inf(-1)
+	for i, prototype := range prototypes {
+		if score := cosineSimilarity(vectors[0], prototype); score > bestScore {
+			best, bestScore = categories[i], score
+		}
+	}
+	return best
+}
+
+// loadPrototypes returns the example vectors, embedding every example prompt in a single
+// request on first use. It fails fast while the classifier is waiting to retry.
+func (c *EmbeddingClassifier) loadPrototypes(ctx context.Context) ([]string, [][]float64, error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+
+	if time.Now().Before(c.retryAt) {
+		return nil, nil, errors.New("embeddings unavailable")
+	}
+	if c.prototypes != nil {
+		return c.categories, c.prototypes, nil
+	}
+
+	var categories, examples []string
+	for category, prompts := range promptCategories {
+		for _, prompt := range prompts {
+			categories = append(categories, category)
+			examples = append(examples, prompt)
+		}
+	}
+
+	vectors, err := c.embedder.Embed(ctx, examples)
+	if err != nil {
+		c.failLocked(err)
+		return nil, nil, err
+	}
+	c.categories = categories
+	c.prototypes = vectors
+	return c.categories, c.prototypes, nil
+}
+
+// fail switches to the fallback classifier until the retry delay passes
+func (c *EmbeddingClassifier) fail(err error) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.failLocked(err)
+}
+
+// failLocked is fail for callers that hold c.mutex
+func (c *EmbeddingClassifier) failLocked(err error) {
+	log.Printf("Embedding classifier unavailable, using keywords for %v: %v", embeddingRetryDelay, err)
+	c.retryAt = time.Now().Add(embeddingRetryDelay)
+}
+
+// cosineSimilarity returns the cosine of the angle between two vectors
+func cosineSimilarity(a, b []float64) float64 {
+	if len(a) != len(b) || len(a) == 0 {
+		return 0
+	}
+	var dot, normA, normB float64
+	for i := range a {
+		dot += a[i] * b[i]
+		normA += a[i] * a[i]
+		normB += b[i] * b[i]
+	}
+	if normA == 0 || normB == 0 {
+		return 0
+	}
+	return dot / (math.Sqrt(normA) * math.Sqrt(normB))
+}
 
 // ClassifyPrompt analyzes a user input and returns a category for metrics tracking
 // This centralizes the prompt classification logic used by both CLI and Web modes
@@ -0,0 +1,120 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nleiva/chatgbt/pkg/backend"
+)
+
+// commitPrompt instructs the model to write a Conventional Commits message for a staged diff
+const commitPrompt = `You write git commit messages following the Conventional Commits specification.
+
+Format:
+<type>(<optional scope>): <short imperative summary, at most 72 characters>
+
+<optional body explaining what changed and why, wrapped at 72 characters>
+
+Allowed types: feat, fix, docs, style, refactor, perf, test, build, ci, chore, revert.
+Add "!" after the type and a BREAKING CHANGE footer only for incompatible changes.
+Describe the intent of the change, not a file-by-file list. Reply with the commit message only,
+without code fences or commentary.`
+
+// DefaultMaxDiffBytes caps how much of a staged diff is sent to the model
+const DefaultMaxDiffBytes = 60000
+
+// CommitMessageGenerator writes commit messages for diffs
+type CommitMessageGenerator struct {
+	client       LLMClient
+	logger       Logger
+	maxDiffBytes int
+}
+
+// CommitMessage is a generated commit message with the request's metrics
+type CommitMessage struct {
+	Message      string
+	Usage        *backend.Usage
+	ResponseTime time.Duration
+	Truncated    bool // The diff was cut to fit maxDiffBytes
+}
+
+// NewCommitMessageGenerator creates a generator that logs its requests to logger.
+// maxDiffBytes <= 0 uses DefaultMaxDiffBytes.
+func NewCommitMessageGenerator(client LLMClient, logger Logger, maxDiffBytes int) *CommitMessageGenerator {
+	if maxDiffBytes <= 0 {
+		maxDiffBytes = DefaultMaxDiffBytes
+	}
+	return &CommitMessageGenerator{client: client, logger: logger, maxDiffBytes: maxDiffBytes}
+}
+
+// Generate asks the model for a commit message describing diff
+func (g *CommitMessageGenerator) Generate(ctx context.Context, diff string) (*CommitMessage, error) {
+	if strings.TrimSpace(diff) == "" {
+		return nil, fmt.Errorf("nothing to describe: the diff is empty")
+	}
+
+	result := &CommitMessage{}
+	if len(diff) > g.maxDiffBytes {
+		diff = diff[:g.maxDiffBytes]
+		result.Truncated = true
+	}
+
+	content := "Write a commit message for this staged diff:\n\n" + diff
+	if result.Truncated {
+		content += "\n\n(The diff was truncated; describe the change from the part shown.)"
+	}
+
+	req := &backend.ChatCompletionRequest{
+		Messages: []backend.Message{
+			{Role: backend.RoleSystem, Content: commitPrompt},
+			{Role: backend.RoleUser, Content: content},
+		},
+	}
+
+	start := time.Now()
+	resp, err := g.client.CreateCompletion(ctx, req)
+	result.ResponseTime = time.Since(start)
+
+	provider, model := clientModelInfo(g.client, resp)
+	interaction := backend.InteractionLog{
+		ResponseTime: result.ResponseTime,
+		Success:      err == nil,
+		ErrorType:    getErrorType(err),
+		PromptType:   "commit_message",
+		Provider:     provider,
+		Model:        model,
+	}
+	if err == nil {
+		interaction.Usage = resp.Usage
+	}
+	g.logger.LogInteraction(interaction)
+
+	if err != nil {
+		return nil, err
+	}
+	if len(resp.Choices) == 0 {
+		return nil, fmt.Errorf("provider returned no choices")
+	}
+
+	result.Message = cleanCommitMessage(resp.Choices[0].Message.Content)
+	result.Usage = resp.Usage
+	if result.Message == "" {
+		return nil, fmt.Errorf("provider returned an empty commit message")
+	}
+	return result, nil
+}
+
+// cleanCommitMessage strips code fences and surrounding whitespace that models sometimes add
+func cleanCommitMessage(message string) string {
+	message = strings.TrimSpace(message)
+	if strings.HasPrefix(message, "```") {
+		message = strings.TrimPrefix(message, "```")
+		if newline := strings.Index(message, "\n"); newline >= 0 {
+			message = message[newline+1:] // Drop the fence's language tag
+		}
+		message = strings.TrimSuffix(strings.TrimSpace(message), "```")
+	}
+	return strings.TrimSpace(message)
+}
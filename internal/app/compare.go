@@ -0,0 +1,190 @@
+package app
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+const (
+	// VoteA, VoteB, and VoteTie are the accepted comparison votes
+	VoteA   = "a"
+	VoteB   = "b"
+	VoteTie = "tie"
+
+	compareVotesFile = "compare_votes.jsonl"
+)
+
+// voteLogMutex serializes appends to the shared vote log
+var voteLogMutex sync.Mutex
+
+// Comparison sends the same user messages to two sessions for side-by-side evaluation
+type Comparison struct {
+	ID string
+	A  *ChatSession
+	B  *ChatSession
+
+	mutex     sync.Mutex
+	rounds    map[string]*CompareRound
+	nextRound int
+}
+
+// CompareRound holds both answers to a single user message
+type CompareRound struct {
+	ID     string
+	Prompt string
+	A      CompareResult
+	B      CompareResult
+	Voted  bool
+}
+
+// CompareResult is one side of a comparison round
+type CompareResult struct {
+	Model    string
+	Response *ChatResponse
+	Err      error
+}
+
+// CompareVote is the record appended to the vote log
+type CompareVote struct {
+	Timestamp     time.Time `json:"timestamp"`
+	ComparisonID  string    `json:"comparison_id"`
+	RoundID       string    `json:"round_id"`
+	Prompt        string    `json:"prompt"`
+	ModelA        string    `json:"model_a"`
+	ModelB        string    `json:"model_b"`
+	Winner        string    `json:"winner"` // "a", "b", or "tie"
+	WinnerModel   string    `json:"winner_model,omitempty"`
+	ResponseTimeA int64     `json:"response_time_a_ms"`
+	ResponseTimeB int64     `json:"response_time_b_ms"`
+	TokensA       int       `json:"tokens_a"`
+	TokensB       int       `json:"tokens_b"`
+}
+
+// NewComparison creates a comparison between two sessions
+func NewComparison(id string, a, b *ChatSession) *Comparison {
+	return &Comparison{
+		ID:     id,
+		A:      a,
+		B:      b,
+		rounds: make(map[string]*CompareRound),
+	}
+}
+
+// Ask sends the message to both sessions concurrently and records the round
+func (c *Comparison) Ask(message string) *CompareRound {
+	round := &CompareRound{
+		Prompt: message,
+		A:      CompareResult{Model: c.A.ModelLabel()},
+		B:      CompareResult{Model: c.B.ModelLabel()},
+	}
+
+	var wg sync.WaitGroup
+	wg.Add(2)
+	go func() {
+		defer wg.Done()
+		round.A.Response, round.A.Err = c.A.ProcessUserMessage(message)
+	}()
+	go func() {
+		defer wg.Done()
+		round.B.Response, round.B.Err = c.B.ProcessUserMessage(message)
+	}()
+	wg.Wait()
+
+	c.mutex.Lock()
+	c.nextRound++
+	round.ID = strconv.Itoa(c.nextRound)
+	c.rounds[round.ID] = round
+	c.mutex.Unlock()
+
+	return round
+}
+
+// Vote records the user's preference for a round in logs/compare_votes.jsonl
+func (c *Comparison) Vote(roundID, winner string) error {
+	if winner != VoteA && winner != VoteB && winner != VoteTie {
+		return fmt.Errorf("invalid vote %q", winner)
+	}
+
+	c.mutex.Lock()
+	round, exists := c.rounds[roundID]
+	if exists && round.Voted {
+		c.mutex.Unlock()
+		return fmt.Errorf("round %s already has a vote", roundID)
+	}
+	if exists {
+		round.Voted = true
+	}
+	c.mutex.Unlock()
+
+	if !exists {
+		return fmt.Errorf("round %s not found", roundID)
+	}
+
+	vote := CompareVote{
+		Timestamp:    time.Now(),
+		ComparisonID: c.ID,
+		RoundID:      roundID,
+		Prompt:       round.Prompt,
+		ModelA:       round.A.Model,
+		ModelB:       round.B.Model,
+		Winner:       winner,
+	}
+	switch winner {
+	case VoteA:
+		vote.WinnerModel = round.A.Model
+	case VoteB:
+		vote.WinnerModel = round.B.Model
+	}
+	if resp := round.A.Response; resp != nil {
+		vote.ResponseTimeA = resp.ResponseTime.Milliseconds()
+		if resp.Usage != nil {
+			vote.TokensA = resp.Usage.TotalTokens
+		}
+	}
+	if resp := round.B.Response; resp != nil {
+		vote.ResponseTimeB = resp.ResponseTime.Milliseconds()
+		if resp.Usage != nil {
+			vote.TokensB = resp.Usage.TotalTokens
+		}
+	}
+
+	return appendVote(vote)
+}
+
+// Close closes both sessions
+func (c *Comparison) Close() error {
+	errA := c.A.Close()
+	errB := c.B.Close()
+	if errA != nil {
+		return errA
+	}
+	return errB
+}
+
+// appendVote writes a single vote to the vote log
+func appendVote(vote CompareVote) error {
+	data, err := json.Marshal(vote)
+	if err != nil {
+		return fmt.Errorf("failed to encode vote: %w", err)
+	}
+
+	voteLogMutex.Lock()
+	defer voteLogMutex.Unlock()
+
+	if err := os.MkdirAll("logs", 0755); err != nil {
+		return fmt.Errorf("failed to create logs directory: %w", err)
+	}
+	f, err := os.OpenFile(filepath.Join("logs", compareVotesFile), os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open vote log: %w", err)
+	}
+	defer f.Close()
+
+	_, err = f.Write(append(data, '\n'))
+	return err
+}
@@ -0,0 +1,124 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/nleiva/chatgbt/pkg/backend"
+	"github.com/nleiva/chatgbt/pkg/i18n"
+)
+
+// maxContinuations is how many times AutoContinue asks for the rest of one reply
+const maxContinuations = 3
+
+// continuePrompt asks for the rest of a reply cut off at the token limit
+const continuePrompt = "Your last reply was cut off. Continue exactly where it stopped, " +
+	"without repeating anything or adding an introduction."
+
+// continuationPromptType is the prompt type continuation requests are logged under
+const continuationPromptType = "continuation"
+
+// Continue asks the model for the rest of the last reply, when it was cut off at the
+// token limit or stopped, and appends it to that reply so the conversation keeps a single answer.
+// onDelta receives only the new text; the response carries the whole reply. A
+// continuation stopped by Abort is kept as far as it got.
+func (s *ChatSession) Continue(ctx context.Context, onDelta backend.StreamHandler) (*ChatResponse, error) {
+	ctx, err := s.startGeneration(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.endGeneration()
+	last := len(s.Messages) - 1
+	if s.turns() == 0 || s.Messages[last].Role != backend.RoleAssistant {
+		return nil, errors.New("no reply to continue")
+	}
+	if err := s.costCeiling.Check(); err != nil {
+		return nil, err
+	}
+
+	partial := s.Messages[last].Content
+	startTime := time.Now()
+	costBefore := s.Logger.GetBudgetStatus().SessionCost
+	s.Messages = s.Messages[:last]
+	rest, err := s.extend(ctx, s.route(ctx, continuationPromptType), partial, startTime, onDelta)
+	s.costCeiling.Add(s.ID, s.Logger.GetBudgetStatus().SessionCost-costBefore)
+	if err != nil && rest.finish == backend.FinishAborted {
+		err = nil
+	}
+
+	var overBudget *BudgetExceededError
+	if !errors.As(err, &overBudget) {
+		s.checkAlerts(rest.model, err)
+	}
+	if err != nil {
+		s.Messages = append(s.Messages, backend.Message{Role: backend.RoleAssistant, Content: partial})
+		return nil, err
+	}
+
+	reply := partial + rest.reply
+	s.Messages = append(s.Messages, backend.Message{Role: backend.RoleAssistant, Content: reply})
+	if n := len(s.conversation.Messages); n > 0 && s.conversation.Messages[n-1].Role == backend.RoleAssistant {
+		s.conversation.Messages[n-1].Content = reply
+		s.conversation.Messages[n-1].Truncated = rest.finish == backend.FinishAborted
+		s.conversation.UpdatedAt = time.Now()
+		_ = s.Store.Save(s.conversation)
+	}
+
+	var warnings []string
+	if rest.maxTokens > 0 {
+		warnings = append(warnings, i18n.T(s.Language, "budget.trimmed", rest.maxTokens))
+	}
+	response := &ChatResponse{
+		Content:      reply,
+		Usage:        rest.usage,
+		ResponseTime: time.Since(startTime),
+		TTFT:         rest.ttft,
+		Warnings:     append(warnings, s.Logger.GetBudgetStatus().Warnings...),
+		PromptType:   continuationPromptType,
+		Provider:     rest.provider,
+		Model:        rest.model,
+		FinishReason: rest.finish,
+		ToolsUsed:    rest.toolsUsed,
+	}
+	if rest.usage != nil {
+		response.TokensPerSecond = backend.TokensPerSecond(rest.usage.CompletionTokens, response.ResponseTime, rest.ttft)
+	}
+	return response, nil
+}
+
+// continueTruncated asks for the rest of result's reply while it was cut off at the
+// token limit, up to maxContinuations times, and joins the parts. A failed
+// continuation leaves the reply as far as it got; a stopped one adds what it got.
+func (s *ChatSession) continueTruncated(ctx context.Context, route Route, result completion, onDelta backend.StreamHandler) completion {
+	for range maxContinuations {
+		if result.finish != backend.FinishLength {
+			break
+		}
+		rest, err := s.extend(ctx, route, result.reply, time.Now(), onDelta)
+		if err != nil && rest.finish != backend.FinishAborted {
+			break
+		}
+		result.reply += rest.reply
+		result.usage = addUsage(result.usage, rest.usage)
+		result.provider, result.model, result.finish = rest.provider, rest.model, rest.finish
+		result.toolsUsed = append(result.toolsUsed, rest.toolsUsed...)
+		result.neutralized += rest.neutralized
+		if rest.maxTokens > 0 {
+			result.maxTokens = rest.maxTokens
+		}
+	}
+	return result
+}
+
+// extend asks the model of route to continue partial, a reply to the current messages,
+// and returns only the continuation. The messages are left as they were.
+func (s *ChatSession) extend(ctx context.Context, route Route, partial string, startTime time.Time, onDelta backend.StreamHandler) (completion, error) {
+	base := len(s.Messages)
+	s.Messages = append(s.Messages,
+		backend.Message{Role: backend.RoleAssistant, Content: partial},
+		backend.Message{Role: backend.RoleUser, Content: continuePrompt},
+	)
+	defer func() { s.Messages = s.Messages[:base] }()
+	return s.complete(ctx, route, startTime, continuationPromptType, "", nil, onDelta)
+}
@@ -0,0 +1,98 @@
+package app
+
+// DefaultConversationType is used by CLI and TUI sessions unless another type is chosen
+const DefaultConversationType = "cli_session"
+
+// ConversationDefaults are the system prompt, context pruning, and budget settings a
+// session starts with for its conversation type
+type ConversationDefaults struct {
+	SystemPrompt     string // Used when the session isn't given one
+	MaxTokens        int    // Auto-prune conversation context at this limit
+	KeepRecent       int    // Number of recent exchanges to preserve when pruning
+	KeepRecentTokens int    // When positive, preserve recent messages up to this many tokens instead
+	KeepRelevant     int    // Older exchanges most relevant to the latest message kept when pruning; needs embeddings
+	SummaryEnabled   bool   // Summarize pruned content
+	SessionLimit     int    // Session token budget; 0 keeps the configured TOKEN_SESSION_LIMIT
+	JSONMode         bool   // Ask for every reply as a JSON object
+}
+
+// genericConversationDefaults apply to conversation types without an entry in the registry
+var genericConversationDefaults = ConversationDefaults{
+	SystemPrompt:   "You are a helpful assistant.",
+	MaxTokens:      6000,
+	KeepRecent:     3,
+	SummaryEnabled: true,
+}
+
+// conversationDefaults is the built-in registry, keyed by conversation type
+var conversationDefaults = map[string]ConversationDefaults{
+	DefaultConversationType: genericConversationDefaults,
+	"web": {
+		SystemPrompt:   "You are ChatGBT, a helpful AI assistant.",
+		MaxTokens:      8000,
+		KeepRecent:     10,
+		SummaryEnabled: true,
+	},
+	"slack": {
+		SystemPrompt:   "You are ChatGBT, a helpful AI assistant.",
+		MaxTokens:      8000,
+		KeepRecent:     10,
+		SummaryEnabled: true,
+	},
+	"code_review": {
+		SystemPrompt: "You are an experienced code reviewer. Point out bugs, security issues, " +
+			"and unclear code, and suggest concrete fixes.",
+		MaxTokens:      12000,
+		KeepRecent:     6,
+		SummaryEnabled: true,
+	},
+	"tutor": {
+		SystemPrompt: "You are a patient tutor. Explain concepts step by step, check understanding " +
+			"with short questions, and adapt to the learner's level.",
+		MaxTokens:      8000,
+		KeepRecent:     10,
+		SummaryEnabled: true,
+	},
+}
+
+// ConversationDefaultsFor returns the built-in defaults for conversationType
+func ConversationDefaultsFor(conversationType string) ConversationDefaults {
+	if defaults, ok := conversationDefaults[conversationType]; ok {
+		return defaults
+	}
+	return genericConversationDefaults
+}
+
+// CLIConversationType returns the conversation type of CLI and TUI sessions
+func (opts SessionOptions) CLIConversationType() string {
+	if opts.ConversationType != "" {
+		return opts.ConversationType
+	}
+	return DefaultConversationType
+}
+
+// conversationDefaults returns the defaults for conversationType, preferring those
+// loaded from the configuration over the built-in ones
+func (opts SessionOptions) conversationDefaults(conversationType string) ConversationDefaults {
+	if defaults, ok := opts.Conversations[conversationType]; ok {
+		return defaults
+	}
+	return ConversationDefaultsFor(conversationType)
+}
+
+// apply fills in the settings of config that come from its conversation type.
+// An explicit SystemPrompt on config wins over the type's prompt.
+func (d ConversationDefaults) apply(config *SessionConfig) {
+	if config.SystemPrompt == "" {
+		config.SystemPrompt = d.SystemPrompt
+	}
+	config.MaxTokens = d.MaxTokens
+	config.KeepRecent = d.KeepRecent
+	config.KeepRecentTokens = d.KeepRecentTokens
+	config.KeepRelevant = d.KeepRelevant
+	config.SummaryEnabled = d.SummaryEnabled
+	if d.SessionLimit > 0 {
+		config.BudgetConfig.SessionLimit = d.SessionLimit
+	}
+	config.JSONMode = d.JSONMode
+}
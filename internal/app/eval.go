@@ -0,0 +1,232 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/nleiva/chatgbt/pkg/backend"
+)
+
+// EvalSuite is a set of evaluation cases loaded from a YAML file
+type EvalSuite struct {
+	Model      string     `yaml:"model"`       // Optional "provider:model" to evaluate
+	JudgeModel string     `yaml:"judge_model"` // Optional "provider:model" used for judge grading
+	System     string     `yaml:"system"`      // Optional system prompt applied to every case
+	Cases      []EvalCase `yaml:"cases"`
+}
+
+// EvalCase is a single prompt with its grading criteria
+type EvalCase struct {
+	Name   string       `yaml:"name"`
+	Prompt string       `yaml:"prompt"`
+	System string       `yaml:"system"` // Overrides the suite system prompt
+	Expect EvalCriteria `yaml:"expect"`
+}
+
+// EvalCriteria describes how an answer is graded; every criterion set must pass
+type EvalCriteria struct {
+	Exact string `yaml:"exact"` // Answer must equal this, ignoring case and surrounding whitespace
+	Regex string `yaml:"regex"` // Answer must match this regular expression
+	Judge string `yaml:"judge"` // Rubric checked by an LLM judge
+}
+
+// EvalResult is the outcome of running one case
+type EvalResult struct {
+	Case         EvalCase
+	Answer       string
+	Passed       bool
+	Reason       string
+	ResponseTime time.Duration
+	Usage        *backend.Usage
+	Err          error
+}
+
+// LoadEvalSuite reads and validates an evaluation suite from a YAML file
+func LoadEvalSuite(path string) (*EvalSuite, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read eval file: %w", err)
+	}
+
+	var suite EvalSuite
+	if err := yaml.Unmarshal(data, &suite); err != nil {
+		return nil, fmt.Errorf("failed to parse eval file: %w", err)
+	}
+
+	if len(suite.Cases) == 0 {
+		return nil, fmt.Errorf("eval file %s has no cases", path)
+	}
+	for i, c := range suite.Cases {
+		if c.Name == "" {
+			suite.Cases[i].Name = fmt.Sprintf("case_%d", i+1)
+		}
+		if strings.TrimSpace(c.Prompt) == "" {
+			return nil, fmt.Errorf("case %s has an empty prompt", suite.Cases[i].Name)
+		}
+		if c.Expect.Exact == "" && c.Expect.Regex == "" && c.Expect.Judge == "" {
+			return nil, fmt.Errorf("case %s has no grading criteria (exact, regex, or judge)", suite.Cases[i].Name)
+		}
+		if c.Expect.Regex != "" {
+			if _, err := regexp.Compile(c.Expect.Regex); err != nil {
+				return nil, fmt.Errorf("case %s has an invalid regex: %w", suite.Cases[i].Name, err)
+			}
+		}
+	}
+
+	return &suite, nil
+}
+
+// Evaluator runs evaluation cases against a model and grades the answers
+type Evaluator struct {
+	client LLMClient
+	judge  LLMClient
+	logger InteractionLogger
+}
+
+// NewEvaluator creates an evaluator. judge may be nil when no case uses judge grading.
+func NewEvaluator(client, judge LLMClient, logger InteractionLogger) *Evaluator {
+	return &Evaluator{
+		client: client,
+		judge:  judge,
+		logger: logger,
+	}
+}
+
+// Run executes a single case and grades its answer
+func (e *Evaluator) Run(ctx context.Context, evalCase EvalCase, suiteSystem string) EvalResult {
+	result := EvalResult{Case: evalCase}
+
+	var messages []backend.Message
+	system := evalCase.System
+	if system == "" {
+		system = suiteSystem
+	}
+	if system != "" {
+		messages = append(messages, backend.Message{Role: backend.RoleSystem, Content: system})
+	}
+	messages = append(messages, backend.Message{Role: backend.RoleUser, Content: evalCase.Prompt})
+
+	start := time.Now()
+	resp, err := e.client.CreateCompletion(ctx, &backend.ChatCompletionRequest{Messages: messages})
+	result.ResponseTime = time.Since(start)
+	e.logCompletion(resp, err, result.ResponseTime, "eval", e.client)
+
+	if err != nil {
+		result.Err = err
+		result.Reason = "request failed"
+		return result
+	}
+	if len(resp.Choices) > 0 {
+		result.Answer = resp.Choices[0].Message.Content
+	}
+	result.Usage = resp.Usage
+
+	result.Passed, result.Reason = e.grade(ctx, evalCase, result.Answer)
+	return result
+}
+
+// grade checks an answer against every criterion in the case
+func (e *Evaluator) grade(ctx context.Context, evalCase EvalCase, answer string) (bool, string) {
+	expect := evalCase.Expect
+
+	if expect.Exact != "" && !strings.EqualFold(strings.TrimSpace(answer), strings.TrimSpace(expect.Exact)) {
+		return false, fmt.Sprintf("exact: expected %q", expect.Exact)
+	}
+
+	if expect.Regex != "" && !regexp.MustCompile(expect.Regex).MatchString(answer) {
+		return false, fmt.Sprintf("regex: no match for %q", expect.Regex)
+	}
+
+	if expect.Judge != "" {
+		if e.judge == nil {
+			return false, "judge: no judge model configured"
+		}
+		passed, reason, err := e.judgeAnswer(ctx, evalCase.Prompt, answer, expect.Judge)
+		if err != nil {
+			return false, "judge: " + err.Error()
+		}
+		if !passed {
+			return false, "judge: " + reason
+		}
+	}
+
+	return true, "ok"
+}
+
+// judgeAnswer asks the judge model whether an answer satisfies the rubric
+func (e *Evaluator) judgeAnswer(ctx context.Context, question, answer, rubric string) (bool, string, error) {
+	prompt := fmt.Sprintf(`Grade the answer against the rubric.
+
+Question:
+%s
+
+Answer:
+%s
+
+Rubric:
+%s
+
+Reply with PASS or FAIL on the first line, followed by a one-sentence reason.`, question, answer, rubric)
+
+	temperature := 0.0
+	start := time.Now()
+	resp, err := e.judge.CreateCompletion(ctx, &backend.ChatCompletionRequest{
+		Messages: []backend.Message{
+			{Role: backend.RoleSystem, Content: "You are a strict, impartial grader."},
+			{Role: backend.RoleUser, Content: prompt},
+		},
+		Temperature: &temperature,
+	})
+	e.logCompletion(resp, err, time.Since(start), "eval_judge", e.judge)
+	if err != nil {
+		return false, "", err
+	}
+	if len(resp.Choices) == 0 {
+		return false, "", fmt.Errorf("judge returned no answer")
+	}
+
+	return parseVerdict(resp.Choices[0].Message.Content)
+}
+
+// parseVerdict extracts a PASS/FAIL verdict and reason from a judge reply
+func parseVerdict(reply string) (bool, string, error) {
+	reply = strings.TrimSpace(reply)
+	firstLine, rest, _ := strings.Cut(reply, "\n")
+	verdict := strings.ToUpper(strings.Trim(strings.TrimSpace(firstLine), "*.:"))
+	reason := strings.TrimSpace(rest)
+
+	switch {
+	case strings.HasPrefix(verdict, "PASS"):
+		return true, reason, nil
+	case strings.HasPrefix(verdict, "FAIL"):
+		if reason == "" {
+			reason = "rubric not met"
+		}
+		return false, reason, nil
+	default:
+		return false, "", fmt.Errorf("unrecognized verdict %q", firstLine)
+	}
+}
+
+// logCompletion records an evaluation request in the metrics log
+func (e *Evaluator) logCompletion(resp *backend.ChatCompletionResponse, err error, responseTime time.Duration, promptType string, client LLMClient) {
+	provider, model := clientModelInfo(client, resp)
+	interaction := backend.InteractionLog{
+		ResponseTime: responseTime,
+		Success:      err == nil,
+		ErrorType:    getErrorType(err),
+		PromptType:   promptType,
+		Provider:     provider,
+		Model:        model,
+	}
+	if err == nil {
+		interaction.Usage = resp.Usage
+	}
+	e.logger.LogInteraction(interaction)
+}
@@ -0,0 +1,128 @@
+package app
+
+import (
+	"cmp"
+	"context"
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nleiva/chatgbt/pkg/backend"
+	"github.com/nleiva/chatgbt/pkg/i18n"
+	"github.com/nleiva/chatgbt/pkg/llm"
+)
+
+// fanoutTimeout bounds each model's answer; fan-out answers aren't streamed, so long
+// replies need more time than an interactive request
+const fanoutTimeout = 2 * time.Minute
+
+// mergePrompt instructs the model that combines fan-out answers
+const mergePrompt = "You merge answers from several AI models into a single answer. Keep what they agree on, " +
+	"settle disagreements in favor of the best supported answer, and point out any disagreement that " +
+	"remains. Reply with the merged answer only, without mentioning the individual models."
+
+// FanoutResult is one model's answer to a fan-out query
+type FanoutResult struct {
+	Model        string // "provider/model" label
+	Content      string
+	Usage        *backend.Usage
+	Cost         float64 // Estimated USD cost of the answer
+	ResponseTime time.Duration
+	Err          error
+}
+
+// Fanout sends query to every model concurrently and returns their answers in the order
+// of configs. Each answer is logged to logger and priced at its provider's preset rate,
+// or at budgetCfg's when the provider has no preset.
+func Fanout(ctx context.Context, configs []backend.LLMConfig, query string, budgetCfg backend.TokenBudgetConfig, logger Logger) []FanoutResult {
+	messages := []backend.Message{{Role: backend.RoleUser, Content: query}}
+	results := make([]FanoutResult, len(configs))
+
+	var wg sync.WaitGroup
+	for i, cfg := range configs {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			results[i] = fanoutAsk(ctx, cfg, messages)
+		}()
+	}
+	wg.Wait()
+
+	// The metrics logger isn't safe for concurrent use, so answers are logged afterwards
+	language := i18n.Detect(query)
+	for i, cfg := range configs {
+		logFanoutResult(logger, &results[i], cfg, budgetCfg, "fanout", language)
+	}
+	return results
+}
+
+// MergeAnswers asks the model of cfg to combine the successful answers to query into
+// one consensus answer, which is logged to logger like a fan-out answer
+func MergeAnswers(ctx context.Context, cfg backend.LLMConfig, query string, answers []FanoutResult, budgetCfg backend.TokenBudgetConfig, logger Logger) FanoutResult {
+	var b strings.Builder
+	fmt.Fprintf(&b, "Question:\n%s\n", query)
+	n := 0
+	for _, answer := range answers {
+		if answer.Err == nil {
+			n++
+			fmt.Fprintf(&b, "\nAnswer %d:\n%s\n", n, answer.Content)
+		}
+	}
+
+	result := fanoutAsk(ctx, cfg, []backend.Message{
+		{Role: backend.RoleSystem, Content: mergePrompt},
+		{Role: backend.RoleUser, Content: b.String()},
+	})
+	logFanoutResult(logger, &result, cfg, budgetCfg, "fanout_merge", i18n.Detect(query))
+	return result
+}
+
+// fanoutAsk sends messages to the model of cfg
+func fanoutAsk(ctx context.Context, cfg backend.LLMConfig, messages []backend.Message) FanoutResult {
+	result := FanoutResult{Model: string(cfg.Provider) + "/" + cfg.Model}
+
+	client, err := llm.NewClient(cfg, fanoutTimeout)
+	if err != nil {
+		result.Err = fmt.Errorf("failed to create LLM client: %w", err)
+		return result
+	}
+
+	start := time.Now()
+	resp, err := client.CreateCompletion(ctx, &backend.ChatCompletionRequest{Messages: messages})
+	result.ResponseTime = time.Since(start)
+	if err != nil {
+		result.Err = err
+		return result
+	}
+
+	if resp.Model != "" {
+		result.Model = client.ProviderName() + "/" + resp.Model
+	}
+	if len(resp.Choices) > 0 {
+		result.Content = resp.Choices[0].Message.Content
+	}
+	result.Usage = resp.Usage
+	return result
+}
+
+// logFanoutResult prices result and records it as an interaction
+func logFanoutResult(logger Logger, result *FanoutResult, cfg backend.LLMConfig, budgetCfg backend.TokenBudgetConfig, promptType, language string) {
+	costPerToken := cmp.Or(presetCostPerToken(cfg.Provider), budgetCfg.CostPerToken)
+	if result.Usage != nil {
+		result.Cost = float64(result.Usage.TotalTokens) * costPerToken
+	}
+
+	provider, model, _ := strings.Cut(result.Model, "/")
+	logger.LogInteraction(backend.InteractionLog{
+		Usage:        result.Usage,
+		ResponseTime: result.ResponseTime,
+		Success:      result.Err == nil,
+		ErrorType:    getErrorType(result.Err),
+		PromptType:   promptType,
+		Language:     language,
+		Provider:     provider,
+		Model:        model,
+		CostPerToken: costPerToken,
+	})
+}
@@ -0,0 +1,95 @@
+package app
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/nleiva/chatgbt/pkg/backend"
+)
+
+// maxFollowUps is the most follow-up questions suggested after a reply
+const maxFollowUps = 3
+
+// followUpMaxTokens bounds the reply of the suggestion request, which only needs a few short questions
+const followUpMaxTokens = 150
+
+// followUpPrompt asks for the questions suggested by SuggestFollowUps
+const followUpPrompt = "Suggest 2 or 3 short follow-up questions the user might ask next, " +
+	"written from the user's point of view and in the language of the conversation. " +
+	`Respond with a JSON object of the form {"questions": ["...", "..."]}.`
+
+// SuggestFollowUps asks the model for a few questions the user might ask after the
+// latest exchange. It is best effort: failures and unusable replies yield no
+// suggestions rather than an error.
+func (s *ChatSession) SuggestFollowUps(ctx context.Context) []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.suggestFollowUps(ctx)
+}
+
+// suggestFollowUps is SuggestFollowUps for callers already holding the session's mutex
+func (s *ChatSession) suggestFollowUps(ctx context.Context) []string {
+	if s.turns() == 0 || s.costCeiling.Check() != nil {
+		return nil
+	}
+	last := s.conversation.Messages[len(s.conversation.Messages)-2:]
+
+	maxTokens := followUpMaxTokens
+	req := &backend.ChatCompletionRequest{
+		Messages: []backend.Message{
+			{Role: backend.RoleSystem, Content: followUpPrompt},
+			{Role: backend.RoleUser, Content: fmt.Sprintf("User: %s\n\nAssistant: %s", last[0].Content, last[1].Content)},
+		},
+		MaxTokens: &maxTokens,
+		User:      s.endUser,
+	}
+	s.requestJSON(req, clientCapabilities(s.LLMClient))
+	if _, err := s.preflight(req, s.LLMClient); err != nil {
+		return nil
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+
+	costBefore := s.Logger.GetBudgetStatus().SessionCost
+	start := time.Now()
+	resp, err := s.LLMClient.CreateCompletion(ctx, req)
+	logCompletion(s.Logger, s.LLMClient, resp, time.Since(start), err, "follow_ups")
+	s.costCeiling.Add(s.ID, s.Logger.GetBudgetStatus().SessionCost-costBefore)
+	if err != nil || len(resp.Choices) == 0 {
+		return nil
+	}
+	return parseFollowUps(resp.Choices[0].Message.Content)
+}
+
+// parseFollowUps reads the questions from the model's reply, accepting a plain list
+// with one question per line when the reply isn't the requested JSON object
+func parseFollowUps(reply string) []string {
+	reply = strings.TrimSpace(reply)
+	reply = strings.TrimPrefix(strings.TrimPrefix(reply, "```json"), "```")
+	reply = strings.TrimSpace(strings.TrimSuffix(reply, "```"))
+
+	var parsed struct {
+		Questions []string `json:"questions"`
+	}
+	candidates := strings.Split(reply, "\n")
+	if err := json.Unmarshal([]byte(reply), &parsed); err == nil {
+		candidates = parsed.Questions
+	}
+
+	var questions []string
+	for _, question := range candidates {
+		question = strings.TrimSpace(strings.TrimLeft(question, "-*•0123456789.) \t"))
+		if question == "" || strings.ContainsAny(question, "{}") {
+			continue
+		}
+		questions = append(questions, question)
+		if len(questions) == maxFollowUps {
+			break
+		}
+	}
+	return questions
+}
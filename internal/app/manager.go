@@ -1,6 +1,8 @@
 package app
 
 import (
+	"log"
+	"sort"
 	"sync"
 	"time"
 
@@ -10,58 +12,177 @@ import (
 // SessionManager handles creation and lifecycle of chat sessions
 type SessionManager interface {
 	CreateSession(userID string) (*ChatSession, error)
+	CreateSessionForModel(userID string, llmConfig backend.LLMConfig) (*ChatSession, error)
+	CreateTenantSession(userID, tenant string) (*ChatSession, error)
 	GetSession(sessionID string) (*ChatSession, error)
 	CloseSession(sessionID string) error
 	CleanupExpiredSessions() int
+	SaveSnapshot(path string) error
+	RestoreSnapshot(path string) (int, error)
+	ListSessions() []SessionInfo
+	DailyCost() (spent, limit float64)
+	TenantUsage() []TenantUsage
+	Reload(llmConfig backend.LLMConfig, budgetConfig backend.TokenBudgetConfig, opts SessionOptions)
+}
+
+// SessionInfo summarizes a managed session for operators
+type SessionInfo struct {
+	ID         string
+	UserID     string
+	Tenant     string        // Empty outside multi-tenant use
+	Age        time.Duration // Since the session started
+	Idle       time.Duration // Since it was last used
+	Requests   int
+	Tokens     int
+	Cost       float64
+	OverBudget bool
 }
 
 // InMemorySessionManager implements SessionManager with in-memory storage
 type InMemorySessionManager struct {
-	sessions     map[string]*ChatSession
-	sessionAge   map[string]time.Time
-	mutex        sync.RWMutex
-	llmConfig    backend.LLMConfig
-	budgetConfig backend.TokenBudgetConfig
-	maxAge       time.Duration
+	sessions         map[string]*ChatSession
+	sessionAge       map[string]time.Time
+	mutex            sync.RWMutex
+	llmConfig        backend.LLMConfig
+	budgetConfig     backend.TokenBudgetConfig
+	maxAge           time.Duration
+	maxSessions      int          // 0 for no limit
+	costCeiling      *CostCeiling // Shared by every session; nil without MaxDailyCost
+	conversationType string
+	opts             SessionOptions
+	tenants          TenantResolver          // nil until SetTenantResolver
+	tenantCeilings   map[string]*CostCeiling // Each tenant's own daily cost limit, for those with one
+	generation       int                     // Incremented by Reload
 }
 
 // NewInMemorySessionManager creates a new session manager
-func NewInMemorySessionManager(llmConfig backend.LLMConfig, budgetConfig backend.TokenBudgetConfig, maxAge time.Duration) *InMemorySessionManager {
+// whose sessions are tagged with conversationType (e.g. "web", "slack")
+func NewInMemorySessionManager(llmConfig backend.LLMConfig, budgetConfig backend.TokenBudgetConfig, maxAge time.Duration, conversationType string, opts SessionOptions) *InMemorySessionManager {
+	var costCeiling *CostCeiling
+	if opts.MaxDailyCost > 0 {
+		costCeiling = NewCostCeiling(opts.MaxDailyCost, opts.Notifier)
+	}
+
 	return &InMemorySessionManager{
-		sessions:     make(map[string]*ChatSession),
-		sessionAge:   make(map[string]time.Time),
-		llmConfig:    llmConfig,
-		budgetConfig: budgetConfig,
-		maxAge:       maxAge,
+		sessions:         make(map[string]*ChatSession),
+		sessionAge:       make(map[string]time.Time),
+		llmConfig:        llmConfig,
+		budgetConfig:     budgetConfig,
+		maxAge:           maxAge,
+		conversationType: conversationType,
+		opts:             opts,
+		costCeiling:      costCeiling,
+		tenantCeilings:   make(map[string]*CostCeiling),
 	}
 }
 
+// SetMaxSessions caps the number of concurrent sessions. Once the cap is reached, adding
+// a session evicts the least recently used one. 0 removes the limit.
+func (sm *InMemorySessionManager) SetMaxSessions(max int) {
+	sm.mutex.Lock()
+	sm.maxSessions = max
+	sm.mutex.Unlock()
+}
+
 // CreateSession creates a new chat session for a user
 func (sm *InMemorySessionManager) CreateSession(userID string) (*ChatSession, error) {
-	sessionID := GenerateSessionID(userID)
-
-	config := SessionConfig{
-		ID:               sessionID,
-		ConversationType: "web",
-		SystemPrompt:     "You are ChatGBT, a helpful AI assistant.",
-		LLMConfig:        sm.llmConfig,
-		BudgetConfig:     sm.budgetConfig,
-		MaxTokens:        8000,
-		KeepRecent:       10,
-		SummaryEnabled:   true,
+	session, err := sm.CreateSessionForModel(userID, sm.defaultLLMConfig())
+	if err == nil {
+		session.defaultModel = true
 	}
+	return session, err
+}
+
+// CreateSessionForModel creates a new chat session for a user that talks to a specific provider and model
+func (sm *InMemorySessionManager) CreateSessionForModel(userID string, llmConfig backend.LLMConfig) (*ChatSession, error) {
+	sessionID := GenerateSessionID(userID)
 
+	config, generation := sm.sessionConfig(sessionID, llmConfig)
+	config.UserID = userID
 	session, err := NewChatSession(config)
 	if err != nil {
 		return nil, NewSessionError("failed to create session", sessionID, err)
 	}
+	session.generation, session.typePrompt = generation, config.SystemPrompt
 
+	sm.add(session, time.Now())
+	return session, nil
+}
+
+// defaultLLMConfig returns the provider and model sessions are created with
+func (sm *InMemorySessionManager) defaultLLMConfig() backend.LLMConfig {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+	return sm.llmConfig
+}
+
+// add starts managing session, last used at lastAccess, making room for it under the
+// session limit
+func (sm *InMemorySessionManager) add(session *ChatSession, lastAccess time.Time) {
 	sm.mutex.Lock()
-	sm.sessions[sessionID] = session
-	sm.sessionAge[sessionID] = time.Now()
-	sm.mutex.Unlock()
+	defer sm.mutex.Unlock()
+	sm.evictForNewSession()
+	sm.sessions[session.ID] = session
+	sm.sessionAge[session.ID] = lastAccess
+}
 
-	return session, nil
+// evictForNewSession closes least recently used sessions until there is room for one
+// more under maxSessions. Callers must hold the write lock.
+func (sm *InMemorySessionManager) evictForNewSession() {
+	if sm.maxSessions <= 0 {
+		return
+	}
+
+	for len(sm.sessions) >= sm.maxSessions {
+		var oldestID string
+		var oldest time.Time
+		for id, lastAccess := range sm.sessionAge {
+			if oldestID == "" || lastAccess.Before(oldest) {
+				oldestID, oldest = id, lastAccess
+			}
+		}
+		if oldestID == "" {
+			return
+		}
+
+		if session, exists := sm.sessions[oldestID]; exists {
+			session.Close() // Best effort cleanup
+		}
+		delete(sm.sessions, oldestID)
+		delete(sm.sessionAge, oldestID)
+		log.Printf("Session limit of %d reached; evicted session %s idle since %s",
+			sm.maxSessions, oldestID, oldest.Format(time.RFC3339))
+	}
+}
+
+// sessionConfig returns the configuration of a new managed session, and the generation
+// of the settings it was built from
+func (sm *InMemorySessionManager) sessionConfig(sessionID string, llmConfig backend.LLMConfig) (SessionConfig, int) {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+
+	config := SessionConfig{
+		ID:               sessionID,
+		ConversationType: sm.conversationType,
+		LLMConfig:        llmConfig,
+		BudgetConfig:     sm.budgetConfig,
+		FewShot:          sm.opts.FewShot,
+		Notifier:         sm.opts.Notifier,
+		FailureThreshold: sm.opts.FailureThreshold,
+		Tools:            sm.opts.Tools,
+		Classifier:       sm.opts.Classifier,
+		Language:         sm.opts.Language,
+		AutoLanguage:     sm.opts.AutoLanguage,
+		MaxMessageLength: sm.opts.MaxMessageLength,
+		CostCeiling:      sm.costCeiling,
+		Router:           sm.opts.Router,
+		FollowUps:        sm.opts.FollowUps,
+		AutoContinue:     sm.opts.AutoContinue,
+		MaxQueued:        sm.opts.MaxQueued,
+		InjectionGuard:   sm.opts.InjectionGuard,
+	}
+	sm.opts.conversationDefaults(sm.conversationType).apply(&config)
+	return config, sm.generation
 }
 
 // GetSession retrieves an existing session
@@ -79,6 +200,7 @@ func (sm *InMemorySessionManager) GetSession(sessionID string) (*ChatSession, er
 	sm.sessionAge[sessionID] = time.Now()
 	sm.mutex.Unlock()
 
+	sm.refresh(session)
 	return session, nil
 }
 
@@ -101,6 +223,38 @@ func (sm *InMemorySessionManager) CloseSession(sessionID string) error {
 	return nil
 }
 
+// ListSessions returns every session, most recently used first
+func (sm *InMemorySessionManager) ListSessions() []SessionInfo {
+	sm.mutex.RLock()
+	defer sm.mutex.RUnlock()
+
+	now := time.Now()
+	infos := make([]SessionInfo, 0, len(sm.sessions))
+	for id, session := range sm.sessions {
+		summary := session.GetSessionSummary()
+		budget := session.GetBudgetStatus()
+		infos = append(infos, SessionInfo{
+			ID:         id,
+			UserID:     session.UserID,
+			Tenant:     session.Tenant,
+			Age:        summary.Duration,
+			Idle:       now.Sub(sm.sessionAge[id]),
+			Requests:   summary.TotalRequests,
+			Tokens:     budget.SessionTokens,
+			Cost:       budget.SessionCost,
+			OverBudget: budget.OverBudget,
+		})
+	}
+	sort.Slice(infos, func(i, j int) bool { return infos[i].Idle < infos[j].Idle })
+	return infos
+}
+
+// DailyCost returns what all sessions have spent today (UTC) and the MaxDailyCost
+// limit, which is 0 when there is none
+func (sm *InMemorySessionManager) DailyCost() (spent, limit float64) {
+	return sm.costCeiling.Spent()
+}
+
 // CleanupExpiredSessions removes sessions older than maxAge
 func (sm *InMemorySessionManager) CleanupExpiredSessions() int {
 	sm.mutex.Lock()
@@ -0,0 +1,41 @@
+package app
+
+import (
+	"context"
+
+	"github.com/nleiva/chatgbt/pkg/backend"
+)
+
+// Overrides change the model and sampling of a single request, in place of the
+// session's defaults. Unset fields keep the defaults.
+type Overrides struct {
+	Model       string   // Model of the session's provider; skips routing
+	Temperature *float64 // Sampling temperature between 0 and 2
+	MaxTokens   *int     // Longest reply; the budget preflight may still lower it
+}
+
+// overridesKey is the context key under which WithOverrides stores the overrides
+type overridesKey struct{}
+
+// WithOverrides returns a context whose requests to the model, through the session
+// methods that take one, use overrides
+func WithOverrides(ctx context.Context, overrides Overrides) context.Context {
+	return context.WithValue(ctx, overridesKey{}, overrides)
+}
+
+// OverridesFromContext returns the overrides stored by WithOverrides, or none
+func OverridesFromContext(ctx context.Context) Overrides {
+	overrides, _ := ctx.Value(overridesKey{}).(Overrides)
+	return overrides
+}
+
+// apply sets the overridden fields of req; temperature, when not nil, is an explicit
+// choice of the caller and wins over the override
+func (o Overrides) apply(req *backend.ChatCompletionRequest, temperature *float64) {
+	req.Model = o.Model
+	req.MaxTokens = o.MaxTokens
+	req.Temperature = temperature
+	if temperature == nil {
+		req.Temperature = o.Temperature
+	}
+}
@@ -0,0 +1,73 @@
+package app
+
+import (
+	"fmt"
+
+	"github.com/nleiva/chatgbt/pkg/backend"
+)
+
+const (
+	// defaultCompletionEstimate is the reply length assumed when a request sets no max_tokens
+	defaultCompletionEstimate = 4096
+
+	// minPreflightCompletion is the shortest reply worth asking for; with less budget left
+	// than this the request is refused
+	minPreflightCompletion = 64
+)
+
+// BudgetExceededError is returned before a request is sent when the session's remaining
+// token budget can't cover the prompt and a useful reply
+type BudgetExceededError struct {
+	PromptTokens int // Estimated tokens in the context that would be sent
+	Remaining    int // Tokens left in the session budget
+}
+
+func (e *BudgetExceededError) Error() string {
+	return fmt.Sprintf("only %d tokens remain in the session budget, not enough for the %d-token prompt and a reply; "+
+		"prune or reset the conversation to continue", e.Remaining, e.PromptTokens)
+}
+
+// preflight checks req against the remaining session budget before it is sent. When the
+// prompt plus the largest possible reply wouldn't fit, it lowers req.MaxTokens to what
+// is left and returns the new limit; when not even a short reply fits, it returns a
+// *BudgetExceededError. Sessions without a token limit are never checked.
+func (s *ChatSession) preflight(req *backend.ChatCompletionRequest, client LLMClient) (int, error) {
+	return preflight(s.Logger, req, client)
+}
+
+// preflight checks req against the session budget tracked by logger
+func preflight(logger Logger, req *backend.ChatCompletionRequest, client LLMClient) (int, error) {
+	status := logger.GetBudgetStatus()
+	if status.SessionLimit <= 0 {
+		return 0, nil
+	}
+
+	_, model := clientModelInfo(client, nil)
+	promptTokens := countTokens(model, req.Messages)
+
+	remaining := status.SessionLimit - status.SessionTokens
+	available := remaining - promptTokens
+	if available < minPreflightCompletion {
+		return 0, &BudgetExceededError{PromptTokens: promptTokens, Remaining: max(remaining, 0)}
+	}
+
+	completion := defaultCompletionEstimate
+	if req.MaxTokens != nil {
+		completion = *req.MaxTokens
+	}
+	if completion <= available {
+		return 0, nil
+	}
+	req.MaxTokens = &available
+	return available, nil
+}
+
+// countTokens returns the estimated number of tokens messages take up with model's tokenizer
+func countTokens(model string, messages []backend.Message) int {
+	counts, _ := backend.CountMessageTokens(model, messages)
+	total := 0
+	for _, mt := range counts {
+		total += mt.Tokens
+	}
+	return total
+}
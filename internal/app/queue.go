@@ -0,0 +1,94 @@
+package app
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/nleiva/chatgbt/pkg/backend"
+)
+
+// GenerationInProgressError is returned for a request that arrives while the session is
+// generating a reply and no more requests may wait for it
+type GenerationInProgressError struct {
+	Queued int // Requests already waiting for the reply in progress
+}
+
+func (e *GenerationInProgressError) Error() string {
+	if e.Queued > 0 {
+		return fmt.Sprintf("a reply is already being generated in this session and %d more requests are waiting; try again once they finish", e.Queued)
+	}
+	return "a reply is already being generated in this session; try again once it finishes"
+}
+
+// startGeneration waits for the replies requested earlier to finish, then locks the
+// session and returns the context to generate the reply with, which Abort cancels.
+// Up to MaxQueued requests wait behind the one in progress; with more, or when ctx
+// ends while waiting, it returns an error and leaves the session unlocked.
+func (s *ChatSession) startGeneration(ctx context.Context) (context.Context, error) {
+	s.settingsMutex.RLock()
+	maxQueued := s.MaxQueued
+	s.settingsMutex.RUnlock()
+
+	if n := int(s.pending.Add(1)); n > maxQueued+1 {
+		s.pending.Add(-1)
+		return nil, &GenerationInProgressError{Queued: n - 2}
+	}
+
+	select {
+	case s.generating <- struct{}{}:
+	case <-ctx.Done():
+		s.pending.Add(-1)
+		return nil, ctx.Err()
+	}
+	s.mutex.Lock()
+
+	s.settingsMutex.Lock()
+	s.replyContext = append([]backend.Message{}, s.Messages...)
+	s.settingsMutex.Unlock()
+
+	ctx, cancel := context.WithCancel(ctx)
+	s.abortMutex.Lock()
+	s.abort = cancel
+	s.abortMutex.Unlock()
+	return ctx, nil
+}
+
+// endGeneration unlocks the session and lets the next queued request start
+func (s *ChatSession) endGeneration() {
+	s.abortMutex.Lock()
+	s.abort()
+	s.abort = nil
+	s.abortMutex.Unlock()
+
+	s.settingsMutex.Lock()
+	s.replyContext = nil
+	s.settingsMutex.Unlock()
+
+	s.mutex.Unlock()
+	<-s.generating
+	s.pending.Add(-1)
+}
+
+// Abort stops the reply being generated, if any, and reports whether there was one.
+// Text already streamed is kept in the conversation as a truncated reply, with the
+// tokens it used; a reply not streamed yet fails as canceled. Requests queued behind
+// it are unaffected.
+func (s *ChatSession) Abort() bool {
+	s.abortMutex.Lock()
+	defer s.abortMutex.Unlock()
+	if s.abort == nil {
+		return false
+	}
+	s.abort()
+	return true
+}
+
+// Queued returns how many requests are waiting for the reply being generated
+func (s *ChatSession) Queued() int {
+	return max(int(s.pending.Load())-1, 0)
+}
+
+// Generating reports whether a reply is being generated or about to start
+func (s *ChatSession) Generating() bool {
+	return len(s.generating) > 0
+}
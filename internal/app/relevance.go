@@ -0,0 +1,46 @@
+package app
+
+import (
+	"context"
+
+	"github.com/nleiva/chatgbt/pkg/backend"
+)
+
+// maxRelevanceChars trims the texts sent for embedding, well under the input limit of
+// embedding models; the start of an exchange carries most of its topic
+const maxRelevanceChars = 8000
+
+// EmbeddingRelevance scores older exchanges by the cosine similarity of their embeddings
+// to the embedding of the latest user message, for relevance pruning
+type EmbeddingRelevance struct {
+	embedder backend.Embedder
+}
+
+// NewEmbeddingRelevance creates a relevance scorer backed by embedder
+func NewEmbeddingRelevance(embedder backend.Embedder) *EmbeddingRelevance {
+	return &EmbeddingRelevance{embedder: embedder}
+}
+
+// Score implements backend.RelevanceScorer, embedding the query and texts in one request
+func (r *EmbeddingRelevance) Score(query string, texts []string) ([]float64, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), embeddingTimeout)
+	defer cancel()
+
+	inputs := make([]string, 0, len(texts)+1)
+	for _, text := range append([]string{query}, texts...) {
+		if runes := []rune(text); len(runes) > maxRelevanceChars {
+			text = string(runes[:maxRelevanceChars])
+		}
+		inputs = append(inputs, text)
+	}
+
+	vectors, err := r.embedder.Embed(ctx, inputs)
+	if err != nil {
+		return nil, err
+	}
+	scores := make([]float64, len(texts))
+	for i := range texts {
+		scores[i] = cosineSimilarity(vectors[0], vectors[i+1])
+	}
+	return scores, nil
+}
@@ -0,0 +1,88 @@
+package app
+
+import (
+	"log"
+	"time"
+
+	"github.com/nleiva/chatgbt/pkg/backend"
+	"github.com/nleiva/chatgbt/pkg/llm"
+)
+
+// Reload replaces the model, budgets, and options sessions are created with. Sessions
+// already running take them on at their next request (see GetSession), keeping their
+// conversation. Those still using their conversation type's system prompt take on its
+// new one; a prompt chosen in the session is kept. The daily cost limits stay those the
+// manager was created with.
+func (sm *InMemorySessionManager) Reload(llmConfig backend.LLMConfig, budgetConfig backend.TokenBudgetConfig, opts SessionOptions) {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	opts.MaxDailyCost = sm.opts.MaxDailyCost
+	opts.TenantDailyCost = sm.opts.TenantDailyCost
+	sm.llmConfig = llmConfig
+	sm.budgetConfig = budgetConfig
+	sm.opts = opts
+	sm.generation++
+}
+
+// budgetSetter is implemented by loggers whose budget can change, such as the metrics logger
+type budgetSetter interface {
+	SetBudget(budgetCfg backend.TokenBudgetConfig)
+}
+
+// refresh brings session up to date with settings reloaded since it was created or
+// last refreshed, including the context limits, which follow its model's context
+// window. A session busy with a request is left for its next one.
+func (sm *InMemorySessionManager) refresh(session *ChatSession) {
+	if !session.mutex.TryLock() {
+		return
+	}
+	defer session.mutex.Unlock()
+
+	sm.mutex.RLock()
+	current := session.generation == sm.generation
+	sm.mutex.RUnlock()
+	if current {
+		return
+	}
+
+	config, generation := sm.sessionConfig(session.ID, sm.defaultLLMConfig())
+	if session.Tenant != "" {
+		if err := sm.applyTenant(&config, session.Tenant); err != nil {
+			log.Printf("Warning: session %s keeps its settings: %v", session.ID, err)
+			return
+		}
+	}
+
+	// Sessions comparing other models keep them
+	client := session.LLMClient
+	if session.defaultModel {
+		var err error
+		if client, err = llm.NewClient(config.LLMConfig, 30*time.Second); err != nil {
+			log.Printf("Warning: session %s keeps its model: %v", session.ID, err)
+			return
+		}
+	}
+	if l, ok := session.Logger.(budgetSetter); ok {
+		l.SetBudget(config.BudgetConfig)
+	}
+
+	// Everything is built before settingsMutex is taken, as its readers may hold sm.mutex
+	session.settingsMutex.Lock()
+	defer session.settingsMutex.Unlock()
+	session.LLMClient = client
+	session.FewShot = config.FewShot[session.ConversationType]
+	session.Router = config.Router
+	session.MaxMessageLength = config.MaxMessageLength
+	session.FollowUps = config.FollowUps
+	session.AutoContinue = config.AutoContinue
+	session.MaxQueued = config.MaxQueued
+	session.InjectionGuard = config.InjectionGuard
+	session.ContextManager = newContextManager(config, clientCapabilities(client))
+	session.generation = generation
+
+	if session.SystemPrompt == session.typePrompt && config.SystemPrompt != session.typePrompt {
+		session.replaceSystemPrompt(config.SystemPrompt)
+	}
+	session.typePrompt = config.SystemPrompt
+}
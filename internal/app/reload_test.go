@@ -0,0 +1,103 @@
+package app
+
+import (
+	"testing"
+	"time"
+
+	"github.com/nleiva/chatgbt/pkg/backend"
+	"github.com/nleiva/chatgbt/pkg/store"
+)
+
+func TestReloadUpdatesLiveSessions(t *testing.T) {
+	llmConfig := backend.LLMConfig{Provider: backend.ProviderNameOpenAI, APIKey: "test", Model: "model-a"}
+	budgetConfig := backend.DefaultBudgetConfig()
+	budgetConfig.LogsDir = t.TempDir()
+	opts := SessionOptions{
+		Store:            store.NewFileStore(t.TempDir()),
+		MaxMessageLength: 100,
+		Conversations:    map[string]ConversationDefaults{"web": {SystemPrompt: "old prompt", MaxTokens: 1000}},
+	}
+	sm := NewInMemorySessionManager(llmConfig, budgetConfig, time.Hour, "web", opts)
+
+	defaultSession, err := sm.CreateSession("u1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	chosenPrompt, err := sm.CreateSession("u2")
+	if err != nil {
+		t.Fatal(err)
+	}
+	chosenPrompt.UpdateSystemPrompt("my prompt")
+	otherModel, err := sm.CreateSessionForModel("u3", ConfigForModel(llmConfig, "", "model-x"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reloaded := opts
+	reloaded.MaxMessageLength = 200
+	reloaded.MaxQueued = 2
+	reloaded.InjectionGuard = backend.InjectionGuardStrip
+	reloaded.Conversations = map[string]ConversationDefaults{"web": {SystemPrompt: "new prompt", MaxTokens: 2000}}
+	sm.Reload(ConfigForModel(llmConfig, "", "model-b"), budgetConfig, reloaded)
+
+	tests := []struct {
+		name       string
+		session    *ChatSession
+		wantModel  string
+		wantPrompt string
+	}{
+		{name: "default settings", session: defaultSession, wantModel: "model-b", wantPrompt: "new prompt"},
+		{name: "chosen prompt", session: chosenPrompt, wantModel: "model-b", wantPrompt: "my prompt"},
+		{name: "chosen model", session: otherModel, wantModel: "model-x", wantPrompt: "new prompt"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			session, err := sm.GetSession(tt.session.ID)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, model := clientModelInfo(session.LLMClient, nil); model != tt.wantModel {
+				t.Errorf("model = %q, want %q", model, tt.wantModel)
+			}
+			if session.SystemPrompt != tt.wantPrompt || session.Messages[0].Content != tt.wantPrompt {
+				t.Errorf("system prompt = %q (context %q), want %q", session.SystemPrompt, session.Messages[0].Content, tt.wantPrompt)
+			}
+			if session.MaxMessageLength != 200 || session.MaxQueued != 2 || session.InjectionGuard != backend.InjectionGuardStrip {
+				t.Errorf("MaxMessageLength, MaxQueued, InjectionGuard = %d, %d, %v; want 200, 2, strip",
+					session.MaxMessageLength, session.MaxQueued, session.InjectionGuard)
+			}
+			if limit := session.GetContextStats().TokenLimit; limit != 2000 {
+				t.Errorf("context token limit = %d, want 2000", limit)
+			}
+		})
+	}
+}
+
+func TestReloadWaitsForBusySession(t *testing.T) {
+	llmConfig := backend.LLMConfig{Provider: backend.ProviderNameOpenAI, APIKey: "test", Model: "model-a"}
+	budgetConfig := backend.DefaultBudgetConfig()
+	budgetConfig.LogsDir = t.TempDir()
+	opts := SessionOptions{Store: store.NewFileStore(t.TempDir()), MaxMessageLength: 100}
+	sm := NewInMemorySessionManager(llmConfig, budgetConfig, time.Hour, "web", opts)
+
+	session, err := sm.CreateSession("u1")
+	if err != nil {
+		t.Fatal(err)
+	}
+	reloaded := opts
+	reloaded.MaxMessageLength = 200
+	sm.Reload(llmConfig, budgetConfig, reloaded)
+
+	// A session generating a reply keeps its settings until its next request
+	session.mutex.Lock()
+	sm.GetSession(session.ID)
+	session.mutex.Unlock()
+	if session.MaxMessageLength != 100 {
+		t.Errorf("busy session MaxMessageLength = %d, want 100", session.MaxMessageLength)
+	}
+
+	sm.GetSession(session.ID)
+	if session.MaxMessageLength != 200 {
+		t.Errorf("MaxMessageLength after the next request = %d, want 200", session.MaxMessageLength)
+	}
+}
@@ -0,0 +1,342 @@
+package app
+
+import (
+	"context"
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nleiva/chatgbt/pkg/backend"
+)
+
+// reviewPrompt asks for findings in a line-oriented format that can be merged across chunks
+const reviewPrompt = `You are a meticulous senior engineer reviewing a code change.
+Report only real problems: bugs, security issues, race conditions, error handling gaps,
+performance traps, and maintainability concerns. Skip praise and restating the change.
+
+Write one finding per line in exactly this format:
+[SEVERITY] path:line - description
+
+SEVERITY is one of CRITICAL, MAJOR, MINOR, NIT. Use the new-file line number from the hunk
+header, or omit ":line" when it doesn't apply. If there is nothing worth reporting, reply with NONE.`
+
+// DefaultReviewChunkTokens is the token budget for the diff sent in each review request
+const DefaultReviewChunkTokens = 6000
+
+// Severity ranks review findings
+type Severity string
+
+const (
+	SeverityCritical Severity = "CRITICAL" // Bugs, security holes, or data loss that must be fixed
+	SeverityMajor    Severity = "MAJOR"    // Likely defects or significant design problems
+	SeverityMinor    Severity = "MINOR"    // Small correctness or maintainability issues
+	SeverityNit      Severity = "NIT"      // Style and naming suggestions
+)
+
+// severityOrder lists severities from most to least severe
+var severityOrder = []Severity{SeverityCritical, SeverityMajor, SeverityMinor, SeverityNit}
+
+// findingPattern matches "[SEVERITY] location - description", tolerating list markers and bold text
+var findingPattern = regexp.MustCompile(`^(?:[-*]\s*)?\**\[(CRITICAL|MAJOR|MINOR|NIT)\]\**\s*(\S+)\s+[-–—:]\s+(.+)$`)
+
+// ReviewFinding is a single issue reported by the model
+type ReviewFinding struct {
+	Severity    Severity
+	Location    string // path or path:line
+	Description string
+}
+
+// ReviewReport merges the findings from every chunk of a diff
+type ReviewReport struct {
+	Source       string
+	Chunks       int
+	Findings     []ReviewFinding
+	Unparsed     []string // Lines the model returned outside the finding format
+	FailedChunks int
+	Usage        backend.Usage
+	ResponseTime time.Duration
+}
+
+// Reviewer reviews diffs chunk by chunk
+type Reviewer struct {
+	client      LLMClient
+	logger      Logger
+	chunkTokens int
+}
+
+// NewReviewer creates a reviewer that sends at most chunkTokens of diff per request.
+// chunkTokens <= 0 uses DefaultReviewChunkTokens.
+func NewReviewer(client LLMClient, logger Logger, chunkTokens int) *Reviewer {
+	if chunkTokens <= 0 {
+		chunkTokens = DefaultReviewChunkTokens
+	}
+	return &Reviewer{client: client, logger: logger, chunkTokens: chunkTokens}
+}
+
+// Review splits diff into chunks, reviews each one, and merges the findings.
+// onChunk, if not nil, is called before each chunk is sent.
+func (r *Reviewer) Review(ctx context.Context, source, diff string, onChunk func(index, total int)) (*ReviewReport, error) {
+	if strings.TrimSpace(diff) == "" {
+		return nil, fmt.Errorf("nothing to review: the diff is empty")
+	}
+
+	_, model := clientModelInfo(r.client, nil)
+	chunks := ChunkDiff(diff, r.chunkTokens, func(text string) int {
+		tokens, _ := backend.CountTokens(model, text)
+		return tokens
+	})
+
+	report := &ReviewReport{Source: source, Chunks: len(chunks)}
+	var lastErr error
+	for i, chunk := range chunks {
+		if onChunk != nil {
+			onChunk(i+1, len(chunks))
+		}
+		reply, err := r.reviewChunk(ctx, chunk, i+1, len(chunks), report)
+		if err != nil {
+			report.FailedChunks++
+			lastErr = err
+			continue
+		}
+		findings, unparsed := ParseFindings(reply)
+		report.Findings = append(report.Findings, findings...)
+		report.Unparsed = append(report.Unparsed, unparsed...)
+	}
+
+	if report.FailedChunks == len(chunks) {
+		return nil, fmt.Errorf("every review request failed: %w", lastErr)
+	}
+
+	sort.SliceStable(report.Findings, func(i, j int) bool {
+		return severityRank(report.Findings[i].Severity) < severityRank(report.Findings[j].Severity)
+	})
+	return report, nil
+}
+
+// reviewChunk sends one chunk and records its usage in the report
+func (r *Reviewer) reviewChunk(ctx context.Context, chunk string, index, total int, report *ReviewReport) (string, error) {
+	req := &backend.ChatCompletionRequest{
+		Messages: []backend.Message{
+			{Role: backend.RoleSystem, Content: reviewPrompt},
+			{Role: backend.RoleUser, Content: fmt.Sprintf("Review part %d of %d of this diff:\n\n%s", index, total, chunk)},
+		},
+	}
+
+	start := time.Now()
+	resp, err := r.client.CreateCompletion(ctx, req)
+	responseTime := time.Since(start)
+	report.ResponseTime += responseTime
+
+	provider, model := clientModelInfo(r.client, resp)
+	interaction := backend.InteractionLog{
+		ResponseTime: responseTime,
+		Success:      err == nil,
+		ErrorType:    getErrorType(err),
+		PromptType:   "code_review",
+		Provider:     provider,
+		Model:        model,
+	}
+	if err == nil {
+		interaction.Usage = resp.Usage
+	}
+	r.logger.LogInteraction(interaction)
+
+	if err != nil {
+		return "", err
+	}
+	if resp.Usage != nil {
+		report.Usage.PromptTokens += resp.Usage.PromptTokens
+		report.Usage.CompletionTokens += resp.Usage.CompletionTokens
+		report.Usage.TotalTokens += resp.Usage.TotalTokens
+	}
+	if len(resp.Choices) == 0 {
+		return "", fmt.Errorf("provider returned no choices")
+	}
+	return resp.Choices[0].Message.Content, nil
+}
+
+// ChunkDiff splits a unified diff into pieces of at most maxTokens as measured by count.
+// Files are kept whole when they fit; larger files are split at hunk boundaries, and
+// oversized hunks by lines. Each piece of a split file repeats the file header.
+func ChunkDiff(diff string, maxTokens int, count func(string) int) []string {
+	var chunks []string
+	var current strings.Builder
+	currentTokens := 0
+
+	flush := func() {
+		if current.Len() > 0 {
+			chunks = append(chunks, current.String())
+			current.Reset()
+			currentTokens = 0
+		}
+	}
+	// add appends piece, starting a new chunk when it doesn't fit; header is written
+	// at the start of every chunk so split files keep their file names
+	add := func(header, piece string) {
+		tokens := count(piece)
+		if currentTokens > 0 && currentTokens+tokens > maxTokens {
+			flush()
+		}
+		if current.Len() == 0 && header != "" {
+			current.WriteString(header)
+			currentTokens += count(header)
+		}
+		current.WriteString(piece)
+		currentTokens += tokens
+	}
+
+	for _, file := range splitDiffFiles(diff) {
+		if count(file) <= maxTokens {
+			add("", file)
+			continue
+		}
+
+		header, hunks := splitHunks(file)
+		flush()
+		for _, hunk := range hunks {
+			for _, piece := range splitLines(hunk, maxTokens-count(header), count) {
+				add(header, piece)
+			}
+		}
+		flush()
+	}
+	flush()
+	return chunks
+}
+
+// splitDiffFiles splits a diff at "diff --git" lines; input without them is one piece
+func splitDiffFiles(diff string) []string {
+	return splitBefore(diff, func(line string) bool { return strings.HasPrefix(line, "diff --git ") })
+}
+
+// splitHunks separates a file diff's header from its "@@" hunks
+func splitHunks(file string) (string, []string) {
+	pieces := splitBefore(file, func(line string) bool { return strings.HasPrefix(line, "@@") })
+	if len(pieces) > 0 && !strings.HasPrefix(pieces[0], "@@") {
+		return pieces[0], pieces[1:]
+	}
+	return "", pieces
+}
+
+// splitBefore splits text into pieces that each start at a line matching isStart
+func splitBefore(text string, isStart func(string) bool) []string {
+	var pieces []string
+	var current strings.Builder
+	for _, line := range strings.SplitAfter(text, "\n") {
+		if line == "" {
+			continue
+		}
+		if isStart(line) && current.Len() > 0 {
+			pieces = append(pieces, current.String())
+			current.Reset()
+		}
+		current.WriteString(line)
+	}
+	if current.Len() > 0 {
+		pieces = append(pieces, current.String())
+	}
+	return pieces
+}
+
+// splitLines breaks text into pieces of at most maxTokens, cutting only between lines
+func splitLines(text string, maxTokens int, count func(string) int) []string {
+	if maxTokens <= 0 || count(text) <= maxTokens {
+		return []string{text}
+	}
+
+	var pieces []string
+	var current strings.Builder
+	currentTokens := 0
+	for _, line := range strings.SplitAfter(text, "\n") {
+		tokens := count(line)
+		if currentTokens > 0 && currentTokens+tokens > maxTokens {
+			pieces = append(pieces, current.String())
+			current.Reset()
+			currentTokens = 0
+		}
+		current.WriteString(line)
+		currentTokens += tokens
+	}
+	if current.Len() > 0 {
+		pieces = append(pieces, current.String())
+	}
+	return pieces
+}
+
+// ParseFindings extracts findings from a review reply. Lines that look like content but
+// don't follow the format are returned separately so they aren't silently lost.
+func ParseFindings(reply string) ([]ReviewFinding, []string) {
+	var findings []ReviewFinding
+	var unparsed []string
+	for _, line := range strings.Split(reply, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || strings.EqualFold(strings.Trim(line, "*."), "NONE") || strings.HasPrefix(line, "```") {
+			continue
+		}
+		match := findingPattern.FindStringSubmatch(line)
+		if match == nil {
+			unparsed = append(unparsed, line)
+			continue
+		}
+		findings = append(findings, ReviewFinding{
+			Severity:    Severity(match[1]),
+			Location:    strings.Trim(match[2], "`"),
+			Description: strings.TrimSpace(match[3]),
+		})
+	}
+	return findings, unparsed
+}
+
+// severityRank orders severities for sorting; unknown values sort last
+func severityRank(severity Severity) int {
+	for i, s := range severityOrder {
+		if s == severity {
+			return i
+		}
+	}
+	return len(severityOrder)
+}
+
+// Markdown renders the report grouped by severity
+func (r *ReviewReport) Markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Code Review: %s\n\n", r.Source)
+
+	counts := make(map[Severity]int)
+	for _, f := range r.Findings {
+		counts[f.Severity]++
+	}
+	fmt.Fprintf(&b, "Reviewed in %d chunk(s)", r.Chunks)
+	if r.FailedChunks > 0 {
+		fmt.Fprintf(&b, " (%d failed and were skipped)", r.FailedChunks)
+	}
+	b.WriteString(".\n\n| Severity | Findings |\n|---|---|\n")
+	for _, severity := range severityOrder {
+		fmt.Fprintf(&b, "| %s | %d |\n", severity, counts[severity])
+	}
+
+	if len(r.Findings) == 0 {
+		b.WriteString("\nNo issues found.\n")
+	}
+	for _, severity := range severityOrder {
+		if counts[severity] == 0 {
+			continue
+		}
+		fmt.Fprintf(&b, "\n## %s\n\n", severity)
+		for _, f := range r.Findings {
+			if f.Severity == severity {
+				fmt.Fprintf(&b, "- **[%s]** `%s` - %s\n", f.Severity, f.Location, f.Description)
+			}
+		}
+	}
+
+	if len(r.Unparsed) > 0 {
+		b.WriteString("\n## Other Notes\n\n")
+		for _, line := range r.Unparsed {
+			fmt.Fprintf(&b, "- %s\n", strings.TrimLeft(line, "-* "))
+		}
+	}
+	return b.String()
+}
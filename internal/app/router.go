@@ -0,0 +1,86 @@
+package app
+
+import (
+	"cmp"
+	"fmt"
+	"slices"
+	"time"
+
+	"github.com/nleiva/chatgbt/pkg/backend"
+	"github.com/nleiva/chatgbt/pkg/llm"
+)
+
+// RouteRule sends the messages that meet every condition it sets to another model.
+// Unset conditions match anything.
+type RouteRule struct {
+	Model        string   // "provider:model", or a model of the default provider
+	PromptTypes  []string // Classifier categories, e.g. "code_help"
+	MinTokens    int      // Smallest estimated context, in tokens, including the new message
+	MaxTokens    int      // Largest estimated context, in tokens
+	BudgetBelow  float64  // Matches when less than this fraction of the session's token budget is left
+	CostPerToken float64  // USD per token of Model; 0 uses the preset's price or the session's
+}
+
+// matches reports whether a message meets every condition of the rule
+func (r RouteRule) matches(promptType string, promptTokens int, budgetLeft float64) bool {
+	if len(r.PromptTypes) > 0 && !slices.Contains(r.PromptTypes, promptType) {
+		return false
+	}
+	if r.MinTokens > 0 && promptTokens < r.MinTokens {
+		return false
+	}
+	if r.MaxTokens > 0 && promptTokens > r.MaxTokens {
+		return false
+	}
+	return r.BudgetBelow <= 0 || budgetLeft < r.BudgetBelow
+}
+
+// Route is where a message is sent and what its tokens cost
+type Route struct {
+	Client       LLMClient
+	CostPerToken float64 // 0 uses the session's price
+}
+
+// Router picks the model of each message from an ordered list of rules; the first rule
+// that matches wins, and messages no rule matches stay on the session's own model.
+// A nil Router routes nothing. It is safe to share between sessions.
+type Router struct {
+	rules  []RouteRule
+	routes []Route // Parallel to rules
+}
+
+// NewRouter creates the clients of every rule's model, deriving their configuration
+// from base like COMPARE_MODEL does
+func NewRouter(base backend.LLMConfig, rules []RouteRule) (*Router, error) {
+	router := &Router{rules: rules}
+	for i, rule := range rules {
+		cfg := ParseModelSpec(base, rule.Model)
+		client, err := llm.NewClient(cfg, 30*time.Second)
+		if err != nil {
+			return nil, fmt.Errorf("failed to create client for route %d (%s): %w", i+1, rule.Model, err)
+		}
+
+		costPerToken := cmp.Or(rule.CostPerToken, presetCostPerToken(cfg.Provider))
+		router.routes = append(router.routes, Route{Client: client, CostPerToken: costPerToken})
+	}
+	return router, nil
+}
+
+// Route returns the route of the first rule matching a message of promptType whose
+// context is promptTokens long, given the session's budget
+func (r *Router) Route(promptType string, promptTokens int, budget backend.BudgetStatus) (Route, bool) {
+	if r == nil {
+		return Route{}, false
+	}
+
+	budgetLeft := 1.0
+	if budget.SessionLimit > 0 {
+		budgetLeft = float64(budget.SessionLimit-budget.SessionTokens) / float64(budget.SessionLimit)
+	}
+	for i, rule := range r.rules {
+		if rule.matches(promptType, promptTokens, budgetLeft) {
+			return r.routes[i], true
+		}
+	}
+	return Route{}, false
+}
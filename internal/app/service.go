@@ -1,12 +1,18 @@
 package app
 
 import (
+	"cmp"
 	"context"
+	"errors"
 	"fmt"
 	"io"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/nleiva/chatgbt/pkg/backend"
+	"github.com/nleiva/chatgbt/pkg/i18n"
+	"github.com/nleiva/chatgbt/pkg/store"
 )
 
 // LLMClient defines the interface for Large Language Model interactions.
@@ -15,6 +21,99 @@ type LLMClient interface {
 	CreateCompletion(ctx context.Context, req *backend.ChatCompletionRequest) (*backend.ChatCompletionResponse, error)
 }
 
+// StreamingLLMClient is implemented by clients that can deliver completions incrementally
+type StreamingLLMClient interface {
+	CreateCompletionStream(ctx context.Context, req *backend.ChatCompletionRequest, onDelta backend.StreamHandler) (*backend.ChatCompletionResponse, error)
+}
+
+// createCompletion runs a completion, streaming when the client supports it.
+// It returns the time to first token, which is zero for non-streamed responses.
+// A stream canceled through ctx after some text arrived returns that text, as a
+// response finished with backend.FinishAborted and estimated usage, along with the error.
+func createCompletion(ctx context.Context, client LLMClient, req *backend.ChatCompletionRequest, onDelta backend.StreamHandler) (*backend.ChatCompletionResponse, time.Duration, error) {
+	streamer, ok := client.(StreamingLLMClient)
+	if !ok || !backend.FeatureEnabled(backend.FeatureStreaming) {
+		resp, err := client.CreateCompletion(ctx, req)
+		if err == nil && onDelta != nil && len(resp.Choices) > 0 {
+			onDelta(resp.Choices[0].Message.Content)
+		}
+		return resp, 0, err
+	}
+
+	start := time.Now()
+	var ttft time.Duration
+	var partial strings.Builder
+	resp, err := streamer.CreateCompletionStream(ctx, req, func(delta string) {
+		if ttft == 0 {
+			ttft = time.Since(start)
+		}
+		partial.WriteString(delta)
+		if onDelta != nil {
+			onDelta(delta)
+		}
+	})
+	if err != nil && partial.Len() > 0 && errors.Is(ctx.Err(), context.Canceled) {
+		return abortedResponse(client, req, partial.String()), ttft, err
+	}
+	return resp, ttft, err
+}
+
+// abortedResponse is the response for a stream stopped after sending partial. The
+// provider never reported usage for it, so the tokens are counted locally.
+func abortedResponse(client LLMClient, req *backend.ChatCompletionRequest, partial string) *backend.ChatCompletionResponse {
+	_, model := clientModelInfo(client, nil)
+	if req.Model != "" {
+		model = req.Model
+	}
+	completionTokens, _ := backend.CountTokens(model, partial)
+	promptTokens := countTokens(model, req.Messages)
+	return &backend.ChatCompletionResponse{
+		Model: model,
+		Choices: []backend.Choice{{
+			Message:      backend.Message{Role: backend.RoleAssistant, Content: partial},
+			FinishReason: backend.FinishAborted,
+		}},
+		Usage: &backend.Usage{
+			PromptTokens:     promptTokens,
+			CompletionTokens: completionTokens,
+			TotalTokens:      promptTokens + completionTokens,
+		},
+	}
+}
+
+// ModelInfo is implemented by clients that can report which provider and model they target
+type ModelInfo interface {
+	ProviderName() string
+	Model() string
+}
+
+// CapabilityReporter is implemented by clients that know what their provider supports
+type CapabilityReporter interface {
+	Capabilities() backend.Capabilities
+}
+
+// clientCapabilities returns what client supports. Clients that don't report their
+// capabilities are assumed to support every feature, with an unknown context window.
+func clientCapabilities(client LLMClient) backend.Capabilities {
+	if reporter, ok := client.(CapabilityReporter); ok {
+		return reporter.Capabilities()
+	}
+	return backend.Capabilities{Streaming: true, Tools: true, Vision: true, JSONMode: true}
+}
+
+// clientModelInfo returns the provider and model to attribute an interaction to,
+// preferring the model reported by the provider response when available
+func clientModelInfo(client LLMClient, resp *backend.ChatCompletionResponse) (provider, model string) {
+	if info, ok := client.(ModelInfo); ok {
+		provider = info.ProviderName()
+		model = info.Model()
+	}
+	if resp != nil && resp.Model != "" {
+		model = resp.Model
+	}
+	return provider, model
+}
+
 // InteractionLogger handles logging of individual interactions
 type InteractionLogger interface {
 	LogInteraction(log backend.InteractionLog)
@@ -40,6 +139,15 @@ type Logger interface {
 	Closer
 }
 
+// Verbosity selects what a direct query prints besides the reply
+type Verbosity int
+
+const (
+	VerbosityNormal  Verbosity = iota // The reply, warnings, and the usage line when enabled
+	VerbosityQuiet                    // The reply only
+	VerbosityVerbose                  // Also request and response metadata, and the phases of each HTTP attempt
+)
+
 // DirectQueryService handles single-query interactions for quick responses.
 // It coordinates between the LLM client, logger, and output writer to process
 // user queries and display results with optional usage statistics.
@@ -47,17 +155,47 @@ type DirectQueryService struct {
 	client LLMClient
 	logger Logger
 	writer io.Writer
+
+	reply       io.Writer // Receives the reply; the writer unless it goes to a file
+	transcript  *store.Transcript
+	verbosity   Verbosity
+	diagnostics io.Writer // Receives warnings and verbose output, keeping them out of the reply
+	endUser     string    // Hashed ID of who is asking; see HashUserID
 }
 
 // NewDirectQueryService creates a new direct query service with the specified dependencies.
 func NewDirectQueryService(client LLMClient, logger Logger, writer io.Writer) *DirectQueryService {
 	return &DirectQueryService{
-		client: client,
-		logger: logger,
-		writer: writer,
+		client:      client,
+		logger:      logger,
+		writer:      writer,
+		reply:       writer,
+		diagnostics: io.Discard,
 	}
 }
 
+// SetReplyWriter sends the reply to w, such as a file, while the usage line still goes
+// to the service's writer
+func (s *DirectQueryService) SetReplyWriter(w io.Writer) {
+	s.reply = w
+}
+
+// SetTranscript appends each answered query to transcript
+func (s *DirectQueryService) SetTranscript(transcript *store.Transcript) {
+	s.transcript = transcript
+}
+
+// SetEndUser sets who is asking; requests carry it hashed, like ChatSession.SetEndUser
+func (s *DirectQueryService) SetEndUser(id string) {
+	s.endUser = HashUserID(id)
+}
+
+// SetVerbosity selects what is printed besides the reply; warnings and verbose output go to diagnostics
+func (s *DirectQueryService) SetVerbosity(verbosity Verbosity, diagnostics io.Writer) {
+	s.verbosity = verbosity
+	s.diagnostics = diagnostics
+}
+
 // Execute performs a direct query and returns the result
 func (s *DirectQueryService) Execute(ctx context.Context, query string, showUsage bool) error {
 	messages := []backend.Message{
@@ -71,30 +209,59 @@ func (s *DirectQueryService) Execute(ctx context.Context, query string, showUsag
 		defer cancel()
 	}
 
+	language := i18n.Detect(query)
 	start := time.Now()
 	// Create completion request
 	req := &backend.ChatCompletionRequest{
 		Messages: messages,
+		User:     s.endUser,
+	}
+	trimmed, err := preflight(s.logger, req, s.client)
+	if err != nil {
+		return err
+	}
+	if trimmed > 0 && s.verbosity != VerbosityQuiet {
+		fmt.Fprintf(s.diagnostics, "Warning: %s\n", i18n.T(language, "budget.trimmed", trimmed))
+	}
+	if s.verbosity == VerbosityVerbose {
+		s.describeRequest(req)
+		ctx = traceRequests(ctx, s.diagnostics)
 	}
 
-	resp, err := s.client.CreateCompletion(ctx, req)
+	// Stream the response straight to the writer as it arrives
+	var writeErr error
+	resp, ttft, err := createCompletion(ctx, s.client, req, func(delta string) {
+		if writeErr == nil {
+			_, writeErr = io.WriteString(s.reply, delta)
+		}
+	})
 	responseTime := time.Since(start)
 
-	var response string
+	// An aborted stream still spent the tokens it sent
 	var usage *backend.Usage
-	if err == nil && len(resp.Choices) > 0 {
-		response = resp.Choices[0].Message.Content
+	if resp != nil {
 		usage = resp.Usage
 	}
+	provider, model := clientModelInfo(s.client, resp)
 
 	if err != nil {
 		s.logger.LogInteraction(backend.InteractionLog{
-			Usage:        nil,
+			Usage:        usage,
 			ResponseTime: responseTime,
 			Success:      false,
 			ErrorType:    err.Error(),
 			PromptType:   "user_query",
+			Language:     language,
+			Provider:     provider,
+			Model:        model,
 		})
+		if ttft > 0 {
+			// Terminate any partially streamed output before reporting the error
+			io.WriteString(s.reply, "\n")
+		}
+		if s.verbosity == VerbosityVerbose {
+			fmt.Fprintf(s.diagnostics, "< failed after %v\n", responseTime.Round(time.Millisecond))
+		}
 		return err
 	}
 
@@ -104,22 +271,95 @@ func (s *DirectQueryService) Execute(ctx context.Context, query string, showUsag
 		Success:      true,
 		ErrorType:    "",
 		PromptType:   "user_query",
+		Language:     language,
+		Provider:     provider,
+		Model:        model,
+		TTFT:         ttft,
 	})
 
-	// Print the response
-	if _, writeErr := s.writer.Write([]byte(response + "\n")); writeErr != nil {
+	// Terminate the streamed response
+	if writeErr != nil {
+		return writeErr
+	}
+	if _, writeErr := io.WriteString(s.reply, "\n"); writeErr != nil {
 		return writeErr
 	}
 
+	if s.transcript != nil && len(resp.Choices) > 0 {
+		if err := s.transcript.Append(model, append(messages, resp.Choices[0].Message)...); err != nil {
+			return err
+		}
+	}
+
+	if s.verbosity == VerbosityVerbose {
+		s.describeResponse(resp, responseTime, ttft)
+	}
+	finishReason := ""
+	if len(resp.Choices) > 0 {
+		finishReason = resp.Choices[0].FinishReason
+	}
+	if finishReason == backend.FinishLength && s.verbosity != VerbosityQuiet {
+		fmt.Fprintf(s.diagnostics, "Warning: %s\n", i18n.T(language, "reply.truncated"))
+	}
+
 	// Print usage stats if enabled
-	if showUsage && usage != nil {
+	if showUsage && usage != nil && s.verbosity != VerbosityQuiet {
 		summary := s.logger.GetSessionSummary()
-		if _, writeErr := io.WriteString(s.writer,
-			fmt.Sprintf("Tokens: %d | Cost: $%.4f | Time: %.1fs\n",
-				usage.TotalTokens, summary.EstimatedCost, responseTime.Seconds())); writeErr != nil {
+		label := model
+		if provider != "" {
+			label = provider + "/" + model
+		}
+		line := fmt.Sprintf("Model: %s | Finish: %s | Tokens: %d | Cost: $%.4f | Time: %.1fs",
+			label, cmp.Or(finishReason, "-"), usage.TotalTokens, summary.EstimatedCost, responseTime.Seconds())
+		if ttft > 0 {
+			line += fmt.Sprintf(" | TTFT: %dms | %.1f tok/s", ttft.Milliseconds(),
+				backend.TokensPerSecond(usage.CompletionTokens, responseTime, ttft))
+		}
+		if _, writeErr := io.WriteString(s.writer, line+"\n"); writeErr != nil {
 			return writeErr
 		}
 	}
 
 	return nil
 }
+
+// describeRequest prints what is about to be sent
+func (s *DirectQueryService) describeRequest(req *backend.ChatCompletionRequest) {
+	provider, model := clientModelInfo(s.client, nil)
+	_, streaming := s.client.(StreamingLLMClient)
+	streaming = streaming && backend.FeatureEnabled(backend.FeatureStreaming)
+	fmt.Fprintf(s.diagnostics, "> provider %s, model %s, streaming %t\n", provider, model, streaming)
+
+	maxTokens := "provider default"
+	if req.MaxTokens != nil {
+		maxTokens = strconv.Itoa(*req.MaxTokens)
+	}
+	fmt.Fprintf(s.diagnostics, "> %d message(s), ~%d prompt tokens, max_tokens %s\n",
+		len(req.Messages), countTokens(model, req.Messages), maxTokens)
+}
+
+// describeResponse prints the response metadata and how long each part of it took
+func (s *DirectQueryService) describeResponse(resp *backend.ChatCompletionResponse, responseTime, ttft time.Duration) {
+	finishReason := ""
+	if len(resp.Choices) > 0 {
+		finishReason = resp.Choices[0].FinishReason
+	}
+	fmt.Fprintf(s.diagnostics, "< id %s, model %s, finish reason %s\n",
+		cmp.Or(resp.ID, "-"), cmp.Or(resp.Model, "-"), cmp.Or(finishReason, "-"))
+
+	if resp.Usage != nil {
+		fmt.Fprintf(s.diagnostics, "< tokens: %d prompt + %d completion = %d, cost $%.4f\n",
+			resp.Usage.PromptTokens, resp.Usage.CompletionTokens, resp.Usage.TotalTokens,
+			s.logger.GetSessionSummary().EstimatedCost)
+	}
+
+	timing := fmt.Sprintf("total %v", responseTime.Round(time.Millisecond))
+	if ttft > 0 {
+		timing = fmt.Sprintf("first token %v, streaming %v, %s", ttft.Round(time.Millisecond),
+			(responseTime - ttft).Round(time.Millisecond), timing)
+		if resp.Usage != nil {
+			timing += fmt.Sprintf(", %.1f tok/s", backend.TokensPerSecond(resp.Usage.CompletionTokens, responseTime, ttft))
+		}
+	}
+	fmt.Fprintf(s.diagnostics, "< timing: %s\n", timing)
+}
@@ -2,36 +2,175 @@ package app
 
 import (
 	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"slices"
 	"strings"
+	"sync"
+	"sync/atomic"
 	"time"
+	"unicode/utf8"
 
 	"github.com/nleiva/chatgbt/pkg/backend"
+	"github.com/nleiva/chatgbt/pkg/i18n"
 	"github.com/nleiva/chatgbt/pkg/llm"
+	"github.com/nleiva/chatgbt/pkg/notify"
+	"github.com/nleiva/chatgbt/pkg/store"
+	"github.com/nleiva/chatgbt/pkg/tools"
 )
 
+// maxToolRounds bounds how many times a single message can loop through tool calls
+const maxToolRounds = 5
+
+// DefaultFailureThreshold is the number of consecutive provider failures that triggers an alert
+const DefaultFailureThreshold = 3
+
 // ChatSession represents a conversation session with shared logic for CLI and Web modes
 type ChatSession struct {
 	ID               string
+	UserID           string // Who the session was created for, e.g. "web_user" or a Slack user
+	Tenant           string // Tenant whose data and budget the session uses; empty for none
 	Messages         []backend.Message
 	SystemPrompt     string
 	ConversationType string
+	FewShot          []backend.FewShotExample // Examples injected after the system prompt
 
 	// Dependencies
 	LLMClient      LLMClient
 	Logger         Logger
 	ContextManager *backend.ContextManager
+	Store          store.Store
+	Notifier       notify.Notifier // Optional; receives budget and provider failure alerts
+	Tools          *tools.Registry // Optional; tools the model may call
+	Classifier     Classifier      // Categorizes prompts for metrics
+	Router         *Router         // Optional; sends some messages to other models
+
+	// Language is the UI language for this session's messages and budget warnings.
+	// With AutoLanguage set it follows the language the user writes in.
+	Language     string
+	AutoLanguage bool
+
+	// FailureThreshold is the number of consecutive provider failures that triggers an alert
+	FailureThreshold int
+
+	// MaxMessageLength is the longest user message, in characters, the session accepts; 0 for no limit
+	MaxMessageLength int
+
+	// JSONMode asks for every reply as a JSON object. Providers without a JSON mode are
+	// instructed through the prompt instead.
+	JSONMode bool
+
+	// InjectionGuard flags or strips instruction-like lines in tool results before they
+	// enter the context; the zero value passes them through
+	InjectionGuard backend.InjectionGuard
+
+	// FollowUps suggests a few questions the user might ask next after every reply,
+	// at the cost of an extra short request
+	FollowUps bool
+
+	// AutoContinue asks for the rest of a reply cut off at the token limit, up to
+	// maxContinuations times, and returns the parts joined as one reply
+	AutoContinue bool
+
+	// MaxQueued is how many requests may wait while a reply is being generated; more get
+	// a *GenerationInProgressError. 0 rejects every request that overlaps another.
+	MaxQueued int
+
+	// endUser identifies the person using the session to the provider; see SetEndUser
+	endUser string
+
+	// costCeiling is the daily spend limit shared with other sessions; nil for none
+	costCeiling *CostCeiling
+
+	// generation is that of the session manager settings the session last took on;
+	// defaultModel is set when it talks to the manager's model rather than one picked
+	// for it, so that a reload can change its model, and typePrompt is its conversation
+	// type's system prompt in those settings, so that a reload can change the prompt of
+	// sessions that still use it. See InMemorySessionManager.Reload.
+	generation   int
+	defaultModel bool
+	typePrompt   string
+
+	// mutex is held while a request or edit reads or changes the conversation, so
+	// overlapping requests, such as a double submit in the web UI, run one at a time
+	mutex sync.Mutex
+
+	// settingsMutex is held, along with mutex, while a reload changes the session's
+	// settings, such as LLMClient and MaxMessageLength (see refresh). Readers that don't
+	// hold mutex, such as status handlers polling during a reply, take it instead.
+	// It alone guards preferences, which change between and during replies.
+	settingsMutex sync.RWMutex
+
+	// preferences are UI settings chosen during the session; see Preferences
+	preferences Preferences
+
+	// generating is held, before mutex, while a reply is generated; pending counts the
+	// requests holding or waiting for it. See startGeneration.
+	generating chan struct{}
+	pending    atomic.Int32
+
+	// replyContext is a copy of Messages taken as a reply starts, non-nil until it ends,
+	// so status readers needn't wait for mutex; settingsMutex guards it. See statusContext.
+	replyContext []backend.Message
+
+	// abort cancels the reply being generated, nil between replies; abortMutex guards
+	// it, as Abort runs without holding mutex. See Abort.
+	abort      context.CancelFunc
+	abortMutex sync.Mutex
+
+	// conversation is the full, unpruned transcript persisted to Store. mutex guards
+	// its contents; replacing it also takes settingsMutex, so ConversationID needn't
+	// wait for a reply to end.
+	conversation *store.Conversation
+
+	// Alert state, so each condition notifies once rather than on every message
+	consecutiveFailures int
+	warnAlerted         bool
+	overBudgetAlerted   bool
+}
+
+// Preferences are per-session UI settings
+type Preferences struct {
+	ShowDetails bool   `json:"show_details"` // Show timestamps, model, and latency on web chat messages
+	Theme       string `json:"theme"`        // Web UI color theme, "dark" or "light"
 }
 
 // SessionConfig holds configuration for creating a new session
 type SessionConfig struct {
 	ID               string
+	UserID           string
 	ConversationType string
 	SystemPrompt     string
 	LLMConfig        backend.LLMConfig
 	BudgetConfig     backend.TokenBudgetConfig
 	MaxTokens        int
 	KeepRecent       int
+	KeepRecentTokens int // Keep recent messages within this many tokens instead of KeepRecent exchanges (optional)
+	KeepRelevant     int // Older exchanges kept by relevance to the latest message when pruning (optional, needs Embedder)
 	SummaryEnabled   bool
+	FewShot          map[string][]backend.FewShotExample // Few-shot examples keyed by conversation type
+	Store            store.Store                         // Conversation store (default: files in store.DefaultDir)
+	Notifier         notify.Notifier                     // Budget and provider failure alerts (optional)
+	FailureThreshold int                                 // Consecutive failures before alerting (default: 3)
+	Tools            *tools.Registry                     // Tools the model may call (optional)
+	Classifier       Classifier                          // Prompt classifier (default: KeywordClassifier)
+	Language         string                              // UI language (default: i18n.Default)
+	AutoLanguage     bool                                // Switch Language to the language the user writes in
+	MaxMessageLength int                                 // Longest user message in characters (default: no limit)
+	CostCeiling      *CostCeiling                        // Daily spend limit shared across sessions (optional)
+	JSONMode         bool                                // Ask for replies as JSON objects
+	Router           *Router                             // Per-message model routing (optional)
+	FollowUps        bool                                // Suggest follow-up questions after each reply
+	AutoContinue     bool                                // Continue replies cut off at the token limit
+	MaxQueued        int                                 // Requests that may wait for the reply in progress (default: 0, reject them)
+	EndUser          string                              // Person using the session, hashed before it is sent (optional)
+	Tenant           string                              // Tenant the session belongs to (optional)
+	InjectionGuard   backend.InjectionGuard              // Handling of instruction-like content in tool results (default: off)
+	Embedder         backend.Embedder                    // Embeddings for relevance pruning (optional)
 }
 
 // NewChatSession creates a new chat session with all dependencies initialized
@@ -48,8 +187,7 @@ func NewChatSession(config SessionConfig) (*ChatSession, error) {
 		return nil, err
 	}
 
-	// Initialize context manager
-	contextManager := backend.NewContextManager(config.MaxTokens, config.KeepRecent, config.SummaryEnabled)
+	contextManager := newContextManager(config, llmClient.Capabilities())
 
 	// Initialize messages with system prompt
 	systemPrompt := config.SystemPrompt
@@ -57,72 +195,215 @@ func NewChatSession(config SessionConfig) (*ChatSession, error) {
 		systemPrompt = "You are a helpful assistant."
 	}
 
+	conversationStore := config.Store
+	if conversationStore == nil {
+		conversationStore = store.NewFileStore(store.DefaultDir)
+	}
+
+	failureThreshold := config.FailureThreshold
+	if failureThreshold <= 0 {
+		failureThreshold = DefaultFailureThreshold
+	}
+
+	classifier := config.Classifier
+	if classifier == nil {
+		classifier = KeywordClassifier{}
+	}
+
 	session := &ChatSession{
 		ID:               config.ID,
-		Messages:         []backend.Message{{Role: backend.RoleSystem, Content: systemPrompt}},
+		UserID:           config.UserID,
+		Tenant:           config.Tenant,
 		SystemPrompt:     systemPrompt,
 		ConversationType: config.ConversationType,
+		FewShot:          config.FewShot[config.ConversationType],
 		LLMClient:        llmClient,
 		Logger:           logger,
 		ContextManager:   contextManager,
+		Store:            conversationStore,
+		Notifier:         config.Notifier,
+		FailureThreshold: failureThreshold,
+		Tools:            config.Tools,
+		Classifier:       classifier,
+		Router:           config.Router,
+		AutoLanguage:     config.AutoLanguage,
+		MaxMessageLength: config.MaxMessageLength,
+		JSONMode:         config.JSONMode,
+		FollowUps:        config.FollowUps,
+		AutoContinue:     config.AutoContinue,
+		MaxQueued:        config.MaxQueued,
+		generating:       make(chan struct{}, 1),
+		endUser:          HashUserID(config.EndUser),
+		InjectionGuard:   config.InjectionGuard,
+		costCeiling:      config.CostCeiling,
 	}
+	language := config.Language
+	if language == "" {
+		language = i18n.Default
+	}
+	session.SetLanguage(language)
+	session.Messages = session.initialMessages()
+	session.startConversation(config.ID)
 
 	return session, nil
 }
 
+// newContextManager creates the context manager of a session with config, pruning
+// before the context window of a model with capabilities fills up
+func newContextManager(config SessionConfig, capabilities backend.Capabilities) *backend.ContextManager {
+	maxTokens := config.MaxTokens
+	if window := capabilities.MaxContext; window > 0 {
+		maxTokens = min(maxTokens, window-min(defaultCompletionEstimate, window/2))
+	}
+	contextManager := backend.NewContextManager(maxTokens, config.KeepRecent, config.SummaryEnabled)
+	contextManager.SetKeepRecentTokens(config.KeepRecentTokens)
+	if config.KeepRelevant > 0 && config.Embedder != nil {
+		contextManager.SetRelevance(NewEmbeddingRelevance(config.Embedder), config.KeepRelevant)
+	}
+	return contextManager
+}
+
 // ProcessUserMessage handles a user message and returns the assistant's response
 func (s *ChatSession) ProcessUserMessage(userMessage string) (*ChatResponse, error) {
+	return s.ProcessUserMessageStream(userMessage, nil)
+}
+
+// ProcessUserMessageStream handles a user message, calling onDelta with each chunk of
+// the assistant's response as it is generated. onDelta may be nil.
+func (s *ChatSession) ProcessUserMessageStream(userMessage string, onDelta backend.StreamHandler) (*ChatResponse, error) {
+	return s.ProcessUserMessageContext(context.Background(), userMessage, onDelta)
+}
+
+// ProcessUserMessageContext is ProcessUserMessageStream with a parent context, whose
+// request ID (see backend.WithRequestID) is logged with every interaction and sent to
+// the provider
+func (s *ChatSession) ProcessUserMessageContext(ctx context.Context, userMessage string, onDelta backend.StreamHandler) (*ChatResponse, error) {
+	ctx, err := s.startGeneration(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.endGeneration()
+	return s.process(ctx, userMessage, nil, onDelta)
+}
+
+// MessageTooLongError reports a user message over the session's MaxMessageLength
+type MessageTooLongError struct {
+	Length int // Characters in the message
+	Max    int
+}
+
+func (e *MessageTooLongError) Error() string {
+	return fmt.Sprintf("message is %d characters long, the limit is %d", e.Length, e.Max)
+}
+
+// CheckMessage validates a user message before it is sent, so callers can reject it
+// without touching the conversation
+func (s *ChatSession) CheckMessage(userMessage string) error {
+	s.settingsMutex.RLock()
+	maxLength := s.MaxMessageLength
+	s.settingsMutex.RUnlock()
+
+	if maxLength <= 0 {
+		return nil
+	}
+	if length := utf8.RuneCountInString(userMessage); length > maxLength {
+		return &MessageTooLongError{Length: length, Max: maxLength}
+	}
+	return nil
+}
+
+// Regenerate drops the last exchange and asks the model again for the same user
+// message, at the given temperature when it isn't nil. The previous reply is kept
+// if the new request fails.
+func (s *ChatSession) Regenerate(ctx context.Context, temperature *float64) (*ChatResponse, error) {
+	ctx, err := s.startGeneration(ctx)
+	if err != nil {
+		return nil, err
+	}
+	defer s.endGeneration()
+	turns := s.turns()
+	if turns == 0 {
+		return nil, errors.New("no response to regenerate")
+	}
+	userMessage := s.lastUserMessage()
+
+	messages, transcript := slices.Clone(s.Messages), slices.Clone(s.conversation.Messages)
+	if err := s.truncateAt(turns - 1); err != nil {
+		return nil, err
+	}
+	response, err := s.process(ctx, userMessage, temperature, nil)
+	if err != nil {
+		s.Messages, s.conversation.Messages = messages, transcript
+		_ = s.Store.Save(s.conversation)
+		return nil, err
+	}
+	return response, nil
+}
+
+// process runs a user message through the model; temperature overrides the
+// provider's default when it isn't nil
+func (s *ChatSession) process(ctx context.Context, userMessage string, temperature *float64, onDelta backend.StreamHandler) (*ChatResponse, error) {
+	if err := s.CheckMessage(userMessage); err != nil {
+		return nil, err
+	}
+	if err := s.costCeiling.Check(); err != nil {
+		return nil, err
+	}
+
 	// Auto-prune context if needed
+	var pruned *backend.PruneReport
 	if s.ContextManager.ShouldPrune(s.Messages) {
-		s.AutoPrune()
+		pruned = s.pruneFor(userMessage)
 	}
 
 	// Check message bounds to prevent memory issues
 	if len(s.Messages) > 1000 { // Hard limit to prevent unbounded growth
-		s.AutoPrune()
+		if report := s.pruneFor(userMessage); report != nil {
+			pruned = report
+		}
 	}
 
 	// Add user message
+	userIndex := len(s.Messages)
 	s.Messages = append(s.Messages, backend.Message{
 		Role:    backend.RoleUser,
 		Content: userMessage,
 	})
 
-	// Classify prompt type
-	promptType := ClassifyPrompt(userMessage)
+	// Classify prompt type and language
+	promptType := s.Classifier.Classify(ctx, userMessage)
+	language := i18n.Detect(userMessage)
+	if s.AutoLanguage && i18n.IsSupported(language) {
+		s.SetLanguage(language)
+	}
 
 	// Get LLM response with timing and timeout
 	startTime := time.Now()
-	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
-	defer cancel()
-
-	// Create completion request
-	req := &backend.ChatCompletionRequest{
-		Messages: s.Messages,
+	costBefore := s.Logger.GetBudgetStatus().SessionCost
+	route := s.route(ctx, promptType)
+	result, err := s.complete(ctx, route, startTime, promptType, language, temperature, onDelta)
+	if err == nil && s.AutoContinue {
+		result = s.continueTruncated(ctx, route, result, onDelta)
 	}
-
-	resp, err := s.LLMClient.CreateCompletion(ctx, req)
 	responseTime := time.Since(startTime)
+	s.costCeiling.Add(s.ID, s.Logger.GetBudgetStatus().SessionCost-costBefore)
+	reply, usage, ttft, model := result.reply, result.usage, result.ttft, result.model
 
-	var reply string
-	var usage *backend.Usage
-	if err == nil && len(resp.Choices) > 0 {
-		reply = resp.Choices[0].Message.Content
-		usage = resp.Usage
+	// A reply stopped by the user (see Abort) is kept as far as it got
+	aborted := result.finish == backend.FinishAborted
+	if aborted {
+		err = nil
 	}
 
-	// Log the interaction
-	s.Logger.LogInteraction(backend.InteractionLog{
-		Usage:        usage,
-		ResponseTime: responseTime,
-		Success:      err == nil,
-		ErrorType:    getErrorType(err),
-		PromptType:   promptType,
-	})
+	// A refusal by the budget preflight never reached the provider
+	var overBudget *BudgetExceededError
+	if !errors.As(err, &overBudget) {
+		s.checkAlerts(model, err)
+	}
 
 	if err != nil {
-		// Remove failed user message
-		s.removeLastUserMessage()
+		// Remove the failed user message and any partial tool exchange
+		s.Messages = s.Messages[:userIndex]
 		return nil, err
 	}
 
@@ -131,57 +412,517 @@ func (s *ChatSession) ProcessUserMessage(userMessage string) (*ChatResponse, err
 		Role:    backend.RoleAssistant,
 		Content: reply,
 	})
+	s.recordExchange(userMessage, startTime, reply, model, aborted)
 
 	// Prepare budget warnings
 	budgetStatus := s.Logger.GetBudgetStatus()
 	var warnings []string
-	if len(budgetStatus.Warnings) > 0 {
-		warnings = budgetStatus.Warnings
+	if result.maxTokens > 0 {
+		warnings = append(warnings, i18n.T(s.Language, "budget.trimmed", result.maxTokens))
 	}
+	warnings = append(warnings, budgetStatus.Warnings...)
 
-	return &ChatResponse{
+	response := &ChatResponse{
 		Content:      reply,
 		Usage:        usage,
 		ResponseTime: responseTime,
+		TTFT:         ttft,
 		Warnings:     warnings,
 		PromptType:   promptType,
-	}, nil
+		Language:     language,
+		Provider:     result.provider,
+		Model:        model,
+		FinishReason: result.finish,
+		ToolsUsed:    result.toolsUsed,
+		Pruned:       pruned,
+	}
+	if usage != nil {
+		response.TokensPerSecond = backend.TokensPerSecond(usage.CompletionTokens, responseTime, ttft)
+	}
+	if s.FollowUps && !aborted {
+		response.FollowUps = s.suggestFollowUps(ctx)
+	}
+
+	return response, nil
+}
+
+// route picks where a message of promptType goes: the first matching rule of the
+// session's Router, or the session's own client. A request that overrides the model
+// stays on the session's client.
+func (s *ChatSession) route(ctx context.Context, promptType string) Route {
+	if s.Router != nil && backend.FeatureEnabled(backend.FeatureRouting) && OverridesFromContext(ctx).Model == "" {
+		_, model := clientModelInfo(s.LLMClient, nil)
+		if route, ok := s.Router.Route(promptType, countTokens(model, s.Messages), s.Logger.GetBudgetStatus()); ok {
+			return route
+		}
+	}
+	return Route{Client: s.LLMClient}
+}
+
+// requestJSON asks for a JSON object reply, through the provider's JSON mode when it
+// has one. The instruction is always added to the prompt, which OpenAI's JSON mode
+// requires and other providers rely on.
+func (s *ChatSession) requestJSON(req *backend.ChatCompletionRequest, capabilities backend.Capabilities) {
+	req.JSONMode = capabilities.JSONMode
+	req.Messages = append(slices.Clip(req.Messages), backend.Message{
+		Role:    backend.RoleSystem,
+		Content: backend.JSONModeInstruction,
+	})
+}
+
+// completion is the outcome of a possibly multi-round exchange with the model
+type completion struct {
+	reply     string
+	usage     *backend.Usage // Summed over every round
+	ttft      time.Duration  // From the start of the exchange to the first token of the reply
+	provider  string
+	model     string
+	finish    string // Finish reason of the last round
+	toolsUsed []string
+	maxTokens int // Reply limit set by the budget preflight; 0 when it didn't trim
+
+	// neutralized counts the tool result lines the injection guard flagged or stripped
+	neutralized int
+}
+
+// complete asks the model of route for a reply to the current messages. When the model
+// calls tools, it runs them, appends the calls and results to the context, and asks
+// again, up to maxToolRounds times. Every round is logged as its own interaction.
+func (s *ChatSession) complete(parent context.Context, route Route, startTime time.Time, promptType, language string, temperature *float64, onDelta backend.StreamHandler) (completion, error) {
+	var result completion
+	capabilities := clientCapabilities(route.Client)
+	var definitions []backend.ToolDefinition
+	if capabilities.Tools && backend.FeatureEnabled(backend.FeatureTools) {
+		definitions = s.Tools.Definitions()
+	}
+
+	for round := 0; ; round++ {
+		req := &backend.ChatCompletionRequest{Messages: s.Messages, User: s.endUser}
+		OverridesFromContext(parent).apply(req, temperature)
+		if round < maxToolRounds {
+			req.Tools = definitions // Withheld on the last round to force an answer
+		}
+		if s.JSONMode {
+			s.requestJSON(req, capabilities)
+		}
+		trimmed, err := s.preflight(req, route.Client)
+		if err != nil {
+			return result, err
+		}
+		if trimmed > 0 {
+			result.maxTokens = trimmed
+		}
+
+		roundStart := time.Now()
+		ctx, cancel := context.WithTimeout(parent, 30*time.Second)
+		resp, ttft, err := createCompletion(ctx, route.Client, req, onDelta)
+		cancel()
+		responseTime := time.Since(roundStart)
+
+		// An aborted stream still spent the tokens it sent
+		var usage *backend.Usage
+		if resp != nil {
+			usage = resp.Usage
+		}
+		provider, model := clientModelInfo(route.Client, resp)
+		result.provider, result.model = provider, model
+		s.Logger.LogInteraction(backend.InteractionLog{
+			Usage:        usage,
+			ResponseTime: responseTime,
+			Success:      err == nil,
+			ErrorType:    getErrorType(err),
+			PromptType:   promptType,
+			Language:     language,
+			Provider:     provider,
+			Model:        model,
+			TTFT:         ttft,
+			RequestID:    backend.RequestIDFromContext(parent),
+			CostPerToken: route.CostPerToken,
+			Neutralized:  result.neutralized,
+		})
+		if err != nil {
+			if resp != nil && len(resp.Choices) > 0 {
+				result.usage = addUsage(result.usage, usage)
+				result.reply, result.finish = resp.Choices[0].Message.Content, resp.Choices[0].FinishReason
+				result.ttft = roundStart.Sub(startTime) + ttft
+			}
+			return result, err
+		}
+
+		result.usage = addUsage(result.usage, usage)
+		if len(resp.Choices) == 0 {
+			return result, nil
+		}
+
+		message := resp.Choices[0].Message
+		result.finish = resp.Choices[0].FinishReason
+		if len(message.ToolCalls) == 0 || len(req.Tools) == 0 {
+			result.reply = message.Content
+			if ttft > 0 {
+				result.ttft = roundStart.Sub(startTime) + ttft
+			}
+			return result, nil
+		}
+
+		s.Messages = append(s.Messages, backend.Message{
+			Role:      backend.RoleAssistant,
+			Content:   message.Content,
+			ToolCalls: message.ToolCalls,
+		})
+		for _, call := range message.ToolCalls {
+			result.toolsUsed = append(result.toolsUsed, call.Function.Name)
+			output, neutralized := s.InjectionGuard.Sanitize(s.runTool(call))
+			result.neutralized += neutralized
+			s.Messages = append(s.Messages, backend.Message{
+				Role:       backend.RoleTool,
+				Content:    output,
+				ToolCallID: call.ID,
+			})
+		}
+	}
+}
+
+// runTool executes a tool call and returns the text sent back to the model.
+// Failures are reported to the model rather than aborting the exchange.
+func (s *ChatSession) runTool(call backend.ToolCall) string {
+	tool, ok := s.Tools.Get(call.Function.Name)
+	if !ok {
+		return fmt.Sprintf("Error: unknown tool %q", call.Function.Name)
+	}
+
+	arguments := json.RawMessage(call.Function.Arguments)
+	if len(arguments) > 0 && !json.Valid(arguments) {
+		return "Error: tool arguments are not valid JSON"
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), tools.DefaultCallTimeout)
+	defer cancel()
+
+	output, err := tool.Call(ctx, arguments)
+	if err != nil {
+		return "Error: " + err.Error()
+	}
+	return output
+}
+
+// addUsage sums token usage, treating nil as zero
+func addUsage(total, usage *backend.Usage) *backend.Usage {
+	if usage == nil {
+		return total
+	}
+	if total == nil {
+		return &backend.Usage{
+			PromptTokens:     usage.PromptTokens,
+			CompletionTokens: usage.CompletionTokens,
+			TotalTokens:      usage.TotalTokens,
+		}
+	}
+	total.PromptTokens += usage.PromptTokens
+	total.CompletionTokens += usage.CompletionTokens
+	total.TotalTokens += usage.TotalTokens
+	return total
+}
+
+// checkAlerts notifies on budget threshold crossings and repeated provider failures.
+// Each condition fires once when it is first reached; failures re-arm after a success.
+func (s *ChatSession) checkAlerts(model string, err error) {
+	if s.Notifier == nil {
+		return
+	}
+
+	event := notify.Event{
+		Timestamp:        time.Now(),
+		SessionID:        s.ID,
+		ConversationType: s.ConversationType,
+		Model:            model,
+	}
+
+	if err != nil {
+		s.consecutiveFailures++
+		if s.consecutiveFailures == s.FailureThreshold {
+			event.Type = notify.EventProviderFailures
+			event.Failures = s.consecutiveFailures
+			event.Error = err.Error()
+			event.Message = fmt.Sprintf("%d consecutive provider requests failed", s.consecutiveFailures)
+			s.Notifier.Notify(event)
+		}
+		return
+	}
+	s.consecutiveFailures = 0
+
+	status := s.Logger.GetBudgetStatus()
+	event.Tokens = status.SessionTokens
+	event.Limit = status.SessionLimit
+	event.Cost = status.SessionCost
+
+	if status.OverBudget && !s.overBudgetAlerted {
+		s.overBudgetAlerted = true
+		s.warnAlerted = true
+		event.Type = notify.EventOverBudget
+		event.Message = fmt.Sprintf("Session is over budget (%d/%d tokens, $%.4f)", status.SessionTokens, status.SessionLimit, status.SessionCost)
+		s.Notifier.Notify(event)
+	} else if status.NearLimit && !s.warnAlerted {
+		s.warnAlerted = true
+		event.Type = notify.EventBudgetWarning
+		event.Message = fmt.Sprintf("Session is nearing its budget (%d/%d tokens, $%.4f)", status.SessionTokens, status.SessionLimit, status.SessionCost)
+		s.Notifier.Notify(event)
+	}
 }
 
 // Reset resets the conversation with a new system prompt
 func (s *ChatSession) Reset(systemPrompt string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	s.reset(systemPrompt)
+}
+
+// reset is Reset for callers already holding the session's mutex
+func (s *ChatSession) reset(systemPrompt string) {
 	if systemPrompt == "" {
 		systemPrompt = s.SystemPrompt
 	}
 	s.SystemPrompt = systemPrompt
-	s.Messages = []backend.Message{{Role: backend.RoleSystem, Content: systemPrompt}}
+	s.Messages = s.initialMessages()
+	s.startConversation(GenerateSessionID(s.ConversationType))
+}
+
+// Turns returns the number of user messages in the current conversation
+func (s *ChatSession) Turns() int {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.turns()
+}
+
+// turns is Turns for callers already holding the session's mutex
+func (s *ChatSession) turns() int {
+	turns := 0
+	for _, msg := range s.conversation.Messages {
+		if msg.Role == backend.RoleUser {
+			turns++
+		}
+	}
+	return turns
+}
+
+// LastUserMessage returns the most recent user message of the conversation, or "" if there is none
+func (s *ChatSession) LastUserMessage() string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.lastUserMessage()
+}
+
+// lastUserMessage is LastUserMessage for callers already holding the session's mutex
+func (s *ChatSession) lastUserMessage() string {
+	for i := len(s.conversation.Messages) - 1; i >= 0; i-- {
+		if s.conversation.Messages[i].Role == backend.RoleUser {
+			return s.conversation.Messages[i].Content
+		}
+	}
+	return ""
+}
+
+// AssistantReplies returns the assistant replies of the conversation, most recent first
+func (s *ChatSession) AssistantReplies() []string {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	var replies []string
+	for i := len(s.conversation.Messages) - 1; i >= 0; i-- {
+		if msg := s.conversation.Messages[i]; msg.Role == backend.RoleAssistant && msg.Content != "" {
+			replies = append(replies, msg.Content)
+		}
+	}
+	return replies
+}
+
+// TruncateAt removes the user message of the given turn (0-based, counted from the
+// start of the conversation) and everything after it, from both the context and the
+// persisted transcript. It fails if the turn has already been pruned from the context.
+func (s *ChatSession) TruncateAt(turn int) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.truncateAt(turn)
+}
+
+// truncateAt is TruncateAt for callers already holding the session's mutex
+func (s *ChatSession) truncateAt(turn int) error {
+	turns := s.turns()
+	if turn < 0 || turn >= turns {
+		return fmt.Errorf("turn %d out of range (conversation has %d)", turn, turns)
+	}
+
+	// Pruning drops the oldest messages, so find the turn by counting back from the end
+	fromEnd := turns - turn
+	index := -1
+	for i := len(s.Messages) - 1; i >= 0 && !s.Messages[i].Pinned; i-- {
+		if s.Messages[i].Role == backend.RoleUser {
+			fromEnd--
+			if fromEnd == 0 {
+				index = i
+				break
+			}
+		}
+	}
+	if index < 0 {
+		return fmt.Errorf("turn %d has been pruned from the context", turn)
+	}
+	s.Messages = s.Messages[:index]
+
+	seen := 0
+	for i, msg := range s.conversation.Messages {
+		if msg.Role == backend.RoleUser {
+			if seen == turn {
+				s.conversation.Messages = s.conversation.Messages[:i]
+				break
+			}
+			seen++
+		}
+	}
+	s.conversation.UpdatedAt = time.Now()
+	_ = s.Store.Save(s.conversation)
+	return nil
+}
+
+// ConversationID returns the ID under which the current conversation is persisted
+func (s *ChatSession) ConversationID() string {
+	s.settingsMutex.RLock()
+	defer s.settingsMutex.RUnlock()
+	return s.conversation.ID
+}
+
+// ExportConversation writes the persisted transcript, including any summary, to w.
+// During a reply it waits for the reply to be recorded.
+func (s *ChatSession) ExportConversation(w io.Writer, format store.Format) error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return store.Export(w, s.conversation, format)
+}
+
+// logsDirReporter is implemented by loggers that know where they write, such as the metrics logger
+type logsDirReporter interface {
+	LogsDir() string
+}
+
+// SearchHistory full-text searches the conversations and logs of this session only,
+// newest first; limit <= 0 means no limit. Logs are skipped when the session's logger
+// doesn't say where it writes.
+func (s *ChatSession) SearchHistory(query string, limit int) ([]store.SearchHit, error) {
+	var logsDir string
+	if l, ok := s.Logger.(logsDirReporter); ok {
+		logsDir = l.LogsDir()
+	}
+	return store.SearchSession(s.Store, logsDir, s.ID, query, limit)
+}
+
+// startConversation begins a new persisted transcript; nothing is written until the first exchange
+func (s *ChatSession) startConversation(id string) {
+	now := time.Now()
+	conversation := &store.Conversation{
+		ID:               id,
+		SessionID:        s.ID,
+		ConversationType: s.ConversationType,
+		SystemPrompt:     s.SystemPrompt,
+		CreatedAt:        now,
+		UpdatedAt:        now,
+	}
+	s.settingsMutex.Lock()
+	s.conversation = conversation
+	s.settingsMutex.Unlock()
+}
+
+// recordExchange appends a completed exchange to the transcript and persists it.
+// Persistence is best effort, like metrics logging, and never fails the chat. truncated
+// marks a reply stopped before it finished.
+func (s *ChatSession) recordExchange(userMessage string, sentAt time.Time, reply, model string, truncated bool) {
+	now := time.Now()
+	s.conversation.Messages = append(s.conversation.Messages,
+		store.Message{Role: backend.RoleUser, Content: userMessage, Timestamp: sentAt},
+		store.Message{Role: backend.RoleAssistant, Content: reply, Timestamp: now, Truncated: truncated},
+	)
+	s.conversation.Model = model
+	s.conversation.UpdatedAt = now
+	_ = s.Store.Save(s.conversation)
+}
+
+// initialMessages returns the system prompt followed by the pinned few-shot examples
+func (s *ChatSession) initialMessages() []backend.Message {
+	messages := []backend.Message{{Role: backend.RoleSystem, Content: s.SystemPrompt}}
+	return append(messages, backend.FewShotMessages(s.FewShot)...)
 }
 
 // UpdateSystemPrompt updates the system prompt and resets the conversation
 func (s *ChatSession) UpdateSystemPrompt(newPrompt string) {
-	s.SystemPrompt = newPrompt
 	s.Reset(newPrompt)
 }
 
+// replaceSystemPrompt changes the system prompt while keeping the conversation, for
+// callers already holding the session's mutex
+func (s *ChatSession) replaceSystemPrompt(systemPrompt string) {
+	s.SystemPrompt = systemPrompt
+	if len(s.Messages) > 0 && s.Messages[0].Role == backend.RoleSystem {
+		s.Messages = slices.Clone(s.Messages)
+		s.Messages[0].Content = systemPrompt
+	}
+	s.conversation.SystemPrompt = systemPrompt
+}
+
 // AutoPrune performs automatic context pruning
 func (s *ChatSession) AutoPrune() bool {
+	return s.Prune() != nil
+}
+
+// Prune is AutoPrune with a report of what was dropped; nil when nothing was pruned
+func (s *ChatSession) Prune() *backend.PruneReport {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s.pruneFor(s.lastUserMessage())
+}
+
+// pruneFor prunes the context before query is sent, keeping the older exchanges most
+// relevant to it when relevance pruning is on
+func (s *ChatSession) pruneFor(query string) *backend.PruneReport {
 	originalTokens := s.ContextManager.EstimateTokens(s.Messages)
 
-	newMessages, pruned := s.ContextManager.PruneContext(s.Messages, originalTokens)
-	if pruned {
+	newMessages, report := s.ContextManager.PruneFor(s.Messages, originalTokens, query)
+	if report != nil {
 		s.Messages = newMessages
-		return true
 	}
-	return false
+	return report
+}
+
+// SetLanguage changes the UI language, including the language of budget warnings
+func (s *ChatSession) SetLanguage(lang string) {
+	s.Language = lang
+	if l, ok := s.Logger.(interface{ SetLanguage(string) }); ok {
+		l.SetLanguage(lang)
+	}
 }
 
 // GetContextStats returns current context statistics
 func (s *ChatSession) GetContextStats() backend.ContextStats {
-	return s.ContextManager.GetContextStats(s.Messages)
+	messages, unlock := s.statusContext()
+	defer unlock()
+	return s.ContextManager.GetContextStats(messages)
+}
+
+// statusContext returns the messages in the context for status readers, and the
+// function that releases the lock held while they're read. While a reply is generated
+// they're those the reply started with, read under settingsMutex rather than waiting
+// for mutex until the reply ends.
+func (s *ChatSession) statusContext() ([]backend.Message, func()) {
+	s.settingsMutex.RLock()
+	if s.replyContext != nil {
+		return s.replyContext, s.settingsMutex.RUnlock
+	}
+	s.settingsMutex.RUnlock()
+
+	s.mutex.Lock()
+	return s.Messages, s.mutex.Unlock
 }
 
 // GetSessionSummary returns session metrics summary
 func (s *ChatSession) GetSessionSummary() backend.SessionSummary {
+	s.settingsMutex.RLock()
+	defer s.settingsMutex.RUnlock()
 	return s.Logger.GetSessionSummary()
 }
 
@@ -195,25 +936,106 @@ func (s *ChatSession) GetPromptTypeBreakdown() map[string]int {
 	return s.Logger.GetPromptTypeBreakdown()
 }
 
+// Preferences returns the session's UI settings
+func (s *ChatSession) Preferences() Preferences {
+	s.settingsMutex.RLock()
+	defer s.settingsMutex.RUnlock()
+	return s.preferences
+}
+
+// UpdatePreferences changes the session's UI settings through update, which may be
+// called while a reply is being generated
+func (s *ChatSession) UpdatePreferences(update func(*Preferences)) {
+	s.settingsMutex.Lock()
+	defer s.settingsMutex.Unlock()
+	update(&s.preferences)
+}
+
+// SetEndUser sets who is using the session, e.g. a profile or Slack user. Requests carry
+// it, hashed, so the provider's abuse monitoring and usage analytics can tell users apart
+// without learning who they are.
+func (s *ChatSession) SetEndUser(id string) {
+	s.endUser = HashUserID(id)
+}
+
+// HashUserID returns the stable, opaque form of a user identifier sent to providers;
+// empty stays empty
+func HashUserID(id string) string {
+	if id == "" {
+		return ""
+	}
+	sum := sha256.Sum256([]byte("chatgbt:" + id))
+	return hex.EncodeToString(sum[:16])
+}
+
+// ModelLabel returns a "provider/model" label for the model this session talks to
+func (s *ChatSession) ModelLabel() string {
+	s.settingsMutex.RLock()
+	provider, model := clientModelInfo(s.LLMClient, nil)
+	s.settingsMutex.RUnlock()
+	if provider == "" {
+		return model
+	}
+	return provider + "/" + model
+}
+
+// Capabilities reports what the session's provider and model support
+func (s *ChatSession) Capabilities() backend.Capabilities {
+	s.settingsMutex.RLock()
+	defer s.settingsMutex.RUnlock()
+	return clientCapabilities(s.LLMClient)
+}
+
+// GetMessageTokens returns the token count of each message in the current context
+// using the session model's tokenizer. exact is false when counts are estimates.
+func (s *ChatSession) GetMessageTokens() (counts []backend.MessageTokens, exact bool) {
+	messages, unlock := s.statusContext()
+	defer unlock()
+	_, model := clientModelInfo(s.LLMClient, nil)
+	return backend.CountMessageTokens(model, messages)
+}
+
 // Close properly closes the session
 func (s *ChatSession) Close() error {
 	return s.Logger.Close()
 }
 
-// removeLastUserMessage removes the last user message (used on errors)
-func (s *ChatSession) removeLastUserMessage() {
-	if len(s.Messages) > 0 && s.Messages[len(s.Messages)-1].Role == backend.RoleUser {
-		s.Messages = s.Messages[:len(s.Messages)-1]
+// ChatResponse represents the response from processing a user message
+type ChatResponse struct {
+	Content         string
+	Usage           *backend.Usage
+	ResponseTime    time.Duration
+	TTFT            time.Duration // Time to first token; zero when the response wasn't streamed
+	TokensPerSecond float64
+	Warnings        []string
+	PromptType      string
+	Language        string   // Detected language of the user message; empty when unsure
+	Provider        string   // Provider that answered, which routing may have picked
+	Model           string   // Model that produced the reply
+	FinishReason    string   // Why the reply ended, e.g. backend.FinishStop; empty when the provider didn't say
+	ToolsUsed       []string // Names of the tools called while producing the reply, in order
+	FollowUps       []string // Suggested next questions when the session's FollowUps is set
+
+	// Pruned reports what was dropped from the context to make room for the message; nil when nothing was
+	Pruned *backend.PruneReport
+}
+
+// ModelLabel returns a "provider/model" label for the model that answered
+func (r *ChatResponse) ModelLabel() string {
+	if r.Provider == "" {
+		return r.Model
 	}
+	return r.Provider + "/" + r.Model
 }
 
-// ChatResponse represents the response from processing a user message
-type ChatResponse struct {
-	Content      string
-	Usage        *backend.Usage
-	ResponseTime time.Duration
-	Warnings     []string
-	PromptType   string
+// Truncated reports whether the reply was cut off at the max_tokens limit
+func (r *ChatResponse) Truncated() bool {
+	return r.FinishReason == backend.FinishLength
+}
+
+// Aborted reports whether the reply was stopped by the user before it finished
+func (r *ChatResponse) Aborted() bool {
+	return r.FinishReason == backend.FinishAborted
 }
 
 // getErrorType converts an error to a classification string
@@ -221,6 +1043,10 @@ func getErrorType(err error) string {
 	if err == nil {
 		return ""
 	}
+	var queueTimeout *llm.QueueTimeoutError
+	if errors.As(err, &queueTimeout) {
+		return "queue_timeout"
+	}
 
 	errStr := strings.ToLower(err.Error())
 	switch {
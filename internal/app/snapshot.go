@@ -0,0 +1,188 @@
+package app
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"slices"
+	"sort"
+	"time"
+
+	"github.com/nleiva/chatgbt/pkg/backend"
+	"github.com/nleiva/chatgbt/pkg/store"
+)
+
+// SessionSnapshot is the state of a session saved across server restarts
+type SessionSnapshot struct {
+	ID               string                  `json:"id"`
+	UserID           string                  `json:"user_id,omitempty"`
+	Tenant           string                  `json:"tenant,omitempty"`
+	EndUser          string                  `json:"end_user,omitempty"` // Already hashed
+	ConversationType string                  `json:"conversation_type"`
+	SystemPrompt     string                  `json:"system_prompt"`
+	Provider         string                  `json:"provider,omitempty"`
+	Model            string                  `json:"model,omitempty"`
+	Language         string                  `json:"language"`
+	Preferences      Preferences             `json:"preferences"`
+	Messages         []backend.Message       `json:"messages"`     // Context sent to the model, after pruning
+	Conversation     *store.Conversation     `json:"conversation"` // Full transcript
+	Metrics          *backend.SessionMetrics `json:"metrics,omitempty"`
+	LastAccess       time.Time               `json:"last_access"`
+}
+
+// metricsSnapshotter is implemented by loggers whose counters can be saved and restored
+type metricsSnapshotter interface {
+	Snapshot() backend.SessionMetrics
+	Restore(snapshot backend.SessionMetrics)
+}
+
+// Snapshot captures the session's conversation, context, preferences, and metrics
+func (s *ChatSession) Snapshot() SessionSnapshot {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	// Copies, so a request after the snapshot doesn't change it while it is written out
+	conversation := *s.conversation
+	conversation.Messages = slices.Clone(s.conversation.Messages)
+
+	provider, model := clientModelInfo(s.LLMClient, nil)
+	snapshot := SessionSnapshot{
+		ID:               s.ID,
+		UserID:           s.UserID,
+		Tenant:           s.Tenant,
+		EndUser:          s.endUser,
+		ConversationType: s.ConversationType,
+		SystemPrompt:     s.SystemPrompt,
+		Provider:         provider,
+		Model:            model,
+		Language:         s.Language,
+		Preferences:      s.Preferences(),
+		Messages:         slices.Clone(s.Messages),
+		Conversation:     &conversation,
+	}
+	if logger, ok := s.Logger.(metricsSnapshotter); ok {
+		metrics := logger.Snapshot()
+		snapshot.Metrics = &metrics
+	}
+	return snapshot
+}
+
+// restore loads the state captured by Snapshot into a freshly created session
+func (s *ChatSession) restore(snapshot SessionSnapshot) {
+	if len(snapshot.Messages) > 0 {
+		s.Messages = snapshot.Messages
+	}
+	if snapshot.Conversation != nil {
+		s.settingsMutex.Lock()
+		s.conversation = snapshot.Conversation
+		s.settingsMutex.Unlock()
+	}
+	if snapshot.Language != "" {
+		s.SetLanguage(snapshot.Language)
+	}
+	s.UpdatePreferences(func(p *Preferences) { *p = snapshot.Preferences })
+	s.endUser = snapshot.EndUser
+	if logger, ok := s.Logger.(metricsSnapshotter); ok && snapshot.Metrics != nil {
+		logger.Restore(*snapshot.Metrics)
+	}
+}
+
+// SaveSnapshot writes every session to path, replacing the file atomically, so
+// RestoreSnapshot can bring them back after a restart
+func (sm *InMemorySessionManager) SaveSnapshot(path string) error {
+	sm.mutex.RLock()
+	sessions := make([]*ChatSession, 0, len(sm.sessions))
+	lastAccess := make([]time.Time, 0, len(sm.sessions))
+	for id, session := range sm.sessions {
+		sessions = append(sessions, session)
+		lastAccess = append(lastAccess, sm.sessionAge[id])
+	}
+	sm.mutex.RUnlock()
+
+	// Snapshot waits for a session's request in flight, so take them without holding
+	// the manager's lock
+	snapshots := make([]SessionSnapshot, 0, len(sessions))
+	for i, session := range sessions {
+		snapshot := session.Snapshot()
+		snapshot.LastAccess = lastAccess[i]
+		snapshots = append(snapshots, snapshot)
+	}
+
+	data, err := json.Marshal(snapshots)
+	if err != nil {
+		return fmt.Errorf("failed to encode sessions: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create session snapshot directory: %w", err)
+	}
+	// Snapshots hold conversation text, so keep them private to the server's user
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write session snapshot: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to replace session snapshot: %w", err)
+	}
+	return nil
+}
+
+// RestoreSnapshot recreates the sessions saved by SaveSnapshot, skipping those that
+// have expired since. It returns how many were restored; a missing file restores none.
+func (sm *InMemorySessionManager) RestoreSnapshot(path string) (int, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return 0, nil
+	}
+	if err != nil {
+		return 0, fmt.Errorf("failed to read session snapshot: %w", err)
+	}
+
+	var snapshots []SessionSnapshot
+	if err := json.Unmarshal(data, &snapshots); err != nil {
+		return 0, fmt.Errorf("failed to parse session snapshot %s: %w", path, err)
+	}
+
+	// Restore oldest first so that, under a session limit, the most recent ones survive
+	sort.Slice(snapshots, func(i, j int) bool {
+		return snapshots[i].LastAccess.Before(snapshots[j].LastAccess)
+	})
+
+	restored := 0
+	for _, snapshot := range snapshots {
+		if time.Since(snapshot.LastAccess) > sm.maxAge {
+			continue
+		}
+
+		defaultConfig := sm.defaultLLMConfig()
+		llmConfig := ConfigForModel(defaultConfig, backend.ProviderName(snapshot.Provider), snapshot.Model)
+		config, generation := sm.sessionConfig(snapshot.ID, llmConfig)
+		config.UserID = snapshot.UserID
+		config.ConversationType = snapshot.ConversationType
+		typePrompt := config.SystemPrompt
+		config.SystemPrompt = snapshot.SystemPrompt
+		if snapshot.Tenant != "" {
+			if err := sm.applyTenant(&config, snapshot.Tenant); err != nil {
+				log.Printf("Warning: skipping saved session %s: %v", snapshot.ID, err)
+				continue
+			}
+		}
+
+		session, err := NewChatSession(config)
+		if err != nil {
+			log.Printf("Warning: skipping saved session %s: %v", snapshot.ID, err)
+			continue
+		}
+		session.restore(snapshot)
+		session.generation = generation
+		session.defaultModel = llmConfig == defaultConfig
+		session.typePrompt = typePrompt
+
+		sm.add(session, snapshot.LastAccess)
+		restored++
+	}
+	return restored, nil
+}
@@ -0,0 +1,123 @@
+package app
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/nleiva/chatgbt/pkg/backend"
+)
+
+// summarizePrompt asks for the structured summary produced by Summarize
+const summarizePrompt = "Summarize the conversation below for someone who wasn't part of it. " +
+	"Use Markdown with exactly these sections, each a short bulleted list:\n\n" +
+	"## Key questions\n## Answers\n## Action items\n\n" +
+	"Write \"None\" under a section with nothing to list, and write in the language of the conversation."
+
+// summaryNotePrefix starts the pinned system note that carries a conversation summary
+const summaryNotePrefix = "Summary of the conversation so far:\n\n"
+
+// Summarize asks the model for a structured summary of the conversation (key questions,
+// answers, and action items) and saves it with the persisted transcript, where exports
+// include it. When the transcript is longer than the context limit, its oldest messages
+// are left out.
+func (s *ChatSession) Summarize(ctx context.Context) (string, error) {
+	ctx, err := s.startGeneration(ctx)
+	if err != nil {
+		return "", err
+	}
+	defer s.endGeneration()
+	if s.turns() == 0 {
+		return "", errors.New("nothing to summarize yet")
+	}
+	if err := s.costCeiling.Check(); err != nil {
+		return "", err
+	}
+
+	req := &backend.ChatCompletionRequest{Messages: []backend.Message{
+		{Role: backend.RoleSystem, Content: summarizePrompt},
+		{Role: backend.RoleUser, Content: s.summaryTranscript()},
+	}, User: s.endUser}
+	if _, err := s.preflight(req, s.LLMClient); err != nil {
+		return "", err
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, time.Minute)
+	defer cancel()
+
+	costBefore := s.Logger.GetBudgetStatus().SessionCost
+	start := time.Now()
+	resp, err := s.LLMClient.CreateCompletion(ctx, req)
+	logCompletion(s.Logger, s.LLMClient, resp, time.Since(start), err, "summary")
+	s.costCeiling.Add(s.ID, s.Logger.GetBudgetStatus().SessionCost-costBefore)
+	if err != nil {
+		return "", err
+	}
+	if len(resp.Choices) == 0 || strings.TrimSpace(resp.Choices[0].Message.Content) == "" {
+		return "", errors.New("the model returned an empty summary")
+	}
+
+	summary := strings.TrimSpace(resp.Choices[0].Message.Content)
+	s.conversation.Summary = summary
+	s.conversation.UpdatedAt = time.Now()
+	_ = s.Store.Save(s.conversation)
+	return summary, nil
+}
+
+// summaryTranscript formats the persisted transcript for summarizing, dropping the
+// oldest messages that don't fit in the context limit
+func (s *ChatSession) summaryTranscript() string {
+	messages := make([]backend.Message, 0, len(s.conversation.Messages))
+	for _, msg := range s.conversation.Messages {
+		messages = append(messages, backend.Message{Role: msg.Role, Content: msg.Content})
+	}
+
+	_, model := clientModelInfo(s.LLMClient, nil)
+	counts, _ := backend.CountMessageTokens(model, messages)
+	limit := s.ContextManager.GetContextStats(s.Messages).TokenLimit
+	start, total := len(messages), 0
+	for start > 0 && (limit <= 0 || total+counts[start-1].Tokens <= limit) {
+		start--
+		total += counts[start].Tokens
+	}
+	start = min(start, len(messages)-1) // Always include the latest message
+
+	var b strings.Builder
+	if start > 0 {
+		fmt.Fprintf(&b, "(The first %d messages are omitted.)\n\n", start)
+	}
+	for _, msg := range messages[start:] {
+		role := "User"
+		if msg.Role == backend.RoleAssistant {
+			role = "Assistant"
+		}
+		fmt.Fprintf(&b, "%s: %s\n\n", role, msg.Content)
+	}
+	return b.String()
+}
+
+// PinSummary keeps summary in the context as a pinned system note after the system
+// prompt and few-shot examples, so pruning never drops it. It replaces any summary
+// pinned before.
+func (s *ChatSession) PinSummary(summary string) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	start := 0
+	if len(s.Messages) > 0 && s.Messages[0].Role == backend.RoleSystem {
+		start = 1
+	}
+	s.Messages = slices.DeleteFunc(s.Messages, func(msg backend.Message) bool {
+		return msg.Pinned && msg.Role == backend.RoleSystem && strings.HasPrefix(msg.Content, summaryNotePrefix)
+	})
+	for start < len(s.Messages) && s.Messages[start].Pinned {
+		start++
+	}
+	s.Messages = slices.Insert(s.Messages, start, backend.Message{
+		Role:    backend.RoleSystem,
+		Content: summaryNotePrefix + summary,
+		Pinned:  true,
+	})
+}
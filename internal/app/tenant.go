@@ -0,0 +1,137 @@
+package app
+
+import (
+	"cmp"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/nleiva/chatgbt/pkg/backend"
+	"github.com/nleiva/chatgbt/pkg/store"
+)
+
+// Tenant keeps the sessions, conversations, logs, and budget of one group of users,
+// such as a class, apart from the others sharing a server
+type Tenant struct {
+	Name   string
+	Budget backend.TokenBudgetConfig // Session budget, with LogsDir pointing at the tenant's logs
+	Store  store.Store               // The tenant's conversations
+
+	// DailyCost caps the USD spend of the tenant's sessions per UTC day; 0 for the
+	// manager's TenantDailyCost
+	DailyCost float64
+}
+
+// TenantResolver loads the tenant called name, whose budget builds on budget
+type TenantResolver func(name string, budget backend.TokenBudgetConfig) (*Tenant, error)
+
+// TenantUsage sums what a tenant's sessions have used
+type TenantUsage struct {
+	Name           string
+	Sessions       int     // Active sessions
+	Requests       int     // Across active sessions
+	Tokens         int     // Across active sessions
+	Cost           float64 // Across active sessions
+	DailyCost      float64 // Spent today (UTC), including closed sessions; needs a tenant daily cost limit
+	DailyCostLimit float64 // 0 when there is none
+}
+
+// SetTenantResolver enables tenant sessions, resolving tenants by name with resolve
+func (sm *InMemorySessionManager) SetTenantResolver(resolve TenantResolver) {
+	sm.mutex.Lock()
+	sm.tenants = resolve
+	sm.mutex.Unlock()
+}
+
+// CreateTenantSession creates a session for a user of tenant, which logs, saves
+// conversations, and spends against the budget and daily cost limit of that tenant only
+func (sm *InMemorySessionManager) CreateTenantSession(userID, tenant string) (*ChatSession, error) {
+	sessionID := GenerateSessionID(userID)
+
+	config, generation := sm.sessionConfig(sessionID, sm.defaultLLMConfig())
+	config.UserID = userID
+	if err := sm.applyTenant(&config, tenant); err != nil {
+		return nil, NewSessionError("failed to create session", sessionID, err)
+	}
+	session, err := NewChatSession(config)
+	if err != nil {
+		return nil, NewSessionError("failed to create session", sessionID, err)
+	}
+	session.generation, session.defaultModel = generation, true
+	session.typePrompt = config.SystemPrompt
+
+	sm.add(session, time.Now())
+	return session, nil
+}
+
+// applyTenant points config at the budget, store, and cost ceiling of tenant. A tenant
+// with a daily cost limit, its own or TenantDailyCost, gets a ceiling of that limit,
+// so it can't use up more than its share of the global one it is nested in, which
+// still caps the spend of all tenants together. Without one, the tenant only shares
+// the global ceiling. The limit is read when the tenant's first session starts.
+func (sm *InMemorySessionManager) applyTenant(config *SessionConfig, tenant string) error {
+	sm.mutex.Lock()
+	defer sm.mutex.Unlock()
+
+	if sm.tenants == nil {
+		return fmt.Errorf("tenant %s: tenants are not enabled", tenant)
+	}
+	t, err := sm.tenants(tenant, sm.budgetConfig)
+	if err != nil {
+		return fmt.Errorf("tenant %s: %w", tenant, err)
+	}
+
+	config.Tenant = t.Name
+	config.BudgetConfig = t.Budget
+	config.Store = t.Store
+	ceiling, ok := sm.tenantCeilings[t.Name]
+	if !ok {
+		limit := cmp.Or(t.DailyCost, sm.opts.TenantDailyCost)
+		if limit <= 0 {
+			return nil
+		}
+		ceiling = sm.costCeiling.Nested(limit, sm.opts.Notifier)
+		sm.tenantCeilings[t.Name] = ceiling
+	}
+	config.CostCeiling = ceiling
+	return nil
+}
+
+// TenantUsage returns the usage of every tenant with active sessions or spend today,
+// sorted by name
+func (sm *InMemorySessionManager) TenantUsage() []TenantUsage {
+	usage := make(map[string]*TenantUsage)
+	get := func(name string) *TenantUsage {
+		u, ok := usage[name]
+		if !ok {
+			u = &TenantUsage{Name: name}
+			usage[name] = u
+		}
+		return u
+	}
+
+	for _, info := range sm.ListSessions() {
+		if info.Tenant == "" {
+			continue
+		}
+		u := get(info.Tenant)
+		u.Sessions++
+		u.Requests += info.Requests
+		u.Tokens += info.Tokens
+		u.Cost += info.Cost
+	}
+
+	sm.mutex.RLock()
+	for name, ceiling := range sm.tenantCeilings {
+		u := get(name)
+		u.DailyCost, u.DailyCostLimit = ceiling.Spent()
+	}
+	sm.mutex.RUnlock()
+
+	result := make([]TenantUsage, 0, len(usage))
+	for _, u := range usage {
+		result = append(result, *u)
+	}
+	sort.Slice(result, func(i, j int) bool { return result[i].Name < result[j].Name })
+	return result
+}
@@ -0,0 +1,99 @@
+package app
+
+import (
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/nleiva/chatgbt/pkg/backend"
+)
+
+func TestApplyTenantCostCeiling(t *testing.T) {
+	tests := []struct {
+		name            string
+		maxDailyCost    float64
+		tenantDailyCost float64
+		ownDailyCost    float64
+		wantLimit       float64 // 0 when the tenant only shares the global ceiling
+	}{
+		{name: "own limit", maxDailyCost: 10, tenantDailyCost: 2, ownDailyCost: 1, wantLimit: 1},
+		{name: "default limit", maxDailyCost: 10, tenantDailyCost: 2, wantLimit: 2},
+		{name: "own limit without global cap", ownDailyCost: 3, wantLimit: 3},
+		{name: "no tenant limit", maxDailyCost: 10},
+		{name: "no limits"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			sm := newTenantManager(tt.maxDailyCost, tt.tenantDailyCost, map[string]float64{"a": tt.ownDailyCost})
+			config, _ := sm.sessionConfig("s1", sm.defaultLLMConfig())
+			if err := sm.applyTenant(&config, "a"); err != nil {
+				t.Fatalf("applyTenant: %v", err)
+			}
+
+			if tt.wantLimit == 0 {
+				if config.CostCeiling != sm.costCeiling {
+					t.Errorf("CostCeiling = %p, want the global ceiling %p", config.CostCeiling, sm.costCeiling)
+				}
+				return
+			}
+			if _, limit := config.CostCeiling.Spent(); limit != tt.wantLimit {
+				t.Errorf("tenant limit = %v, want %v", limit, tt.wantLimit)
+			}
+			if config.CostCeiling.parent != sm.costCeiling {
+				t.Errorf("tenant ceiling is not nested in the global one")
+			}
+		})
+	}
+}
+
+func TestTenantCeilingsAreIsolated(t *testing.T) {
+	sm := newTenantManager(10, 2, map[string]float64{"a": 0, "b": 0, "big": 9})
+	ceilings := make(map[string]*CostCeiling)
+	for _, tenant := range []string{"a", "b", "big"} {
+		config, _ := sm.sessionConfig("s-"+tenant, sm.defaultLLMConfig())
+		if err := sm.applyTenant(&config, tenant); err != nil {
+			t.Fatalf("applyTenant(%s): %v", tenant, err)
+		}
+		ceilings[tenant] = config.CostCeiling
+	}
+
+	steps := []struct {
+		spender string
+		cost    float64
+		blocked map[string]bool // Tenants whose next request is refused after the spend
+	}{
+		{spender: "a", cost: 1.5, blocked: map[string]bool{}},
+		{spender: "a", cost: 0.5, blocked: map[string]bool{"a": true}},
+		{spender: "big", cost: 7, blocked: map[string]bool{"a": true}},
+		// The global $10 is now $9 spent; b's $1 reaches it for everyone
+		{spender: "b", cost: 1, blocked: map[string]bool{"a": true, "b": true, "big": true}},
+	}
+	for i, step := range steps {
+		ceilings[step.spender].Add("s-"+step.spender, step.cost)
+		for tenant, ceiling := range ceilings {
+			var exceeded *DailyCostExceededError
+			blocked := errors.As(ceiling.Check(), &exceeded)
+			if blocked != step.blocked[tenant] {
+				t.Errorf("step %d: tenant %s blocked = %v, want %v", i, tenant, blocked, step.blocked[tenant])
+			}
+		}
+	}
+}
+
+// newTenantManager returns a session manager whose tenants have the given daily cost
+// limits, 0 for none of their own
+func newTenantManager(maxDailyCost, tenantDailyCost float64, limits map[string]float64) *InMemorySessionManager {
+	sm := NewInMemorySessionManager(backend.LLMConfig{}, backend.TokenBudgetConfig{}, time.Hour, "web", SessionOptions{
+		MaxDailyCost:    maxDailyCost,
+		TenantDailyCost: tenantDailyCost,
+	})
+	sm.SetTenantResolver(func(name string, budget backend.TokenBudgetConfig) (*Tenant, error) {
+		limit, ok := limits[name]
+		if !ok {
+			return nil, errors.New("unknown tenant")
+		}
+		return &Tenant{Name: name, Budget: budget, DailyCost: limit}, nil
+	})
+	return sm
+}
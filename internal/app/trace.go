@@ -0,0 +1,90 @@
+package app
+
+import (
+	"context"
+	"crypto/tls"
+	"fmt"
+	"io"
+	"net/http/httptrace"
+	"strings"
+	"sync"
+	"time"
+)
+
+// requestTrace reports the timing phases of every HTTP attempt made with its context,
+// so retries show up as further attempts
+type requestTrace struct {
+	mutex    sync.Mutex
+	writer   io.Writer
+	attempts int
+	start    time.Time
+	phases   []string
+	phase    time.Time // Start of the phase in progress
+}
+
+// traceRequests returns a context that reports the HTTP attempts made with it to w
+func traceRequests(ctx context.Context, w io.Writer) context.Context {
+	t := &requestTrace{writer: w}
+	return httptrace.WithClientTrace(ctx, &httptrace.ClientTrace{
+		GetConn: func(hostPort string) {
+			t.mutex.Lock()
+			defer t.mutex.Unlock()
+			t.attempts++
+			t.start = time.Now()
+			t.phases = nil
+		},
+		GotConn: func(info httptrace.GotConnInfo) {
+			if info.Reused {
+				t.record("connection reused", time.Time{})
+			}
+		},
+		DNSStart:          func(httptrace.DNSStartInfo) { t.begin() },
+		DNSDone:           func(httptrace.DNSDoneInfo) { t.end("dns") },
+		ConnectStart:      func(string, string) { t.begin() },
+		ConnectDone:       func(string, string, error) { t.end("connect") },
+		TLSHandshakeStart: func() { t.begin() },
+		TLSHandshakeDone:  func(tls.ConnectionState, error) { t.end("tls") },
+		WroteRequest:      func(httptrace.WroteRequestInfo) { t.begin() },
+		GotFirstResponseByte: func() {
+			t.end("server")
+			t.report()
+		},
+	})
+}
+
+// begin marks the start of a phase
+func (t *requestTrace) begin() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.phase = time.Now()
+}
+
+// end records the phase that began last as name
+func (t *requestTrace) end(name string) {
+	t.mutex.Lock()
+	start := t.phase
+	t.mutex.Unlock()
+	t.record(name, start)
+}
+
+// record adds a phase; without a start time it's recorded without a duration
+func (t *requestTrace) record(name string, start time.Time) {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	if !start.IsZero() {
+		name = fmt.Sprintf("%s %v", name, time.Since(start).Round(time.Millisecond))
+	}
+	t.phases = append(t.phases, name)
+}
+
+// report prints the phases of the attempt that just got its first response byte
+func (t *requestTrace) report() {
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	label := "attempt"
+	if t.attempts > 1 {
+		label = "retry"
+	}
+	fmt.Fprintf(t.writer, "> %s %d: %s, first byte after %v\n", label, t.attempts,
+		strings.Join(t.phases, ", "), time.Since(t.start).Round(time.Millisecond))
+}
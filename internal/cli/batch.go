@@ -0,0 +1,393 @@
+package cli
+
+import (
+	"cmp"
+	"context"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+
+	"github.com/nleiva/chatgbt/internal/app"
+	"github.com/nleiva/chatgbt/pkg/backend"
+	"github.com/nleiva/chatgbt/pkg/llm"
+	"github.com/nleiva/chatgbt/pkg/store"
+)
+
+// BatchRunner answers every prompt in a file, either one request at a time or as a
+// single job on OpenAI's Batch API
+type BatchRunner struct {
+	path         string
+	output       string
+	model        string
+	system       string
+	openAIBatch  bool
+	stateFile    string
+	pollInterval time.Duration
+	transcript   string
+	writer       io.Writer
+}
+
+// batchState is persisted while an OpenAI batch job is pending so that an interrupted
+// run can resume polling instead of submitting the prompts again
+type batchState struct {
+	BatchID     string    `json:"batch_id"`
+	InputHash   string    `json:"input_hash"` // Prompts, system prompt, and model the job was submitted with
+	Model       string    `json:"model"`
+	SubmittedAt time.Time `json:"submitted_at"`
+}
+
+// batchOutput is one line of the results file
+type batchOutput struct {
+	ID       string         `json:"id"`
+	Prompt   string         `json:"prompt"`
+	Response string         `json:"response,omitempty"`
+	Model    string         `json:"model,omitempty"`
+	Usage    *backend.Usage `json:"usage,omitempty"`
+	Error    string         `json:"error,omitempty"`
+}
+
+// NewBatchRunner parses the batch subcommand arguments
+func NewBatchRunner(args []string) (*BatchRunner, error) {
+	fs := flag.NewFlagSet("batch", flag.ContinueOnError)
+	output := fs.String("o", "", "Write JSONL results to this file instead of stdout")
+	model := fs.String("model", "", "provider:model to use (overrides MODEL)")
+	system := fs.String("system", "", "System prompt sent with every prompt")
+	openAIBatch := fs.Bool("openai-batch", false, "Submit through OpenAI's Batch API (half price, results within 24h)")
+	stateFile := fs.String("state", "", "Job state file used to resume an --openai-batch run (default: <file>.batch.json)")
+	pollInterval := fs.Duration("poll", 30*time.Second, "How often to check an --openai-batch job")
+	transcript := fs.String("transcript", "", "Append each prompt and its answer to this Markdown file")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() != 1 {
+		return nil, fmt.Errorf("usage: batch [flags] prompts.txt")
+	}
+	if *pollInterval <= 0 {
+		return nil, fmt.Errorf("--poll must be positive, got %v", *pollInterval)
+	}
+
+	runner := &BatchRunner{
+		path:         fs.Arg(0),
+		output:       *output,
+		model:        *model,
+		system:       *system,
+		openAIBatch:  *openAIBatch,
+		stateFile:    *stateFile,
+		pollInterval: *pollInterval,
+		transcript:   *transcript,
+		writer:       os.Stdout,
+	}
+	if runner.stateFile == "" {
+		runner.stateFile = runner.path + ".batch.json"
+	}
+	return runner, nil
+}
+
+// Run answers the prompts and writes one JSON result per prompt, in input order
+func (b *BatchRunner) Run(cfg backend.LLMConfig, budgetCfg backend.TokenBudgetConfig) error {
+	data, err := os.ReadFile(b.path)
+	if err != nil {
+		return fmt.Errorf("failed to read prompt file: %w", err)
+	}
+	prompts := splitPrompts(string(data))
+	if len(prompts) == 0 {
+		return fmt.Errorf("no prompts found in %s", b.path)
+	}
+
+	if b.model != "" {
+		cfg = app.ParseModelSpec(cfg, b.model)
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	var results []batchOutput
+	if b.openAIBatch {
+		if cfg.Provider != backend.ProviderNameOpenAI {
+			return fmt.Errorf("--openai-batch requires the openai provider, got %s", cfg.Provider)
+		}
+		// Batch API requests are billed at a discount
+		budgetCfg.CostPerToken *= backend.OpenAIBatchDiscount
+		results, err = b.runOpenAIBatch(ctx, cfg, prompts)
+	} else {
+		results, err = b.runSequential(ctx, cfg, prompts)
+	}
+	if err != nil {
+		return err
+	}
+
+	if err := b.writeResults(results); err != nil {
+		return err
+	}
+	if err := b.appendTranscript(cfg.Model, results); err != nil {
+		return err
+	}
+	return b.logUsage(cfg, budgetCfg, results)
+}
+
+// request builds the chat completion request for a prompt
+func (b *BatchRunner) request(prompt string) *backend.ChatCompletionRequest {
+	var messages []backend.Message
+	if b.system != "" {
+		messages = append(messages, backend.Message{Role: backend.RoleSystem, Content: b.system})
+	}
+	messages = append(messages, backend.Message{Role: backend.RoleUser, Content: prompt})
+	return &backend.ChatCompletionRequest{Messages: messages}
+}
+
+// runSequential sends the prompts one at a time
+func (b *BatchRunner) runSequential(ctx context.Context, cfg backend.LLMConfig, prompts []string) ([]batchOutput, error) {
+	client, err := llm.NewClient(cfg, 60*time.Second)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create LLM client: %w", err)
+	}
+
+	results := make([]batchOutput, len(prompts))
+	for i, prompt := range prompts {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		fmt.Fprintf(os.Stderr, "Prompt %d/%d...\n", i+1, len(prompts))
+
+		reqCtx, cancel := context.WithTimeout(ctx, 60*time.Second)
+		resp, err := client.CreateCompletion(reqCtx, b.request(prompt))
+		cancel()
+		results[i] = newBatchOutput(batchID(i), prompt, resp, err)
+	}
+	return results, nil
+}
+
+// runOpenAIBatch submits the prompts as one Batch API job, or resumes the job recorded
+// in the state file, then waits for it and merges the results with the prompts
+func (b *BatchRunner) runOpenAIBatch(ctx context.Context, cfg backend.LLMConfig, prompts []string) ([]batchOutput, error) {
+	client := backend.NewOpenAIBatchClient(backend.ProviderConfig{
+		Name:   cfg.Provider,
+		APIKey: cfg.APIKey,
+		URL:    cfg.URL,
+		Model:  cfg.Model,
+
+		Organization: cfg.Organization,
+		Project:      cfg.Project,
+	})
+	hash := b.inputHash(cfg.Model, prompts)
+
+	state, err := loadBatchState(b.stateFile)
+	if err != nil {
+		return nil, err
+	}
+	if state != nil && state.InputHash != hash {
+		return nil, fmt.Errorf("%s belongs to a job for different prompts or model; remove it to submit a new job", b.stateFile)
+	}
+
+	if state != nil {
+		fmt.Fprintf(os.Stderr, "Resuming batch %s submitted %s\n", state.BatchID, state.SubmittedAt.Format(time.RFC3339))
+	} else {
+		requests := make([]backend.OpenAIBatchRequest, len(prompts))
+		for i, prompt := range prompts {
+			requests[i] = backend.OpenAIBatchRequest{CustomID: batchID(i), Request: b.request(prompt)}
+		}
+		batch, err := client.Submit(ctx, requests)
+		if err != nil {
+			return nil, err
+		}
+		state = &batchState{BatchID: batch.ID, InputHash: hash, Model: cfg.Model, SubmittedAt: time.Now()}
+		if err := saveBatchState(b.stateFile, state); err != nil {
+			return nil, err
+		}
+		fmt.Fprintf(os.Stderr, "Submitted batch %s with %d prompt(s); state saved to %s\n", batch.ID, len(prompts), b.stateFile)
+	}
+
+	lastStatus := ""
+	batch, err := client.Wait(ctx, state.BatchID, b.pollInterval, func(batch *backend.OpenAIBatch) {
+		status := fmt.Sprintf("%s (%d/%d done, %d failed)", batch.Status,
+			batch.RequestCounts.Completed, batch.RequestCounts.Total, batch.RequestCounts.Failed)
+		if status != lastStatus {
+			fmt.Fprintf(os.Stderr, "Batch %s: %s\n", batch.ID, status)
+			lastStatus = status
+		}
+	})
+	if err != nil {
+		if errors.Is(err, context.Canceled) {
+			return nil, fmt.Errorf("interrupted; run the same command again to resume batch %s", state.BatchID)
+		}
+		return nil, err
+	}
+	if batch.Status != backend.BatchStatusCompleted && batch.OutputFileID == "" && batch.ErrorFileID == "" {
+		os.Remove(b.stateFile)
+		return nil, fmt.Errorf("batch %s %s: %s", batch.ID, batch.Status, batchErrors(batch))
+	}
+
+	batchResults, err := client.Results(ctx, batch)
+	if err != nil {
+		return nil, err
+	}
+	byID := make(map[string]backend.OpenAIBatchResult, len(batchResults))
+	for _, r := range batchResults {
+		byID[r.CustomID] = r
+	}
+
+	results := make([]batchOutput, len(prompts))
+	for i, prompt := range prompts {
+		r, ok := byID[batchID(i)]
+		if !ok {
+			r.Err = fmt.Errorf("no result returned (batch %s)", batch.Status)
+		}
+		results[i] = newBatchOutput(batchID(i), prompt, r.Response, r.Err)
+	}
+
+	if err := os.Remove(b.stateFile); err != nil && !os.IsNotExist(err) {
+		return nil, fmt.Errorf("failed to remove batch state: %w", err)
+	}
+	return results, nil
+}
+
+// inputHash identifies the job a state file was written for
+func (b *BatchRunner) inputHash(model string, prompts []string) string {
+	h := sha256.New()
+	fmt.Fprintf(h, "%s\x00%s\x00", model, b.system)
+	for _, prompt := range prompts {
+		fmt.Fprintf(h, "%s\x00", prompt)
+	}
+	return hex.EncodeToString(h.Sum(nil))
+}
+
+// writeResults writes the results as JSONL to the output file or stdout
+func (b *BatchRunner) writeResults(results []batchOutput) error {
+	w := b.writer
+	if b.output != "" {
+		f, err := os.Create(b.output)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer f.Close()
+		w = f
+	}
+
+	encoder := json.NewEncoder(w)
+	for _, r := range results {
+		if err := encoder.Encode(r); err != nil {
+			return fmt.Errorf("failed to write results: %w", err)
+		}
+	}
+	return nil
+}
+
+// appendTranscript adds the answered prompts to the --transcript file, in input order
+func (b *BatchRunner) appendTranscript(model string, results []batchOutput) error {
+	if b.transcript == "" {
+		return nil
+	}
+	transcript := store.NewTranscript(b.transcript)
+	for _, r := range results {
+		if r.Error != "" {
+			continue
+		}
+		err := transcript.Append(cmp.Or(r.Model, model),
+			backend.Message{Role: backend.RoleUser, Content: r.Prompt},
+			backend.Message{Role: backend.RoleAssistant, Content: r.Response})
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// logUsage records every result in the metrics log and prints a summary
+func (b *BatchRunner) logUsage(cfg backend.LLMConfig, budgetCfg backend.TokenBudgetConfig, results []batchOutput) error {
+	logger, err := app.NewMetricsLogger(app.GenerateSessionID("batch"), "batch", budgetCfg)
+	if err != nil {
+		return fmt.Errorf("failed to create metrics logger: %w", err)
+	}
+	defer logger.Close()
+
+	failed := 0
+	for _, r := range results {
+		interaction := backend.InteractionLog{
+			Success:    r.Error == "",
+			PromptType: "batch",
+			Provider:   string(cfg.Provider),
+			Model:      cfg.Model,
+			Usage:      r.Usage,
+			ErrorType:  r.Error,
+		}
+		if r.Model != "" {
+			interaction.Model = r.Model
+		}
+		if r.Error != "" {
+			failed++
+		}
+		logger.LogInteraction(interaction)
+	}
+
+	summary := logger.GetSessionSummary()
+	fmt.Fprintf(os.Stderr, "Done: %d/%d succeeded, %d tokens, $%.4f\n",
+		len(results)-failed, len(results), summary.TotalTokens, summary.EstimatedCost)
+	return nil
+}
+
+// loadBatchState reads a saved job, returning nil when there is none
+func loadBatchState(path string) (*batchState, error) {
+	data, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read batch state: %w", err)
+	}
+
+	var state batchState
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("failed to parse batch state %s: %w", path, err)
+	}
+	return &state, nil
+}
+
+// saveBatchState records a submitted job
+func saveBatchState(path string, state *batchState) error {
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to marshal batch state: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0o600); err != nil {
+		return fmt.Errorf("failed to save batch state: %w", err)
+	}
+	return nil
+}
+
+// newBatchOutput builds the result line for a prompt
+func newBatchOutput(id, prompt string, resp *backend.ChatCompletionResponse, err error) batchOutput {
+	out := batchOutput{ID: id, Prompt: prompt}
+	switch {
+	case err != nil:
+		out.Error = err.Error()
+	case resp == nil || len(resp.Choices) == 0:
+		out.Error = "empty response"
+	default:
+		out.Response = resp.Choices[0].Message.Content
+		out.Model = resp.Model
+		out.Usage = resp.Usage
+	}
+	return out
+}
+
+// batchID is the custom ID of the prompt at index i
+func batchID(i int) string {
+	return fmt.Sprintf("prompt-%d", i+1)
+}
+
+// batchErrors describes why a job failed
+func batchErrors(batch *backend.OpenAIBatch) string {
+	if batch.Errors == nil || len(batch.Errors.Data) == 0 {
+		return "no results"
+	}
+	e := batch.Errors.Data[0]
+	return fmt.Sprintf("%s (code: %s)", e.Message, e.Code)
+}
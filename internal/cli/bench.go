@@ -0,0 +1,229 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/nleiva/chatgbt/internal/app"
+	"github.com/nleiva/chatgbt/pkg/backend"
+	"github.com/nleiva/chatgbt/pkg/llm"
+)
+
+// benchDefaultModels are used for providers that aren't the configured one
+// when no model is given through --models
+var benchDefaultModels = map[backend.ProviderName]string{
+	backend.ProviderNameOpenAI:    "gpt-4o-mini",
+	backend.ProviderNameAnthropic: "claude-3-5-haiku-latest",
+	backend.ProviderNameOllama:    "llama3.2",
+}
+
+// BenchRunner runs the same prompts across several providers and compares the results
+type BenchRunner struct {
+	providers []backend.ProviderName
+	models    map[backend.ProviderName]string
+	prompts   []string
+	runs      int
+	writer    io.Writer
+}
+
+// benchResult aggregates the measurements for a single provider
+type benchResult struct {
+	provider    backend.ProviderName
+	model       string
+	summary     backend.SessionSummary
+	outputChars int
+	successes   int
+	lastErr     error
+}
+
+// NewBenchRunner parses the bench subcommand arguments
+func NewBenchRunner(args []string) (*BenchRunner, error) {
+	fs := flag.NewFlagSet("bench", flag.ContinueOnError)
+	providers := fs.String("providers", "openai", "Comma-separated providers to benchmark (openai, anthropic, ollama, groq, mistral, deepseek, openrouter, xai, huggingface, vertex)")
+	models := fs.String("models", "", "Comma-separated provider=model overrides, e.g. openai=gpt-4o,anthropic=claude-3-5-sonnet-latest")
+	promptFile := fs.String("prompt-file", "", "File with prompts separated by lines containing only ---")
+	prompt := fs.String("prompt", "", "Single prompt to benchmark (alternative to --prompt-file)")
+	runs := fs.Int("runs", 1, "Number of times each prompt is sent to each provider")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	runner := &BenchRunner{
+		models: make(map[backend.ProviderName]string),
+		runs:   *runs,
+		writer: os.Stdout,
+	}
+	if runner.runs < 1 {
+		return nil, fmt.Errorf("--runs must be at least 1, got %d", runner.runs)
+	}
+
+	for _, name := range strings.Split(*providers, ",") {
+		if name = strings.TrimSpace(name); name != "" {
+			runner.providers = append(runner.providers, backend.ProviderName(name))
+		}
+	}
+	if len(runner.providers) == 0 {
+		return nil, fmt.Errorf("at least one provider is required")
+	}
+
+	for _, pair := range strings.Split(*models, ",") {
+		if pair = strings.TrimSpace(pair); pair == "" {
+			continue
+		}
+		provider, model, ok := strings.Cut(pair, "=")
+		if !ok {
+			return nil, fmt.Errorf("invalid --models entry %q, expected provider=model", pair)
+		}
+		runner.models[backend.ProviderName(strings.TrimSpace(provider))] = strings.TrimSpace(model)
+	}
+
+	switch {
+	case *promptFile != "":
+		data, err := os.ReadFile(*promptFile)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read prompt file: %w", err)
+		}
+		runner.prompts = splitPrompts(string(data))
+	case *prompt != "":
+		runner.prompts = []string{*prompt}
+	}
+	if len(runner.prompts) == 0 {
+		return nil, fmt.Errorf("no prompts given: use --prompt-file or --prompt")
+	}
+
+	return runner, nil
+}
+
+// splitPrompts splits a prompt file on lines containing only "---"
+func splitPrompts(content string) []string {
+	var prompts []string
+	var current []string
+	flush := func() {
+		if prompt := strings.TrimSpace(strings.Join(current, "\n")); prompt != "" {
+			prompts = append(prompts, prompt)
+		}
+		current = current[:0]
+	}
+
+	for _, line := range strings.Split(content, "\n") {
+		if strings.TrimSpace(line) == "---" {
+			flush()
+			continue
+		}
+		current = append(current, strings.TrimRight(line, "\r"))
+	}
+	flush()
+
+	return prompts
+}
+
+// Run executes the benchmark and prints a comparison table
+func (b *BenchRunner) Run(cfg backend.LLMConfig, budgetCfg backend.TokenBudgetConfig) error {
+	results := make([]benchResult, 0, len(b.providers))
+	for _, provider := range b.providers {
+		providerCfg := b.providerConfig(cfg, provider)
+		fmt.Fprintf(b.writer, "Benchmarking %s (%s) with %d prompt(s) x %d run(s)...\n",
+			provider, providerCfg.Model, len(b.prompts), b.runs)
+
+		result, err := b.benchProvider(providerCfg, budgetCfg)
+		if err != nil {
+			fmt.Fprintf(b.writer, "  skipped: %v\n", err)
+			continue
+		}
+		results = append(results, result)
+	}
+
+	b.printResults(results)
+	return nil
+}
+
+// providerConfig derives the LLM configuration for one benchmarked provider
+func (b *BenchRunner) providerConfig(cfg backend.LLMConfig, provider backend.ProviderName) backend.LLMConfig {
+	model, ok := b.models[provider]
+	if !ok && provider != cfg.Provider {
+		model = benchDefaultModels[provider]
+	}
+	return app.ConfigForModel(cfg, provider, model)
+}
+
+// benchProvider sends every prompt to a single provider
+func (b *BenchRunner) benchProvider(cfg backend.LLMConfig, budgetCfg backend.TokenBudgetConfig) (benchResult, error) {
+	client, err := llm.NewClient(cfg, 60*time.Second)
+	if err != nil {
+		return benchResult{}, err
+	}
+
+	logger, err := app.NewMetricsLogger(app.GenerateSessionID("bench_"+string(cfg.Provider)), "bench", budgetCfg)
+	if err != nil {
+		return benchResult{}, fmt.Errorf("failed to create metrics logger: %w", err)
+	}
+	defer logger.Close()
+
+	result := benchResult{provider: cfg.Provider, model: cfg.Model}
+	for run := 0; run < b.runs; run++ {
+		for _, prompt := range b.prompts {
+			ctx, cancel := context.WithTimeout(context.Background(), 60*time.Second)
+			start := time.Now()
+			resp, err := client.CreateCompletion(ctx, &backend.ChatCompletionRequest{
+				Messages: []backend.Message{{Role: backend.RoleUser, Content: prompt}},
+			})
+			responseTime := time.Since(start)
+			cancel()
+
+			interaction := backend.InteractionLog{
+				ResponseTime: responseTime,
+				Success:      err == nil,
+				PromptType:   "bench",
+				Provider:     string(cfg.Provider),
+				Model:        cfg.Model,
+			}
+			if err != nil {
+				interaction.ErrorType = err.Error()
+				result.lastErr = err
+			} else {
+				interaction.Usage = resp.Usage
+				if resp.Model != "" {
+					interaction.Model = resp.Model
+				}
+				if len(resp.Choices) > 0 {
+					result.outputChars += len(resp.Choices[0].Message.Content)
+				}
+				result.successes++
+			}
+			logger.LogInteraction(interaction)
+		}
+	}
+
+	result.summary = logger.GetSessionSummary()
+	return result, nil
+}
+
+// printResults renders the comparison table
+func (b *BenchRunner) printResults(results []benchResult) {
+	fmt.Fprintln(b.writer)
+	tw := tabwriter.NewWriter(b.writer, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "PROVIDER\tMODEL\tOK\tAVG\tP50\tP95\tTOKENS\tCOST\tAVG CHARS")
+	for _, r := range results {
+		avgChars := 0
+		if r.successes > 0 {
+			avgChars = r.outputChars / r.successes
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%d/%d\t%dms\t%dms\t%dms\t%d\t$%.4f\t%d\n",
+			r.provider, r.model, r.successes, r.summary.TotalRequests,
+			r.summary.AvgResponseTime, r.summary.Latency.P50, r.summary.Latency.P95,
+			r.summary.TotalTokens, r.summary.EstimatedCost, avgChars)
+	}
+	tw.Flush()
+
+	for _, r := range results {
+		if r.lastErr != nil {
+			fmt.Fprintf(b.writer, "\n%s last error: %v\n", r.provider, r.lastErr)
+		}
+	}
+}
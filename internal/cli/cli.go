@@ -3,40 +3,70 @@ package cli
 import (
 	"bufio"
 	"context"
+	"errors"
+	"flag"
 	"fmt"
+	"io"
+	"maps"
 	"os"
+	"os/signal"
+	"path/filepath"
+	"slices"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
+	"github.com/atotto/clipboard"
+
 	"github.com/nleiva/chatgbt/internal/app"
+	"github.com/nleiva/chatgbt/internal/cli/render"
 	"github.com/nleiva/chatgbt/pkg/backend"
+	"github.com/nleiva/chatgbt/pkg/i18n"
 	"github.com/nleiva/chatgbt/pkg/llm"
+	"github.com/nleiva/chatgbt/pkg/store"
 )
 
 const (
-	cmdExit   = "exit"
-	cmdReset  = "/reset"
-	cmdSystem = "/system"
-	cmdBudget = "/budget"
-	cmdStats  = "/stats"
-	cmdPrune  = "/prune"
+	cmdExit     = "exit"
+	cmdReset    = "/reset"
+	cmdSystem   = "/system"
+	cmdBudget   = "/budget"
+	cmdStats    = "/stats"
+	cmdPrune    = "/prune"
+	cmdTokens   = "/tokens"
+	cmdContinue = "/continue"
+
+	cmdSummarize = "/summarize"
+	cmdCopy      = "/copy"
 )
 
 // CLIHandler handles the CLI-specific UI interactions and session management
 type CLIHandler struct {
 	session *app.ChatSession
 	reader  *bufio.Reader
+	unicode bool // Terminal displays emoji and braille, see setupConsole
+	style   *render.Renderer
+
+	// followUps are the questions suggested after the last reply, asked by typing their number
+	followUps []string
+
+	mu        sync.Mutex
+	cancel    context.CancelFunc // Stops the reply being streamed; nil while waiting for input
+	closeOnce sync.Once
+	closeErr  error
 }
 
 // NewCLIHandler creates a new CLI handler with the configured session
-func NewCLIHandler(cfg backend.LLMConfig, budgetCfg backend.TokenBudgetConfig) (*CLIHandler, error) {
+func NewCLIHandler(cfg backend.LLMConfig, budgetCfg backend.TokenBudgetConfig, opts app.SessionOptions) (*CLIHandler, error) {
 	sessionID := app.GenerateSessionID("cli")
 	session, err := app.NewChatSessionWithDefaults(
 		sessionID,
-		"cli_session",
-		"You are a helpful assistant.",
+		opts.CLIConversationType(),
+		"",
 		cfg,
 		budgetCfg,
+		opts,
 	)
 	if err != nil {
 		return nil, err
@@ -45,11 +75,36 @@ func NewCLIHandler(cfg backend.LLMConfig, budgetCfg backend.TokenBudgetConfig) (
 	return &CLIHandler{
 		session: session,
 		reader:  bufio.NewReader(os.Stdin),
+		style:   render.New(render.ColorAuto, os.Stdout),
 	}, nil
 }
 
-// printMOTD displays the ChatGBT ASCII art banner
-func printMOTD() {
+// SetColor sets when output is colored: render.ColorAuto, ColorAlways, or ColorNever
+func (h *CLIHandler) SetColor(mode string) {
+	h.style = render.New(mode, os.Stdout)
+}
+
+// t returns a UI string in the session's language
+func (h *CLIHandler) t(key string, args ...any) string {
+	return i18n.T(h.session.Language, key, args...)
+}
+
+// printMOTD displays the ChatGBT ASCII art banner, in plain ASCII for consoles that
+// can't render box drawing and emoji
+func printMOTD(unicode bool) {
+	if !unicode {
+		fmt.Print(`
+   ____ _           _    ____ ____ _____
+  / ___| |__   __ _| |_ / ___| __ )_   _|
+ | |   | '_ \ / _` + "`" + ` | __| |  _|  _ \ | |
+ | |___| | | | (_| | |_| |_| | |_) || |
+  \____|_| |_|\__,_|\__|\____|____/ |_|
+
+        Language Model Assistant for Educational Purposes
+        -------------------------------------------------
+`)
+		return
+	}
 	fmt.Print(`
  ██████╗██╗  ██╗ █████╗ ████████╗ ██████╗ ██████╗ ████████╗
 ██╔════╝██║  ██║██╔══██╗╚══██╔══╝██╔════╝ ██╔══██╗╚══██╔══╝
@@ -65,21 +120,17 @@ func printMOTD() {
 
 // readMultilineInput reads user input until an empty line is entered
 func (h *CLIHandler) readMultilineInput() (string, error) {
-	fmt.Println("You (end with empty line):")
+	fmt.Println(h.style.User(h.t("cli.input")))
 	var userLines []string
 	for {
 		line, err := h.reader.ReadString('\n')
 		if err != nil {
-			switch err.Error() {
-			case "EOF":
-				if len(userLines) == 0 {
-					return "", err
-				}
+			if errors.Is(err, io.EOF) && len(userLines) > 0 {
 				return strings.TrimSpace(strings.Join(userLines, "\n")), nil
-			default:
-				return "", err
 			}
+			return "", err
 		}
+		// Windows consoles end lines with CRLF
 		line = strings.TrimRight(line, "\r\n")
 		if line == "" {
 			break
@@ -91,13 +142,13 @@ func (h *CLIHandler) readMultilineInput() (string, error) {
 
 // handleSystemPromptUpdate handles the /system command
 func (h *CLIHandler) handleSystemPromptUpdate() error {
-	fmt.Print("Enter new system prompt: ")
+	fmt.Print(h.style.System(h.t("cli.system_input")))
 	newPrompt, err := h.reader.ReadString('\n')
 	if err != nil {
 		return err
 	}
 	h.session.UpdateSystemPrompt(strings.TrimSpace(newPrompt))
-	fmt.Println("System prompt updated.")
+	fmt.Println(h.t("system_updated"))
 	return nil
 }
 
@@ -105,23 +156,32 @@ func (h *CLIHandler) handleSystemPromptUpdate() error {
 func (h *CLIHandler) showBudgetStatus() {
 	status := h.session.GetBudgetStatus()
 
-	fmt.Println("\nBudget Status:")
-	fmt.Printf("   Session Tokens: %d", status.SessionTokens)
+	fmt.Println("\n" + h.t("cli.budget_title"))
+	fmt.Printf("   %s: %d", h.t("cli.session_tokens"), status.SessionTokens)
 	if status.SessionLimit > 0 {
 		fmt.Printf(" / %d (%.1f%%)", status.SessionLimit,
 			float64(status.SessionTokens)/float64(status.SessionLimit)*100)
 	}
-	fmt.Printf("\n   Estimated Cost: $%.4f\n", status.SessionCost)
+	fmt.Printf("\n   %s: $%.4f\n", h.t("cli.estimated_cost"), status.SessionCost)
+
+	// Only worth breaking down once more than one model has answered
+	if usage := h.session.GetSessionSummary().UsageByModel; len(usage) > 1 {
+		fmt.Println("   " + h.t("cli.usage_by_model"))
+		for _, key := range slices.Sorted(maps.Keys(usage)) {
+			u := usage[key]
+			fmt.Printf("     %s: %d tokens, $%.4f (%d requests)\n", key, u.TotalTokens, u.Cost, u.Requests)
+		}
+	}
 
 	if len(status.Warnings) > 0 {
-		fmt.Println("   Warnings:")
+		fmt.Println("   " + h.style.Warning(h.t("cli.warnings")))
 		for _, warning := range status.Warnings {
-			fmt.Printf("     - %s\n", warning)
+			fmt.Printf("     - %s\n", h.style.Warning(warning))
 		}
 	}
 
 	if status.ShouldPrune {
-		fmt.Println("   Recommendation: Consider pruning context (/prune)")
+		fmt.Println("   " + h.t("cli.prune_hint"))
 	}
 
 	fmt.Println()
@@ -138,7 +198,7 @@ func (h *CLIHandler) showContextStats() {
 		stats.EstimatedTokens, stats.TokenLimit, stats.UtilizationPct)
 
 	if stats.ShouldPrune {
-		fmt.Println("   Warning: Context approaching token limit")
+		fmt.Println("   " + h.style.Warning("Warning: Context approaching token limit"))
 	}
 
 	// Show session summary
@@ -149,6 +209,29 @@ func (h *CLIHandler) showContextStats() {
 	if summary.AvgResponseTime > 0 {
 		fmt.Printf("   Avg Response Time: %dms\n", summary.AvgResponseTime)
 	}
+	if summary.AvgTTFT > 0 {
+		fmt.Printf("   Avg Time to First Token: %dms (%.1f tok/s)\n", summary.AvgTTFT, summary.AvgTokensPerSec)
+	}
+	if summary.Latency.Count > 0 {
+		fmt.Printf("   Latency: p50=%dms p95=%dms p99=%dms\n",
+			summary.Latency.P50, summary.Latency.P95, summary.Latency.P99)
+		if len(summary.LatencyByModel) > 1 {
+			for _, key := range slices.Sorted(maps.Keys(summary.LatencyByModel)) {
+				p := summary.LatencyByModel[key]
+				fmt.Printf("     %s: p50=%dms p95=%dms p99=%dms (%d requests)\n",
+					key, p.P50, p.P95, p.P99, p.Count)
+			}
+		}
+	}
+
+	if len(summary.UsageByModel) > 1 {
+		fmt.Println("   Usage by Model:")
+		for _, key := range slices.Sorted(maps.Keys(summary.UsageByModel)) {
+			u := summary.UsageByModel[key]
+			fmt.Printf("     %s: %d requests, %d tokens (%d prompt, %d completion), $%.4f\n",
+				key, u.Requests, u.TotalTokens, u.PromptTokens, u.CompletionTokens, u.Cost)
+		}
+	}
 
 	// Show prompt type breakdown
 	promptBreakdown := h.session.GetPromptTypeBreakdown()
@@ -163,40 +246,242 @@ func (h *CLIHandler) showContextStats() {
 	fmt.Println()
 }
 
+// showMessageTokens displays the token count of each message in the context
+func (h *CLIHandler) showMessageTokens() {
+	counts, exact := h.session.GetMessageTokens()
+	stats := h.session.GetContextStats()
+
+	fmt.Println("\nTokens per Message:")
+	total := 0
+	for _, mt := range counts {
+		pinned := ""
+		if mt.Pinned {
+			pinned = " (pinned)"
+		}
+		fmt.Printf("   #%-3d %s %6d  %s%s\n", mt.Index, h.roleColumn(mt.Role), mt.Tokens, mt.Preview, pinned)
+		total += mt.Tokens
+	}
+	fmt.Printf("   Total: %d tokens (prune limit %d)\n", total, stats.TokenLimit)
+	if !exact {
+		fmt.Println("   Note: tokenizer unavailable, counts are estimates")
+	}
+	fmt.Println()
+}
+
+// roleColumn pads a message role to a fixed-width column, colored by who sent it; the
+// padding is added first so escape codes don't count toward the width
+func (h *CLIHandler) roleColumn(role backend.Role) string {
+	return h.style.Role(role, fmt.Sprintf("%-9s", role))
+}
+
 // pruneContext manually triggers context pruning
 func (h *CLIHandler) pruneContext() {
 	beforeStats := h.session.GetContextStats()
-	pruned := h.session.AutoPrune()
+	report := h.session.Prune()
 
-	if pruned {
+	if report != nil {
 		afterStats := h.session.GetContextStats()
-		fmt.Printf("Context pruned: %d -> %d messages, ~%d -> ~%d tokens\n",
+		fmt.Println(h.t("cli.pruned",
 			beforeStats.TotalMessages, afterStats.TotalMessages,
-			beforeStats.EstimatedTokens, afterStats.EstimatedTokens)
+			beforeStats.EstimatedTokens, afterStats.EstimatedTokens))
+		h.showPruneReport(report)
 	} else {
-		fmt.Println("No pruning needed - context within limits")
+		fmt.Println(h.t("cli.no_prune"))
+	}
+}
+
+// showPruneReport lists the messages pruning dropped and the summary that replaced them
+func (h *CLIHandler) showPruneReport(report *backend.PruneReport) {
+	fmt.Println("   " + h.t("cli.prune_dropped"))
+	for _, mt := range report.Dropped {
+		fmt.Printf("     #%-3d %s %6d  %s\n", mt.Index, h.roleColumn(mt.Role), mt.Tokens, mt.Preview)
+	}
+	if report.Summary != "" {
+		fmt.Println("   " + h.t("cli.prune_summary", report.Summary))
+	}
+}
+
+// summarize asks the model for a summary of the conversation, prints it, and pins it
+// to the context. With a path, it also exports the conversation, summary included,
+// in the format named by the file extension.
+func (h *CLIHandler) summarize(path string) error {
+	var format store.Format
+	if path != "" {
+		var err error
+		if format, err = store.ParseFormat(strings.TrimPrefix(filepath.Ext(path), ".")); err != nil {
+			return err
+		}
+	}
+
+	fmt.Println(h.t("cli.summarizing"))
+	spin := startSpinner(os.Stdout, h.session.ModelLabel(), h.unicode)
+	summary, err := h.session.Summarize(context.Background())
+	spin.stop()
+	if err != nil {
+		return err
+	}
+	h.session.PinSummary(summary)
+	fmt.Printf("\n%s\n\n%s\n", summary, h.t("cli.summary_pinned"))
+
+	if path == "" {
+		return nil
 	}
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+	if err := h.session.ExportConversation(f, format); err != nil {
+		return err
+	}
+	fmt.Println(h.t("cli.summary_saved", path))
+	return nil
+}
+
+// copyReply copies the last reply to the clipboard, or with "code", the last code block
+// of the most recent reply that has one
+func (h *CLIHandler) copyReply(arg string) error {
+	replies := h.session.AssistantReplies()
+	if len(replies) == 0 {
+		fmt.Println(h.t("cli.nothing_to_copy"))
+		return nil
+	}
+
+	switch arg {
+	case "":
+		if err := clipboard.WriteAll(replies[0]); err != nil {
+			return err
+		}
+		fmt.Println(h.t("cli.copied"))
+	case "code":
+		for _, reply := range replies {
+			if code, ok := lastCodeBlock(reply); ok {
+				if err := clipboard.WriteAll(code); err != nil {
+					return err
+				}
+				fmt.Println(h.t("cli.copied_code"))
+				return nil
+			}
+		}
+		fmt.Println(h.t("cli.no_code_block"))
+	default:
+		return fmt.Errorf("usage: %s [code]", cmdCopy)
+	}
+	return nil
+}
+
+// lastCodeBlock returns the contents of the last fenced code block in text
+func lastCodeBlock(text string) (string, bool) {
+	var block []string
+	var last string
+	found, inBlock := false, false
+	for _, line := range strings.Split(text, "\n") {
+		if strings.HasPrefix(strings.TrimSpace(line), "```") {
+			if inBlock {
+				last, found = strings.Join(block, "\n"), true
+			}
+			inBlock, block = !inBlock, nil
+			continue
+		}
+		if inBlock {
+			block = append(block, line)
+		}
+	}
+	return last, found
 }
 
 // handleUserInput processes a user message and gets model response
 func (h *CLIHandler) handleUserInput(userInput string) error {
-	response, err := h.session.ProcessUserMessage(userInput)
+	var tooLong *app.MessageTooLongError
+	if err := h.session.CheckMessage(userInput); errors.As(err, &tooLong) {
+		fmt.Println(h.style.Error(h.t("cli.error")), h.t("message_too_long", tooLong.Length, tooLong.Max))
+		return err
+	}
+
+	fmt.Println("\n" + h.style.Assistant("LLM:"))
+	return h.stream(func(ctx context.Context, onDelta backend.StreamHandler) (*app.ChatResponse, error) {
+		return h.session.ProcessUserMessageContext(ctx, userInput, onDelta)
+	})
+}
+
+// continueReply asks for the rest of the last reply and prints it after the part
+// already shown
+func (h *CLIHandler) continueReply() error {
+	fmt.Println("\n" + h.style.Assistant("LLM (continued):"))
+	return h.stream(h.session.Continue)
+}
+
+// stream prints a reply as ask streams it, followed by its metadata, warnings, and
+// suggested follow-ups. Ctrl+C or Esc cancels the context passed to ask; the part of
+// the reply already streamed is kept.
+func (h *CLIHandler) stream(ask func(context.Context, backend.StreamHandler) (*app.ChatResponse, error)) error {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+	h.setCancel(cancel)
+	defer h.setCancel(nil)
+
+	spin := startSpinner(os.Stdout, h.session.ModelLabel(), h.unicode)
+	stopEscape := watchEscape(cancel)
+	response, err := ask(ctx, func(delta string) {
+		spin.stop()
+		fmt.Print(delta)
+	})
+	stopEscape()
+	spin.stop()
+	if err != nil && ctx.Err() != nil {
+		fmt.Println("\n" + h.style.Warning(h.t("cli.interrupted")))
+		return err
+	}
+	var overBudget *app.BudgetExceededError
+	if errors.As(err, &overBudget) {
+		fmt.Println("\n"+h.style.Error(h.t("cli.error")), h.t("budget_exhausted", overBudget.PromptTokens, overBudget.Remaining))
+		return err
+	}
 	if err != nil {
-		fmt.Println("Error:", err)
+		fmt.Println("\n"+h.style.Error(h.t("cli.error")), err)
 		return err
 	}
+	fmt.Print("\n\n")
+	renderImages(os.Stdout, response.Content, imageProtocol())
 
-	fmt.Println("\nLLM:\n" + response.Content + "\n")
+	if response.Pruned != nil {
+		fmt.Println(h.t("cli.auto_pruned", len(response.Pruned.Dropped), response.Pruned.Reclaimed()))
+		h.showPruneReport(response.Pruned)
+	}
 
-	// Show token usage if available
+	// Show which model answered, why it stopped, and token usage if available
+	meta := "[" + response.ModelLabel()
+	if response.FinishReason != "" {
+		meta += " | Finish: " + response.FinishReason
+	}
 	if response.Usage != nil {
-		fmt.Printf("[Tokens: prompt=%d, completion=%d, total=%d | Response: %dms]\n",
-			response.Usage.PromptTokens, response.Usage.CompletionTokens,
-			response.Usage.TotalTokens, response.ResponseTime.Milliseconds())
+		meta += fmt.Sprintf(" | Tokens: prompt=%d, completion=%d, total=%d",
+			response.Usage.PromptTokens, response.Usage.CompletionTokens, response.Usage.TotalTokens)
+	}
+	meta += fmt.Sprintf(" | Response: %dms", response.ResponseTime.Milliseconds())
+	if response.TTFT > 0 {
+		meta += fmt.Sprintf(" | TTFT: %dms | %.1f tok/s", response.TTFT.Milliseconds(), response.TokensPerSecond)
+	}
+	fmt.Println(h.style.Usage(meta + "]"))
+	if response.Truncated() {
+		fmt.Println(h.style.Warning(h.t("cli.continue_hint", cmdContinue)))
+	} else if response.Aborted() {
+		fmt.Println(h.style.Warning(h.t("cli.aborted_hint", cmdContinue)))
+	}
+	if len(response.ToolsUsed) > 0 {
+		fmt.Println(h.style.Tool("Tools: " + strings.Join(response.ToolsUsed, ", ")))
+	}
 
-		// Show budget warnings if any
-		if len(response.Warnings) > 0 {
-			fmt.Printf("Budget: %s\n", response.Warnings[0])
+	// Show budget warnings if any
+	if len(response.Warnings) > 0 {
+		fmt.Println(h.style.Warning(fmt.Sprintf("%s: %s", h.t("cli.budget"), response.Warnings[0])))
+	}
+
+	h.followUps = response.FollowUps
+	if len(h.followUps) > 0 {
+		fmt.Println(h.t("cli.follow_ups"))
+		for i, question := range h.followUps {
+			fmt.Printf("  %d. %s\n", i+1, question)
 		}
 	}
 
@@ -205,44 +490,69 @@ func (h *CLIHandler) handleUserInput(userInput string) error {
 
 // Run starts the enhanced CLI mode with the new architecture
 func (h *CLIHandler) Run() error {
-	printMOTD()
-	fmt.Println("Welcome to the interactive LLM chat!")
-	fmt.Println("Commands: 'exit', '/reset', '/system', '/budget', '/stats', '/prune'")
+	h.unicode = setupConsole()
+	printMOTD(h.unicode)
+	stopWatching := h.watchInterrupts()
+	defer stopWatching()
+
+	fmt.Println(h.t("cli.welcome"))
+	fmt.Println(h.t("cli.commands", "'exit', '/reset', '/system', '/budget', '/stats', '/tokens', '/prune', '/continue', '/summarize [file]', '/copy [code]'"))
 	fmt.Println()
 
 	for {
 		userInput, inputErr := h.readMultilineInput()
 		if inputErr != nil {
-			switch inputErr.Error() {
-			case "EOF":
-				fmt.Println("\nThanks for using chatGBT! Goodbye!")
+			if errors.Is(inputErr, io.EOF) {
+				fmt.Println("\n" + h.t("cli.goodbye"))
 				return nil
-			default:
-				fmt.Println("Error reading input:", inputErr)
-				continue
 			}
+			fmt.Println(h.style.Error(h.t("cli.read_error")), inputErr)
+			continue
 		}
 
 		switch userInput {
 		case cmdExit:
-			fmt.Println("\nThanks for using chatGBT! Goodbye!")
+			fmt.Println("\n" + h.t("cli.goodbye"))
 			return nil
 		case cmdReset:
-			fmt.Println("Conversation reset.")
+			fmt.Println(h.t("cli.reset"))
 			h.session.Reset("")
+			h.followUps = nil
 		case cmdSystem:
 			if err := h.handleSystemPromptUpdate(); err != nil {
-				fmt.Println("Error reading system prompt:", err)
+				fmt.Println(h.style.Error(h.t("cli.system_error")), err)
 			}
 		case cmdBudget:
 			h.showBudgetStatus()
 		case cmdStats:
 			h.showContextStats()
+		case cmdTokens:
+			h.showMessageTokens()
 		case cmdPrune:
 			h.pruneContext()
+		case cmdContinue:
+			h.continueReply()
 		case "":
 			// Empty input, continue to next iteration
 		default:
+			if n, err := strconv.Atoi(userInput); err == nil && n >= 1 && n <= len(h.followUps) {
+				userInput = h.followUps[n-1]
+				fmt.Println(userInput)
+			}
+			if userInput == cmdSummarize || strings.HasPrefix(userInput, cmdSummarize+" ") {
+				path := strings.TrimSpace(strings.TrimPrefix(userInput, cmdSummarize))
+				if err := h.summarize(path); err != nil {
+					fmt.Println(h.style.Error(h.t("cli.error")), err)
+				}
+				continue
+			}
+			if userInput == cmdCopy || strings.HasPrefix(userInput, cmdCopy+" ") {
+				if err := h.copyReply(strings.TrimSpace(strings.TrimPrefix(userInput, cmdCopy))); err != nil {
+					fmt.Println(h.style.Error(h.t("cli.error")), err)
+				}
+				continue
+			}
+
 			// Handle user input for chat
 			if err := h.handleUserInput(userInput); err != nil {
 				// Error already handled in handleUserInput
@@ -251,31 +561,90 @@ func (h *CLIHandler) Run() error {
 	}
 }
 
-// Close properly closes the CLI handler and session
+// setCancel records how to stop the reply in flight; nil once it is done
+func (h *CLIHandler) setCancel(cancel context.CancelFunc) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.cancel = cancel
+}
+
+// watchInterrupts makes Ctrl+C stop the reply being streamed and return to the prompt.
+// At the prompt it ends the session like exit, so the summary is printed and the log
+// closed. The returned function stops watching.
+func (h *CLIHandler) watchInterrupts() func() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt)
+	done := make(chan struct{})
+
+	go func() {
+		for {
+			select {
+			case <-signals:
+			case <-done:
+				return
+			}
+
+			h.mu.Lock()
+			cancel := h.cancel
+			h.mu.Unlock()
+			if cancel != nil {
+				cancel()
+				continue
+			}
+
+			fmt.Println("\n" + h.t("cli.goodbye"))
+			h.Close()
+			os.Exit(130)
+		}
+	}()
+
+	return func() {
+		signal.Stop(signals)
+		close(done)
+	}
+}
+
+// Close properly closes the CLI handler and session. It is safe to call more than once,
+// as Ctrl+C may close the handler while Run is returning.
 func (h *CLIHandler) Close() error {
-	if h.session != nil {
+	h.closeOnce.Do(func() {
+		if h.session == nil {
+			return
+		}
 		summary := h.session.GetSessionSummary()
 		fmt.Printf("\nSession Summary: %d requests, %.1f%% success, $%.4f cost, %v duration\n",
 			summary.TotalRequests, summary.SuccessRate*100, summary.EstimatedCost, summary.Duration.Round(time.Second))
-		return h.session.Close()
-	}
-	return nil
+		h.closeErr = h.session.Close()
+	})
+	return h.closeErr
 }
 
 // CLIRunner handles interactive CLI mode
-type CLIRunner struct{}
+type CLIRunner struct {
+	opts  app.SessionOptions
+	color string
+}
 
-// NewCLIRunner creates a new CLI runner
-func NewCLIRunner() *CLIRunner {
-	return &CLIRunner{}
+// NewCLIRunner creates a new CLI runner from the cli subcommand's arguments
+func NewCLIRunner(args []string, opts app.SessionOptions) (*CLIRunner, error) {
+	fs := flag.NewFlagSet("cli", flag.ContinueOnError)
+	color := fs.String("color", render.ColorAuto, "Color output: auto (when writing to a terminal and NO_COLOR is unset), always, or never")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if err := render.ParseColor(*color); err != nil {
+		return nil, err
+	}
+	return &CLIRunner{opts: opts, color: *color}, nil
 }
 
 // Run is the main entry point for CLI modeArg
 func (c *CLIRunner) Run(cfg backend.LLMConfig, budgetCfg backend.TokenBudgetConfig) error {
-	handler, err := NewCLIHandler(cfg, budgetCfg)
+	handler, err := NewCLIHandler(cfg, budgetCfg, c.opts)
 	if err != nil {
 		return fmt.Errorf("failed to create CLI handler: %w", err)
 	}
+	handler.SetColor(c.color)
 
 	defer func() {
 		if closeErr := handler.Close(); closeErr != nil {
@@ -290,6 +659,22 @@ func (c *CLIRunner) Run(cfg backend.LLMConfig, budgetCfg backend.TokenBudgetConf
 type DirectQueryRunner struct {
 	query     string
 	showUsage bool
+
+	// bestOf samples this many answers and prints the one selection picks; 0 asks once
+	bestOf    int
+	selection string
+
+	verbosity app.Verbosity
+
+	output     string // File the reply is written to instead of stdout
+	transcript string // Markdown file each exchange is appended to
+
+	// expand runs the template helpers in the query, such as {{file "main.go"}}, before sending
+	expand bool
+	// fromClipboard appends the clipboard text to the query, or sends it alone
+	fromClipboard bool
+
+	endUser string // Who is asking, hashed before it is sent; see app.HashUserID
 }
 
 // NewDirectQueryRunner creates a new direct query runner
@@ -300,8 +685,100 @@ func NewDirectQueryRunner(query string, showUsage bool) *DirectQueryRunner {
 	}
 }
 
+// SetEndUser sets who is asking, e.g. the --user profile, for the provider's abuse monitoring
+func (d *DirectQueryRunner) SetEndUser(id string) {
+	d.endUser = id
+}
+
+// ParseDirectQuery parses a quick query that may start with flags, e.g.
+// --best-of 5 "what is 17 * 23?"
+func ParseDirectQuery(args []string, showUsage bool) (*DirectQueryRunner, error) {
+	return parseDirectQuery(args, showUsage, os.Stderr)
+}
+
+// QuietQuery reports whether args are a quick query asking for --quiet output, so that
+// warnings printed before the query runs can be left out too
+func QuietQuery(args []string) bool {
+	runner, err := parseDirectQuery(args, false, io.Discard)
+	return err == nil && runner.verbosity == app.VerbosityQuiet
+}
+
+// parseDirectQuery parses the quick query flags, writing flag errors to output
+func parseDirectQuery(args []string, showUsage bool, output io.Writer) (*DirectQueryRunner, error) {
+	fs := flag.NewFlagSet("query", flag.ContinueOnError)
+	fs.SetOutput(output)
+	bestOf := fs.Int("best-of", 0, "Sample N answers and print the best one")
+	selection := fs.String("select", app.SelectVote, "How --best-of picks the answer: vote (majority of final answers) or judge (the model decides)")
+	quiet := fs.Bool("quiet", false, "Print the reply only: no usage line or warnings")
+	fs.BoolVar(quiet, "q", false, "Shorthand for --quiet")
+	verbose := fs.Bool("verbose", false, "Also print request and response metadata and timing phases to stderr")
+	fs.BoolVar(verbose, "v", false, "Shorthand for --verbose")
+	outputFile := fs.String("output", "", "Write the reply to this file instead of stdout")
+	fs.StringVar(outputFile, "o", "", "Shorthand for --output")
+	transcript := fs.String("transcript", "", "Append the question and reply to this Markdown file")
+	raw := fs.Bool("raw", false, "Send {{ }} in the question as typed instead of expanding template helpers")
+	fromClipboard := fs.Bool("from-clipboard", false, "Append the clipboard text to the question, or ask it alone")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	query := strings.TrimSpace(strings.Join(fs.Args(), " "))
+	if query == "" && !*fromClipboard {
+		return nil, fmt.Errorf("usage: [--best-of N] [--quiet | --verbose] [--output file] [--transcript file] [--raw] [--from-clipboard] \"question\"")
+	}
+	if *bestOf < 0 || *bestOf == 1 {
+		return nil, fmt.Errorf("--best-of must be at least 2, got %d", *bestOf)
+	}
+	if *selection != app.SelectVote && *selection != app.SelectJudge {
+		return nil, fmt.Errorf("--select must be %s or %s, got %q", app.SelectVote, app.SelectJudge, *selection)
+	}
+	if *quiet && *verbose {
+		return nil, fmt.Errorf("--quiet and --verbose can't be used together")
+	}
+	if *verbose && *bestOf > 0 {
+		return nil, fmt.Errorf("--verbose describes a single request, so it can't be used with --best-of")
+	}
+
+	runner := NewDirectQueryRunner(query, showUsage)
+	runner.bestOf = *bestOf
+	runner.selection = *selection
+	runner.output = *outputFile
+	runner.transcript = *transcript
+	runner.expand = !*raw
+	runner.fromClipboard = *fromClipboard
+	switch {
+	case *quiet:
+		runner.verbosity = app.VerbosityQuiet
+	case *verbose:
+		runner.verbosity = app.VerbosityVerbose
+	}
+	return runner, nil
+}
+
 // Run executes the direct query with the provided configuration
 func (d *DirectQueryRunner) Run(cfg backend.LLMConfig, budgetCfg backend.TokenBudgetConfig) error {
+	if d.expand {
+		warnings := io.Writer(os.Stderr)
+		if d.verbosity == app.VerbosityQuiet {
+			warnings = io.Discard
+		}
+		query, err := ExpandPrompt(d.query, cfg.Model, warnings)
+		if err != nil {
+			return err
+		}
+		d.query = query
+	}
+	if d.fromClipboard {
+		text, err := clipboard.ReadAll()
+		if err != nil {
+			return fmt.Errorf("failed to read the clipboard: %w", err)
+		}
+		if strings.TrimSpace(text) == "" {
+			return fmt.Errorf("the clipboard is empty")
+		}
+		d.query = strings.TrimSpace(d.query + "\n\n" + text)
+	}
+
 	// Create LLM client
 	client, err := llm.NewClient(cfg, 30*time.Second)
 	if err != nil {
@@ -315,11 +792,69 @@ func (d *DirectQueryRunner) Run(cfg backend.LLMConfig, budgetCfg backend.TokenBu
 	}
 	defer logger.Close()
 
-	// Create and execute the service
-	service := app.NewDirectQueryService(client, logger, os.Stdout)
-	ctx := context.Background()
+	reply := io.Writer(os.Stdout)
+	var outputFile *os.File
+	if d.output != "" {
+		outputFile, err = os.Create(d.output)
+		if err != nil {
+			return fmt.Errorf("failed to create output file: %w", err)
+		}
+		defer outputFile.Close()
+		reply = outputFile
+	}
+	var transcript *store.Transcript
+	if d.transcript != "" {
+		transcript = store.NewTranscript(d.transcript)
+	}
+
+	if d.bestOf > 0 {
+		err = d.runBestOf(client, logger, reply, transcript)
+	} else {
+		// Create and execute the service
+		service := app.NewDirectQueryService(client, logger, os.Stdout)
+		service.SetVerbosity(d.verbosity, os.Stderr)
+		service.SetReplyWriter(reply)
+		service.SetTranscript(transcript)
+		service.SetEndUser(d.endUser)
+		err = service.Execute(context.Background(), d.query, d.showUsage)
+	}
+	if err == nil && outputFile != nil {
+		err = outputFile.Close()
+	}
+	return err
+}
+
+// runBestOf samples several answers and prints the one selected
+func (d *DirectQueryRunner) runBestOf(client *llm.Client, logger app.Logger, reply io.Writer, transcript *store.Transcript) error {
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
 
-	return service.Execute(ctx, d.query, d.showUsage)
+	start := time.Now()
+	result, err := app.BestOf(ctx, client, logger, d.query, d.bestOf, d.selection)
+	if err != nil {
+		return err
+	}
+	if _, err := fmt.Fprintln(reply, result.Answer); err != nil {
+		return err
+	}
+	if transcript != nil {
+		err := transcript.Append(client.Model(),
+			backend.Message{Role: backend.RoleUser, Content: d.query},
+			backend.Message{Role: backend.RoleAssistant, Content: result.Answer})
+		if err != nil {
+			return err
+		}
+	}
+
+	if d.showUsage && result.Usage != nil && d.verbosity != app.VerbosityQuiet {
+		picked := fmt.Sprintf("%d/%d agree", result.Votes, result.Samples)
+		if result.Judged {
+			picked = fmt.Sprintf("judged best of %d", result.Samples)
+		}
+		fmt.Printf("Tokens: %d | Cost: $%.4f | Time: %.1fs | Best of %d: %s\n",
+			result.Usage.TotalTokens, logger.GetSessionSummary().EstimatedCost, time.Since(start).Seconds(), d.bestOf, picked)
+	}
+	return nil
 }
 
 // RunDirect handles single-query mode for quick interactions (legacy function)
@@ -0,0 +1,122 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/nleiva/chatgbt/internal/app"
+	"github.com/nleiva/chatgbt/pkg/backend"
+	"github.com/nleiva/chatgbt/pkg/llm"
+)
+
+// CommitRunner writes a commit message for the staged changes and optionally commits them
+type CommitRunner struct {
+	apply        bool
+	model        string
+	maxDiffBytes int
+	showUsage    bool
+	writer       io.Writer
+}
+
+// NewCommitRunner parses the commit subcommand arguments
+func NewCommitRunner(args []string, showUsage bool) (*CommitRunner, error) {
+	fs := flag.NewFlagSet("commit", flag.ContinueOnError)
+	apply := fs.Bool("a", false, "Run git commit with the generated message")
+	model := fs.String("model", "", "provider:model to use (overrides MODEL)")
+	maxDiffBytes := fs.Int("max-diff-bytes", app.DefaultMaxDiffBytes, "Truncate the staged diff to this many bytes")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() != 0 {
+		return nil, fmt.Errorf("usage: commit [-a] [--model provider:model] [--max-diff-bytes N]")
+	}
+
+	return &CommitRunner{
+		apply:        *apply,
+		model:        *model,
+		maxDiffBytes: *maxDiffBytes,
+		showUsage:    showUsage,
+		writer:       os.Stdout,
+	}, nil
+}
+
+// Run generates the message from `git diff --cached` and prints or applies it
+func (c *CommitRunner) Run(cfg backend.LLMConfig, budgetCfg backend.TokenBudgetConfig) error {
+	diff, err := stagedDiff()
+	if err != nil {
+		return err
+	}
+	if strings.TrimSpace(diff) == "" {
+		return fmt.Errorf("no staged changes; stage files with git add first")
+	}
+
+	if c.model != "" {
+		cfg = app.ParseModelSpec(cfg, c.model)
+	}
+	client, err := llm.NewClient(cfg, 60*time.Second)
+	if err != nil {
+		return fmt.Errorf("failed to create LLM client: %w", err)
+	}
+
+	logger, err := app.NewMetricsLogger(app.GenerateSessionID("commit"), "commit", budgetCfg)
+	if err != nil {
+		return fmt.Errorf("failed to create metrics logger: %w", err)
+	}
+	defer logger.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	result, err := app.NewCommitMessageGenerator(client, logger, c.maxDiffBytes).Generate(ctx, diff)
+	if err != nil {
+		return fmt.Errorf("failed to generate commit message: %w", err)
+	}
+	if result.Truncated {
+		fmt.Fprintf(os.Stderr, "Warning: staged diff exceeds %d bytes and was truncated\n", c.maxDiffBytes)
+	}
+
+	if c.showUsage && result.Usage != nil {
+		fmt.Fprintf(os.Stderr, "Tokens: %d | Cost: $%.4f | Time: %.1fs\n",
+			result.Usage.TotalTokens, logger.GetSessionSummary().EstimatedCost, result.ResponseTime.Seconds())
+	}
+	for _, warning := range logger.GetBudgetStatus().Warnings {
+		fmt.Fprintf(os.Stderr, "Budget: %s\n", warning)
+	}
+
+	if !c.apply {
+		fmt.Fprintln(c.writer, result.Message)
+		return nil
+	}
+	return gitCommit(result.Message, c.writer)
+}
+
+// stagedDiff returns the output of git diff --cached
+func stagedDiff() (string, error) {
+	var stderr bytes.Buffer
+	cmd := exec.Command("git", "diff", "--cached")
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		return "", fmt.Errorf("failed to read staged diff: %w: %s", err, strings.TrimSpace(stderr.String()))
+	}
+	return string(out), nil
+}
+
+// gitCommit commits the staged changes with message, passing git's output through to w
+func gitCommit(message string, w io.Writer) error {
+	cmd := exec.Command("git", "commit", "-F", "-")
+	cmd.Stdin = strings.NewReader(message + "\n")
+	cmd.Stdout = w
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return fmt.Errorf("failed to run git commit: %w", err)
+	}
+	return nil
+}
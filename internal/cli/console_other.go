@@ -0,0 +1,9 @@
+//go:build !windows
+
+package cli
+
+// setupConsole prepares the terminal for the CLI and reports whether it displays the
+// banner's box drawing and emoji; POSIX terminals need no setup
+func setupConsole() (unicode bool) {
+	return true
+}
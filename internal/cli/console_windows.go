@@ -0,0 +1,30 @@
+//go:build windows
+
+package cli
+
+import (
+	"os"
+
+	"golang.org/x/sys/windows"
+)
+
+// codePageUTF8 is the console code page for UTF-8
+const codePageUTF8 = 65001
+
+// setupConsole switches the console to UTF-8 and turns on ANSI escape sequences so
+// colored output renders instead of printing raw codes. It reports whether the banner's
+// box drawing and emoji will display: Windows Terminal renders them, the classic
+// console host shows boxes for the emoji.
+func setupConsole() (unicode bool) {
+	windows.SetConsoleCP(codePageUTF8)
+	windows.SetConsoleOutputCP(codePageUTF8)
+
+	stdout := windows.Handle(os.Stdout.Fd())
+	var mode uint32
+	if err := windows.GetConsoleMode(stdout, &mode); err != nil {
+		return true // Redirected to a file or pipe, which get UTF-8 as is
+	}
+	windows.SetConsoleMode(stdout, mode|windows.ENABLE_VIRTUAL_TERMINAL_PROCESSING)
+
+	return os.Getenv("WT_SESSION") != ""
+}
@@ -0,0 +1,186 @@
+package cli
+
+import (
+	"cmp"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"math"
+	"os"
+	"slices"
+	"text/tabwriter"
+	"time"
+
+	"github.com/nleiva/chatgbt/pkg/backend"
+	"github.com/nleiva/chatgbt/pkg/config"
+)
+
+// costsSyncTimeout bounds reading the organization's usage and costs
+const costsSyncTimeout = 2 * time.Minute
+
+// CostsRunner compares the costs estimated in the session logs with what OpenAI
+// billed, and can replace the estimated prices with the billed ones
+type CostsRunner struct {
+	days      int
+	calibrate bool
+	adminKey  string
+	writer    io.Writer
+}
+
+// loggedUsage aggregates the interactions logged for one model, or the totals of a report
+type loggedUsage struct {
+	requests int
+	tokens   int
+	cost     float64
+}
+
+// NewCostsRunner parses the costs subcommand arguments; sync is its only command.
+// adminKey is the OpenAI admin key the usage and costs APIs need.
+func NewCostsRunner(args []string, adminKey string) (*CostsRunner, error) {
+	if len(args) == 0 || args[0] != "sync" {
+		return nil, fmt.Errorf("usage: costs sync [--days N] [--calibrate]")
+	}
+
+	fs := flag.NewFlagSet("costs sync", flag.ContinueOnError)
+	days := fs.Int("days", 7, "Number of days, up to today, to compare")
+	calibrate := fs.Bool("calibrate", false, "Save the price per token OpenAI billed for each model, to estimate with from now on")
+	if err := fs.Parse(args[1:]); err != nil {
+		return nil, err
+	}
+	if *days < 1 {
+		return nil, fmt.Errorf("--days must be at least 1, got %d", *days)
+	}
+
+	return &CostsRunner{
+		days:      *days,
+		calibrate: *calibrate,
+		adminKey:  adminKey,
+		writer:    os.Stdout,
+	}, nil
+}
+
+// Run reads what OpenAI recorded over the period, compares it model by model with the
+// session logs, and prints how far the estimates drifted from the bill
+func (r *CostsRunner) Run(cfg backend.LLMConfig, budgetCfg backend.TokenBudgetConfig) error {
+	if r.adminKey == "" {
+		return fmt.Errorf("costs sync reads OpenAI's usage and costs APIs, which need an admin key in OPENAI_ADMIN_KEY")
+	}
+
+	end := time.Now().UTC()
+	start := end.Truncate(24*time.Hour).AddDate(0, 0, 1-r.days)
+
+	logged, err := loggedOpenAIUsage(cmp.Or(budgetCfg.LogsDir, "logs"), start)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), costsSyncTimeout)
+	defer cancel()
+	billed, err := backend.NewOpenAIUsageClient(r.adminKey, cfg.Project).Usage(ctx, start, end)
+	if err != nil {
+		return err
+	}
+
+	r.report(logged, billed, start)
+
+	if r.calibrate {
+		return r.saveCalibration(billed)
+	}
+	return nil
+}
+
+// report prints estimated and billed usage side by side for every model either side saw
+func (r *CostsRunner) report(logged map[string]*loggedUsage, billed map[string]*backend.OpenAIModelUsage, start time.Time) {
+	var models []string
+	for model := range logged {
+		models = append(models, model)
+	}
+	for model, u := range billed {
+		if logged[model] == nil && (u.Requests > 0 || u.Cost > 0) {
+			models = append(models, model)
+		}
+	}
+	slices.Sort(models)
+
+	fmt.Fprintf(r.writer, "OpenAI usage since %s\n\n", start.Format("2006-01-02"))
+	tw := tabwriter.NewWriter(r.writer, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "MODEL\tREQUESTS\tBILLED REQUESTS\tTOKENS\tBILLED TOKENS\tESTIMATED\tBILLED\tDRIFT")
+	var total, totalBilled loggedUsage
+	for _, model := range models {
+		l := cmp.Or(logged[model], &loggedUsage{})
+		b := cmp.Or(billed[model], &backend.OpenAIModelUsage{})
+		fmt.Fprintf(tw, "%s\t%d\t%d\t%d\t%d\t$%.4f\t$%.4f\t%s\n",
+			model, l.requests, b.Requests, l.tokens, b.TotalTokens(), l.cost, b.Cost, drift(l.cost, b.Cost))
+
+		total.requests += l.requests
+		total.tokens += l.tokens
+		total.cost += l.cost
+		totalBilled.requests += b.Requests
+		totalBilled.tokens += b.TotalTokens()
+		totalBilled.cost += b.Cost
+	}
+	fmt.Fprintf(tw, "TOTAL\t%d\t%d\t%d\t%d\t$%.4f\t$%.4f\t%s\n",
+		total.requests, totalBilled.requests, total.tokens, totalBilled.tokens,
+		total.cost, totalBilled.cost, drift(total.cost, totalBilled.cost))
+	tw.Flush()
+
+	fmt.Fprintln(r.writer, "\nBilled figures include every client using the organization or project, and OpenAI can take a few hours to record usage.")
+}
+
+// drift is how far an estimate is from the billed amount, as a percentage of the bill
+func drift(estimated, billed float64) string {
+	if billed == 0 {
+		return "-"
+	}
+	percent := (estimated - billed) / billed * 100
+	if math.Abs(percent) < 0.05 {
+		return "0%"
+	}
+	return fmt.Sprintf("%+.1f%%", percent)
+}
+
+// saveCalibration stores the billed price per token of each model with both tokens and
+// a cost, so budgets estimate with it from the next run
+func (r *CostsRunner) saveCalibration(billed map[string]*backend.OpenAIModelUsage) error {
+	path := config.CostCalibrationPath()
+	calibration, err := config.LoadCostCalibration(path)
+	if err != nil {
+		return err
+	}
+
+	updated := 0
+	for model, u := range billed {
+		if u.TotalTokens() > 0 && u.Cost > 0 {
+			calibration[model] = u.Cost / float64(u.TotalTokens())
+			updated++
+		}
+	}
+	if updated == 0 {
+		fmt.Fprintln(r.writer, "\nNo billed usage to calibrate prices with.")
+		return nil
+	}
+	if err := calibration.Save(path); err != nil {
+		return fmt.Errorf("failed to save calibrated prices: %w", err)
+	}
+	fmt.Fprintf(r.writer, "\nSaved the billed price per token of %d models to %s\n", updated, path)
+	return nil
+}
+
+// loggedOpenAIUsage totals the OpenAI interactions logged from since on by model
+func loggedOpenAIUsage(logsDir string, since time.Time) (map[string]*loggedUsage, error) {
+	usage := make(map[string]*loggedUsage)
+	err := readInteractions(logsDir, since, func(_ string, m backend.InteractionMetric) {
+		if m.Provider != string(backend.ProviderNameOpenAI) {
+			return
+		}
+		model := backend.OpenAIBaseModel(m.Model)
+		if usage[model] == nil {
+			usage[model] = &loggedUsage{}
+		}
+		usage[model].requests++
+		usage[model].tokens += m.TotalTokens
+		usage[model].cost += m.Cost
+	})
+	return usage, err
+}
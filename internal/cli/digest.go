@@ -0,0 +1,350 @@
+package cli
+
+import (
+	"bytes"
+	"cmp"
+	"flag"
+	"fmt"
+	"html"
+	"io"
+	"maps"
+	"net/smtp"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/russross/blackfriday/v2"
+
+	"github.com/nleiva/chatgbt/pkg/backend"
+	"github.com/nleiva/chatgbt/pkg/config"
+	"github.com/nleiva/chatgbt/pkg/notify"
+)
+
+// Periods a digest covers, and the formats it is written in
+const (
+	digestDay      = "day"
+	digestWeek     = "week"
+	digestMarkdown = "markdown"
+	digestHTML     = "html"
+	// digestTopTypes is how many prompt types the digest lists
+	digestTopTypes = 5
+)
+
+// DigestRunner summarizes the usage in the session logs over the last day or week,
+// compared with the period before, and delivers it by webhook or email
+type DigestRunner struct {
+	period  string
+	format  string
+	output  string
+	webhook bool
+	email   []string
+	logsDir string
+	deliver config.DigestConfig
+	writer  io.Writer
+}
+
+// digestTotals aggregates the interactions of a period, or of one day, model, or type
+type digestTotals struct {
+	sessions map[string]bool
+	requests int
+	failed   int
+	tokens   int
+	cost     float64
+}
+
+// digest is what the report is written from
+type digest struct {
+	start, end time.Time
+	current    digestTotals
+	previous   digestTotals
+	types      map[string]*digestTotals
+	errors     map[string]int
+	models     map[string]*digestTotals
+	days       map[string]*digestTotals
+}
+
+// NewDigestRunner parses the stats digest arguments. logsDir is where session logs are
+// read unless --logs says otherwise; deliver holds the webhooks and mail server.
+func NewDigestRunner(args []string, logsDir string, deliver config.DigestConfig) (*DigestRunner, error) {
+	fs := flag.NewFlagSet("stats digest", flag.ContinueOnError)
+	period := fs.String("period", digestDay, "Period to summarize: day or week")
+	format := fs.String("format", digestMarkdown, "Output format: markdown or html")
+	output := fs.String("o", "", "File to write to instead of stdout")
+	webhook := fs.Bool("webhook", false, "Also post the digest to WEBHOOK_URLS and SLACK_WEBHOOK_URLS")
+	email := fs.String("email", "", "Comma-separated addresses to also email the digest to through SMTP_ADDR")
+	logs := fs.String("logs", logsDir, "Directory holding session JSONL logs")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	runner := &DigestRunner{
+		period:  strings.ToLower(*period),
+		format:  strings.ToLower(*format),
+		output:  *output,
+		webhook: *webhook,
+		logsDir: *logs,
+		deliver: deliver,
+		writer:  os.Stdout,
+	}
+	for _, address := range strings.Split(*email, ",") {
+		if address = strings.TrimSpace(address); address != "" {
+			runner.email = append(runner.email, address)
+		}
+	}
+
+	if runner.period != digestDay && runner.period != digestWeek {
+		return nil, fmt.Errorf("invalid --period %q: must be %s or %s", *period, digestDay, digestWeek)
+	}
+	if runner.format != digestMarkdown && runner.format != digestHTML {
+		return nil, fmt.Errorf("invalid --format %q: must be %s or %s", *format, digestMarkdown, digestHTML)
+	}
+	if runner.webhook && len(deliver.Hooks.URLs) == 0 && len(deliver.Hooks.SlackURLs) == 0 {
+		return nil, fmt.Errorf("--webhook needs WEBHOOK_URLS or SLACK_WEBHOOK_URLS")
+	}
+	if len(runner.email) > 0 && (deliver.SMTP.Addr == "" || deliver.SMTP.From == "") {
+		return nil, fmt.Errorf("--email needs SMTP_ADDR and SMTP_FROM")
+	}
+	return runner, nil
+}
+
+// Run writes the digest and delivers it wherever it was asked to go. It is meant to be
+// run from cron or a systemd timer at the end of each day or week.
+func (d *DigestRunner) Run(cfg backend.LLMConfig, budgetCfg backend.TokenBudgetConfig) error {
+	length := 24 * time.Hour
+	if d.period == digestWeek {
+		length *= 7
+	}
+	end := time.Now()
+	summary, err := collectDigest(d.logsDir, end.Add(-length), end)
+	if err != nil {
+		return err
+	}
+
+	markdown := summary.markdown(d.period)
+	report := markdown
+	if d.format == digestHTML {
+		report = digestPage(summary.title(d.period), markdown)
+	}
+
+	if d.output != "" {
+		if err := os.WriteFile(d.output, []byte(report), 0644); err != nil {
+			return fmt.Errorf("failed to write %s: %w", d.output, err)
+		}
+	} else {
+		fmt.Fprint(d.writer, report)
+	}
+
+	var failures []string
+	if d.webhook {
+		event := notify.Event{
+			Type:      notify.EventUsageDigest,
+			Timestamp: end,
+			Message:   markdown,
+			Tokens:    summary.current.tokens,
+			Cost:      summary.current.cost,
+		}
+		for _, url := range d.deliver.Hooks.URLs {
+			if err := notify.NewWebhook(url, notify.FormatJSON).Send(event); err != nil {
+				failures = append(failures, err.Error())
+			}
+		}
+		for _, url := range d.deliver.Hooks.SlackURLs {
+			if err := notify.NewWebhook(url, notify.FormatSlack).Send(event); err != nil {
+				failures = append(failures, err.Error())
+			}
+		}
+	}
+	if len(d.email) > 0 {
+		if err := d.sendEmail(summary.title(d.period), digestPage(summary.title(d.period), markdown)); err != nil {
+			failures = append(failures, err.Error())
+		}
+	}
+	if len(failures) > 0 {
+		return fmt.Errorf("failed to deliver the digest: %s", strings.Join(failures, "; "))
+	}
+	return nil
+}
+
+// sendEmail mails the HTML digest to the --email addresses
+func (d *DigestRunner) sendEmail(subject, page string) error {
+	smtpCfg := d.deliver.SMTP
+	var msg bytes.Buffer
+	fmt.Fprintf(&msg, "From: %s\r\n", smtpCfg.From)
+	fmt.Fprintf(&msg, "To: %s\r\n", strings.Join(d.email, ", "))
+	fmt.Fprintf(&msg, "Subject: %s\r\n", subject)
+	fmt.Fprintf(&msg, "Date: %s\r\n", time.Now().Format(time.RFC1123Z))
+	msg.WriteString("MIME-Version: 1.0\r\n")
+	msg.WriteString("Content-Type: text/html; charset=UTF-8\r\n\r\n")
+	msg.WriteString(strings.ReplaceAll(page, "\n", "\r\n"))
+
+	var auth smtp.Auth
+	if smtpCfg.Username != "" {
+		host, _, _ := strings.Cut(smtpCfg.Addr, ":")
+		auth = smtp.PlainAuth("", smtpCfg.Username, smtpCfg.Password, host)
+	}
+	if err := smtp.SendMail(smtpCfg.Addr, auth, smtpCfg.From, d.email, msg.Bytes()); err != nil {
+		return fmt.Errorf("failed to email the digest: %w", err)
+	}
+	return nil
+}
+
+// collectDigest aggregates the interactions between start and end, and those of the
+// period of the same length before it for comparison
+func collectDigest(logsDir string, start, end time.Time) (*digest, error) {
+	summary := &digest{
+		start:    start,
+		end:      end,
+		current:  digestTotals{sessions: map[string]bool{}},
+		previous: digestTotals{sessions: map[string]bool{}},
+		types:    map[string]*digestTotals{},
+		errors:   map[string]int{},
+		models:   map[string]*digestTotals{},
+		days:     map[string]*digestTotals{},
+	}
+	entry := func(groups map[string]*digestTotals, key string) *digestTotals {
+		if groups[key] == nil {
+			groups[key] = &digestTotals{sessions: map[string]bool{}}
+		}
+		return groups[key]
+	}
+
+	err := readInteractions(logsDir, start.Add(-end.Sub(start)), func(session string, m backend.InteractionMetric) {
+		if m.Timestamp.After(end) {
+			return
+		}
+		if m.Timestamp.Before(start) {
+			summary.previous.add(session, m)
+			return
+		}
+
+		summary.current.add(session, m)
+		entry(summary.types, cmp.Or(m.PromptType, "unknown")).add(session, m)
+		entry(summary.days, m.Timestamp.Local().Format("2006-01-02")).add(session, m)
+		model := cmp.Or(m.Model, "unknown")
+		if m.Provider != "" {
+			model = m.Provider + "/" + model
+		}
+		entry(summary.models, model).add(session, m)
+		if !m.Success {
+			summary.errors[cmp.Or(m.ErrorType, "unknown")]++
+		}
+	})
+	return summary, err
+}
+
+// add folds an interaction of session into the totals
+func (t *digestTotals) add(session string, m backend.InteractionMetric) {
+	t.sessions[session] = true
+	t.requests++
+	if !m.Success {
+		t.failed++
+	}
+	t.tokens += m.TotalTokens
+	t.cost += m.Cost
+}
+
+// errorRate is the percentage of requests that failed
+func (t digestTotals) errorRate() float64 {
+	if t.requests == 0 {
+		return 0
+	}
+	return float64(t.failed) / float64(t.requests) * 100
+}
+
+// title names the digest after its period and the day it ends
+func (s *digest) title(period string) string {
+	if period == digestWeek {
+		return fmt.Sprintf("chatgbt weekly usage digest: %s to %s", s.start.Format("2006-01-02"), s.end.Format("2006-01-02"))
+	}
+	return fmt.Sprintf("chatgbt daily usage digest: %s", s.end.Format("2006-01-02"))
+}
+
+// markdown writes the digest as Markdown tables
+func (s *digest) markdown(period string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", s.title(period))
+	if s.current.requests == 0 {
+		fmt.Fprintf(&b, "No requests this %s.\n", period)
+		return b.String()
+	}
+
+	cur, prev := s.current, s.previous
+	span := "24 hours"
+	if period == digestWeek {
+		span = "7 days"
+	}
+	fmt.Fprintf(&b, "| | Last %s | %s before | Change |\n|---|---:|---:|---:|\n", span, span)
+	fmt.Fprintf(&b, "| Sessions | %d | %d | %s |\n", len(cur.sessions), len(prev.sessions), change(float64(len(cur.sessions)), float64(len(prev.sessions))))
+	fmt.Fprintf(&b, "| Requests | %d | %d | %s |\n", cur.requests, prev.requests, change(float64(cur.requests), float64(prev.requests)))
+	fmt.Fprintf(&b, "| Error rate | %.1f%% | %.1f%% | %+.1f pts |\n", cur.errorRate(), prev.errorRate(), cur.errorRate()-prev.errorRate())
+	fmt.Fprintf(&b, "| Tokens | %d | %d | %s |\n", cur.tokens, prev.tokens, change(float64(cur.tokens), float64(prev.tokens)))
+	fmt.Fprintf(&b, "| Estimated cost | $%.4f | $%.4f | %s |\n", cur.cost, prev.cost, change(cur.cost, prev.cost))
+
+	b.WriteString("\n## Top prompt types\n\n| Prompt type | Requests | Share |\n|---|---:|---:|\n")
+	types := slices.SortedFunc(maps.Keys(s.types), func(a, b string) int {
+		return cmp.Or(s.types[b].requests-s.types[a].requests, strings.Compare(a, b))
+	})
+	for _, name := range types[:min(len(types), digestTopTypes)] {
+		t := s.types[name]
+		fmt.Fprintf(&b, "| %s | %d | %.0f%% |\n", name, t.requests, float64(t.requests)/float64(cur.requests)*100)
+	}
+
+	b.WriteString("\n## Errors\n\n")
+	if len(s.errors) == 0 {
+		b.WriteString("No failed requests.\n")
+	} else {
+		b.WriteString("| Error | Requests |\n|---|---:|\n")
+		errors := slices.SortedFunc(maps.Keys(s.errors), func(a, b string) int {
+			return cmp.Or(s.errors[b]-s.errors[a], strings.Compare(a, b))
+		})
+		for _, name := range errors {
+			fmt.Fprintf(&b, "| %s | %d |\n", name, s.errors[name])
+		}
+	}
+
+	b.WriteString("\n## Models\n\n| Model | Requests | Tokens | Estimated cost |\n|---|---:|---:|---:|\n")
+	for _, name := range slices.Sorted(maps.Keys(s.models)) {
+		t := s.models[name]
+		fmt.Fprintf(&b, "| %s | %d | %d | $%.4f |\n", name, t.requests, t.tokens, t.cost)
+	}
+
+	b.WriteString("\n## Cost by day\n\n| Day | Requests | Tokens | Estimated cost |\n|---|---:|---:|---:|\n")
+	for _, day := range slices.Sorted(maps.Keys(s.days)) {
+		t := s.days[day]
+		fmt.Fprintf(&b, "| %s | %d | %d | $%.4f |\n", day, t.requests, t.tokens, t.cost)
+	}
+	return b.String()
+}
+
+// change is the relative change from previous to current, as a signed percentage
+func change(current, previous float64) string {
+	if previous == 0 {
+		if current == 0 {
+			return "0%"
+		}
+		return "new"
+	}
+	return fmt.Sprintf("%+.0f%%", (current-previous)/previous*100)
+}
+
+// digestPage renders the Markdown digest as a standalone HTML page
+func digestPage(title, markdown string) string {
+	body := blackfriday.Run([]byte(markdown), blackfriday.WithExtensions(blackfriday.CommonExtensions))
+	return fmt.Sprintf(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>%s</title>
+<style>
+body { font-family: sans-serif; max-width: 48rem; margin: 2rem auto; color: #222; }
+table { border-collapse: collapse; margin-bottom: 1rem; }
+th, td { border: 1px solid #ddd; padding: 0.3rem 0.6rem; }
+th { background: #f4f4f4; }
+</style>
+</head>
+<body>
+%s</body>
+</html>
+`, html.EscapeString(title), body)
+}
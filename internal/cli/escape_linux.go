@@ -0,0 +1,9 @@
+package cli
+
+import "golang.org/x/sys/unix"
+
+// Requests that read and write the terminal settings
+const (
+	ioctlGetTermios = unix.TCGETS
+	ioctlSetTermios = unix.TCSETS
+)
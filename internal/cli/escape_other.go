@@ -0,0 +1,9 @@
+//go:build !linux && !darwin
+
+package cli
+
+// watchEscape calls cancel when Esc is pressed, until the returned function is called.
+// Esc isn't watched on this platform; Ctrl+C still stops a reply.
+func watchEscape(cancel func()) (stop func()) {
+	return func() {}
+}
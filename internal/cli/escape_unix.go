@@ -0,0 +1,67 @@
+//go:build linux || darwin
+
+package cli
+
+import (
+	"os"
+
+	"golang.org/x/sys/unix"
+)
+
+// escapeKey is the byte a lone Esc key press sends
+const escapeKey = 0x1b
+
+// watchEscape calls cancel when Esc is pressed, until the returned function is called.
+// It puts the terminal in non-canonical mode without echo meanwhile, keeping Ctrl+C
+// and output processing as they are. Arrow and function keys, which send sequences
+// starting with Esc, are ignored, as is other input typed meanwhile. Without a
+// terminal on stdin it watches nothing.
+func watchEscape(cancel func()) (stop func()) {
+	fd := int(os.Stdin.Fd())
+	saved, err := unix.IoctlGetTermios(fd, ioctlGetTermios)
+	if err != nil {
+		return func() {}
+	}
+	raw := *saved
+	raw.Lflag &^= unix.ICANON | unix.ECHO
+	raw.Cc[unix.VMIN], raw.Cc[unix.VTIME] = 1, 0
+	if err := unix.IoctlSetTermios(fd, ioctlSetTermios, &raw); err != nil {
+		return func() {}
+	}
+
+	done := make(chan struct{})
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		fds := []unix.PollFd{{Fd: int32(fd), Events: unix.POLLIN}}
+		buf := make([]byte, 16)
+		for {
+			select {
+			case <-done:
+				return
+			default:
+			}
+			// Poll, in milliseconds, rather than block in Read, so stop doesn't wait for a key
+			n, err := unix.Poll(fds, 100)
+			if err == unix.EINTR || n == 0 {
+				continue
+			}
+			if err != nil {
+				return
+			}
+			n, err = unix.Read(fd, buf)
+			if err != nil || n == 0 {
+				return
+			}
+			if n == 1 && buf[0] == escapeKey {
+				cancel()
+			}
+		}
+	}()
+
+	return func() {
+		close(done)
+		<-finished
+		unix.IoctlSetTermios(fd, ioctlSetTermios, saved)
+	}
+}
@@ -0,0 +1,149 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
+
+	"github.com/nleiva/chatgbt/internal/app"
+	"github.com/nleiva/chatgbt/pkg/backend"
+	"github.com/nleiva/chatgbt/pkg/llm"
+)
+
+// EvalRunner runs an evaluation suite and reports pass rate, cost, and latency
+type EvalRunner struct {
+	path        string
+	model       string
+	judgeModel  string
+	minPassRate float64
+	verbose     bool
+	writer      io.Writer
+}
+
+// NewEvalRunner parses the eval subcommand arguments
+func NewEvalRunner(args []string) (*EvalRunner, error) {
+	fs := flag.NewFlagSet("eval", flag.ContinueOnError)
+	model := fs.String("model", "", "provider:model to evaluate (overrides the suite and MODEL)")
+	judgeModel := fs.String("judge-model", "", "provider:model used for judge grading (default: evaluated model)")
+	minPassRate := fs.Float64("min-pass-rate", 1.0, "Exit with an error when the pass rate is below this fraction")
+	verbose := fs.Bool("v", false, "Print answers for failed cases")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() != 1 {
+		return nil, fmt.Errorf("usage: eval [flags] cases.yaml")
+	}
+
+	return &EvalRunner{
+		path:        fs.Arg(0),
+		model:       *model,
+		judgeModel:  *judgeModel,
+		minPassRate: *minPassRate,
+		verbose:     *verbose,
+		writer:      os.Stdout,
+	}, nil
+}
+
+// Run executes every case in the suite and prints the results
+func (e *EvalRunner) Run(cfg backend.LLMConfig, budgetCfg backend.TokenBudgetConfig) error {
+	suite, err := app.LoadEvalSuite(e.path)
+	if err != nil {
+		return err
+	}
+
+	modelCfg := cfg
+	if spec := firstNonEmpty(e.model, suite.Model); spec != "" {
+		modelCfg = app.ParseModelSpec(cfg, spec)
+	}
+	client, err := llm.NewClient(modelCfg, 60*time.Second)
+	if err != nil {
+		return fmt.Errorf("failed to create LLM client: %w", err)
+	}
+
+	judge := client
+	if spec := firstNonEmpty(e.judgeModel, suite.JudgeModel); spec != "" {
+		judge, err = llm.NewClient(app.ParseModelSpec(cfg, spec), 60*time.Second)
+		if err != nil {
+			return fmt.Errorf("failed to create judge client: %w", err)
+		}
+	}
+
+	logger, err := app.NewMetricsLogger(app.GenerateSessionID("eval"), "eval", budgetCfg)
+	if err != nil {
+		return fmt.Errorf("failed to create metrics logger: %w", err)
+	}
+	defer logger.Close()
+
+	evaluator := app.NewEvaluator(client, judge, logger)
+	fmt.Fprintf(e.writer, "Evaluating %d case(s) from %s against %s/%s\n\n",
+		len(suite.Cases), e.path, modelCfg.Provider, modelCfg.Model)
+
+	tw := tabwriter.NewWriter(e.writer, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "CASE\tRESULT\tTIME\tTOKENS\tREASON")
+
+	var results []app.EvalResult
+	latency := backend.NewLatencyHistogram()
+	passed := 0
+	for _, evalCase := range suite.Cases {
+		ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+		result := evaluator.Run(ctx, evalCase, suite.System)
+		cancel()
+
+		results = append(results, result)
+		if result.Err == nil {
+			latency.Observe(result.ResponseTime.Milliseconds())
+		}
+		status := "FAIL"
+		if result.Passed {
+			status = "PASS"
+			passed++
+		}
+		tokens := 0
+		if result.Usage != nil {
+			tokens = result.Usage.TotalTokens
+		}
+		reason := result.Reason
+		if result.Err != nil {
+			reason = result.Err.Error()
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%dms\t%d\t%s\n",
+			evalCase.Name, status, result.ResponseTime.Milliseconds(), tokens, reason)
+	}
+	tw.Flush()
+
+	if e.verbose {
+		for _, result := range results {
+			if !result.Passed && result.Answer != "" {
+				fmt.Fprintf(e.writer, "\n--- %s answer ---\n%s\n", result.Case.Name, strings.TrimSpace(result.Answer))
+			}
+		}
+	}
+
+	summary := logger.GetSessionSummary()
+	passRate := float64(passed) / float64(len(suite.Cases))
+	fmt.Fprintf(e.writer, "\nPass rate: %d/%d (%.1f%%)\n", passed, len(suite.Cases), passRate*100)
+	fmt.Fprintf(e.writer, "Cost: $%.4f (%d tokens, including judge calls)\n", summary.EstimatedCost, summary.TotalTokens)
+	percentiles := latency.Percentiles()
+	fmt.Fprintf(e.writer, "Latency: p50=%dms p95=%dms p99=%dms\n",
+		percentiles.P50, percentiles.P95, percentiles.P99)
+
+	if passRate < e.minPassRate {
+		return fmt.Errorf("pass rate %.1f%% is below the minimum %.1f%%", passRate*100, e.minPassRate*100)
+	}
+	return nil
+}
+
+// firstNonEmpty returns the first non-empty string
+func firstNonEmpty(values ...string) string {
+	for _, v := range values {
+		if v != "" {
+			return v
+		}
+	}
+	return ""
+}
@@ -0,0 +1,66 @@
+package cli
+
+import (
+	"context"
+	"errors"
+	"net"
+	"net/http"
+	"net/url"
+
+	"github.com/nleiva/chatgbt/internal/app"
+	"github.com/nleiva/chatgbt/pkg/backend"
+)
+
+// Exit codes of a failed run, so scripts can branch on why a query failed
+const (
+	ExitError       = 1 // Any other failure
+	ExitAuth        = 3 // The provider rejected the API key (HTTP 401 or 403)
+	ExitRateLimited = 4 // The provider is rate limiting or out of quota (HTTP 429)
+	ExitBudget      = 5 // The session token budget or daily cost limit is used up
+	ExitTimeout     = 6 // No answer before the deadline
+	ExitUnavailable = 7 // The provider failed (HTTP 5xx) or couldn't be reached
+)
+
+// ExitCode maps the error a run ended with to the process exit code
+func ExitCode(err error) int {
+	var overBudget *app.BudgetExceededError
+	var overCost *app.DailyCostExceededError
+	if errors.As(err, &overBudget) || errors.As(err, &overCost) {
+		return ExitBudget
+	}
+
+	var loading *backend.ModelLoadingError
+	if errors.As(err, &loading) {
+		return ExitUnavailable
+	}
+
+	var status *backend.StatusError
+	if errors.As(err, &status) {
+		switch {
+		case status.StatusCode == http.StatusUnauthorized || status.StatusCode == http.StatusForbidden:
+			return ExitAuth
+		case status.StatusCode == http.StatusTooManyRequests:
+			return ExitRateLimited
+		case status.StatusCode == http.StatusRequestTimeout || status.StatusCode == http.StatusGatewayTimeout:
+			return ExitTimeout
+		case status.StatusCode >= 500:
+			return ExitUnavailable
+		}
+		return ExitError
+	}
+
+	if errors.Is(err, context.DeadlineExceeded) {
+		return ExitTimeout
+	}
+	// Only errors from the network: file errors also have a Timeout method
+	var urlErr *url.Error
+	var opErr *net.OpError
+	if errors.As(err, &urlErr) || errors.As(err, &opErr) {
+		var netErr net.Error
+		if errors.As(err, &netErr) && netErr.Timeout() {
+			return ExitTimeout
+		}
+		return ExitUnavailable
+	}
+	return ExitError
+}
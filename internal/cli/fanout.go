@@ -0,0 +1,95 @@
+package cli
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"time"
+
+	"github.com/nleiva/chatgbt/internal/app"
+	"github.com/nleiva/chatgbt/pkg/backend"
+)
+
+// FanoutRunner asks several models the same question at once and prints every answer,
+// optionally followed by a consensus merged by the default model
+type FanoutRunner struct {
+	query     string
+	models    []string // "provider:model" or plain model specifications
+	merge     bool
+	showUsage bool
+	writer    io.Writer
+}
+
+// NewFanoutRunner creates a runner that asks query of every model in models
+func NewFanoutRunner(query string, models []string, merge, showUsage bool) *FanoutRunner {
+	return &FanoutRunner{
+		query:     query,
+		models:    models,
+		merge:     merge,
+		showUsage: showUsage,
+		writer:    os.Stdout,
+	}
+}
+
+// Run queries the models concurrently and prints their answers in the order given
+func (f *FanoutRunner) Run(cfg backend.LLMConfig, budgetCfg backend.TokenBudgetConfig) error {
+	configs := make([]backend.LLMConfig, 0, len(f.models))
+	for _, spec := range f.models {
+		configs = append(configs, app.InferModelSpec(cfg, spec))
+	}
+
+	logger, err := app.NewMetricsLogger(app.GenerateSessionID("fanout"), "fanout", budgetCfg)
+	if err != nil {
+		return fmt.Errorf("failed to create metrics logger: %w", err)
+	}
+	defer logger.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 5*time.Minute)
+	defer cancel()
+
+	fmt.Fprintf(os.Stderr, "Asking %d models...\n", len(configs))
+	results := app.Fanout(ctx, configs, f.query, budgetCfg, logger)
+
+	succeeded := 0
+	for _, result := range results {
+		f.printResult(result.Model, result)
+		if result.Err == nil {
+			succeeded++
+		}
+	}
+	if succeeded == 0 {
+		return fmt.Errorf("all %d models failed", len(results))
+	}
+
+	if f.merge {
+		if succeeded < 2 {
+			fmt.Fprintln(os.Stderr, "Only one model answered, nothing to merge.")
+		} else {
+			merged := app.MergeAnswers(ctx, cfg, f.query, results, budgetCfg, logger)
+			f.printResult("Consensus ("+merged.Model+")", merged)
+		}
+	}
+
+	if f.showUsage {
+		summary := logger.GetSessionSummary()
+		fmt.Fprintf(f.writer, "Total: %d/%d models answered | Tokens: %d | Cost: $%.4f\n",
+			succeeded, len(results), summary.TotalTokens, summary.EstimatedCost)
+	}
+	return nil
+}
+
+// printResult writes one answer under a heading, with its usage when enabled
+func (f *FanoutRunner) printResult(heading string, result app.FanoutResult) {
+	fmt.Fprintf(f.writer, "=== %s ===\n", heading)
+	if result.Err != nil {
+		fmt.Fprintf(f.writer, "Error: %v\n\n", result.Err)
+		return
+	}
+	fmt.Fprintln(f.writer, result.Content)
+	if f.showUsage && result.Usage != nil {
+		fmt.Fprintf(f.writer, "Tokens: %d | Cost: $%.4f | Time: %.1fs\n",
+			result.Usage.TotalTokens, result.Cost, result.ResponseTime.Seconds())
+	}
+	fmt.Fprintln(f.writer)
+}
@@ -0,0 +1,268 @@
+package cli
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"image"
+	"image/color/palette"
+	"image/draw"
+	_ "image/gif" // Decoders for the formats models link to
+	_ "image/jpeg"
+	"image/png"
+	"io"
+	"net/http"
+	"os"
+	"path/filepath"
+	"regexp"
+	"strings"
+	"time"
+)
+
+// Terminal graphics protocols images can be drawn with
+const (
+	imagesOff    = "off"    // Print where the image is instead
+	imagesKitty  = "kitty"  // kitty graphics protocol (kitty, Ghostty, WezTerm)
+	imagesITerm  = "iterm"  // iTerm2 inline images (iTerm2, WezTerm, VS Code)
+	imagesSixel  = "sixel"  // DEC sixel (foot, mlterm, Windows Terminal, xterm -ti vt340)
+	maxImageSize = 20 << 20 // Largest image downloaded
+	// maxImageWidth is the widest image drawn, in pixels; larger ones are scaled down
+	maxImageWidth = 800
+	// imageFetchTimeout bounds downloading an image a reply links to
+	imageFetchTimeout = 15 * time.Second
+)
+
+// markdownImage matches ![alt](target) where target is a URL, data URI, or file path
+var markdownImage = regexp.MustCompile(`!\[([^\]]*)\]\(([^)\s]+)\)`)
+
+// imageProtocol picks how to draw images: TERMINAL_IMAGES when set to a protocol or off,
+// otherwise what the terminal is known to support
+func imageProtocol() string {
+	switch protocol := strings.ToLower(os.Getenv("TERMINAL_IMAGES")); protocol {
+	case imagesOff, imagesKitty, imagesITerm, imagesSixel:
+		return protocol
+	}
+
+	term, program := os.Getenv("TERM"), os.Getenv("TERM_PROGRAM")
+	switch {
+	case os.Getenv("KITTY_WINDOW_ID") != "" || term == "xterm-kitty" || program == "ghostty":
+		return imagesKitty
+	case program == "iTerm.app" || program == "WezTerm" || program == "vscode" || os.Getenv("LC_TERMINAL") == "iTerm2":
+		return imagesITerm
+	case strings.HasPrefix(term, "foot") || term == "mlterm" || strings.Contains(term, "sixel") || os.Getenv("WT_SESSION") != "":
+		return imagesSixel
+	}
+	return imagesOff
+}
+
+// renderImages draws the images a reply links to with protocol, or prints where each
+// one is when it can't be drawn. Images embedded as data URIs are saved to a file first.
+func renderImages(w io.Writer, reply, protocol string) {
+	for _, match := range markdownImage.FindAllStringSubmatch(reply, -1) {
+		alt, target := match[1], match[2]
+		if renderImage(w, target, protocol) == nil {
+			continue
+		}
+
+		location := target
+		if strings.HasPrefix(target, "data:") {
+			path, err := saveDataImage(target)
+			if err != nil {
+				fmt.Fprintf(w, "Warning: failed to save embedded image: %v\n", err)
+				continue
+			}
+			location = path
+		}
+		if alt == "" {
+			alt = "image"
+		}
+		fmt.Fprintf(w, "[%s] %s\n", alt, location)
+	}
+}
+
+// renderImage draws one image, failing when it can't be loaded or drawn
+func renderImage(w io.Writer, target, protocol string) error {
+	if protocol == imagesOff {
+		return fmt.Errorf("terminal can't draw images")
+	}
+	data, err := loadImage(target)
+	if err != nil {
+		return err
+	}
+
+	if protocol == imagesITerm {
+		// iTerm2 decodes the image itself and scales it to the window
+		fmt.Fprintf(w, "\x1b]1337;File=inline=1;size=%d;preserveAspectRatio=1:%s\a\n",
+			len(data), base64.StdEncoding.EncodeToString(data))
+		return nil
+	}
+
+	img, _, err := image.Decode(bytes.NewReader(data))
+	if err != nil {
+		return err
+	}
+	img = fitWidth(img, maxImageWidth)
+	if protocol == imagesKitty {
+		return writeKitty(w, img)
+	}
+	return writeSixel(w, img)
+}
+
+// loadImage reads the image at a data URI, http(s) URL, or file path
+func loadImage(target string) ([]byte, error) {
+	switch {
+	case strings.HasPrefix(target, "data:"):
+		_, payload, ok := strings.Cut(target, ";base64,")
+		if !ok {
+			return nil, fmt.Errorf("only base64 data URIs are supported")
+		}
+		return base64.StdEncoding.DecodeString(payload)
+	case strings.HasPrefix(target, "http://") || strings.HasPrefix(target, "https://"):
+		ctx, cancel := context.WithTimeout(context.Background(), imageFetchTimeout)
+		defer cancel()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, target, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return nil, err
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("%s answered %s", target, resp.Status)
+		}
+		return io.ReadAll(io.LimitReader(resp.Body, maxImageSize))
+	default:
+		return os.ReadFile(strings.TrimPrefix(target, "file://"))
+	}
+}
+
+// saveDataImage writes an image embedded as a data URI to the temporary directory and
+// returns its path; the name is derived from the contents, so saving twice is harmless
+func saveDataImage(target string) (string, error) {
+	data, err := loadImage(target)
+	if err != nil {
+		return "", err
+	}
+
+	ext := ".img"
+	if mediaType, _, _ := strings.Cut(strings.TrimPrefix(target, "data:"), ";"); strings.HasPrefix(mediaType, "image/") {
+		ext = "." + strings.TrimPrefix(mediaType, "image/")
+	}
+	sum := sha256.Sum256(data)
+	dir := filepath.Join(os.TempDir(), "chatgbt-images")
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", err
+	}
+	path := filepath.Join(dir, hex.EncodeToString(sum[:8])+ext)
+	return path, os.WriteFile(path, data, 0600)
+}
+
+// fitWidth scales img down to width pixels, keeping its aspect ratio
+func fitWidth(img image.Image, width int) image.Image {
+	b := img.Bounds()
+	if b.Dx() <= width {
+		return img
+	}
+	height := max(b.Dy()*width/b.Dx(), 1)
+	scaled := image.NewRGBA(image.Rect(0, 0, width, height))
+	for y := range height {
+		for x := range width {
+			scaled.Set(x, y, img.At(b.Min.X+x*b.Dx()/width, b.Min.Y+y*b.Dy()/height))
+		}
+	}
+	return scaled
+}
+
+// writeKitty draws img with the kitty graphics protocol, sending it as PNG in the
+// 4096-byte chunks the protocol requires
+func writeKitty(w io.Writer, img image.Image) error {
+	var buf bytes.Buffer
+	if err := png.Encode(&buf, img); err != nil {
+		return err
+	}
+	payload := base64.StdEncoding.EncodeToString(buf.Bytes())
+
+	out := bufio.NewWriter(w)
+	for first := true; len(payload) > 0; first = false {
+		chunk := payload[:min(len(payload), 4096)]
+		payload = payload[len(chunk):]
+		more := 0
+		if len(payload) > 0 {
+			more = 1
+		}
+		if first {
+			fmt.Fprintf(out, "\x1b_Gf=100,a=T,m=%d;%s\x1b\\", more, chunk)
+		} else {
+			fmt.Fprintf(out, "\x1b_Gm=%d;%s\x1b\\", more, chunk)
+		}
+	}
+	out.WriteString("\n")
+	return out.Flush()
+}
+
+// writeSixel draws img as sixels, dithered to the 216 web-safe colors
+func writeSixel(w io.Writer, img image.Image) error {
+	b := img.Bounds()
+	paletted := image.NewPaletted(image.Rect(0, 0, b.Dx(), b.Dy()), palette.WebSafe)
+	draw.FloydSteinberg.Draw(paletted, paletted.Bounds(), img, b.Min)
+	width, height := b.Dx(), b.Dy()
+
+	out := bufio.NewWriter(w)
+	fmt.Fprintf(out, "\x1bPq\"1;1;%d;%d", width, height)
+	for i, c := range paletted.Palette {
+		r, g, bl, _ := c.RGBA()
+		fmt.Fprintf(out, "#%d;2;%d;%d;%d", i, r*100/0xffff, g*100/0xffff, bl*100/0xffff)
+	}
+
+	// Each band is six pixel rows; every color used in it is drawn as a pass over the band
+	row := make([]byte, width)
+	for top := 0; top < height; top += 6 {
+		used := map[uint8]bool{}
+		for y := top; y < min(top+6, height); y++ {
+			for x := range width {
+				used[paletted.ColorIndexAt(x, y)] = true
+			}
+		}
+		for index := range used {
+			for x := range width {
+				var bits byte
+				for dy := 0; dy < 6 && top+dy < height; dy++ {
+					if paletted.ColorIndexAt(x, top+dy) == index {
+						bits |= 1 << dy
+					}
+				}
+				row[x] = 63 + bits
+			}
+			fmt.Fprintf(out, "#%d", index)
+			writeSixelRow(out, row)
+			out.WriteByte('$')
+		}
+		out.WriteByte('-')
+	}
+	out.WriteString("\x1b\\\n")
+	return out.Flush()
+}
+
+// writeSixelRow writes one color's pass over a band, run-length encoded
+func writeSixelRow(out *bufio.Writer, row []byte) {
+	for i := 0; i < len(row); {
+		run := 1
+		for i+run < len(row) && row[i+run] == row[i] {
+			run++
+		}
+		if run > 3 {
+			fmt.Fprintf(out, "!%d%c", run, row[i])
+		} else {
+			for range run {
+				out.WriteByte(row[i])
+			}
+		}
+		i += run
+	}
+}
@@ -0,0 +1,111 @@
+package render
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/nleiva/chatgbt/pkg/backend"
+)
+
+// Values accepted by --color
+const (
+	ColorAuto   = "auto"   // Color when writing to a terminal and NO_COLOR is unset
+	ColorAlways = "always" // Color even when redirected, e.g. for less -R
+	ColorNever  = "never"
+)
+
+// SGR codes for each kind of output
+const (
+	reset     = "\x1b[0m"
+	bold      = "\x1b[1m"
+	dim       = "\x1b[2m"
+	red       = "\x1b[31m"
+	green     = "\x1b[32m"
+	yellow    = "\x1b[33m"
+	magenta   = "\x1b[35m"
+	cyan      = "\x1b[36m"
+	boldRed   = bold + red
+	boldGreen = bold + green
+	boldCyan  = bold + cyan
+)
+
+// Renderer styles CLI output by what it is: role labels, usage lines, warnings, and
+// errors. A Renderer with color off returns text unchanged.
+type Renderer struct {
+	color bool
+}
+
+// ParseColor validates a --color value
+func ParseColor(mode string) error {
+	switch mode {
+	case ColorAuto, ColorAlways, ColorNever:
+		return nil
+	}
+	return fmt.Errorf("invalid --color %q: must be %s, %s, or %s", mode, ColorAuto, ColorAlways, ColorNever)
+}
+
+// New creates a Renderer for output written to f. In auto mode, color is used only when f
+// is a terminal and NO_COLOR is unset or empty, following https://no-color.org.
+func New(mode string, f *os.File) *Renderer {
+	switch mode {
+	case ColorAlways:
+		return &Renderer{color: true}
+	case ColorNever:
+		return &Renderer{}
+	}
+	return &Renderer{color: os.Getenv("NO_COLOR") == "" && IsTerminal(f)}
+}
+
+// Color reports whether the renderer adds escape codes
+func (r *Renderer) Color() bool {
+	return r.color
+}
+
+// User styles the label of the user's messages
+func (r *Renderer) User(s string) string { return r.style(boldGreen, s) }
+
+// Assistant styles the label of the model's replies
+func (r *Renderer) Assistant(s string) string { return r.style(boldCyan, s) }
+
+// System styles system prompts and the label of system messages
+func (r *Renderer) System(s string) string { return r.style(magenta, s) }
+
+// Tool styles tool calls and the label of tool results
+func (r *Renderer) Tool(s string) string { return r.style(yellow, s) }
+
+// Role styles a message role by who sent it, e.g. in a list of messages
+func (r *Renderer) Role(role backend.Role, s string) string {
+	switch role {
+	case backend.RoleUser:
+		return r.User(s)
+	case backend.RoleAssistant:
+		return r.Assistant(s)
+	case backend.RoleSystem:
+		return r.System(s)
+	case backend.RoleTool:
+		return r.Tool(s)
+	}
+	return s
+}
+
+// Usage styles token usage and timing lines, which are dimmed to keep replies prominent
+func (r *Renderer) Usage(s string) string { return r.style(dim, s) }
+
+// Warning highlights warnings
+func (r *Renderer) Warning(s string) string { return r.style(bold+yellow, s) }
+
+// Error highlights errors
+func (r *Renderer) Error(s string) string { return r.style(boldRed, s) }
+
+func (r *Renderer) style(code, s string) string {
+	if !r.color || s == "" {
+		return s
+	}
+	return code + s + reset
+}
+
+// IsTerminal reports whether f is a terminal rather than a file or pipe
+func IsTerminal(f *os.File) bool {
+	info, err := f.Stat()
+	return err == nil && info.Mode()&os.ModeCharDevice != 0
+}
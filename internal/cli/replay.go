@@ -0,0 +1,308 @@
+package cli
+
+import (
+	"cmp"
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/nleiva/chatgbt/internal/app"
+	"github.com/nleiva/chatgbt/pkg/backend"
+	"github.com/nleiva/chatgbt/pkg/llm"
+	"github.com/nleiva/chatgbt/pkg/store"
+)
+
+// replayTimeout bounds each replayed request
+const replayTimeout = 2 * time.Minute
+
+// ReplayRunner re-sends the user messages of a logged session to another model and
+// reports how its answers differ from the original ones
+type ReplayRunner struct {
+	logPath       string
+	model         string
+	output        string
+	conversations *store.FileStore
+	writer        io.Writer
+}
+
+// replayTurn is one user message with the original answer and the replayed one
+type replayTurn struct {
+	question     string
+	original     string
+	replay       string
+	tokens       int
+	responseTime time.Duration
+	err          error
+}
+
+// loggedSession totals the interactions of the original session from its log
+type loggedSession struct {
+	requests     int
+	answered     int
+	tokens       int
+	responseTime int64 // Milliseconds across answered requests
+}
+
+// NewReplayRunner parses the replay subcommand arguments. The session's messages are
+// read from the conversations in conversationsDir unless --dir says otherwise.
+func NewReplayRunner(args []string, conversationsDir string) (*ReplayRunner, error) {
+	fs := flag.NewFlagSet("replay", flag.ContinueOnError)
+	model := fs.String("model", "", "Model to replay against: a model of LLM_PROVIDER, a well-known model such as claude-sonnet-4-20250514, or provider:model")
+	output := fs.String("o", "", "File to write the Markdown report to instead of stdout")
+	dir := fs.String("dir", conversationsDir, "Directory holding persisted conversations")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	// Flags may also follow the log, as in replay logs/session_X.jsonl --model gpt-4o
+	logPath := fs.Arg(0)
+	if fs.NArg() > 1 {
+		if err := fs.Parse(fs.Args()[1:]); err != nil {
+			return nil, err
+		}
+		if fs.NArg() > 0 {
+			logPath = ""
+		}
+	}
+	if logPath == "" {
+		return nil, fmt.Errorf("usage: replay logs/session_<date>_<id>.jsonl --model <model> [-o report.md]")
+	}
+	if *model == "" {
+		return nil, fmt.Errorf("--model is required")
+	}
+	return &ReplayRunner{
+		logPath:       logPath,
+		model:         *model,
+		output:        *output,
+		conversations: store.NewFileStore(*dir),
+		writer:        os.Stdout,
+	}, nil
+}
+
+// Run replays every conversation of the session in order and writes the report. Each
+// message is sent with the original conversation before it, so every answer is
+// compared with the one given to the same context.
+func (r *ReplayRunner) Run(cfg backend.LLMConfig, budgetCfg backend.TokenBudgetConfig) error {
+	sessionID := logSessionID(r.logPath)
+	var original loggedSession
+	err := readInteractionFile(r.logPath, time.Time{}, func(_ string, m backend.InteractionMetric) {
+		original.requests++
+		if m.Success {
+			original.answered++
+			original.tokens += m.TotalTokens
+			original.responseTime += m.ResponseTime
+		}
+	})
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", r.logPath, err)
+	}
+
+	conversations, err := r.sessionConversations(sessionID)
+	if err != nil {
+		return err
+	}
+
+	modelCfg := app.InferModelSpec(cfg, r.model)
+	client, err := llm.NewClient(modelCfg, replayTimeout)
+	if err != nil {
+		return fmt.Errorf("failed to create LLM client: %w", err)
+	}
+	logger, err := app.NewMetricsLogger(app.GenerateSessionID("replay"), "replay", budgetCfg)
+	if err != nil {
+		return fmt.Errorf("failed to create metrics logger: %w", err)
+	}
+	defer logger.Close()
+
+	var turns []replayTurn
+	var originalModel string
+	for _, conv := range conversations {
+		originalModel = cmp.Or(conv.Model, originalModel)
+		turns = append(turns, r.replayConversation(client, logger, conv)...)
+	}
+
+	w := r.writer
+	if r.output != "" {
+		file, err := os.Create(r.output)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", r.output, err)
+		}
+		defer file.Close()
+		w = file
+	}
+	writeReplayReport(w, sessionID, originalModel, string(modelCfg.Provider)+":"+modelCfg.Model, original, turns)
+	if r.output != "" {
+		fmt.Fprintf(r.writer, "Replayed %d messages; report written to %s\n", len(turns), r.output)
+	}
+	return nil
+}
+
+// sessionConversations returns the stored conversations of a session, oldest first. A
+// session has one per reset.
+func (r *ReplayRunner) sessionConversations(sessionID string) ([]*store.Conversation, error) {
+	all, err := r.conversations.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list conversations: %w", err)
+	}
+
+	var conversations []*store.Conversation
+	for _, conv := range all {
+		if conv.SessionID == sessionID && len(conv.Messages) > 0 {
+			conversations = append(conversations, conv)
+		}
+	}
+	if len(conversations) == 0 {
+		return nil, fmt.Errorf("no saved conversation of session %s; replay needs the messages saved with it", sessionID)
+	}
+	slices.SortFunc(conversations, func(a, b *store.Conversation) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+	return conversations, nil
+}
+
+// replayConversation sends each user message of conv, after the original messages
+// before it, and pairs the answer with the original one
+func (r *ReplayRunner) replayConversation(client *llm.Client, logger app.Logger, conv *store.Conversation) []replayTurn {
+	var turns []replayTurn
+	history := []backend.Message{{Role: backend.RoleSystem, Content: conv.SystemPrompt}}
+	for i, msg := range conv.Messages {
+		if msg.Role != backend.RoleUser {
+			history = append(history, backend.Message{Role: msg.Role, Content: msg.Content})
+			continue
+		}
+
+		turn := replayTurn{question: msg.Content}
+		if i+1 < len(conv.Messages) && conv.Messages[i+1].Role == backend.RoleAssistant {
+			turn.original = conv.Messages[i+1].Content
+		}
+		history = append(history, backend.Message{Role: backend.RoleUser, Content: msg.Content})
+
+		ctx, cancel := context.WithTimeout(context.Background(), replayTimeout)
+		start := time.Now()
+		resp, err := client.CreateCompletion(ctx, &backend.ChatCompletionRequest{Messages: history})
+		cancel()
+		turn.responseTime = time.Since(start)
+
+		interaction := backend.InteractionLog{
+			ResponseTime: turn.responseTime,
+			Success:      err == nil,
+			PromptType:   "replay",
+			Provider:     client.ProviderName(),
+			Model:        client.Model(),
+		}
+		switch {
+		case err != nil:
+			turn.err = err
+		case len(resp.Choices) == 0:
+			turn.err = fmt.Errorf("no choices in response")
+		default:
+			turn.replay = resp.Choices[0].Message.Content
+			interaction.Usage = resp.Usage
+			if resp.Usage != nil {
+				turn.tokens = resp.Usage.TotalTokens
+			}
+		}
+		logger.LogInteraction(interaction)
+		turns = append(turns, turn)
+	}
+	return turns
+}
+
+// writeReplayReport writes a Markdown report: totals side by side, then every message
+// with a line diff from the original answer to the replayed one
+func writeReplayReport(w io.Writer, sessionID, originalModel, replayModel string, original loggedSession, turns []replayTurn) {
+	var tokens, failed, unchanged int
+	var responseTime time.Duration
+	for _, turn := range turns {
+		switch {
+		case turn.err != nil:
+			failed++
+		case strings.TrimSpace(turn.replay) == strings.TrimSpace(turn.original):
+			unchanged++
+		}
+		tokens += turn.tokens
+		responseTime += turn.responseTime
+	}
+	var avgResponseTime, originalAvgResponseTime int64
+	if len(turns) > 0 {
+		avgResponseTime = responseTime.Milliseconds() / int64(len(turns))
+	}
+	if original.answered > 0 {
+		originalAvgResponseTime = original.responseTime / int64(original.answered)
+	}
+
+	fmt.Fprintf(w, "# Replay of session %s\n\n", sessionID)
+	fmt.Fprintf(w, "| | Original | Replay |\n|---|---|---|\n")
+	fmt.Fprintf(w, "| Model | %s | %s |\n", cmp.Or(originalModel, "unknown"), replayModel)
+	fmt.Fprintf(w, "| Answers | %d of %d requests | %d of %d messages |\n",
+		original.answered, original.requests, len(turns)-failed, len(turns))
+	fmt.Fprintf(w, "| Tokens | %d | %d |\n", original.tokens, tokens)
+	fmt.Fprintf(w, "| Avg response time | %dms | %dms |\n", originalAvgResponseTime, avgResponseTime)
+	fmt.Fprintf(w, "\n%d of %d answers unchanged. Original figures come from the session log, which\n", unchanged, len(turns))
+	fmt.Fprintf(w, "also counts requests such as follow-up suggestions that aren't replayed. In the diffs, lines\n")
+	fmt.Fprintf(w, "starting with - are only in the original answer and lines starting with + only in the replay.\n")
+
+	for i, turn := range turns {
+		fmt.Fprintf(w, "\n## Message %d\n\n", i+1)
+		fmt.Fprintf(w, "> %s\n\n", strings.ReplaceAll(strings.TrimSpace(turn.question), "\n", "\n> "))
+		if turn.err != nil {
+			fmt.Fprintf(w, "Replay failed: %v\n", turn.err)
+			continue
+		}
+		fmt.Fprintf(w, "%d tokens, %dms\n\n", turn.tokens, turn.responseTime.Milliseconds())
+		fmt.Fprintln(w, "```diff")
+		for _, line := range lineDiff(turn.original, turn.replay) {
+			fmt.Fprintln(w, line)
+		}
+		fmt.Fprintln(w, "```")
+	}
+}
+
+// lineDiff compares a and b line by line, returning every line prefixed with "  " when
+// both have it, "- " when only a does, and "+ " when only b does
+func lineDiff(a, b string) []string {
+	x := strings.Split(strings.TrimSpace(a), "\n")
+	y := strings.Split(strings.TrimSpace(b), "\n")
+
+	// common[i][j] is the length of the longest common subsequence of x[i:] and y[j:]
+	common := make([][]int, len(x)+1)
+	for i := range common {
+		common[i] = make([]int, len(y)+1)
+	}
+	for i := len(x) - 1; i >= 0; i-- {
+		for j := len(y) - 1; j >= 0; j-- {
+			if x[i] == y[j] {
+				common[i][j] = common[i+1][j+1] + 1
+			} else {
+				common[i][j] = max(common[i+1][j], common[i][j+1])
+			}
+		}
+	}
+
+	var lines []string
+	i, j := 0, 0
+	for i < len(x) && j < len(y) {
+		switch {
+		case x[i] == y[j]:
+			lines = append(lines, "  "+x[i])
+			i++
+			j++
+		case common[i+1][j] >= common[i][j+1]:
+			lines = append(lines, "- "+x[i])
+			i++
+		default:
+			lines = append(lines, "+ "+y[j])
+			j++
+		}
+	}
+	for ; i < len(x); i++ {
+		lines = append(lines, "- "+x[i])
+	}
+	for ; j < len(y); j++ {
+		lines = append(lines, "+ "+y[j])
+	}
+	return lines
+}
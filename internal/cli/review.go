@@ -0,0 +1,178 @@
+package cli
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/nleiva/chatgbt/internal/app"
+	"github.com/nleiva/chatgbt/pkg/backend"
+	"github.com/nleiva/chatgbt/pkg/llm"
+)
+
+// pullRequestPattern matches GitHub pull request URLs
+var pullRequestPattern = regexp.MustCompile(`^https://github\.com/([^/]+)/([^/]+)/pull/(\d+)`)
+
+// ReviewRunner reviews a diff, source file, or pull request and prints a Markdown report
+type ReviewRunner struct {
+	source      string
+	model       string
+	chunkTokens int
+	output      string
+	showUsage   bool
+	writer      io.Writer
+}
+
+// NewReviewRunner parses the review subcommand arguments
+func NewReviewRunner(args []string, showUsage bool) (*ReviewRunner, error) {
+	fs := flag.NewFlagSet("review", flag.ContinueOnError)
+	model := fs.String("model", "", "provider:model to use (overrides MODEL)")
+	chunkTokens := fs.Int("chunk-tokens", app.DefaultReviewChunkTokens, "Maximum diff tokens sent per review request")
+	output := fs.String("o", "", "Write the report to this file instead of stdout")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() != 1 {
+		return nil, fmt.Errorf("usage: review [flags] <file|diff|PR-URL|->")
+	}
+
+	return &ReviewRunner{
+		source:      fs.Arg(0),
+		model:       *model,
+		chunkTokens: *chunkTokens,
+		output:      *output,
+		showUsage:   showUsage,
+		writer:      os.Stdout,
+	}, nil
+}
+
+// Run loads the change, reviews it chunk by chunk, and writes the merged report
+func (r *ReviewRunner) Run(cfg backend.LLMConfig, budgetCfg backend.TokenBudgetConfig) error {
+	diff, err := loadReviewSource(r.source)
+	if err != nil {
+		return err
+	}
+
+	if r.model != "" {
+		cfg = app.ParseModelSpec(cfg, r.model)
+	}
+	client, err := llm.NewClient(cfg, 2*time.Minute)
+	if err != nil {
+		return fmt.Errorf("failed to create LLM client: %w", err)
+	}
+
+	logger, err := app.NewMetricsLogger(app.GenerateSessionID("review"), "review", budgetCfg)
+	if err != nil {
+		return fmt.Errorf("failed to create metrics logger: %w", err)
+	}
+	defer logger.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 15*time.Minute)
+	defer cancel()
+
+	reviewer := app.NewReviewer(client, logger, r.chunkTokens)
+	report, err := reviewer.Review(ctx, r.source, diff, func(index, total int) {
+		fmt.Fprintf(os.Stderr, "Reviewing chunk %d/%d...\n", index, total)
+	})
+	if err != nil {
+		return err
+	}
+
+	if r.output != "" {
+		if err := os.WriteFile(r.output, []byte(report.Markdown()), 0644); err != nil {
+			return fmt.Errorf("failed to write report: %w", err)
+		}
+		fmt.Fprintf(os.Stderr, "Report written to %s\n", r.output)
+	} else {
+		fmt.Fprint(r.writer, report.Markdown())
+	}
+
+	if r.showUsage {
+		fmt.Fprintf(os.Stderr, "Findings: %d | Tokens: %d | Cost: $%.4f | Time: %.1fs\n",
+			len(report.Findings), report.Usage.TotalTokens, logger.GetSessionSummary().EstimatedCost,
+			report.ResponseTime.Seconds())
+	}
+	for _, warning := range logger.GetBudgetStatus().Warnings {
+		fmt.Fprintf(os.Stderr, "Budget: %s\n", warning)
+	}
+	return nil
+}
+
+// loadReviewSource returns the diff to review: a GitHub PR, stdin ("-"), or a file.
+// Files that aren't diffs are presented as newly added so findings get line numbers.
+func loadReviewSource(source string) (string, error) {
+	if match := pullRequestPattern.FindStringSubmatch(source); match != nil {
+		return fetchPullRequestDiff(match[1], match[2], match[3])
+	}
+
+	var data []byte
+	var err error
+	if source == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(source)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", source, err)
+	}
+
+	content := string(data)
+	if isDiff(content) {
+		return content, nil
+	}
+	return newFileDiff(source, content), nil
+}
+
+// fetchPullRequestDiff downloads a pull request as a unified diff, using GITHUB_TOKEN when set
+func fetchPullRequestDiff(owner, repo, number string) (string, error) {
+	url := fmt.Sprintf("https://api.github.com/repos/%s/%s/pulls/%s", owner, repo, number)
+	req, err := http.NewRequest(http.MethodGet, url, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github.diff")
+	if token := os.Getenv("GITHUB_TOKEN"); token != "" {
+		req.Header.Set("Authorization", "Bearer "+token)
+	}
+
+	client := &http.Client{Timeout: 30 * time.Second}
+	resp, err := client.Do(req)
+	if err != nil {
+		return "", fmt.Errorf("failed to fetch pull request: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read pull request diff: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("GitHub returned status %d for %s/%s#%s", resp.StatusCode, owner, repo, number)
+	}
+	return string(body), nil
+}
+
+// isDiff reports whether content looks like a unified diff
+func isDiff(content string) bool {
+	return strings.HasPrefix(content, "diff --git ") ||
+		strings.Contains(content, "\ndiff --git ") ||
+		(strings.Contains(content, "\n+++ ") && strings.Contains(content, "\n@@ "))
+}
+
+// newFileDiff renders content as a diff that adds the whole file
+func newFileDiff(path, content string) string {
+	lines := strings.Split(strings.TrimSuffix(content, "\n"), "\n")
+
+	var b strings.Builder
+	fmt.Fprintf(&b, "diff --git a/%s b/%s\n--- /dev/null\n+++ b/%s\n@@ -0,0 +1,%d @@\n", path, path, path, len(lines))
+	for _, line := range lines {
+		b.WriteString("+" + line + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,71 @@
+package cli
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/nleiva/chatgbt/pkg/backend"
+	"github.com/nleiva/chatgbt/pkg/store"
+)
+
+// SearchRunner searches persisted conversations and session logs
+type SearchRunner struct {
+	query   string
+	limit   int
+	dir     string
+	logsDir string
+	writer  io.Writer
+}
+
+// NewSearchRunner parses the search subcommand arguments
+func NewSearchRunner(args []string) (*SearchRunner, error) {
+	fs := flag.NewFlagSet("search", flag.ContinueOnError)
+	limit := fs.Int("limit", 20, "Maximum number of results (0 for no limit)")
+	dir := fs.String("dir", store.DefaultDir, "Directory holding persisted conversations")
+	logsDir := fs.String("logs", "logs", "Directory holding session JSONL logs (empty to skip)")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	query := strings.TrimSpace(strings.Join(fs.Args(), " "))
+	if query == "" {
+		return nil, fmt.Errorf("usage: search [flags] \"query\"")
+	}
+
+	return &SearchRunner{
+		query:   query,
+		limit:   *limit,
+		dir:     *dir,
+		logsDir: *logsDir,
+		writer:  os.Stdout,
+	}, nil
+}
+
+// Run prints the matching sessions with timestamps and snippets
+func (s *SearchRunner) Run(cfg backend.LLMConfig, budgetCfg backend.TokenBudgetConfig) error {
+	hits, err := store.Search(store.NewFileStore(s.dir), s.logsDir, s.query, s.limit)
+	if err != nil {
+		return fmt.Errorf("failed to search conversations: %w", err)
+	}
+
+	if len(hits) == 0 {
+		fmt.Fprintf(s.writer, "No matches for %q\n", s.query)
+		return nil
+	}
+
+	tw := tabwriter.NewWriter(s.writer, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tTIME\tSOURCE\tSNIPPET")
+	for _, hit := range hits {
+		source := hit.Source
+		if hit.Role != "" {
+			source += "/" + hit.Role
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n",
+			hit.ID, hit.Timestamp.Local().Format("2006-01-02 15:04"), source, hit.Snippet)
+	}
+	return tw.Flush()
+}
@@ -0,0 +1,67 @@
+package cli
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/nleiva/chatgbt/internal/cli/render"
+)
+
+// spinnerInterval is how often the spinner advances a frame
+const spinnerInterval = 100 * time.Millisecond
+
+var (
+	spinnerFrames      = []string{"⠋", "⠙", "⠹", "⠸", "⠼", "⠴", "⠦", "⠧", "⠇", "⠏"}
+	spinnerFramesASCII = []string{"|", "/", "-", "\\"}
+)
+
+// spinner shows that a request is in flight: an animated frame, the model, and the
+// seconds elapsed, redrawn in place until stop erases it
+type spinner struct {
+	w    io.Writer
+	once sync.Once
+	done chan struct{}
+	wg   sync.WaitGroup
+}
+
+// startSpinner starts a spinner on w labelled with model. Nothing is drawn when w isn't
+// a terminal, so redirected output stays free of escape codes.
+func startSpinner(w *os.File, model string, unicode bool) *spinner {
+	s := &spinner{w: w, done: make(chan struct{})}
+	if !render.IsTerminal(w) {
+		return s
+	}
+
+	frames := spinnerFrames
+	if !unicode {
+		frames = spinnerFramesASCII
+	}
+	start := time.Now()
+	s.wg.Add(1)
+	go func() {
+		defer s.wg.Done()
+		ticker := time.NewTicker(spinnerInterval)
+		defer ticker.Stop()
+		for frame := 0; ; frame++ {
+			fmt.Fprintf(s.w, "\r%s %s · %ds", frames[frame%len(frames)], model, int(time.Since(start).Seconds()))
+			select {
+			case <-s.done:
+				fmt.Fprint(s.w, "\r\x1b[K")
+				return
+			case <-ticker.C:
+			}
+		}
+	}()
+	return s
+}
+
+// stop erases the spinner; it is safe to call more than once
+func (s *spinner) stop() {
+	s.once.Do(func() {
+		close(s.done)
+		s.wg.Wait()
+	})
+}
@@ -0,0 +1,209 @@
+package cli
+
+import (
+	"bufio"
+	"encoding/csv"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/nleiva/chatgbt/pkg/backend"
+)
+
+// Formats stats export writes
+const (
+	statsFormatCSV   = "csv"
+	statsFormatJSONL = "jsonl"
+)
+
+// statsColumns are the CSV header, one column per field of an exported interaction
+var statsColumns = []string{
+	"timestamp", "session", "provider", "model", "prompt_type", "success", "error_type",
+	"prompt_tokens", "completion_tokens", "total_tokens", "cost_usd", "response_time_ms", "ttft_ms",
+}
+
+// StatsRunner exports the interactions in the session logs for spreadsheets and reports
+type StatsRunner struct {
+	format  string
+	since   time.Time
+	output  string
+	logsDir string
+	writer  io.Writer
+}
+
+// exportedInteraction is an interaction with the session it belongs to
+type exportedInteraction struct {
+	Session string `json:"session"`
+	backend.InteractionMetric
+}
+
+// NewStatsRunner parses the stats export arguments; stats digest has NewDigestRunner.
+// logsDir is where session logs are read unless --logs says otherwise.
+func NewStatsRunner(args []string, logsDir string) (*StatsRunner, error) {
+	if len(args) == 0 || args[0] != "export" {
+		return nil, fmt.Errorf("usage: stats export [--format csv|jsonl] [--since 7d] [-o file], or stats digest [--period day|week]")
+	}
+
+	fs := flag.NewFlagSet("stats export", flag.ContinueOnError)
+	format := fs.String("format", statsFormatCSV, "Output format: csv or jsonl")
+	since := fs.String("since", "", "Only export interactions this recent (7d, 12h) or from this date on (2006-01-02); empty for all")
+	output := fs.String("o", "", "File to write to instead of stdout")
+	logs := fs.String("logs", logsDir, "Directory holding session JSONL logs")
+	if err := fs.Parse(args[1:]); err != nil {
+		return nil, err
+	}
+
+	runner := &StatsRunner{
+		format:  strings.ToLower(*format),
+		output:  *output,
+		logsDir: *logs,
+		writer:  os.Stdout,
+	}
+	if runner.format != statsFormatCSV && runner.format != statsFormatJSONL {
+		return nil, fmt.Errorf("invalid --format %q: must be %s or %s", *format, statsFormatCSV, statsFormatJSONL)
+	}
+	start, err := parseSince(*since, time.Now())
+	if err != nil {
+		return nil, err
+	}
+	runner.since = start
+	return runner, nil
+}
+
+// Run writes one row per logged interaction, oldest first
+func (s *StatsRunner) Run(cfg backend.LLMConfig, budgetCfg backend.TokenBudgetConfig) error {
+	var rows []exportedInteraction
+	err := readInteractions(s.logsDir, s.since, func(session string, m backend.InteractionMetric) {
+		rows = append(rows, exportedInteraction{Session: session, InteractionMetric: m})
+	})
+	if err != nil {
+		return err
+	}
+	slices.SortStableFunc(rows, func(a, b exportedInteraction) int {
+		return a.Timestamp.Compare(b.Timestamp)
+	})
+
+	w := s.writer
+	if s.output != "" {
+		file, err := os.Create(s.output)
+		if err != nil {
+			return fmt.Errorf("failed to create %s: %w", s.output, err)
+		}
+		defer file.Close()
+		w = file
+	}
+
+	if s.format == statsFormatJSONL {
+		encoder := json.NewEncoder(w)
+		for _, row := range rows {
+			if err := encoder.Encode(row); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+
+	out := csv.NewWriter(w)
+	out.Write(statsColumns)
+	for _, row := range rows {
+		out.Write([]string{
+			row.Timestamp.UTC().Format(time.RFC3339),
+			row.Session,
+			row.Provider,
+			row.Model,
+			row.PromptType,
+			strconv.FormatBool(row.Success),
+			row.ErrorType,
+			strconv.Itoa(row.RequestTokens),
+			strconv.Itoa(row.ResponseTokens),
+			strconv.Itoa(row.TotalTokens),
+			strconv.FormatFloat(row.Cost, 'f', 6, 64),
+			strconv.FormatInt(row.ResponseTime, 10),
+			strconv.FormatInt(row.TTFT, 10),
+		})
+	}
+	out.Flush()
+	return out.Error()
+}
+
+// parseSince turns a --since value into the earliest time to include: a number of days
+// (7d) or any Go duration (12h) back from now, or a date. Empty includes everything.
+func parseSince(value string, now time.Time) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if days, ok := strings.CutSuffix(value, "d"); ok {
+		if n, err := strconv.Atoi(days); err == nil && n > 0 {
+			return now.AddDate(0, 0, -n), nil
+		}
+	}
+	if d, err := time.ParseDuration(value); err == nil && d > 0 {
+		return now.Add(-d), nil
+	}
+	if date, err := time.ParseInLocation("2006-01-02", value, time.Local); err == nil {
+		return date, nil
+	}
+	return time.Time{}, fmt.Errorf("invalid --since %q: use a number of days (7d), a duration (12h), or a date (2006-01-02)", value)
+}
+
+// readInteractions calls fn with every interaction logged from since on in the session
+// JSONL logs under logsDir, along with the session it belongs to. Summary and malformed
+// lines are skipped.
+func readInteractions(logsDir string, since time.Time, fn func(session string, m backend.InteractionMetric)) error {
+	files, err := filepath.Glob(filepath.Join(logsDir, "session_*.jsonl"))
+	if err != nil {
+		return err
+	}
+	slices.Sort(files)
+
+	for _, path := range files {
+		if err := readInteractionFile(path, since, fn); err != nil {
+			return fmt.Errorf("failed to read %s: %w", path, err)
+		}
+	}
+	return nil
+}
+
+// logSessionID returns the ID of the session a log belongs to; logs are named
+// session_<date>_<id>.jsonl
+func logSessionID(path string) string {
+	session := strings.TrimSuffix(strings.TrimPrefix(filepath.Base(path), "session_"), ".jsonl")
+	if _, id, ok := strings.Cut(session, "_"); ok {
+		session = id
+	}
+	return session
+}
+
+// readInteractionFile calls fn with the interactions logged from since on in one session log
+func readInteractionFile(path string, since time.Time, fn func(session string, m backend.InteractionMetric)) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	session := logSessionID(path)
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "{") {
+			continue // SESSION_SUMMARY lines repeat the interactions
+		}
+		var m backend.InteractionMetric
+		if err := json.Unmarshal([]byte(line), &m); err != nil || m.Timestamp.Before(since) {
+			continue
+		}
+		fn(session, m)
+	}
+	return scanner.Err()
+}
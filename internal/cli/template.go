@@ -0,0 +1,133 @@
+package cli
+
+import (
+	"bytes"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"runtime"
+	"strings"
+	"text/template"
+	"time"
+
+	"github.com/atotto/clipboard"
+
+	"github.com/nleiva/chatgbt/pkg/backend"
+)
+
+const (
+	// shellTimeout bounds a {{sh}} command in a prompt
+	shellTimeout = 30 * time.Second
+
+	// largeInclusionTokens is the size above which an inclusion is reported before sending
+	largeInclusionTokens = 2000
+)
+
+// inclusion is text a template helper put into the prompt
+type inclusion struct {
+	source string // e.g. file main.go
+	tokens int
+}
+
+// ExpandPrompt expands the template helpers in a quick query:
+//
+//	{{file "main.go"}}  contents of a file
+//	{{env "HOME"}}      value of an environment variable
+//	{{clipboard}}       text on the clipboard
+//	{{sh "git diff"}}   output of a shell command
+//
+// Queries without "{{" are returned as they are. Warnings about large inclusions, and
+// about a prompt longer than model's context window, are written to w.
+func ExpandPrompt(query, model string, w io.Writer) (string, error) {
+	if !strings.Contains(query, "{{") {
+		return query, nil
+	}
+
+	var inclusions []inclusion
+	include := func(source, text string) string {
+		tokens, _ := backend.CountTokens(model, text)
+		inclusions = append(inclusions, inclusion{source: source, tokens: tokens})
+		return text
+	}
+
+	tmpl, err := template.New("prompt").Option("missingkey=error").Funcs(template.FuncMap{
+		"file": func(path string) (string, error) {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				return "", err
+			}
+			return include("file "+path, string(data)), nil
+		},
+		"env": func(name string) (string, error) {
+			value, ok := os.LookupEnv(name)
+			if !ok {
+				return "", fmt.Errorf("environment variable %s is not set", name)
+			}
+			return include("env "+name, value), nil
+		},
+		"clipboard": func() (string, error) {
+			text, err := clipboard.ReadAll()
+			if err != nil {
+				return "", err
+			}
+			return include("clipboard", text), nil
+		},
+		"sh": func(command string) (string, error) {
+			out, err := runShell(command)
+			if err != nil {
+				return "", err
+			}
+			return include("sh "+command, out), nil
+		},
+	}).Parse(query)
+	if err != nil {
+		return "", fmt.Errorf("invalid prompt template (use --raw to send {{ }} as typed): %w", err)
+	}
+
+	var b strings.Builder
+	if err := tmpl.Execute(&b, nil); err != nil {
+		return "", fmt.Errorf("failed to expand prompt (use --raw to send {{ }} as typed): %w", err)
+	}
+	expanded := b.String()
+
+	for _, inc := range inclusions {
+		if inc.tokens > largeInclusionTokens {
+			fmt.Fprintf(w, "Warning: %s adds ~%d tokens to the prompt\n", inc.source, inc.tokens)
+		}
+	}
+	tokens, _ := backend.CountTokens(model, expanded)
+	if window := backend.ModelContextWindow(model); window > 0 && tokens > window {
+		fmt.Fprintf(w, "Warning: the expanded prompt is ~%d tokens, more than the %d-token context window of %s\n",
+			tokens, window, model)
+	}
+	return expanded, nil
+}
+
+// runShell runs command with the platform's shell and returns its output, without the
+// trailing newline
+func runShell(command string) (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), shellTimeout)
+	defer cancel()
+
+	shell, flag := "sh", "-c"
+	if runtime.GOOS == "windows" {
+		shell, flag = "cmd", "/C"
+	}
+
+	var stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, shell, flag, command)
+	cmd.Stderr = &stderr
+	out, err := cmd.Output()
+	if err != nil {
+		if ctx.Err() != nil {
+			return "", fmt.Errorf("%q timed out after %v", command, shellTimeout)
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return "", fmt.Errorf("%q failed: %w: %s", command, err, msg)
+		}
+		return "", fmt.Errorf("%q failed: %w", command, err)
+	}
+	return strings.TrimRight(string(out), "\r\n"), nil
+}
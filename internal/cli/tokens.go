@@ -0,0 +1,136 @@
+package cli
+
+import (
+	"cmp"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"strconv"
+	"text/tabwriter"
+
+	"github.com/nleiva/chatgbt/pkg/apikeys"
+	"github.com/nleiva/chatgbt/pkg/backend"
+	"github.com/nleiva/chatgbt/pkg/config"
+)
+
+// tokensUsage lists the tokens subcommands
+const tokensUsage = "usage: tokens create --name <name> [--tenant <tenant>] [--budget <tokens>] [--rpm <requests>] | tokens list | tokens revoke <id or name>"
+
+// TokensRunner creates, lists, and revokes the API tokens of the web server's JSON API
+type TokensRunner struct {
+	action string
+	path   string
+	writer io.Writer
+
+	// create settings
+	name   string
+	tenant string
+	budget int
+	rpm    int
+
+	target string // ID or name to revoke
+}
+
+// NewTokensRunner parses the tokens subcommand arguments. Tokens are kept in path.
+func NewTokensRunner(args []string, path string) (*TokensRunner, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf(tokensUsage)
+	}
+	r := &TokensRunner{action: args[0], writer: os.Stdout}
+
+	fs := flag.NewFlagSet("tokens "+args[0], flag.ContinueOnError)
+	file := fs.String("file", path, "File holding the API tokens")
+	switch r.action {
+	case "create":
+		fs.StringVar(&r.name, "name", "", "Name of the token, such as the client it is for")
+		fs.StringVar(&r.tenant, "tenant", "", "Tenant whose sessions, conversations, logs, and budget the token's requests use")
+		fs.IntVar(&r.budget, "budget", 0, "Model tokens the API token may spend in all (0 for no limit)")
+		fs.IntVar(&r.rpm, "rpm", 0, "Requests per minute the API token may make (0 for no limit)")
+	case "list", "revoke":
+	default:
+		return nil, fmt.Errorf("unknown tokens action %q; %s", r.action, tokensUsage)
+	}
+	if err := fs.Parse(args[1:]); err != nil {
+		return nil, err
+	}
+	r.path = *file
+
+	switch r.action {
+	case "create":
+		if r.name == "" {
+			return nil, fmt.Errorf("--name is required")
+		}
+	case "revoke":
+		if fs.NArg() != 1 {
+			return nil, fmt.Errorf(tokensUsage)
+		}
+		r.target = fs.Arg(0)
+	}
+	return r, nil
+}
+
+// Run performs the action
+func (r *TokensRunner) Run(cfg backend.LLMConfig, budgetCfg backend.TokenBudgetConfig) error {
+	tokens, err := apikeys.Open(r.path)
+	if err != nil {
+		return err
+	}
+
+	switch r.action {
+	case "create":
+		if r.tenant != "" {
+			// Tenants keep their data in a user profile, created here if new
+			if _, err := config.LoadUserProfile(r.tenant); err != nil {
+				return err
+			}
+		}
+		value, token, err := tokens.Create(r.name, r.tenant, r.budget, r.rpm)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(r.writer, "Created API token %s (%s). Copy it now; it isn't shown again:\n\n  %s\n\n", token.Name, token.ID, value)
+		fmt.Fprintf(r.writer, "Send it as \"Authorization: Bearer <token>\" to /api/v1.\n")
+		return nil
+	case "revoke":
+		token, err := tokens.Revoke(r.target)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(r.writer, "Revoked API token %s (%s)\n", token.Name, token.ID)
+		return nil
+	}
+
+	list, err := tokens.List()
+	if err != nil {
+		return err
+	}
+	if len(list) == 0 {
+		if tokens.Enabled() {
+			fmt.Fprintf(r.writer, "No API tokens in %s; the API refuses every request until one is created\n", r.path)
+		} else {
+			fmt.Fprintf(r.writer, "No API tokens in %s; the API is open to anyone who can reach the server\n", r.path)
+		}
+		return nil
+	}
+	tw := tabwriter.NewWriter(r.writer, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tNAME\tTENANT\tCREATED\tLAST USED\tREQUESTS\tTOKENS USED\tBUDGET\tRPM")
+	for _, t := range list {
+		lastUsed := "never"
+		if !t.LastUsed.IsZero() {
+			lastUsed = t.LastUsed.Local().Format("2006-01-02 15:04")
+		}
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\t%s\t%d\t%d\t%s\t%s\n",
+			t.ID, t.Name, cmp.Or(t.Tenant, "-"), t.CreatedAt.Local().Format("2006-01-02 15:04"), lastUsed,
+			t.Requests, t.TokensUsed, limitText(t.TokenBudget), limitText(t.RequestsPerMinute))
+	}
+	return tw.Flush()
+}
+
+// limitText shows a limit, or "-" for none
+func limitText(limit int) string {
+	if limit == 0 {
+		return "-"
+	}
+	return strconv.Itoa(limit)
+}
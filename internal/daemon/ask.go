@@ -0,0 +1,200 @@
+package daemon
+
+import (
+	"bufio"
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"os"
+	"strings"
+	"syscall"
+	"time"
+
+	"github.com/nleiva/chatgbt/internal/cli"
+	"github.com/nleiva/chatgbt/pkg/backend"
+)
+
+// ErrNotRunning is returned by Client when no daemon is listening on the socket
+var ErrNotRunning = errors.New("daemon is not running")
+
+// Client talks to a running daemon over its Unix socket
+type Client struct {
+	socket string
+	http   *http.Client
+}
+
+// NewClient creates a client for the daemon listening on socket
+func NewClient(socket string) *Client {
+	return &Client{
+		socket: socket,
+		http: &http.Client{
+			Transport: &http.Transport{
+				DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+					var dialer net.Dialer
+					return dialer.DialContext(ctx, "unix", socket)
+				},
+			},
+		},
+	}
+}
+
+// Ask sends a message to a named session, calling onDelta with each chunk of the reply
+func (c *Client) Ask(ctx context.Context, req AskRequest, onDelta backend.StreamHandler) (*AskEvent, error) {
+	body, err := json.Marshal(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodPost, "http://chatgbt/ask", bytes.NewReader(body))
+	if err != nil {
+		return nil, err
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+
+	resp, err := c.http.Do(httpReq)
+	if err != nil {
+		if isNotRunning(err) {
+			return nil, ErrNotRunning
+		}
+		return nil, fmt.Errorf("failed to reach daemon: %w", err)
+	}
+	defer resp.Body.Close()
+
+	scanner := bufio.NewScanner(resp.Body)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		var event AskEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return nil, fmt.Errorf("failed to decode daemon response: %w", err)
+		}
+		if event.Error != "" {
+			return nil, fmt.Errorf("daemon: %s", event.Error)
+		}
+		if event.Done {
+			return &event, nil
+		}
+		if onDelta != nil {
+			onDelta(event.Delta)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read daemon response: %w", err)
+	}
+	return nil, fmt.Errorf("daemon closed the connection before the reply finished")
+}
+
+// isNotRunning reports whether a dial error means nobody is listening on the socket
+func isNotRunning(err error) bool {
+	return errors.Is(err, os.ErrNotExist) || errors.Is(err, syscall.ECONNREFUSED)
+}
+
+// AskRunner sends a quick query to a warm daemon session, falling back to a cold
+// direct query when no daemon is running
+type AskRunner struct {
+	query     string
+	session   string
+	reset     bool
+	socket    string
+	showUsage bool
+	writer    io.Writer
+
+	// fanout lists the models asked at once instead of the daemon; merge combines their answers
+	fanout []string
+	merge  bool
+
+	raw bool // Send {{ }} as typed instead of expanding template helpers
+}
+
+// NewAskRunner parses the ask subcommand arguments
+func NewAskRunner(args []string, showUsage bool) (*AskRunner, error) {
+	fs := flag.NewFlagSet("ask", flag.ContinueOnError)
+	session := fs.String("session", DefaultSessionName, "Named daemon session to continue")
+	reset := fs.Bool("new", false, "Start a fresh conversation in the session")
+	socket := fs.String("socket", DefaultSocketPath(), "Daemon Unix socket")
+	fanout := fs.String("fanout", "", "Comma-separated models to ask at once instead of the daemon, e.g. gpt-4o,claude-sonnet-4-20250514,groq:llama-3.3-70b-versatile")
+	merge := fs.Bool("merge", false, "With --fanout, merge the answers into a consensus using MODEL")
+	raw := fs.Bool("raw", false, "Send {{ }} in the question as typed instead of expanding template helpers")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	query := strings.TrimSpace(strings.Join(fs.Args(), " "))
+	if query == "" {
+		return nil, fmt.Errorf("usage: ask [flags] \"question\"")
+	}
+	var models []string
+	for _, model := range strings.Split(*fanout, ",") {
+		if model = strings.TrimSpace(model); model != "" {
+			models = append(models, model)
+		}
+	}
+	if *merge && len(models) == 0 {
+		return nil, fmt.Errorf("--merge requires --fanout")
+	}
+
+	return &AskRunner{
+		query:     query,
+		session:   *session,
+		reset:     *reset,
+		socket:    *socket,
+		showUsage: showUsage,
+		writer:    os.Stdout,
+		fanout:    models,
+		merge:     *merge,
+		raw:       *raw,
+	}, nil
+}
+
+// Run asks the daemon, or runs the query directly when the daemon isn't available
+func (a *AskRunner) Run(cfg backend.LLMConfig, budgetCfg backend.TokenBudgetConfig) error {
+	// Helpers such as {{file "main.go"}} are expanded here, where the files are, not in the daemon
+	if !a.raw {
+		query, err := cli.ExpandPrompt(a.query, cfg.Model, os.Stderr)
+		if err != nil {
+			return err
+		}
+		a.query = query
+	}
+
+	if len(a.fanout) > 0 {
+		return cli.NewFanoutRunner(a.query, a.fanout, a.merge, a.showUsage).Run(cfg, budgetCfg)
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), 2*time.Minute)
+	defer cancel()
+
+	event, err := NewClient(a.socket).Ask(ctx, AskRequest{
+		Session: a.session,
+		Message: a.query,
+		Reset:   a.reset,
+	}, func(delta string) {
+		fmt.Fprint(a.writer, delta)
+	})
+	if errors.Is(err, ErrNotRunning) {
+		fmt.Fprintln(os.Stderr, "No daemon running (start one with 'chatgbt daemon'), answering without session context.")
+		return cli.NewDirectQueryRunner(a.query, a.showUsage).Run(cfg, budgetCfg)
+	}
+	if err != nil {
+		return err
+	}
+	fmt.Fprintln(a.writer)
+
+	if a.showUsage && event.Usage != nil {
+		line := fmt.Sprintf("Session: %s | Tokens: %d | Cost: $%.4f | Time: %.1fs",
+			a.session, event.Usage.TotalTokens, event.SessionCost, float64(event.ResponseTimeMs)/1000)
+		if event.TTFTMs > 0 {
+			line += fmt.Sprintf(" | TTFT: %dms | %.1f tok/s", event.TTFTMs, event.TokensPerSecond)
+		}
+		fmt.Fprintln(a.writer, line)
+	}
+	for _, warning := range event.Warnings {
+		fmt.Fprintf(a.writer, "Budget: %s\n", warning)
+	}
+	return nil
+}
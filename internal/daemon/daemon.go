@@ -0,0 +1,363 @@
+package daemon
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/nleiva/chatgbt/internal/app"
+	"github.com/nleiva/chatgbt/pkg/backend"
+)
+
+const (
+	// DefaultSessionName is used when ask doesn't name a session
+	DefaultSessionName = "default"
+
+	conversationType = "cli_session"
+	systemPrompt     = "You are a helpful assistant."
+)
+
+// DefaultSocketPath returns the per-user socket location, preferring XDG_RUNTIME_DIR
+func DefaultSocketPath() string {
+	if dir := os.Getenv("XDG_RUNTIME_DIR"); dir != "" {
+		return filepath.Join(dir, "chatgbt.sock")
+	}
+	return filepath.Join(os.TempDir(), fmt.Sprintf("chatgbt-%d.sock", os.Getuid()))
+}
+
+// AskRequest is the body of POST /ask
+type AskRequest struct {
+	Session string `json:"session"`         // Named session; created on first use
+	Message string `json:"message"`         // User message
+	Reset   bool   `json:"reset,omitempty"` // Start a fresh conversation before asking
+}
+
+// AskEvent is one line of the newline-delimited JSON stream returned by POST /ask.
+// Deltas arrive as they are generated; the final event has Done set.
+type AskEvent struct {
+	Delta           string         `json:"delta,omitempty"`
+	Done            bool           `json:"done,omitempty"`
+	SessionID       string         `json:"session_id,omitempty"`
+	Usage           *backend.Usage `json:"usage,omitempty"`
+	ResponseTimeMs  int64          `json:"response_time_ms,omitempty"`
+	TTFTMs          int64          `json:"ttft_ms,omitempty"`
+	TokensPerSecond float64        `json:"tokens_per_second,omitempty"`
+	SessionCost     float64        `json:"session_cost,omitempty"`
+	Warnings        []string       `json:"warnings,omitempty"`
+	Error           string         `json:"error,omitempty"`
+}
+
+// SessionInfo describes a warm session in GET /sessions
+type SessionInfo struct {
+	Name       string    `json:"name"`
+	ID         string    `json:"id"`
+	Messages   int       `json:"messages"`
+	Tokens     int       `json:"tokens"`
+	Cost       float64   `json:"cost"`
+	LastAccess time.Time `json:"last_access"`
+}
+
+// namedSession serializes access to a ChatSession, which isn't safe for concurrent use
+type namedSession struct {
+	mutex      sync.Mutex
+	session    *app.ChatSession
+	lastAccess time.Time
+}
+
+// DaemonRunner keeps sessions warm and serves them over a local Unix socket
+type DaemonRunner struct {
+	socket  string
+	idleTTL time.Duration
+	opts    app.SessionOptions
+
+	cfg       backend.LLMConfig
+	budgetCfg backend.TokenBudgetConfig
+
+	mutex    sync.Mutex
+	sessions map[string]*namedSession
+}
+
+// NewDaemonRunner parses the daemon subcommand arguments
+func NewDaemonRunner(args []string, opts app.SessionOptions) (*DaemonRunner, error) {
+	fs := flag.NewFlagSet("daemon", flag.ContinueOnError)
+	socket := fs.String("socket", DefaultSocketPath(), "Unix socket to listen on")
+	idleTTL := fs.Duration("idle", 2*time.Hour, "Close sessions that haven't been used for this long")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+
+	return &DaemonRunner{
+		socket:   *socket,
+		idleTTL:  *idleTTL,
+		opts:     opts,
+		sessions: make(map[string]*namedSession),
+	}, nil
+}
+
+// Run listens on the socket until interrupted
+func (d *DaemonRunner) Run(cfg backend.LLMConfig, budgetCfg backend.TokenBudgetConfig) error {
+	d.cfg = cfg
+	d.budgetCfg = budgetCfg
+
+	listener, err := d.listen()
+	if err != nil {
+		return err
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("GET /health", d.handleHealth)
+	mux.HandleFunc("POST /ask", d.handleAsk)
+	mux.HandleFunc("POST /reset", d.handleReset)
+	mux.HandleFunc("GET /sessions", d.handleSessions)
+	server := &http.Server{Handler: mux}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	go d.cleanupIdleSessions(ctx)
+	go func() {
+		<-ctx.Done()
+		shutdownCtx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		defer cancel()
+		server.Shutdown(shutdownCtx)
+	}()
+
+	log.Printf("chatgbt daemon listening on %s (%s/%s)", d.socket, cfg.Provider, cfg.Model)
+	err = server.Serve(listener)
+	d.closeSessions()
+	if errors.Is(err, http.ErrServerClosed) {
+		return nil
+	}
+	return err
+}
+
+// listen creates the socket, replacing a stale one left by a daemon that didn't exit cleanly
+func (d *DaemonRunner) listen() (net.Listener, error) {
+	if _, err := os.Stat(d.socket); err == nil {
+		if conn, err := net.DialTimeout("unix", d.socket, time.Second); err == nil {
+			conn.Close()
+			return nil, fmt.Errorf("a daemon is already listening on %s", d.socket)
+		}
+		if err := os.Remove(d.socket); err != nil {
+			return nil, fmt.Errorf("failed to remove stale socket: %w", err)
+		}
+	}
+
+	if err := os.MkdirAll(filepath.Dir(d.socket), 0700); err != nil {
+		return nil, fmt.Errorf("failed to create socket directory: %w", err)
+	}
+	listener, err := net.Listen("unix", d.socket)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen on %s: %w", d.socket, err)
+	}
+	// Only the current user may talk to the daemon; it spends their API budget
+	if err := os.Chmod(d.socket, 0600); err != nil {
+		listener.Close()
+		return nil, fmt.Errorf("failed to restrict socket permissions: %w", err)
+	}
+	return listener, nil
+}
+
+// getSession returns the named session, creating it on first use
+func (d *DaemonRunner) getSession(name string) (*namedSession, error) {
+	if name == "" {
+		name = DefaultSessionName
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	if ns, exists := d.sessions[name]; exists {
+		return ns, nil
+	}
+
+	session, err := app.NewChatSessionWithDefaults(
+		app.GenerateSessionID("daemon_"+name),
+		conversationType,
+		systemPrompt,
+		d.cfg,
+		d.budgetCfg,
+		d.opts,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	ns := &namedSession{session: session, lastAccess: time.Now()}
+	d.sessions[name] = ns
+	return ns, nil
+}
+
+// handleHealth lets clients check whether the daemon is running
+func (d *DaemonRunner) handleHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+}
+
+// handleAsk sends a message to a warm session and streams the reply as NDJSON
+func (d *DaemonRunner) handleAsk(w http.ResponseWriter, r *http.Request) {
+	var req AskRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, AskEvent{Error: "invalid request: " + err.Error()})
+		return
+	}
+	if strings.TrimSpace(req.Message) == "" {
+		writeJSON(w, http.StatusBadRequest, AskEvent{Error: "message is required"})
+		return
+	}
+
+	ns, err := d.getSession(req.Session)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, AskEvent{Error: err.Error()})
+		return
+	}
+
+	ns.mutex.Lock()
+	defer ns.mutex.Unlock()
+	ns.lastAccess = time.Now()
+	if req.Reset {
+		ns.session.Reset("")
+	}
+
+	w.Header().Set("Content-Type", "application/x-ndjson")
+	w.WriteHeader(http.StatusOK)
+	enc := json.NewEncoder(w)
+	flusher, _ := w.(http.Flusher)
+	send := func(event AskEvent) {
+		enc.Encode(event)
+		if flusher != nil {
+			flusher.Flush()
+		}
+	}
+
+	response, err := ns.session.ProcessUserMessageStream(req.Message, func(delta string) {
+		send(AskEvent{Delta: delta})
+	})
+	if err != nil {
+		send(AskEvent{Done: true, SessionID: ns.session.ID, Error: err.Error()})
+		return
+	}
+
+	send(AskEvent{
+		Done:            true,
+		SessionID:       ns.session.ID,
+		Usage:           response.Usage,
+		ResponseTimeMs:  response.ResponseTime.Milliseconds(),
+		TTFTMs:          response.TTFT.Milliseconds(),
+		TokensPerSecond: response.TokensPerSecond,
+		SessionCost:     ns.session.GetBudgetStatus().SessionCost,
+		Warnings:        response.Warnings,
+	})
+}
+
+// handleReset clears the conversation of a named session
+func (d *DaemonRunner) handleReset(w http.ResponseWriter, r *http.Request) {
+	var req AskRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		writeJSON(w, http.StatusBadRequest, AskEvent{Error: "invalid request: " + err.Error()})
+		return
+	}
+
+	ns, err := d.getSession(req.Session)
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, AskEvent{Error: err.Error()})
+		return
+	}
+
+	ns.mutex.Lock()
+	ns.session.Reset("")
+	ns.lastAccess = time.Now()
+	ns.mutex.Unlock()
+
+	writeJSON(w, http.StatusOK, AskEvent{Done: true, SessionID: ns.session.ID})
+}
+
+// handleSessions lists the warm sessions
+func (d *DaemonRunner) handleSessions(w http.ResponseWriter, r *http.Request) {
+	d.mutex.Lock()
+	names := make([]string, 0, len(d.sessions))
+	for name := range d.sessions {
+		names = append(names, name)
+	}
+	d.mutex.Unlock()
+	sort.Strings(names)
+
+	infos := make([]SessionInfo, 0, len(names))
+	for _, name := range names {
+		ns, err := d.getSession(name)
+		if err != nil {
+			continue
+		}
+		ns.mutex.Lock()
+		status := ns.session.GetBudgetStatus()
+		infos = append(infos, SessionInfo{
+			Name:       name,
+			ID:         ns.session.ID,
+			Messages:   len(ns.session.Messages),
+			Tokens:     status.SessionTokens,
+			Cost:       status.SessionCost,
+			LastAccess: ns.lastAccess,
+		})
+		ns.mutex.Unlock()
+	}
+	writeJSON(w, http.StatusOK, infos)
+}
+
+// cleanupIdleSessions closes sessions that haven't been used within the idle TTL
+func (d *DaemonRunner) cleanupIdleSessions(ctx context.Context) {
+	ticker := time.NewTicker(time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		d.mutex.Lock()
+		for name, ns := range d.sessions {
+			if !ns.mutex.TryLock() {
+				continue // In use, so not idle
+			}
+			if time.Since(ns.lastAccess) > d.idleTTL {
+				ns.session.Close()
+				delete(d.sessions, name)
+				log.Printf("Closed idle session %s", name)
+			}
+			ns.mutex.Unlock()
+		}
+		d.mutex.Unlock()
+	}
+}
+
+// closeSessions closes every session on shutdown
+func (d *DaemonRunner) closeSessions() {
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+
+	for name, ns := range d.sessions {
+		ns.mutex.Lock()
+		ns.session.Close()
+		ns.mutex.Unlock()
+		delete(d.sessions, name)
+	}
+}
+
+// writeJSON writes v as a JSON response
+func writeJSON(w http.ResponseWriter, status int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,108 @@
+package mcp
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"github.com/nleiva/chatgbt/pkg/backend"
+)
+
+// usageTotals aggregates interactions for one model or day
+type usageTotals struct {
+	Requests         int   `json:"requests"`
+	Failed           int   `json:"failed"`
+	PromptTokens     int   `json:"prompt_tokens"`
+	CompletionTokens int   `json:"completion_tokens"`
+	TotalTokens      int   `json:"total_tokens"`
+	TotalResponseMs  int64 `json:"total_response_time_ms"`
+}
+
+// metricsReport is the body of the chatgbt://metrics resource
+type metricsReport struct {
+	Sessions int                                   `json:"sessions"`
+	Totals   usageTotals                           `json:"totals"`
+	ByModel  map[string]*usageTotals               `json:"by_model"`
+	ByDay    map[string]*usageTotals               `json:"by_day"`
+	Latency  map[string]backend.LatencyPercentiles `json:"latency_this_process,omitempty"`
+}
+
+// add folds an interaction into the totals
+func (t *usageTotals) add(m backend.InteractionMetric) {
+	t.Requests++
+	if !m.Success {
+		t.Failed++
+	}
+	t.PromptTokens += m.RequestTokens
+	t.CompletionTokens += m.ResponseTokens
+	t.TotalTokens += m.TotalTokens
+	t.TotalResponseMs += m.ResponseTime
+}
+
+// collectMetrics aggregates every interaction in the session JSONL logs under logsDir
+func collectMetrics(logsDir string) (*metricsReport, error) {
+	report := &metricsReport{
+		ByModel: make(map[string]*usageTotals),
+		ByDay:   make(map[string]*usageTotals),
+		Latency: backend.DefaultLatencyRegistry.Percentiles(),
+	}
+
+	files, err := filepath.Glob(filepath.Join(logsDir, "session_*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+	sort.Strings(files)
+
+	for _, path := range files {
+		if err := collectFile(path, report); err != nil {
+			return nil, err
+		}
+		report.Sessions++
+	}
+	return report, nil
+}
+
+// collectFile adds one session log to the report, skipping summary and malformed lines
+func collectFile(path string, report *metricsReport) error {
+	file, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer file.Close()
+
+	scanner := bufio.NewScanner(file)
+	scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+	for scanner.Scan() {
+		line := scanner.Text()
+		if !strings.HasPrefix(line, "{") {
+			continue // SESSION_SUMMARY lines repeat the interactions
+		}
+		var m backend.InteractionMetric
+		if err := json.Unmarshal([]byte(line), &m); err != nil {
+			continue
+		}
+
+		model := m.Model
+		if m.Provider != "" {
+			model = m.Provider + "/" + m.Model
+		}
+		if model == "" {
+			model = "unknown"
+		}
+		day := m.Timestamp.Format("2006-01-02")
+
+		report.Totals.add(m)
+		if report.ByModel[model] == nil {
+			report.ByModel[model] = &usageTotals{}
+		}
+		report.ByModel[model].add(m)
+		if report.ByDay[day] == nil {
+			report.ByDay[day] = &usageTotals{}
+		}
+		report.ByDay[day].add(m)
+	}
+	return scanner.Err()
+}
@@ -0,0 +1,364 @@
+package mcp
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"os/signal"
+	"strings"
+	"sync"
+	"syscall"
+
+	"github.com/nleiva/chatgbt/internal/app"
+	"github.com/nleiva/chatgbt/pkg/backend"
+	"github.com/nleiva/chatgbt/pkg/prompts"
+	"github.com/nleiva/chatgbt/pkg/store"
+)
+
+const (
+	// DefaultSessionName is used when the chat tool doesn't name a session
+	DefaultSessionName = "default"
+
+	conversationType = "mcp"
+	logsDir          = "logs"
+
+	uriConversations = "chatgbt://conversations"
+	uriPrompts       = "chatgbt://prompts"
+	uriMetrics       = "chatgbt://metrics"
+	mimeJSON         = "application/json"
+)
+
+// MCPRunner serves chatgbt's conversations, prompt library, and metrics to MCP clients over stdio
+type MCPRunner struct {
+	promptsFile string
+	opts        app.SessionOptions
+}
+
+// NewMCPRunner parses the mcp subcommand arguments
+func NewMCPRunner(args []string, promptsFile string, opts app.SessionOptions) (*MCPRunner, error) {
+	fs := flag.NewFlagSet("mcp", flag.ContinueOnError)
+	promptsPath := fs.String("prompts", promptsFile, "System prompt preset file")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	return &MCPRunner{promptsFile: *promptsPath, opts: opts}, nil
+}
+
+// Run reads JSON-RPC requests from stdin and writes responses to stdout until stdin closes
+func (r *MCPRunner) Run(cfg backend.LLMConfig, budgetCfg backend.TokenBudgetConfig) error {
+	library, err := prompts.NewLibrary(r.promptsFile)
+	if err != nil {
+		log.Printf("Warning: %v", err)
+	}
+
+	server := &Server{
+		cfg:           cfg,
+		budgetCfg:     budgetCfg,
+		opts:          r.opts,
+		library:       library,
+		conversations: store.NewFileStore(store.DefaultDir),
+		sessions:      make(map[string]*namedSession),
+	}
+	defer server.closeSessions()
+
+	// stdout carries the protocol, so diagnostics must go to stderr
+	log.SetOutput(os.Stderr)
+	log.Printf("chatgbt MCP server ready on stdio (%s/%s)", cfg.Provider, cfg.Model)
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+	return server.Serve(ctx, os.Stdin, os.Stdout)
+}
+
+// namedSession serializes access to a ChatSession, which isn't safe for concurrent use
+type namedSession struct {
+	mutex   sync.Mutex
+	session *app.ChatSession
+}
+
+// Server handles MCP requests
+type Server struct {
+	cfg           backend.LLMConfig
+	budgetCfg     backend.TokenBudgetConfig
+	opts          app.SessionOptions
+	library       *prompts.Library
+	conversations store.Store
+
+	writeMutex sync.Mutex
+	mutex      sync.Mutex
+	sessions   map[string]*namedSession
+}
+
+// Serve processes newline-delimited JSON-RPC messages. Requests run concurrently so a
+// long chat call doesn't block pings or resource reads.
+func (s *Server) Serve(ctx context.Context, in io.Reader, out io.Writer) error {
+	scanner := bufio.NewScanner(in)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	var wg sync.WaitGroup
+	defer wg.Wait()
+
+	lines := make(chan []byte)
+	go func() {
+		defer close(lines)
+		for scanner.Scan() {
+			lines <- append([]byte(nil), scanner.Bytes()...)
+		}
+	}()
+
+	for {
+		var line []byte
+		var ok bool
+		select {
+		case <-ctx.Done():
+			return nil
+		case line, ok = <-lines:
+		}
+		if !ok {
+			return scanner.Err()
+		}
+		if len(strings.TrimSpace(string(line))) == 0 {
+			continue
+		}
+
+		var req request
+		if err := json.Unmarshal(line, &req); err != nil {
+			s.write(out, response{JSONRPC: "2.0", ID: json.RawMessage("null"),
+				Error: &rpcError{Code: codeParseError, Message: "parse error: " + err.Error()}})
+			continue
+		}
+
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			result, err := s.handle(ctx, req)
+			if len(req.ID) == 0 {
+				return // Notifications get no response
+			}
+			resp := response{JSONRPC: "2.0", ID: req.ID, Result: result}
+			if err != nil {
+				var rpcErr *rpcError
+				if !errors.As(err, &rpcErr) {
+					rpcErr = &rpcError{Code: codeInternalError, Message: err.Error()}
+				}
+				resp.Result = nil
+				resp.Error = rpcErr
+			}
+			s.write(out, resp)
+		}()
+	}
+}
+
+// write sends one response line
+func (s *Server) write(out io.Writer, resp response) {
+	s.writeMutex.Lock()
+	defer s.writeMutex.Unlock()
+
+	data, err := json.Marshal(resp)
+	if err != nil {
+		log.Printf("Failed to encode MCP response: %v", err)
+		return
+	}
+	out.Write(append(data, '\n'))
+}
+
+// handle dispatches a request by method
+func (s *Server) handle(ctx context.Context, req request) (any, error) {
+	switch req.Method {
+	case "initialize":
+		return initializeResult{
+			ProtocolVersion: protocolVersion,
+			Capabilities: map[string]any{
+				"resources": map[string]any{},
+				"tools":     map[string]any{},
+			},
+			ServerInfo:   serverInfo{Name: "chatgbt", Version: "1.0.0"},
+			Instructions: "Use the chat tool to talk to the configured model with chatgbt's budget tracking; read chatgbt:// resources for past conversations, prompt presets, and usage metrics.",
+		}, nil
+	case "notifications/initialized", "notifications/cancelled":
+		return nil, nil
+	case "ping":
+		return map[string]any{}, nil
+	case "resources/list":
+		resources, err := s.listResources()
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"resources": resources}, nil
+	case "resources/templates/list":
+		return map[string]any{"resourceTemplates": []ResourceTemplate{{
+			URITemplate: uriConversations + "/{id}",
+			Name:        "Conversation",
+			Description: "A stored conversation transcript",
+			MimeType:    mimeJSON,
+		}}}, nil
+	case "resources/read":
+		var params struct {
+			URI string `json:"uri"`
+		}
+		if err := decodeParams(req.Params, &params); err != nil {
+			return nil, err
+		}
+		contents, err := s.readResource(params.URI)
+		if err != nil {
+			return nil, err
+		}
+		return map[string]any{"contents": []ResourceContents{contents}}, nil
+	case "tools/list":
+		return map[string]any{"tools": tools}, nil
+	case "tools/call":
+		var params struct {
+			Name      string          `json:"name"`
+			Arguments json.RawMessage `json:"arguments"`
+		}
+		if err := decodeParams(req.Params, &params); err != nil {
+			return nil, err
+		}
+		return s.callTool(ctx, params.Name, params.Arguments)
+	default:
+		return nil, &rpcError{Code: codeMethodNotFound, Message: "method not found: " + req.Method}
+	}
+}
+
+// listResources returns the fixed resources followed by one entry per stored conversation
+func (s *Server) listResources() ([]Resource, error) {
+	resources := []Resource{
+		{URI: uriConversations, Name: "Conversations", Description: "Index of stored conversations, newest first", MimeType: mimeJSON},
+		{URI: uriPrompts, Name: "Prompt library", Description: "Built-in and user system prompt presets", MimeType: mimeJSON},
+		{URI: uriMetrics, Name: "Usage metrics", Description: "Token usage and request counts aggregated from session logs", MimeType: mimeJSON},
+	}
+
+	conversations, err := s.conversations.List()
+	if err != nil {
+		return nil, fmt.Errorf("failed to list conversations: %w", err)
+	}
+	for _, conv := range conversations {
+		resources = append(resources, Resource{
+			URI:         uriConversations + "/" + conv.ID,
+			Name:        conversationTitle(conv),
+			Description: fmt.Sprintf("%s conversation, %d messages, updated %s", conv.ConversationType, len(conv.Messages), conv.UpdatedAt.Format("2006-01-02 15:04")),
+			MimeType:    mimeJSON,
+		})
+	}
+	return resources, nil
+}
+
+// readResource returns the contents of a chatgbt:// resource
+func (s *Server) readResource(uri string) (ResourceContents, error) {
+	var v any
+	switch {
+	case uri == uriConversations:
+		conversations, err := s.conversations.List()
+		if err != nil {
+			return ResourceContents{}, fmt.Errorf("failed to list conversations: %w", err)
+		}
+		index := make([]map[string]any, 0, len(conversations))
+		for _, conv := range conversations {
+			index = append(index, map[string]any{
+				"id":                conv.ID,
+				"title":             conversationTitle(conv),
+				"conversation_type": conv.ConversationType,
+				"model":             conv.Model,
+				"messages":          len(conv.Messages),
+				"updated_at":        conv.UpdatedAt,
+			})
+		}
+		v = index
+	case strings.HasPrefix(uri, uriConversations+"/"):
+		conv, err := s.conversations.Load(strings.TrimPrefix(uri, uriConversations+"/"))
+		if err != nil {
+			return ResourceContents{}, &rpcError{Code: codeInvalidParams, Message: "unknown resource: " + uri}
+		}
+		v = conv
+	case uri == uriPrompts:
+		v = s.library.List()
+	case uri == uriMetrics:
+		report, err := collectMetrics(logsDir)
+		if err != nil {
+			return ResourceContents{}, fmt.Errorf("failed to read metrics: %w", err)
+		}
+		v = report
+	default:
+		return ResourceContents{}, &rpcError{Code: codeInvalidParams, Message: "unknown resource: " + uri}
+	}
+
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return ResourceContents{}, err
+	}
+	return ResourceContents{URI: uri, MimeType: mimeJSON, Text: string(data)}, nil
+}
+
+// getSession returns the named session, creating it on first use
+func (s *Server) getSession(name string) (*namedSession, error) {
+	if name == "" {
+		name = DefaultSessionName
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if ns, exists := s.sessions[name]; exists {
+		return ns, nil
+	}
+
+	session, err := app.NewChatSessionWithDefaults(
+		app.GenerateSessionID("mcp_"+name),
+		conversationType,
+		"",
+		s.cfg,
+		s.budgetCfg,
+		s.opts,
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session: %w", err)
+	}
+
+	ns := &namedSession{session: session}
+	s.sessions[name] = ns
+	return ns, nil
+}
+
+// closeSessions closes every session on shutdown
+func (s *Server) closeSessions() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	for name, ns := range s.sessions {
+		ns.mutex.Lock()
+		ns.session.Close()
+		ns.mutex.Unlock()
+		delete(s.sessions, name)
+	}
+}
+
+// conversationTitle uses the first user message as a readable name
+func conversationTitle(conv *store.Conversation) string {
+	for _, msg := range conv.Messages {
+		if msg.Role == backend.RoleUser {
+			title := strings.Join(strings.Fields(msg.Content), " ")
+			if len([]rune(title)) > 60 {
+				title = string([]rune(title)[:60]) + "..."
+			}
+			return title
+		}
+	}
+	return conv.ID
+}
+
+// decodeParams unmarshals request params, reporting failures as invalid params
+func decodeParams(raw json.RawMessage, v any) error {
+	if len(raw) == 0 {
+		raw = json.RawMessage("{}")
+	}
+	if err := json.Unmarshal(raw, v); err != nil {
+		return &rpcError{Code: codeInvalidParams, Message: "invalid params: " + err.Error()}
+	}
+	return nil
+}
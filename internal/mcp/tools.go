@@ -0,0 +1,161 @@
+package mcp
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"text/tabwriter"
+
+	"github.com/nleiva/chatgbt/pkg/store"
+)
+
+// searchLimit caps the number of hits returned by search_conversations
+const searchLimit = 20
+
+// tools are the tools advertised in tools/list
+var tools = []Tool{
+	{
+		Name:        "chat",
+		Description: "Send a message to a named chatgbt session and return the reply. Sessions keep their context between calls and are tracked against the token budget.",
+		InputSchema: objectSchema(map[string]any{
+			"message": stringProperty("The user message to send"),
+			"session": stringProperty("Session name; created on first use (default: \"default\")"),
+			"preset":  stringProperty("Prompt library preset to use as the system prompt; resets the session"),
+			"reset":   map[string]any{"type": "boolean", "description": "Start a fresh conversation before sending"},
+		}, "message"),
+	},
+	{
+		Name:        "search_conversations",
+		Description: "Full-text search over stored conversations and session logs.",
+		InputSchema: objectSchema(map[string]any{
+			"query": stringProperty("Text to search for (case-insensitive)"),
+		}, "query"),
+	},
+	{
+		Name:        "session_status",
+		Description: "Report token usage, cost, and budget warnings for a named chat session.",
+		InputSchema: objectSchema(map[string]any{
+			"session": stringProperty("Session name (default: \"default\")"),
+		}),
+	},
+}
+
+// callTool runs a tool. Tool failures are returned as error results, not protocol errors.
+func (s *Server) callTool(ctx context.Context, name string, arguments json.RawMessage) (any, error) {
+	switch name {
+	case "chat":
+		var args struct {
+			Message string `json:"message"`
+			Session string `json:"session"`
+			Preset  string `json:"preset"`
+			Reset   bool   `json:"reset"`
+		}
+		if err := decodeParams(arguments, &args); err != nil {
+			return nil, err
+		}
+		return s.chat(args.Message, args.Session, args.Preset, args.Reset), nil
+	case "search_conversations":
+		var args struct {
+			Query string `json:"query"`
+		}
+		if err := decodeParams(arguments, &args); err != nil {
+			return nil, err
+		}
+		return s.search(args.Query), nil
+	case "session_status":
+		var args struct {
+			Session string `json:"session"`
+		}
+		if err := decodeParams(arguments, &args); err != nil {
+			return nil, err
+		}
+		return s.sessionStatus(args.Session), nil
+	default:
+		return nil, &rpcError{Code: codeInvalidParams, Message: "unknown tool: " + name}
+	}
+}
+
+// chat sends a message to a named session
+func (s *Server) chat(message, name, preset string, reset bool) ToolResult {
+	if strings.TrimSpace(message) == "" {
+		return errorResult(fmt.Errorf("message is required"))
+	}
+
+	ns, err := s.getSession(name)
+	if err != nil {
+		return errorResult(err)
+	}
+
+	ns.mutex.Lock()
+	defer ns.mutex.Unlock()
+
+	if preset != "" {
+		p, ok := s.library.Get(preset)
+		if !ok {
+			return errorResult(fmt.Errorf("unknown preset %q", preset))
+		}
+		ns.session.UpdateSystemPrompt(p.Prompt)
+	} else if reset {
+		ns.session.Reset("")
+	}
+
+	response, err := ns.session.ProcessUserMessage(message)
+	if err != nil {
+		return errorResult(fmt.Errorf("request failed: %w", err))
+	}
+
+	result := textResult(response.Content)
+	if response.Usage != nil {
+		footer := fmt.Sprintf("[%s | %d tokens | session cost $%.4f | %.1fs]",
+			ns.session.ModelLabel(), response.Usage.TotalTokens,
+			ns.session.GetBudgetStatus().SessionCost, response.ResponseTime.Seconds())
+		for _, warning := range response.Warnings {
+			footer += "\nBudget: " + warning
+		}
+		result.Content = append(result.Content, Content{Type: "text", Text: footer})
+	}
+	return result
+}
+
+// search runs a full-text search and formats the hits as a table
+func (s *Server) search(query string) ToolResult {
+	hits, err := store.Search(s.conversations, logsDir, query, searchLimit)
+	if err != nil {
+		return errorResult(fmt.Errorf("search failed: %w", err))
+	}
+	if len(hits) == 0 {
+		return textResult("No matches for " + query)
+	}
+
+	var b strings.Builder
+	tw := tabwriter.NewWriter(&b, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(tw, "ID\tSOURCE\tTIME\tSNIPPET")
+	for _, hit := range hits {
+		fmt.Fprintf(tw, "%s\t%s\t%s\t%s\n", hit.ID, hit.Source, hit.Timestamp.Format("2006-01-02 15:04"), hit.Snippet)
+	}
+	tw.Flush()
+	b.WriteString("\nRead a conversation with resource " + uriConversations + "/<id>.")
+	return textResult(b.String())
+}
+
+// sessionStatus reports usage for a named session
+func (s *Server) sessionStatus(name string) ToolResult {
+	ns, err := s.getSession(name)
+	if err != nil {
+		return errorResult(err)
+	}
+
+	ns.mutex.Lock()
+	defer ns.mutex.Unlock()
+
+	status := ns.session.GetBudgetStatus()
+	stats := ns.session.GetContextStats()
+	text := fmt.Sprintf("Session: %s\nModel: %s\nMessages: %d\nTokens: %d / %d\nCost: $%.4f",
+		ns.session.ID, ns.session.ModelLabel(), stats.TotalMessages, status.SessionTokens,
+		status.SessionLimit, status.SessionCost)
+	for _, warning := range status.Warnings {
+		text += "\nWarning: " + warning
+	}
+	return textResult(text)
+}
@@ -0,0 +1,326 @@
+package slackbot
+
+import (
+	"context"
+	"fmt"
+	"log"
+	"os"
+	"os/signal"
+	"regexp"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+	"unicode/utf8"
+
+	"github.com/slack-go/slack"
+	"github.com/slack-go/slack/slackevents"
+	"github.com/slack-go/slack/socketmode"
+
+	"github.com/nleiva/chatgbt/internal/app"
+	"github.com/nleiva/chatgbt/pkg/backend"
+	"github.com/nleiva/chatgbt/pkg/config"
+)
+
+const (
+	conversationType = "slack"
+	sessionMaxAge    = 24 * time.Hour
+
+	// maxSectionText is Slack's limit for the text of a single section block
+	maxSectionText = 3000
+)
+
+// mentionPattern matches user mentions such as <@U012ABC> so they can be stripped from prompts
+var mentionPattern = regexp.MustCompile(`<@[A-Z0-9]+>`)
+
+// SlackRunner runs chatgbt as a Slack bot over Socket Mode
+type SlackRunner struct {
+	slackConfig config.SlackConfig
+	opts        app.SessionOptions
+}
+
+// NewSlackRunner creates a new Slack bot runner
+func NewSlackRunner(slackConfig config.SlackConfig, opts app.SessionOptions) *SlackRunner {
+	return &SlackRunner{slackConfig: slackConfig, opts: opts}
+}
+
+// Run connects to Slack and answers mentions and direct messages until interrupted
+func (r *SlackRunner) Run(cfg backend.LLMConfig, budgetCfg backend.TokenBudgetConfig) error {
+	if err := r.slackConfig.Validate(); err != nil {
+		return err
+	}
+
+	api := slack.New(r.slackConfig.BotToken, slack.OptionAppLevelToken(r.slackConfig.AppToken))
+	auth, err := api.AuthTest()
+	if err != nil {
+		return fmt.Errorf("failed to authenticate with Slack: %w", err)
+	}
+
+	bot := &bot{
+		api:            api,
+		botUserID:      auth.UserID,
+		sessionManager: app.NewInMemorySessionManager(cfg, budgetCfg, sessionMaxAge, conversationType, r.opts),
+		workspaceLimit: r.slackConfig.WorkspaceTokenBudget,
+		threads:        make(map[string]*thread),
+		workspaces:     make(map[string]int),
+	}
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
+	defer stop()
+
+	client := socketmode.New(api)
+	go bot.handleEvents(ctx, client)
+	go bot.cleanupThreads(ctx)
+
+	log.Printf("Slack bot connected as %s in %s (%s/%s)", auth.User, auth.Team, cfg.Provider, cfg.Model)
+	err = client.RunContext(ctx)
+	bot.closeThreads()
+	if ctx.Err() != nil {
+		return nil
+	}
+	return err
+}
+
+// thread maps a Slack thread to its chat session
+type thread struct {
+	mutex     sync.Mutex
+	sessionID string
+	lastUsed  time.Time
+}
+
+// bot dispatches Slack events to chat sessions
+type bot struct {
+	api            *slack.Client
+	botUserID      string
+	sessionManager app.SessionManager
+	workspaceLimit int
+
+	mutex      sync.Mutex
+	threads    map[string]*thread // Keyed by team/channel/thread timestamp
+	workspaces map[string]int     // Tokens spent per workspace
+}
+
+// handleEvents acknowledges Socket Mode events and answers messages addressed to the bot
+func (b *bot) handleEvents(ctx context.Context, client *socketmode.Client) {
+	for {
+		var evt socketmode.Event
+		select {
+		case <-ctx.Done():
+			return
+		case evt = <-client.Events:
+		}
+
+		switch evt.Type {
+		case socketmode.EventTypeConnecting:
+			log.Println("Connecting to Slack with Socket Mode...")
+		case socketmode.EventTypeConnectionError:
+			log.Println("Slack connection failed, retrying...")
+		case socketmode.EventTypeEventsAPI:
+			eventsAPIEvent, ok := evt.Data.(slackevents.EventsAPIEvent)
+			if !ok {
+				continue
+			}
+			client.Ack(*evt.Request)
+
+			switch ev := eventsAPIEvent.InnerEvent.Data.(type) {
+			case *slackevents.AppMentionEvent:
+				go b.answer(ctx, eventsAPIEvent.TeamID, ev.User, ev.Channel, threadTS(ev.ThreadTimeStamp, ev.TimeStamp), ev.Text)
+			case *slackevents.MessageEvent:
+				// Channel messages arrive as app mentions; only direct messages are handled here
+				if ev.ChannelType != "im" || ev.BotID != "" || ev.SubType != "" || ev.User == b.botUserID {
+					continue
+				}
+				go b.answer(ctx, eventsAPIEvent.TeamID, ev.User, ev.Channel, threadTS(ev.ThreadTimeStamp, ev.TimeStamp), ev.Text)
+			}
+		}
+	}
+}
+
+// answer sends a message to the thread's session and posts the reply in the thread
+func (b *bot) answer(ctx context.Context, teamID, user, channel, ts, text string) {
+	prompt := strings.TrimSpace(mentionPattern.ReplaceAllString(text, ""))
+	if prompt == "" {
+		return
+	}
+
+	if used, over := b.workspaceOverBudget(teamID); over {
+		b.post(ctx, channel, ts, fmt.Sprintf(
+			"This workspace has used its token budget (%d / %d tokens). Ask an admin to raise SLACK_WORKSPACE_BUDGET.",
+			used, b.workspaceLimit), "")
+		return
+	}
+
+	t := b.thread(teamID, channel, ts)
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+	t.lastUsed = time.Now()
+
+	session, err := b.session(t)
+	if err != nil {
+		b.post(ctx, channel, ts, "Sorry, I couldn't start a session: "+err.Error(), "")
+		return
+	}
+
+	// Threads can be shared, so the session speaks for whoever sent this message
+	session.SetEndUser(teamID + "/" + user)
+	response, err := session.ProcessUserMessage(prompt)
+	if err != nil {
+		b.post(ctx, channel, ts, "Sorry, the request failed: "+err.Error(), "")
+		return
+	}
+
+	used := b.recordUsage(teamID, response)
+	footer := fmt.Sprintf("%s · %.1fs", session.ModelLabel(), response.ResponseTime.Seconds())
+	if response.Usage != nil {
+		footer += fmt.Sprintf(" · %d tokens · thread $%.4f · workspace %d / %d tokens",
+			response.Usage.TotalTokens, session.GetBudgetStatus().SessionCost, used, b.workspaceLimit)
+	}
+	for _, warning := range response.Warnings {
+		footer += " · ⚠️ " + warning
+	}
+	b.post(ctx, channel, ts, response.Content, footer)
+}
+
+// thread returns the state for a Slack thread, creating it on first use
+func (b *bot) thread(teamID, channel, ts string) *thread {
+	key := teamID + "/" + channel + "/" + ts
+
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	t, exists := b.threads[key]
+	if !exists {
+		t = &thread{}
+		b.threads[key] = t
+	}
+	return t
+}
+
+// session returns the thread's chat session, replacing it if it expired; callers hold t.mutex
+func (b *bot) session(t *thread) (*app.ChatSession, error) {
+	if t.sessionID != "" {
+		if session, err := b.sessionManager.GetSession(t.sessionID); err == nil {
+			return session, nil
+		}
+	}
+
+	session, err := b.sessionManager.CreateSession("slack")
+	if err != nil {
+		return nil, err
+	}
+	t.sessionID = session.ID
+	return session, nil
+}
+
+// workspaceOverBudget reports the workspace's token usage and whether it reached the limit
+func (b *bot) workspaceOverBudget(teamID string) (int, bool) {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	used := b.workspaces[teamID]
+	return used, b.workspaceLimit > 0 && used >= b.workspaceLimit
+}
+
+// recordUsage adds a response's tokens to the workspace total and returns the new total
+func (b *bot) recordUsage(teamID string, response *app.ChatResponse) int {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	if response.Usage != nil {
+		b.workspaces[teamID] += response.Usage.TotalTokens
+	}
+	return b.workspaces[teamID]
+}
+
+// post replies in a thread, with an optional usage footer rendered as a context block
+func (b *bot) post(ctx context.Context, channel, ts, text, footer string) {
+	var blocks []slack.Block
+	for _, chunk := range splitText(text, maxSectionText) {
+		blocks = append(blocks, slack.NewSectionBlock(
+			slack.NewTextBlockObject(slack.MarkdownType, chunk, false, false), nil, nil))
+	}
+	if footer != "" {
+		blocks = append(blocks, slack.NewContextBlock("",
+			slack.NewTextBlockObject(slack.MarkdownType, footer, false, false)))
+	}
+
+	_, _, err := b.api.PostMessageContext(ctx, channel,
+		slack.MsgOptionText(text, false),
+		slack.MsgOptionBlocks(blocks...),
+		slack.MsgOptionTS(ts),
+	)
+	if err != nil {
+		log.Printf("Failed to post Slack message: %v", err)
+	}
+}
+
+// cleanupThreads drops threads that outlived their sessions
+func (b *bot) cleanupThreads(ctx context.Context) {
+	ticker := time.NewTicker(30 * time.Minute)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		if cleaned := b.sessionManager.CleanupExpiredSessions(); cleaned > 0 {
+			log.Printf("Cleaned up %d expired sessions", cleaned)
+		}
+
+		b.mutex.Lock()
+		for key, t := range b.threads {
+			if !t.mutex.TryLock() {
+				continue // Answering right now
+			}
+			if time.Since(t.lastUsed) > sessionMaxAge {
+				delete(b.threads, key)
+			}
+			t.mutex.Unlock()
+		}
+		b.mutex.Unlock()
+	}
+}
+
+// closeThreads closes every thread session on shutdown
+func (b *bot) closeThreads() {
+	b.mutex.Lock()
+	defer b.mutex.Unlock()
+
+	for key, t := range b.threads {
+		if t.sessionID != "" {
+			b.sessionManager.CloseSession(t.sessionID)
+		}
+		delete(b.threads, key)
+	}
+}
+
+// threadTS returns the thread a message belongs to; top-level messages start a new thread
+func threadTS(threadTimeStamp, timeStamp string) string {
+	if threadTimeStamp != "" {
+		return threadTimeStamp
+	}
+	return timeStamp
+}
+
+// splitText breaks text into chunks of at most max bytes, preferring line boundaries
+func splitText(text string, max int) []string {
+	var chunks []string
+	for len(text) > max {
+		cut := strings.LastIndex(text[:max], "\n")
+		if cut <= 0 {
+			cut = max
+			for cut > 0 && !utf8.RuneStart(text[cut]) {
+				cut--
+			}
+		}
+		chunks = append(chunks, text[:cut])
+		text = strings.TrimLeft(text[cut:], "\n")
+	}
+	if text != "" {
+		chunks = append(chunks, text)
+	}
+	return chunks
+}
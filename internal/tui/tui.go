@@ -0,0 +1,378 @@
+package tui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/gdamore/tcell/v2"
+	"github.com/rivo/tview"
+
+	"github.com/nleiva/chatgbt/internal/app"
+	"github.com/nleiva/chatgbt/pkg/backend"
+	"github.com/nleiva/chatgbt/pkg/i18n"
+	"github.com/nleiva/chatgbt/pkg/store"
+)
+
+const (
+	cmdExit   = "exit"
+	cmdQuit   = "/quit"
+	cmdReset  = "/reset"
+	cmdSystem = "/system"
+	cmdPrune  = "/prune"
+
+	gaugeWidth = 20
+	helpText   = "[gray]Enter send · Tab switch pane · /reset · /system <prompt> · /prune · Ctrl+C quit"
+)
+
+// TUIRunner handles the full-screen terminal UI mode
+type TUIRunner struct {
+	opts app.SessionOptions
+}
+
+// NewTUIRunner creates a new TUI runner
+func NewTUIRunner(opts app.SessionOptions) *TUIRunner {
+	return &TUIRunner{opts: opts}
+}
+
+// Run starts the terminal UI
+func (t *TUIRunner) Run(cfg backend.LLMConfig, budgetCfg backend.TokenBudgetConfig) error {
+	session, err := app.NewChatSessionWithDefaults(
+		app.GenerateSessionID("tui"),
+		t.opts.CLIConversationType(),
+		"",
+		cfg,
+		budgetCfg,
+		t.opts,
+	)
+	if err != nil {
+		return fmt.Errorf("failed to create session: %w", err)
+	}
+	defer session.Close()
+
+	return newView(session).run()
+}
+
+// view holds the TUI widgets and the session they drive
+type view struct {
+	session *app.ChatSession
+	store   store.Store
+
+	app     *tview.Application
+	chat    *tview.TextView
+	stats   *tview.TextView
+	history *tview.List
+	input   *tview.InputField
+
+	busy     bool
+	viewing  bool // A stored conversation is shown instead of the current one
+	previews []*store.Conversation
+}
+
+// newView lays out the panes: history on the left, chat in the middle, stats on the right
+func newView(session *app.ChatSession) *view {
+	v := &view{
+		session: session,
+		store:   session.Store,
+		app:     tview.NewApplication(),
+		chat:    tview.NewTextView(),
+		stats:   tview.NewTextView(),
+		history: tview.NewList(),
+		input:   tview.NewInputField(),
+	}
+
+	v.chat.SetDynamicColors(true).SetWrap(true).SetWordWrap(true)
+	v.chat.SetBorder(true).SetTitle(" " + session.ModelLabel() + " ")
+
+	v.stats.SetDynamicColors(true)
+	v.stats.SetBorder(true).SetTitle(" Stats ")
+
+	v.history.ShowSecondaryText(true).SetHighlightFullLine(true)
+	v.history.SetBorder(true).SetTitle(" History ")
+	v.history.SetSelectedFunc(func(index int, _, _ string, _ rune) {
+		v.showHistory(index)
+	})
+
+	v.input.SetLabel("> ").SetFieldBackgroundColor(tcell.ColorDefault)
+	v.input.SetBorder(true).SetTitle(" Message ")
+	v.input.SetDoneFunc(func(key tcell.Key) {
+		if key == tcell.KeyEnter {
+			v.submit(strings.TrimSpace(v.input.GetText()))
+		}
+	})
+
+	help := tview.NewTextView().SetDynamicColors(true).SetText(helpText)
+
+	center := tview.NewFlex().SetDirection(tview.FlexRow).
+		AddItem(v.chat, 0, 1, false).
+		AddItem(v.input, 3, 0, true).
+		AddItem(help, 1, 0, false)
+
+	layout := tview.NewFlex().
+		AddItem(v.history, 30, 0, false).
+		AddItem(center, 0, 1, true).
+		AddItem(v.stats, 34, 0, false)
+
+	panes := []tview.Primitive{v.input, v.chat, v.history}
+	v.app.SetInputCapture(func(event *tcell.EventKey) *tcell.EventKey {
+		if event.Key() != tcell.KeyTab {
+			return event
+		}
+		for i, pane := range panes {
+			if pane.HasFocus() {
+				v.app.SetFocus(panes[(i+1)%len(panes)])
+				return nil
+			}
+		}
+		v.app.SetFocus(v.input)
+		return nil
+	})
+
+	v.app.SetRoot(layout, true).SetFocus(v.input)
+	return v
+}
+
+// run refreshes the panes and blocks until the user quits
+func (v *view) run() error {
+	v.renderChat()
+	v.refreshStats()
+	v.refreshHistory()
+	return v.app.Run()
+}
+
+// submit handles a line typed into the input field
+func (v *view) submit(text string) {
+	if text == "" || v.busy {
+		return
+	}
+	v.input.SetText("")
+
+	switch {
+	case text == cmdExit || text == cmdQuit:
+		v.app.Stop()
+	case text == cmdReset:
+		v.session.Reset("")
+		v.renderChat()
+		v.refreshStats()
+	case strings.HasPrefix(text, cmdSystem):
+		if prompt := strings.TrimSpace(strings.TrimPrefix(text, cmdSystem)); prompt != "" {
+			v.session.UpdateSystemPrompt(prompt)
+			v.renderChat()
+			v.refreshStats()
+		}
+	case text == cmdPrune:
+		before := v.session.GetContextStats()
+		if v.session.AutoPrune() {
+			after := v.session.GetContextStats()
+			v.notice(fmt.Sprintf("Context pruned: %d -> %d messages", before.TotalMessages, after.TotalMessages))
+		} else {
+			v.notice("No pruning needed - context within limits")
+		}
+		v.refreshStats()
+	default:
+		v.send(text)
+	}
+}
+
+// send streams the assistant's reply into the chat pane without blocking the UI
+func (v *view) send(text string) {
+	if v.viewing {
+		v.renderChat()
+	}
+	v.busy = true
+	v.input.SetLabel("… ")
+	fmt.Fprintf(v.chat, "[green::b]You[-::-]\n%s\n\n[blue::b]Assistant[-::-]\n", tview.Escape(text))
+	v.chat.ScrollToEnd()
+
+	go func() {
+		response, err := v.session.ProcessUserMessageStream(text, func(delta string) {
+			v.app.QueueUpdateDraw(func() {
+				fmt.Fprint(v.chat, tview.Escape(delta))
+				v.chat.ScrollToEnd()
+			})
+		})
+
+		v.app.QueueUpdateDraw(func() {
+			if err != nil {
+				fmt.Fprintf(v.chat, "[red]Error: %s[-]\n\n", tview.Escape(err.Error()))
+			} else {
+				fmt.Fprintf(v.chat, "\n[gray]%s[-]\n\n", usageLine(response))
+				if response.Truncated() {
+					fmt.Fprintf(v.chat, "[yellow]%s[-]\n\n", tview.Escape(i18n.T(v.session.Language, "reply.truncated")))
+				} else if response.Aborted() {
+					fmt.Fprintf(v.chat, "[yellow]%s[-]\n\n", tview.Escape(i18n.T(v.session.Language, "reply.aborted")))
+				}
+				for _, warning := range response.Warnings {
+					fmt.Fprintf(v.chat, "[yellow]Budget: %s[-]\n\n", tview.Escape(warning))
+				}
+			}
+			v.chat.ScrollToEnd()
+			v.busy = false
+			v.input.SetLabel("> ")
+			v.refreshStats()
+			v.refreshHistory()
+		})
+	}()
+}
+
+// renderChat redraws the chat pane from the session context
+func (v *view) renderChat() {
+	v.viewing = false
+	v.chat.Clear()
+	v.chat.SetTitle(" " + v.session.ModelLabel() + " ")
+	for _, msg := range v.session.Messages {
+		switch {
+		case msg.Role == backend.RoleSystem:
+			fmt.Fprintf(v.chat, "[gray]System: %s[-]\n\n", tview.Escape(msg.Content))
+		case msg.Pinned:
+			// Few-shot examples steer the model but aren't part of the conversation
+		case msg.Role == backend.RoleUser:
+			fmt.Fprintf(v.chat, "[green::b]You[-::-]\n%s\n\n", tview.Escape(msg.Content))
+		case msg.Role == backend.RoleTool:
+			// Tool results are shown through the call that requested them
+		case len(msg.ToolCalls) > 0:
+			for _, call := range msg.ToolCalls {
+				fmt.Fprintf(v.chat, "[gray]Tool: %s(%s)[-]\n\n", tview.Escape(call.Function.Name), tview.Escape(call.Function.Arguments))
+			}
+		default:
+			fmt.Fprintf(v.chat, "[blue::b]Assistant[-::-]\n%s\n\n", tview.Escape(msg.Content))
+		}
+	}
+	v.chat.ScrollToEnd()
+}
+
+// showHistory displays a stored conversation read-only in the chat pane
+func (v *view) showHistory(index int) {
+	// The session context is being updated while a reply streams in
+	if v.busy || index < 0 || index >= len(v.previews) {
+		return
+	}
+	conv := v.previews[index]
+	if conv.ID == v.session.ConversationID() {
+		v.renderChat()
+		v.app.SetFocus(v.input)
+		return
+	}
+
+	v.viewing = true
+	v.chat.Clear()
+	v.chat.SetTitle(fmt.Sprintf(" %s (read-only) ", conv.ID))
+	for _, msg := range conv.Messages {
+		name := "[blue::b]Assistant[-::-]"
+		if msg.Role == backend.RoleUser {
+			name = "[green::b]You[-::-]"
+		}
+		fmt.Fprintf(v.chat, "%s [gray]%s[-]\n%s\n\n", name, msg.Timestamp.Local().Format("Jan 2 15:04"), tview.Escape(msg.Content))
+	}
+	v.chat.ScrollToBeginning()
+}
+
+// notice prints an informational line in the chat pane
+func (v *view) notice(text string) {
+	fmt.Fprintf(v.chat, "[yellow]%s[-]\n\n", tview.Escape(text))
+	v.chat.ScrollToEnd()
+}
+
+// refreshStats redraws the budget and context gauges
+func (v *view) refreshStats() {
+	budget := v.session.GetBudgetStatus()
+	context := v.session.GetContextStats()
+	summary := v.session.GetSessionSummary()
+
+	var b strings.Builder
+	budgetPct := 0.0
+	if budget.SessionLimit > 0 {
+		budgetPct = float64(budget.SessionTokens) / float64(budget.SessionLimit) * 100
+	}
+	fmt.Fprintf(&b, "[::b]Budget[::-]\n%s\n", gauge(budgetPct))
+	fmt.Fprintf(&b, "%d / %d tokens\n$%.4f\n\n", budget.SessionTokens, budget.SessionLimit, budget.SessionCost)
+
+	fmt.Fprintf(&b, "[::b]Context[::-]\n%s\n", gauge(context.UtilizationPct))
+	fmt.Fprintf(&b, "~%d / %d tokens\n%d messages\n\n", context.EstimatedTokens, context.TokenLimit, context.TotalMessages)
+
+	fmt.Fprintf(&b, "[::b]Session[::-]\n")
+	fmt.Fprintf(&b, "Requests: %d\n", summary.TotalRequests)
+	if summary.TotalRequests > 0 {
+		fmt.Fprintf(&b, "Success: %.0f%%\n", summary.SuccessRate*100)
+		fmt.Fprintf(&b, "Avg time: %dms\n", summary.AvgResponseTime)
+	}
+	if summary.AvgTTFT > 0 {
+		fmt.Fprintf(&b, "Avg TTFT: %dms\n", summary.AvgTTFT)
+	}
+	fmt.Fprintf(&b, "Duration: %v\n", summary.Duration.Round(time.Second))
+
+	for _, warning := range budget.Warnings {
+		fmt.Fprintf(&b, "\n[yellow]%s[-]\n", tview.Escape(warning))
+	}
+	if context.ShouldPrune {
+		fmt.Fprintf(&b, "\n[yellow]Context near limit, try /prune[-]\n")
+	}
+
+	v.stats.SetText(b.String())
+}
+
+// refreshHistory reloads the list of stored conversations
+func (v *view) refreshHistory() {
+	conversations, err := v.store.List()
+	if err != nil {
+		return
+	}
+
+	v.history.Clear()
+	v.previews = v.previews[:0]
+	for _, conv := range conversations {
+		title := "(empty)"
+		for _, msg := range conv.Messages {
+			if msg.Role == backend.RoleUser {
+				title = firstLine(msg.Content)
+				break
+			}
+		}
+		if conv.ID == v.session.ConversationID() {
+			title = "● " + title
+		}
+		v.history.AddItem(title, conv.UpdatedAt.Local().Format("Jan 2 15:04"), 0, nil)
+		v.previews = append(v.previews, conv)
+	}
+}
+
+// gauge renders a colored progress bar for a percentage
+func gauge(pct float64) string {
+	filled := int(pct / 100 * gaugeWidth)
+	filled = max(0, min(filled, gaugeWidth))
+
+	color := "green"
+	switch {
+	case pct >= 100:
+		color = "red"
+	case pct >= 80:
+		color = "yellow"
+	}
+	return fmt.Sprintf("[%s]%s[gray]%s[-] %.0f%%",
+		color, strings.Repeat("█", filled), strings.Repeat("░", gaugeWidth-filled), pct)
+}
+
+// usageLine formats the token usage shown after each reply
+func usageLine(response *app.ChatResponse) string {
+	line := response.ModelLabel()
+	if response.FinishReason != "" {
+		line += " · " + response.FinishReason
+	}
+	if response.Usage == nil {
+		return fmt.Sprintf("%s · %dms", line, response.ResponseTime.Milliseconds())
+	}
+	line += fmt.Sprintf(" · %d tokens · %dms", response.Usage.TotalTokens, response.ResponseTime.Milliseconds())
+	if response.TTFT > 0 {
+		line += fmt.Sprintf(" · TTFT %dms · %.1f tok/s", response.TTFT.Milliseconds(), response.TokensPerSecond)
+	}
+	return line
+}
+
+// firstLine returns the first line of s, truncated for the history pane
+func firstLine(s string) string {
+	line, _, _ := strings.Cut(strings.TrimSpace(s), "\n")
+	if runes := []rune(line); len(runes) > 26 {
+		return string(runes[:25]) + "…"
+	}
+	return line
+}
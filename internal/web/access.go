@@ -0,0 +1,254 @@
+package web
+
+import (
+	"cmp"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"math/rand/v2"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/nleiva/chatgbt/pkg/config"
+)
+
+// requestBuckets are the upper bounds in seconds of the request duration histogram.
+// Pages answer in milliseconds; chat requests take as long as the model.
+var requestBuckets = []float64{0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10, 30, 60}
+
+// unmatchedRoute labels requests no route matched, so scans of random paths don't add series
+const unmatchedRoute = "unmatched"
+
+// routeKey identifies a route in the request metrics
+type routeKey struct {
+	method string
+	route  string
+}
+
+// routeStats counts the requests of one route by status and their durations
+type routeStats struct {
+	codes   map[int]int
+	errors  int   // Requests answered with a 5xx status
+	buckets []int // buckets[i] counts durations <= requestBuckets[i]; the last is +Inf
+	sum     float64
+}
+
+// routeMetrics aggregates request counts, errors, and latency per route for /metrics
+type routeMetrics struct {
+	mutex  sync.Mutex // Guards the field below
+	routes map[routeKey]*routeStats
+}
+
+func newRouteMetrics() *routeMetrics {
+	return &routeMetrics{routes: make(map[routeKey]*routeStats)}
+}
+
+// observe records one request to route answered with status after elapsed
+func (m *routeMetrics) observe(method, route string, status int, elapsed time.Duration) {
+	key := routeKey{method: method, route: route}
+
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	stats, ok := m.routes[key]
+	if !ok {
+		stats = &routeStats{codes: make(map[int]int), buckets: make([]int, len(requestBuckets)+1)}
+		m.routes[key] = stats
+	}
+	stats.codes[status]++
+	if status >= 500 {
+		stats.errors++
+	}
+	seconds := elapsed.Seconds()
+	stats.buckets[sort.SearchFloat64s(requestBuckets, seconds)]++
+	stats.sum += seconds
+}
+
+// WritePrometheus writes the request counters and duration histograms in the Prometheus
+// text exposition format
+func (m *routeMetrics) WritePrometheus(w io.Writer) error {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	keys := make([]routeKey, 0, len(m.routes))
+	for key := range m.routes {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool {
+		if keys[i].route != keys[j].route {
+			return keys[i].route < keys[j].route
+		}
+		return keys[i].method < keys[j].method
+	})
+
+	var b strings.Builder
+	b.WriteString("# HELP chatgbt_http_requests_total HTTP requests by route and status code.\n")
+	b.WriteString("# TYPE chatgbt_http_requests_total counter\n")
+	for _, key := range keys {
+		stats := m.routes[key]
+		codes := make([]int, 0, len(stats.codes))
+		for code := range stats.codes {
+			codes = append(codes, code)
+		}
+		sort.Ints(codes)
+		for _, code := range codes {
+			fmt.Fprintf(&b, "chatgbt_http_requests_total{method=%q,route=%q,code=\"%d\"} %d\n",
+				key.method, key.route, code, stats.codes[code])
+		}
+	}
+
+	b.WriteString("# HELP chatgbt_http_request_errors_total HTTP requests answered with a server error.\n")
+	b.WriteString("# TYPE chatgbt_http_request_errors_total counter\n")
+	for _, key := range keys {
+		fmt.Fprintf(&b, "chatgbt_http_request_errors_total{method=%q,route=%q} %d\n",
+			key.method, key.route, m.routes[key].errors)
+	}
+
+	b.WriteString("# HELP chatgbt_http_request_duration_seconds HTTP request duration.\n")
+	b.WriteString("# TYPE chatgbt_http_request_duration_seconds histogram\n")
+	for _, key := range keys {
+		stats := m.routes[key]
+		labels := fmt.Sprintf("method=%q,route=%q", key.method, key.route)
+
+		cumulative := 0
+		for i, bound := range requestBuckets {
+			cumulative += stats.buckets[i]
+			fmt.Fprintf(&b, "chatgbt_http_request_duration_seconds_bucket{%s,le=\"%g\"} %d\n", labels, bound, cumulative)
+		}
+		cumulative += stats.buckets[len(requestBuckets)]
+		fmt.Fprintf(&b, "chatgbt_http_request_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, cumulative)
+		fmt.Fprintf(&b, "chatgbt_http_request_duration_seconds_sum{%s} %g\n", labels, stats.sum)
+		fmt.Fprintf(&b, "chatgbt_http_request_duration_seconds_count{%s} %d\n", labels, cumulative)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// markUnmatched is registered after every route, so it only sees requests no route
+// answered. Fiber then replies 404 as usual.
+func markUnmatched(c *fiber.Ctx) error {
+	c.Locals(unmatchedRoute, true)
+	return c.Next()
+}
+
+// accessLogger writes one line per request in the configured format and records the
+// request in the route metrics. It replaces Fiber's logger middleware.
+type accessLogger struct {
+	cfg     config.AccessLogConfig
+	metrics *routeMetrics
+	mutex   sync.Mutex // Keeps lines from concurrent requests whole
+	out     io.Writer
+}
+
+func newAccessLogger(cfg config.AccessLogConfig, metrics *routeMetrics) *accessLogger {
+	return &accessLogger{cfg: cfg, metrics: metrics, out: os.Stdout}
+}
+
+// handle is the middleware. Errors returned by later handlers are rendered here, as
+// the logger middleware does, so the logged status is the one the client gets.
+func (l *accessLogger) handle(c *fiber.Ctx) error {
+	start := time.Now()
+	chainErr := c.Next()
+	if chainErr != nil {
+		if err := c.App().Config().ErrorHandler(c, chainErr); err != nil {
+			_ = c.SendStatus(fiber.StatusInternalServerError)
+		}
+	}
+	elapsed := time.Since(start)
+	status := c.Response().StatusCode()
+
+	route := c.Route().Path
+	if c.Locals(unmatchedRoute) != nil {
+		route = unmatchedRoute
+	}
+	l.metrics.observe(c.Method(), route, status, elapsed)
+
+	if l.cfg.Format == config.AccessLogOff {
+		return nil
+	}
+	if status < 500 && chainErr == nil && l.cfg.SampleRate < 1 && rand.Float64() >= l.cfg.SampleRate {
+		return nil
+	}
+
+	values := make(map[string]any, len(l.cfg.Fields))
+	for _, field := range l.cfg.Fields {
+		values[field] = accessLogValue(c, field, route, start, elapsed, chainErr)
+	}
+	l.write(values)
+	return nil
+}
+
+// accessLogValue returns one field of the access log line of c
+func accessLogValue(c *fiber.Ctx, field, route string, start time.Time, elapsed time.Duration, err error) any {
+	switch field {
+	case "time":
+		return start.Format(time.RFC3339)
+	case "request_id":
+		return requestID(c)
+	case "status":
+		return c.Response().StatusCode()
+	case "latency":
+		return elapsed
+	case "ip":
+		return c.IP()
+	case "method":
+		return c.Method()
+	case "path":
+		return c.Path()
+	case "route":
+		return route
+	case "bytes":
+		return len(c.Response().Body())
+	case "user_agent":
+		return c.Get(fiber.HeaderUserAgent)
+	case "error":
+		if err != nil {
+			return err.Error()
+		}
+		return ""
+	}
+	return nil
+}
+
+// write logs the fields of one request: as a JSON object, with the latency in
+// milliseconds, or as text separated like Fiber's default log line
+func (l *accessLogger) write(values map[string]any) {
+	var line []byte
+	if l.cfg.Format == config.AccessLogJSON {
+		if latency, ok := values["latency"].(time.Duration); ok {
+			delete(values, "latency")
+			values["latency_ms"] = float64(latency.Microseconds()) / 1000
+		}
+		data, err := json.Marshal(values)
+		if err != nil {
+			log.Printf("Warning: failed to encode access log entry: %v", err)
+			return
+		}
+		line = append(data, '\n')
+	} else {
+		parts := make([]string, 0, len(l.cfg.Fields))
+		for _, field := range l.cfg.Fields {
+			switch value := values[field].(type) {
+			case string:
+				parts = append(parts, cmp.Or(value, "-"))
+			case int:
+				parts = append(parts, strconv.Itoa(value))
+			default:
+				parts = append(parts, fmt.Sprint(value))
+			}
+		}
+		line = []byte(strings.Join(parts, " | ") + "\n")
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+	l.out.Write(line)
+}
@@ -0,0 +1,327 @@
+package web
+
+import (
+	"crypto/subtle"
+	"errors"
+	"fmt"
+	"log"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/gofiber/fiber/v2/middleware/cors"
+
+	"github.com/nleiva/chatgbt/internal/app"
+	"github.com/nleiva/chatgbt/pkg/apikeys"
+	"github.com/nleiva/chatgbt/pkg/backend"
+	"github.com/nleiva/chatgbt/pkg/llm"
+)
+
+// apiUsageKey holds the model tokens an API request used, for requireAPIToken
+const apiUsageKey = "api_usage"
+
+// apiUsageFlushInterval is how often the usage of API tokens is written to their file
+const apiUsageFlushInterval = 30 * time.Second
+
+// tenantKey holds the tenant of the API token a request was made with
+const tenantKey = "tenant"
+
+// apiChatRequest is the body of POST /api/v1/chat. Model, Temperature, and MaxTokens
+// override the session's defaults for this message only.
+type apiChatRequest struct {
+	Message     string   `json:"message" form:"message"`
+	Model       string   `json:"model" form:"model"`
+	Temperature *float64 `json:"temperature" form:"temperature"`
+	MaxTokens   *int     `json:"max_tokens" form:"max_tokens"`
+}
+
+// overrides checks the per-request settings of req against API_MODELS and
+// API_MAX_TOKENS, returning the HTTP status to refuse it with
+func (s *Server) overrides(req apiChatRequest) (app.Overrides, int, error) {
+	if req.Model != "" && !slices.Contains(s.webConfig.APIModels, "*") && !slices.Contains(s.webConfig.APIModels, req.Model) {
+		if len(s.webConfig.APIModels) == 0 {
+			return app.Overrides{}, 403, errors.New("model overrides are disabled; set API_MODELS to allow them")
+		}
+		return app.Overrides{}, 403, fmt.Errorf("model %s is not allowed; pick one of %s", req.Model, strings.Join(s.webConfig.APIModels, ", "))
+	}
+	if req.Temperature != nil && (*req.Temperature < 0 || *req.Temperature > 2) {
+		return app.Overrides{}, 400, errors.New("temperature must be between 0 and 2")
+	}
+	if req.MaxTokens != nil {
+		if *req.MaxTokens <= 0 {
+			return app.Overrides{}, 400, errors.New("max_tokens must be positive")
+		}
+		if limit := s.webConfig.APIMaxTokens; limit > 0 && *req.MaxTokens > limit {
+			return app.Overrides{}, 400, fmt.Errorf("max_tokens %d is over the limit of %d", *req.MaxTokens, limit)
+		}
+	}
+	return app.Overrides{Model: req.Model, Temperature: req.Temperature, MaxTokens: req.MaxTokens}, 0, nil
+}
+
+// setupAPIRoutes registers the versioned JSON API. Browsers on other origins may call
+// it when those origins are listed in CORS_ALLOWED_ORIGINS.
+func (s *Server) setupAPIRoutes() {
+	api := s.app.Group("/api/v1")
+	if len(s.webConfig.CORSOrigins) > 0 {
+		api.Use(newCORS(s.webConfig.CORSOrigins))
+	}
+
+	api.Post("/chat", s.requireAPIToken, s.handleAPIChat)
+	api.Post("/chat/abort", s.requireAPIToken, s.handleAbort)
+	api.Get("/status", s.requireAPIToken, s.handleStatus)
+	api.Get("/presets", s.requireAPIToken, s.handleListPresets)
+
+	// Operator endpoints
+	admin := api.Group("/sessions", s.requireAdmin)
+	admin.Get("/", s.handleListSessions)
+	admin.Delete("/:id", s.handleCloseSession)
+	api.Get("/tenants", s.requireAdmin, s.handleListTenants)
+	api.Post("/reload", s.requireAdmin, s.handleReload)
+
+	debug := api.Group("/debug", s.requireAdmin)
+	debug.Get("/http", s.handleHTTPDebug)
+	debug.Post("/http", s.handleSetHTTPDebug)
+}
+
+// requireAdmin lets requests through only with "Authorization: Bearer <ADMIN_TOKEN>".
+// Without a configured token the admin endpoints are disabled.
+func (s *Server) requireAdmin(c *fiber.Ctx) error {
+	if s.webConfig.AdminToken == "" {
+		return c.Status(403).JSON(fiber.Map{"error": "admin API disabled; set ADMIN_TOKEN to enable it"})
+	}
+
+	token, found := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+	if !found || subtle.ConstantTimeCompare([]byte(token), []byte(s.webConfig.AdminToken)) != 1 {
+		return c.Status(401).JSON(fiber.Map{"error": "invalid admin token"})
+	}
+	return c.Next()
+}
+
+// requireAPIToken lets requests through only with "Authorization: Bearer <token>" for
+// a token issued by "chatgbt tokens create", enforcing its rate limit and budget, then
+// adds the model tokens the request used to the token's total. Until the first token
+// is created the API stays open; once every token is revoked it refuses all requests.
+func (s *Server) requireAPIToken(c *fiber.Ctx) error {
+	if !s.apiTokens.Enabled() {
+		return c.Next()
+	}
+
+	value, found := strings.CutPrefix(c.Get(fiber.HeaderAuthorization), "Bearer ")
+	if !found {
+		c.Set(fiber.HeaderWWWAuthenticate, "Bearer")
+		return c.Status(401).JSON(fiber.Map{"error": "API token required"})
+	}
+	token, err := s.apiTokens.Authenticate(value)
+	var limited *apikeys.RateLimitedError
+	switch {
+	case errors.As(err, &limited):
+		c.Set(fiber.HeaderRetryAfter, strconv.Itoa(int(limited.RetryAfter.Seconds())+1))
+		return c.Status(429).JSON(fiber.Map{"error": err.Error()})
+	case errors.Is(err, apikeys.ErrInvalidToken):
+		c.Set(fiber.HeaderWWWAuthenticate, "Bearer")
+		return c.Status(401).JSON(fiber.Map{"error": err.Error()})
+	case err != nil:
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+	if token.OverBudget() {
+		return c.Status(429).JSON(fiber.Map{
+			"error": fmt.Sprintf("API token %s has spent its budget of %d tokens", token.Name, token.TokenBudget),
+		})
+	}
+
+	c.Locals(tenantKey, token.Tenant)
+	err = c.Next()
+	used, _ := c.Locals(apiUsageKey).(int)
+	s.apiTokens.RecordUsage(token.ID, used)
+	return err
+}
+
+// startAPIUsageFlush writes the usage of API tokens to their file every
+// apiUsageFlushInterval, rather than on every request
+func (s *Server) startAPIUsageFlush() {
+	ticker := time.NewTicker(apiUsageFlushInterval)
+	defer ticker.Stop()
+
+	for range ticker.C {
+		s.flushAPIUsage()
+	}
+}
+
+// flushAPIUsage writes the usage of API tokens recorded since the last flush
+func (s *Server) flushAPIUsage() {
+	if err := s.apiTokens.Flush(); err != nil {
+		log.Printf("Warning: failed to record API token usage: %v", err)
+	}
+}
+
+// handleListSessions lists every active session with its usage, most recently used first
+func (s *Server) handleListSessions(c *fiber.Ctx) error {
+	infos := s.sessionManager.ListSessions()
+	sessions := make([]fiber.Map, 0, len(infos))
+	for _, info := range infos {
+		sessions = append(sessions, fiber.Map{
+			"id":           info.ID,
+			"user":         info.UserID,
+			"tenant":       info.Tenant,
+			"age_seconds":  int(info.Age.Seconds()),
+			"idle_seconds": int(info.Idle.Seconds()),
+			"requests":     info.Requests,
+			"tokens":       info.Tokens,
+			"cost":         info.Cost,
+			"over_budget":  info.OverBudget,
+		})
+	}
+	spent, limit := s.sessionManager.DailyCost()
+	return c.JSON(fiber.Map{
+		"sessions":         sessions,
+		"daily_cost":       spent,
+		"daily_cost_limit": limit,
+	})
+}
+
+// handleListTenants reports what each tenant's sessions have used and spent today
+func (s *Server) handleListTenants(c *fiber.Ctx) error {
+	usage := s.sessionManager.TenantUsage()
+	tenants := make([]fiber.Map, 0, len(usage))
+	for _, u := range usage {
+		tenants = append(tenants, fiber.Map{
+			"name":             u.Name,
+			"sessions":         u.Sessions,
+			"requests":         u.Requests,
+			"tokens":           u.Tokens,
+			"cost":             u.Cost,
+			"daily_cost":       u.DailyCost,
+			"daily_cost_limit": u.DailyCostLimit,
+		})
+	}
+	return c.JSON(fiber.Map{"tenants": tenants})
+}
+
+// handleCloseSession force-closes a session; its user starts a fresh one on their next request
+func (s *Server) handleCloseSession(c *fiber.Ctx) error {
+	id := c.Params("id")
+	if _, err := s.sessionManager.GetSession(id); err != nil {
+		return c.Status(404).JSON(fiber.Map{"error": "session not found"})
+	}
+	if err := s.sessionManager.CloseSession(id); err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	log.Printf("Closed session %s on request %s", id, requestID(c))
+	return c.SendStatus(204)
+}
+
+// handleHTTPDebug reports whether provider traffic is being recorded, and where
+func (s *Server) handleHTTPDebug(c *fiber.Ctx) error {
+	enabled, dir := backend.HTTPDebug()
+	return c.JSON(fiber.Map{"enabled": enabled, "dir": dir})
+}
+
+// handleSetHTTPDebug turns recording of provider traffic on or off without a restart,
+// from a body like {"enabled": true}
+func (s *Server) handleSetHTTPDebug(c *fiber.Ctx) error {
+	var req struct {
+		Enabled *bool `json:"enabled"`
+	}
+	if err := c.BodyParser(&req); err != nil || req.Enabled == nil {
+		return c.Status(400).JSON(fiber.Map{"error": `body must be {"enabled": true} or {"enabled": false}`})
+	}
+
+	backend.SetHTTPDebug(*req.Enabled, "")
+	state := "off"
+	if *req.Enabled {
+		state = "on"
+	}
+	log.Printf("HTTP debugging turned %s on request %s", state, requestID(c))
+	return s.handleHTTPDebug(c)
+}
+
+// newCORS allows the given origins to call the API. Listed origins may send the session
+// cookie or an Authorization token; a "*" entry allows any origin, but then without credentials.
+func newCORS(origins []string) fiber.Handler {
+	cfg := cors.Config{
+		AllowMethods:  "GET,POST,DELETE,OPTIONS",
+		AllowHeaders:  "Authorization,Content-Type," + backend.RequestIDHeader,
+		ExposeHeaders: backend.RequestIDHeader,
+		MaxAge:        600,
+	}
+
+	allowed := make(map[string]bool, len(origins))
+	for _, origin := range origins {
+		if origin == "*" {
+			cfg.AllowOrigins = "*"
+			return cors.New(cfg)
+		}
+		allowed[origin] = true
+	}
+
+	// Matched here rather than with AllowOrigins, which only accepts http(s) origins
+	// and so would reject browser extensions
+	cfg.AllowOriginsFunc = func(origin string) bool {
+		return allowed[strings.ToLower(origin)]
+	}
+	cfg.AllowCredentials = true
+	return cors.New(cfg)
+}
+
+// handleAPIChat sends a message to the caller's session and returns the reply as JSON
+func (s *Server) handleAPIChat(c *fiber.Ctx) error {
+	session, err := s.getOrCreateSession(c)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to get session: " + err.Error()})
+	}
+
+	var req apiChatRequest
+	if err := c.BodyParser(&req); err != nil {
+		return c.Status(400).JSON(fiber.Map{"error": "invalid request body: " + err.Error()})
+	}
+	if req.Message == "" {
+		return c.Status(400).JSON(fiber.Map{"error": "message is required"})
+	}
+	overrides, status, err := s.overrides(req)
+	if err != nil {
+		return c.Status(status).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	ctx := app.WithOverrides(requestContext(c), overrides)
+	response, err := session.ProcessUserMessageContext(ctx, req.Message, nil)
+	var tooLong *app.MessageTooLongError
+	if errors.As(err, &tooLong) {
+		return c.Status(413).JSON(fiber.Map{"error": err.Error()})
+	}
+	var overCost *app.DailyCostExceededError
+	var overBudget *app.BudgetExceededError
+	if errors.As(err, &overCost) || errors.As(err, &overBudget) {
+		return c.Status(429).JSON(fiber.Map{"error": err.Error()})
+	}
+	var inProgress *app.GenerationInProgressError
+	if errors.As(err, &inProgress) {
+		return c.Status(409).JSON(fiber.Map{"error": err.Error()})
+	}
+	var queueTimeout *llm.QueueTimeoutError
+	if errors.As(err, &queueTimeout) {
+		return c.Status(503).JSON(fiber.Map{"error": err.Error()})
+	}
+	if err != nil {
+		return c.Status(502).JSON(fiber.Map{
+			"error":      err.Error(),
+			"request_id": requestID(c),
+		})
+	}
+
+	if response.Usage != nil {
+		c.Locals(apiUsageKey, response.Usage.TotalTokens)
+	}
+	return c.JSON(fiber.Map{
+		"content":          response.Content,
+		"provider":         response.Provider,
+		"model":            response.Model,
+		"finish_reason":    response.FinishReason,
+		"usage":            response.Usage,
+		"response_time_ms": response.ResponseTime.Milliseconds(),
+		"warnings":         response.Warnings,
+		"request_id":       requestID(c),
+	})
+}
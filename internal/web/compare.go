@@ -0,0 +1,172 @@
+package web
+
+import (
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/nleiva/chatgbt/internal/app"
+	"github.com/nleiva/chatgbt/internal/web/templates"
+)
+
+// defaultCompareSpecs returns the model specs pre-filled in the compare view
+func (s *Server) defaultCompareSpecs() (string, string) {
+	specA := fmt.Sprintf("%s:%s", s.llmConfig.Provider, s.llmConfig.Model)
+	specB := s.webConfig.CompareModel
+	if specB == "" {
+		specB = specA
+	}
+	return specA, specB
+}
+
+// getOrCreateComparison returns the browser's comparison for the given model specs,
+// replacing it when the specs changed or its sessions expired
+func (s *Server) getOrCreateComparison(c *fiber.Ctx, specA, specB string) (*app.Comparison, error) {
+	compareID := c.Cookies(compareCookieName)
+
+	s.compareMutex.Lock()
+	entry, exists := s.comparisons[compareID]
+	s.compareMutex.Unlock()
+
+	if exists {
+		if entry.specA == specA && entry.specB == specB && s.comparisonAlive(entry.comparison) {
+			return entry.comparison, nil
+		}
+		s.closeComparison(compareID, entry)
+	}
+
+	sessionA, err := s.sessionManager.CreateSessionForModel("compare_a", app.ParseModelSpec(s.llmConfig, specA))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create session for %s: %w", specA, err)
+	}
+	sessionB, err := s.sessionManager.CreateSessionForModel("compare_b", app.ParseModelSpec(s.llmConfig, specB))
+	if err != nil {
+		s.sessionManager.CloseSession(sessionA.ID)
+		return nil, fmt.Errorf("failed to create session for %s: %w", specB, err)
+	}
+
+	compareID = app.GenerateSessionID("compare")
+	comparison := app.NewComparison(compareID, sessionA, sessionB)
+
+	s.compareMutex.Lock()
+	s.comparisons[compareID] = &compareEntry{comparison: comparison, specA: specA, specB: specB}
+	s.compareMutex.Unlock()
+
+	c.Cookie(&fiber.Cookie{
+		Name:     compareCookieName,
+		Value:    compareID,
+		MaxAge:   int(sessionTTL(s.webConfig).Seconds()),
+		HTTPOnly: true,
+		SameSite: "Lax",
+	})
+
+	return comparison, nil
+}
+
+// comparisonAlive reports whether both sessions of a comparison are still managed
+func (s *Server) comparisonAlive(comparison *app.Comparison) bool {
+	if _, err := s.sessionManager.GetSession(comparison.A.ID); err != nil {
+		return false
+	}
+	if _, err := s.sessionManager.GetSession(comparison.B.ID); err != nil {
+		return false
+	}
+	return true
+}
+
+// closeComparison removes a comparison and closes its sessions
+func (s *Server) closeComparison(compareID string, entry *compareEntry) {
+	s.compareMutex.Lock()
+	delete(s.comparisons, compareID)
+	s.compareMutex.Unlock()
+
+	// Sessions may already be gone if they expired; best effort
+	s.sessionManager.CloseSession(entry.comparison.A.ID)
+	s.sessionManager.CloseSession(entry.comparison.B.ID)
+}
+
+// cleanupComparisons drops comparisons whose sessions have expired
+func (s *Server) cleanupComparisons() {
+	s.compareMutex.Lock()
+	entries := make(map[string]*compareEntry, len(s.comparisons))
+	for id, entry := range s.comparisons {
+		entries[id] = entry
+	}
+	s.compareMutex.Unlock()
+
+	for id, entry := range entries {
+		if !s.comparisonAlive(entry.comparison) {
+			s.closeComparison(id, entry)
+		}
+	}
+}
+
+func (s *Server) handleComparePage(c *fiber.Ctx) error {
+	c.Set("Content-Type", htmlContentType)
+	specA, specB := s.defaultCompareSpecs()
+	return s.renderComponent(c, templates.ComparePage(specA, specB, s.theme(c)))
+}
+
+func (s *Server) handleCompare(c *fiber.Ctx) error {
+	userMessage := c.FormValue("message")
+	if userMessage == "" {
+		return c.Status(400).SendString("Message is required")
+	}
+
+	defaultA, defaultB := s.defaultCompareSpecs()
+	specA := c.FormValue("model_a", defaultA)
+	specB := c.FormValue("model_b", defaultB)
+
+	comparison, err := s.getOrCreateComparison(c, specA, specB)
+	if err != nil {
+		return c.Status(500).SendString("Failed to start comparison: " + err.Error())
+	}
+
+	round := comparison.Ask(userMessage)
+
+	return s.renderComponent(c, templates.CompareRoundComponent(
+		round.ID,
+		userMessage,
+		compareAnswer(round.A),
+		compareAnswer(round.B),
+	))
+}
+
+func (s *Server) handleCompareVote(c *fiber.Ctx) error {
+	compareID := c.Cookies(compareCookieName)
+
+	s.compareMutex.Lock()
+	entry, exists := s.comparisons[compareID]
+	s.compareMutex.Unlock()
+
+	if !exists {
+		return c.Status(404).SendString("Comparison not found")
+	}
+
+	winner := c.FormValue("winner")
+	if err := entry.comparison.Vote(c.FormValue("round"), winner); err != nil {
+		return c.Status(400).SendString(err.Error())
+	}
+
+	var label string
+	switch winner {
+	case app.VoteA:
+		label = entry.comparison.A.ModelLabel()
+	case app.VoteB:
+		label = entry.comparison.B.ModelLabel()
+	}
+	return s.renderComponent(c, templates.CompareVoteRecorded(label))
+}
+
+// compareAnswer converts a comparison result into its template view
+func compareAnswer(result app.CompareResult) templates.CompareAnswer {
+	answer := templates.CompareAnswer{Model: result.Model}
+	if result.Err != nil {
+		answer.Error = result.Err.Error()
+		return answer
+	}
+	answer.Content = result.Response.Content
+	answer.Usage = result.Response.Usage
+	answer.ResponseTime = result.Response.ResponseTime.Milliseconds()
+	return answer
+}
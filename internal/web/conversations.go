@@ -0,0 +1,56 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/nleiva/chatgbt/internal/app"
+	"github.com/nleiva/chatgbt/pkg/store"
+)
+
+// currentConversation can stand in for the ID of the session's own conversation
+const currentConversation = "current"
+
+// ownConversation loads the conversation named by the :id route parameter, which may be
+// "current", from the session's store, its tenant's for tenant sessions. Only
+// conversations started in this browser session are visible; anything else is reported
+// as not found. On failure the returned error is a *fiber.Error.
+func (s *Server) ownConversation(c *fiber.Ctx) (*app.ChatSession, *store.Conversation, error) {
+	session, err := s.getOrCreateSession(c)
+	if err != nil {
+		return nil, nil, fiber.NewError(500, "Failed to get session: "+err.Error())
+	}
+
+	id := c.Params("id")
+	if id == currentConversation {
+		id = session.ConversationID()
+	}
+
+	conv, err := session.Store.Load(id)
+	if errors.Is(err, store.ErrNotFound) || (err == nil && conv.SessionID != session.ID) {
+		return nil, nil, fiber.NewError(404, "Conversation not found; send a message first")
+	}
+	if err != nil {
+		return nil, nil, fiber.NewError(500, "Failed to load conversation: "+err.Error())
+	}
+	return session, conv, nil
+}
+
+// handleExport downloads one of the session's conversations as Markdown, JSON, or HTML
+func (s *Server) handleExport(c *fiber.Ctx) error {
+	format, err := store.ParseFormat(c.Query("format", string(store.FormatMarkdown)))
+	if err != nil {
+		return c.Status(400).SendString(err.Error())
+	}
+
+	_, conv, err := s.ownConversation(c)
+	if err != nil {
+		return err
+	}
+
+	c.Set("Content-Type", format.ContentType())
+	c.Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s.%s"`, conv.ID, format))
+	return store.Export(c.Response().BodyWriter(), conv, format)
+}
@@ -0,0 +1,46 @@
+package web
+
+import (
+	"net/netip"
+	"slices"
+
+	"github.com/gofiber/fiber/v2"
+)
+
+// ipFilter refuses clients outside the allowed networks or inside the denied ones
+type ipFilter struct {
+	allowed []netip.Prefix // Empty allows every client not denied
+	denied  []netip.Prefix
+}
+
+// newIPFilter returns the access control middleware, or nil when both lists are empty
+func newIPFilter(allowed, denied []netip.Prefix) fiber.Handler {
+	if len(allowed) == 0 && len(denied) == 0 {
+		return nil
+	}
+	return (&ipFilter{allowed: allowed, denied: denied}).handle
+}
+
+func (f *ipFilter) handle(c *fiber.Ctx) error {
+	if !f.permits(c.IP()) {
+		return fiber.NewError(fiber.StatusForbidden, "Forbidden")
+	}
+	return c.Next()
+}
+
+// permits reports whether the client at ip may reach the server. Addresses that can't
+// be parsed are only let through when no allowlist is set.
+func (f *ipFilter) permits(ip string) bool {
+	addr, err := netip.ParseAddr(ip)
+	if err != nil {
+		return len(f.allowed) == 0
+	}
+	// IPv4 clients of a dual-stack listener show up as ::ffff:a.b.c.d
+	addr = addr.Unmap()
+	contains := func(prefix netip.Prefix) bool { return prefix.Contains(addr) }
+
+	if slices.ContainsFunc(f.denied, contains) {
+		return false
+	}
+	return len(f.allowed) == 0 || slices.ContainsFunc(f.allowed, contains)
+}
@@ -0,0 +1,53 @@
+package web
+
+import (
+	"net/netip"
+	"testing"
+)
+
+func TestIPFilterPermits(t *testing.T) {
+	prefixes := func(s ...string) []netip.Prefix {
+		var p []netip.Prefix
+		for _, prefix := range s {
+			p = append(p, netip.MustParsePrefix(prefix))
+		}
+		return p
+	}
+
+	tests := []struct {
+		name    string
+		allowed []netip.Prefix
+		denied  []netip.Prefix
+		ip      string
+		want    bool
+	}{
+		{name: "allowed", allowed: prefixes("10.0.0.0/8"), ip: "10.1.2.3", want: true},
+		{name: "outside allowlist", allowed: prefixes("10.0.0.0/8"), ip: "192.168.1.1", want: false},
+		{name: "denied", denied: prefixes("192.168.1.0/24"), ip: "192.168.1.7", want: false},
+		{name: "not denied", denied: prefixes("192.168.1.0/24"), ip: "192.168.2.7", want: true},
+		{name: "deny wins over allow", allowed: prefixes("10.0.0.0/8"), denied: prefixes("10.9.0.0/16"), ip: "10.9.1.1", want: false},
+		{name: "allowed beside denied", allowed: prefixes("10.0.0.0/8"), denied: prefixes("10.9.0.0/16"), ip: "10.8.1.1", want: true},
+		{name: "mapped IPv4", allowed: prefixes("10.0.0.0/8"), ip: "::ffff:10.1.2.3", want: true},
+		{name: "mapped IPv4 denied", denied: prefixes("10.0.0.0/8"), ip: "::ffff:10.1.2.3", want: false},
+		{name: "IPv6", allowed: prefixes("fd00::/8"), ip: "fd12::1", want: true},
+		{name: "IPv6 outside allowlist", allowed: prefixes("fd00::/8"), ip: "2001:db8::1", want: false},
+		{name: "single host", allowed: prefixes("203.0.113.5/32"), ip: "203.0.113.6", want: false},
+		{name: "unparsable without allowlist", denied: prefixes("10.0.0.0/8"), ip: "unknown", want: true},
+		{name: "unparsable with allowlist", allowed: prefixes("10.0.0.0/8"), ip: "unknown", want: false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			f := &ipFilter{allowed: tt.allowed, denied: tt.denied}
+			if got := f.permits(tt.ip); got != tt.want {
+				t.Errorf("permits(%q) = %v, want %v", tt.ip, got, tt.want)
+			}
+		})
+	}
+}
+
+func TestNewIPFilterWithoutLists(t *testing.T) {
+	if newIPFilter(nil, nil) != nil {
+		t.Error("newIPFilter(nil, nil) returned middleware, want nil")
+	}
+}
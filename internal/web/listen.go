@@ -0,0 +1,109 @@
+package web
+
+import (
+	"errors"
+	"fmt"
+	"io/fs"
+	"net"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// unixPrefix marks an address as the path of a Unix domain socket
+const unixPrefix = "unix:"
+
+// systemdFirstFD is the first file descriptor systemd passes to activated services
+const systemdFirstFD = 3
+
+// socketMode lets the owner and group, such as a reverse proxy's, connect to the socket
+const socketMode = 0660
+
+// listen opens the listener the server accepts connections on: a socket inherited from
+// systemd socket activation if there is one, otherwise address, which is either a TCP
+// address such as ":3000" or a Unix socket path prefixed with "unix:"
+func listen(address string) (net.Listener, error) {
+	ln, err := systemdListener()
+	if ln != nil || err != nil {
+		return ln, err
+	}
+
+	path, ok := strings.CutPrefix(address, unixPrefix)
+	if !ok {
+		return net.Listen("tcp", address)
+	}
+	if err := removeStaleSocket(path); err != nil {
+		return nil, err
+	}
+	ln, err = net.Listen("unix", path)
+	if err != nil {
+		return nil, err
+	}
+	if err := os.Chmod(path, socketMode); err != nil {
+		ln.Close()
+		return nil, fmt.Errorf("failed to set permissions of %s: %w", path, err)
+	}
+	return ln, nil
+}
+
+// systemdListener returns the socket systemd passed in under the LISTEN_FDS protocol,
+// or nil when the process wasn't socket activated
+func systemdListener() (net.Listener, error) {
+	if os.Getenv("LISTEN_PID") != strconv.Itoa(os.Getpid()) {
+		return nil, nil
+	}
+	count, err := strconv.Atoi(os.Getenv("LISTEN_FDS"))
+	if err != nil || count < 1 {
+		return nil, nil
+	}
+	if count > 1 {
+		return nil, fmt.Errorf("systemd passed %d sockets; the web server listens on exactly one", count)
+	}
+	// Not inherited by processes started from here, such as tools
+	os.Unsetenv("LISTEN_PID")
+	os.Unsetenv("LISTEN_FDS")
+	os.Unsetenv("LISTEN_FDNAMES")
+
+	file := os.NewFile(systemdFirstFD, "systemd-socket")
+	defer file.Close()
+	ln, err := net.FileListener(file)
+	if err != nil {
+		return nil, fmt.Errorf("failed to use the systemd socket: %w", err)
+	}
+	return ln, nil
+}
+
+// removeStaleSocket removes a socket file left behind by a server that didn't shut down
+// cleanly, refusing to touch anything else at path
+func removeStaleSocket(path string) error {
+	info, err := os.Lstat(path)
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	if info.Mode()&fs.ModeSocket == 0 {
+		return fmt.Errorf("%s exists and is not a socket", path)
+	}
+	if conn, err := net.Dial("unix", path); err == nil {
+		conn.Close()
+		return fmt.Errorf("%s is in use by another server", path)
+	}
+	return os.Remove(path)
+}
+
+// describeListener names where the server can be reached, for the startup log
+func describeListener(ln net.Listener) string {
+	switch addr := ln.Addr().(type) {
+	case *net.UnixAddr:
+		return "unix socket " + addr.Name
+	case *net.TCPAddr:
+		if addr.IP.IsUnspecified() {
+			return fmt.Sprintf("http://localhost:%d", addr.Port)
+		}
+		return "http://" + addr.String()
+	default:
+		return addr.String()
+	}
+}
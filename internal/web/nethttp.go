@@ -0,0 +1,109 @@
+package web
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log"
+	"net"
+	"net/http"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+	"github.com/valyala/fasthttp"
+)
+
+// readHeaderTimeout bounds how long the net/http server waits for request headers
+const readHeaderTimeout = 10 * time.Second
+
+// httpContextKey stores the net/http request context on the fasthttp request, from
+// which useHTTPContext hands it to the handlers
+type httpContextKey struct{}
+
+// Handler returns the web UI and API as a net/http handler, so they can be served by
+// the standard library or mounted in another server. It converts each request for the
+// Fiber routes, which still handle it. Handlers see the net/http request context, so
+// model calls stop when the client goes away, which Fiber's own server doesn't offer.
+func (s *Server) Handler() http.Handler {
+	handler := s.app.Handler()
+	limit := int64(bodyLimit(s.webConfig))
+
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if r.ContentLength > limit {
+			http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+			return
+		}
+
+		req := fasthttp.AcquireRequest()
+		defer fasthttp.ReleaseRequest(req)
+		if r.Body != nil {
+			n, err := io.Copy(req.BodyWriter(), http.MaxBytesReader(w, r.Body, limit))
+			var tooLarge *http.MaxBytesError
+			if errors.As(err, &tooLarge) {
+				http.Error(w, http.StatusText(http.StatusRequestEntityTooLarge), http.StatusRequestEntityTooLarge)
+				return
+			}
+			if err != nil {
+				http.Error(w, http.StatusText(http.StatusBadRequest), http.StatusBadRequest)
+				return
+			}
+			req.Header.SetContentLength(int(n))
+		}
+		req.Header.SetMethod(r.Method)
+		req.SetRequestURI(r.RequestURI)
+		req.SetHost(r.Host)
+		for name, values := range r.Header {
+			for _, value := range values {
+				req.Header.Add(name, value)
+			}
+		}
+
+		var fctx fasthttp.RequestCtx
+		fctx.Init(req, remoteAddr(r), nil)
+		fctx.SetUserValue(httpContextKey{}, r.Context())
+		handler(&fctx)
+
+		fctx.Response.Header.VisitAll(func(name, value []byte) {
+			w.Header().Add(string(name), string(value))
+		})
+		w.WriteHeader(fctx.Response.StatusCode())
+		if _, err := w.Write(fctx.Response.Body()); err != nil && r.Context().Err() == nil {
+			log.Printf("Warning: failed to write response: %v", err)
+		}
+	})
+}
+
+// useHTTPContext makes the net/http request context of requests served through
+// Handler the user context of the Fiber request
+func useHTTPContext(c *fiber.Ctx) error {
+	if ctx, ok := c.Context().UserValue(httpContextKey{}).(context.Context); ok {
+		c.SetUserContext(ctx)
+	}
+	return c.Next()
+}
+
+// remoteAddr parses the client address of r for the request logs; unparseable
+// addresses, such as those of unix sockets, are left out
+func remoteAddr(r *http.Request) net.Addr {
+	if addr, err := net.ResolveTCPAddr("tcp", r.RemoteAddr); err == nil {
+		return addr
+	}
+	return &net.TCPAddr{}
+}
+
+// newHTTPServer returns a standard library HTTP server for the web UI
+func (s *Server) newHTTPServer(address string) *http.Server {
+	return &http.Server{
+		Addr:              address,
+		Handler:           s.Handler(),
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+}
+
+// shutdown stops whichever server Run started, letting requests in flight finish
+func (s *Server) shutdown() error {
+	if s.httpServer != nil {
+		return s.httpServer.Shutdown(context.Background())
+	}
+	return s.app.Shutdown()
+}
@@ -0,0 +1,77 @@
+package web
+
+import (
+	"errors"
+	"log"
+	"os"
+	"os/signal"
+	"syscall"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/nleiva/chatgbt/internal/app"
+	"github.com/nleiva/chatgbt/pkg/backend"
+)
+
+// Reloader rereads the model, budgets, and session options, such as after ENV_FILE
+// was edited
+type Reloader func() (backend.LLMConfig, backend.TokenBudgetConfig, app.SessionOptions, error)
+
+// errReloadUnavailable is returned by reload for servers started without a Reloader
+var errReloadUnavailable = errors.New("reloading settings is not available")
+
+// reload rereads the settings and the prompt library. New sessions start with the new
+// settings; active sessions take them on at their next request. When the settings
+// can't be read, the server keeps those it has.
+func (s *Server) reload() (backend.LLMConfig, backend.TokenBudgetConfig, error) {
+	if s.reloader == nil {
+		return backend.LLMConfig{}, backend.TokenBudgetConfig{}, errReloadUnavailable
+	}
+
+	s.reloadMutex.Lock()
+	defer s.reloadMutex.Unlock()
+
+	cfg, budgetCfg, opts, err := s.reloader()
+	if err != nil {
+		return cfg, budgetCfg, err
+	}
+	s.sessionManager.Reload(cfg, budgetCfg, opts)
+	if err := s.prompts.Reload(); err != nil {
+		log.Printf("Warning: %v", err)
+	}
+
+	log.Printf("Reloaded settings: provider %s, model %s, session budget %d tokens",
+		cfg.Provider, cfg.Model, budgetCfg.SessionLimit)
+	return cfg, budgetCfg, nil
+}
+
+// reloadOnHangup reloads the settings every time the process receives SIGHUP
+func (s *Server) reloadOnHangup() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, syscall.SIGHUP)
+	for range signals {
+		if _, _, err := s.reload(); err != nil {
+			log.Printf("Warning: failed to reload settings: %v", err)
+		}
+	}
+}
+
+// handleReload reloads the settings on an operator's request and reports the new ones
+func (s *Server) handleReload(c *fiber.Ctx) error {
+	cfg, budgetCfg, err := s.reload()
+	switch {
+	case errors.Is(err, errReloadUnavailable):
+		return c.Status(501).JSON(fiber.Map{"error": err.Error()})
+	case err != nil:
+		log.Printf("Failed to reload settings on request %s: %v", requestID(c), err)
+		return c.Status(422).JSON(fiber.Map{"error": err.Error()})
+	}
+
+	log.Printf("Reloaded settings on request %s", requestID(c))
+	return c.JSON(fiber.Map{
+		"provider":      cfg.Provider,
+		"model":         cfg.Model,
+		"session_limit": budgetCfg.SessionLimit,
+		"daily_limit":   budgetCfg.DailyLimit,
+	})
+}
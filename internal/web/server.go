@@ -1,18 +1,36 @@
 package web
 
 import (
+	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net"
+	"net/http"
+	"os"
+	"os/signal"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
 	"time"
 
 	"github.com/a-h/templ"
 	"github.com/gofiber/fiber/v2"
-	"github.com/gofiber/fiber/v2/middleware/logger"
+	"github.com/gofiber/fiber/v2/middleware/filesystem"
 	"github.com/gofiber/fiber/v2/middleware/recover"
+	"github.com/gofiber/fiber/v2/middleware/requestid"
 
 	"github.com/nleiva/chatgbt/internal/app"
+	"github.com/nleiva/chatgbt/internal/web/static"
 	"github.com/nleiva/chatgbt/internal/web/templates"
+	"github.com/nleiva/chatgbt/pkg/apikeys"
 	"github.com/nleiva/chatgbt/pkg/backend"
+	"github.com/nleiva/chatgbt/pkg/config"
+	"github.com/nleiva/chatgbt/pkg/i18n"
+	"github.com/nleiva/chatgbt/pkg/llm"
+	"github.com/nleiva/chatgbt/pkg/prompts"
+	"github.com/nleiva/chatgbt/pkg/store"
 )
 
 const (
@@ -20,60 +38,155 @@ const (
 	defaultAddress      = ":3000"
 	htmlContentType     = "text/html; charset=utf-8"
 	sessionCookieName   = "chatgbt_session_id"
-	sessionMaxAge       = 24 * time.Hour
+	compareCookieName   = "chatgbt_compare_id"
+	themeCookieName     = "chatgbt_theme"
+	themeCookieMaxAge   = 365 * 24 * time.Hour
+	searchResultLimit   = 20
+	usageChangedEvent   = "usage-changed" // HTMX event that refreshes the header usage gauge
 )
 
 // Server represents the web server with session management
 type Server struct {
 	app            *fiber.App
+	httpServer     *http.Server // Set when WEB_SERVER selects the net/http server
 	sessionManager app.SessionManager
+	llmConfig      backend.LLMConfig
+	webConfig      config.WebConfig
+	prompts        *prompts.Library
+	conversations  store.Store
+	language       string        // UI language for browsers that don't ask for a supported one
+	share          *shareSigner  // Signs read-only conversation links
+	metrics        *routeMetrics // Request counts and latency per route
+	apiTokens      *apikeys.Store
+	reloader       Reloader   // nil when the settings can't be reloaded
+	reloadMutex    sync.Mutex // Serializes reloads
+
+	// Side-by-side model comparisons keyed by compare cookie
+	comparisons  map[string]*compareEntry
+	compareMutex sync.Mutex
+}
+
+// compareEntry tracks a comparison and the model specs it was created for
+type compareEntry struct {
+	comparison *app.Comparison
+	specA      string
+	specB      string
 }
 
 // WebRunner handles web server mode with consistent signature
 type WebRunner struct {
-	address string
+	address   string
+	webConfig config.WebConfig
+	opts      app.SessionOptions
+	reloader  Reloader
 }
 
 // NewWebRunner creates a new web runner for the specified address
-func NewWebRunner(address string) *WebRunner {
-	return &WebRunner{address: address}
+func NewWebRunner(address string, webConfig config.WebConfig, opts app.SessionOptions) *WebRunner {
+	return &WebRunner{address: address, webConfig: webConfig, opts: opts}
+}
+
+// SetReloader lets the server reload its settings with reload on POST /api/v1/reload
+// and SIGHUP
+func (w *WebRunner) SetReloader(reload Reloader) {
+	w.reloader = reload
 }
 
 // Run starts the web server with the provided configuration
 func (w *WebRunner) Run(cfg backend.LLMConfig, budgetCfg backend.TokenBudgetConfig) error {
-	server := NewServer(cfg, budgetCfg)
+	server := NewServer(cfg, budgetCfg, w.webConfig, w.opts)
+	server.reloader = w.reloader
 	return server.Run(w.address)
 }
 
 // NewServer creates a new web server instance with session management
-func NewServer(cfg backend.LLMConfig, budgetCfg backend.TokenBudgetConfig) *Server {
+func NewServer(cfg backend.LLMConfig, budgetCfg backend.TokenBudgetConfig, webConfig config.WebConfig, opts app.SessionOptions) *Server {
 	fiberApp := fiber.New(fiber.Config{
 		DisableStartupMessage: false,
+		// Form values are kept in session history beyond the request lifetime
+		Immutable: true,
+		BodyLimit: bodyLimit(webConfig),
 	})
 
 	// Middleware
-	fiberApp.Use(logger.New())
+	fiberApp.Use(requestid.New(requestid.Config{Header: backend.RequestIDHeader}))
+	metrics := newRouteMetrics()
+	fiberApp.Use(newAccessLogger(webConfig.AccessLog, metrics).handle)
+	// After the access logger, so refused clients are logged
+	if filter := newIPFilter(webConfig.AllowedIPs, webConfig.DeniedIPs); filter != nil {
+		fiberApp.Use(filter)
+	}
 	fiberApp.Use(recover.New())
+	fiberApp.Use(useHTTPContext)
 
 	// Initialize session manager
-	sessionManager := app.NewInMemorySessionManager(cfg, budgetCfg, sessionMaxAge)
+	sessionManager := app.NewInMemorySessionManager(cfg, budgetCfg, sessionTTL(webConfig), "web", opts)
+	sessionManager.SetMaxSessions(webConfig.MaxSessions)
+	sessionManager.SetTenantResolver(resolveTenant)
+
+	// Load system prompt presets; built-in presets remain available on error
+	promptLibrary, err := prompts.NewLibrary(webConfig.PromptsFile)
+	if err != nil {
+		log.Printf("Warning: %v", err)
+	}
+
+	// Without a readable token file the API rejects every request rather than open up
+	apiTokens, err := apikeys.Open(webConfig.APITokensFile)
+	if err != nil {
+		log.Printf("Warning: %v", err)
+	}
 
 	server := &Server{
 		app:            fiberApp,
 		sessionManager: sessionManager,
+		llmConfig:      cfg,
+		webConfig:      webConfig,
+		prompts:        promptLibrary,
+		conversations:  store.NewFileStore(store.DefaultDir),
+		comparisons:    make(map[string]*compareEntry),
+		language:       opts.Language,
+		share:          newShareSigner(webConfig),
+		metrics:        metrics,
+		apiTokens:      apiTokens,
 	}
 
 	server.setupRoutes()
+	server.restoreSessions()
 
 	// Start cleanup routine for expired sessions
 	go server.startSessionCleanup()
+	go server.startAPIUsageFlush()
 
 	return server
 }
 
+// bodyLimit returns the configured request size limit; Fiber answers larger requests with 413
+func bodyLimit(webConfig config.WebConfig) int {
+	if webConfig.MaxBodySize > 0 {
+		return webConfig.MaxBodySize
+	}
+	return config.DefaultMaxBodySize
+}
+
+// sessionTTL returns how long an idle web session lives
+func sessionTTL(webConfig config.WebConfig) time.Duration {
+	if webConfig.SessionTTL > 0 {
+		return webConfig.SessionTTL
+	}
+	return config.DefaultSessionTTL
+}
+
+// cleanupInterval returns how often expired sessions are removed
+func cleanupInterval(webConfig config.WebConfig) time.Duration {
+	if webConfig.SessionCleanupInterval > 0 {
+		return webConfig.SessionCleanupInterval
+	}
+	return config.DefaultSessionCleanupInterval
+}
+
 // startSessionCleanup runs a background cleanup routine for expired sessions
 func (s *Server) startSessionCleanup() {
-	ticker := time.NewTicker(30 * time.Minute)
+	ticker := time.NewTicker(cleanupInterval(s.webConfig))
 	defer ticker.Stop()
 
 	for range ticker.C {
@@ -81,30 +194,71 @@ func (s *Server) startSessionCleanup() {
 		if cleaned > 0 {
 			log.Printf("Cleaned up %d expired sessions", cleaned)
 		}
+		s.cleanupComparisons()
+		s.saveSessions()
+	}
+}
+
+// restoreSessions reloads the sessions saved before the last shutdown
+func (s *Server) restoreSessions() {
+	if s.webConfig.SessionFile == "" {
+		return
+	}
+	restored, err := s.sessionManager.RestoreSnapshot(s.webConfig.SessionFile)
+	if err != nil {
+		log.Printf("Warning: %v", err)
+		return
+	}
+	if restored > 0 {
+		log.Printf("Restored %d sessions from %s", restored, s.webConfig.SessionFile)
+	}
+}
+
+// saveSessions writes every session to the session file, if one is configured
+func (s *Server) saveSessions() {
+	if s.webConfig.SessionFile == "" {
+		return
+	}
+	if err := s.sessionManager.SaveSnapshot(s.webConfig.SessionFile); err != nil {
+		log.Printf("Warning: %v", err)
 	}
 }
 
 // getOrCreateSession gets an existing session or creates a new one for the user
 func (s *Server) getOrCreateSession(c *fiber.Ctx) (*app.ChatSession, error) {
 	sessionID := c.Cookies(sessionCookieName)
+	tenant, _ := c.Locals(tenantKey).(string)
 
 	if sessionID != "" {
-		if session, err := s.sessionManager.GetSession(sessionID); err == nil {
+		// A session is only ever used by requests of its own tenant
+		if session, err := s.sessionManager.GetSession(sessionID); err == nil && session.Tenant == tenant {
 			return session, nil
 		}
 	}
 
 	// Create new session
-	session, err := s.sessionManager.CreateSession("web_user")
+	var session *app.ChatSession
+	var err error
+	if tenant != "" {
+		session, err = s.sessionManager.CreateTenantSession("api_user", tenant)
+	} else {
+		session, err = s.sessionManager.CreateSession("web_user")
+	}
 	if err != nil {
 		return nil, fmt.Errorf("failed to create session: %w", err)
 	}
 
+	session.SetLanguage(s.requestLanguage(c))
+	theme := s.theme(c)
+	session.UpdatePreferences(func(p *app.Preferences) { p.Theme = theme })
+	// Without accounts, the browser's session is the closest thing to a user
+	session.SetEndUser(session.ID)
+
 	// Set session cookie
 	c.Cookie(&fiber.Cookie{
 		Name:     sessionCookieName,
 		Value:    session.ID,
-		MaxAge:   int(sessionMaxAge.Seconds()),
+		MaxAge:   int(sessionTTL(s.webConfig).Seconds()),
 		HTTPOnly: true,
 		SameSite: "Lax",
 	})
@@ -113,8 +267,11 @@ func (s *Server) getOrCreateSession(c *fiber.Ctx) (*app.ChatSession, error) {
 }
 
 func (s *Server) setupRoutes() {
-	// Serve static files
-	s.app.Static("/static", "./web/static")
+	// Serve the embedded static files
+	s.app.Use("/static", filesystem.New(filesystem.Config{
+		Root:   http.FS(static.Files),
+		MaxAge: 3600,
+	}))
 
 	// Favicon handler
 	s.app.Get("/favicon.ico", s.handleFavicon)
@@ -124,19 +281,124 @@ func (s *Server) setupRoutes() {
 
 	// API endpoints
 	s.app.Post("/chat", s.handleChat)
+	s.app.Post("/edit", s.handleEdit)
+	s.app.Post("/regenerate", s.handleRegenerate)
+	s.app.Post("/continue", s.handleContinue)
+	s.app.Post("/chat/abort", s.handleAbort)
 	s.app.Post("/reset", s.handleReset)
+	s.app.Post("/summarize", s.handleSummarize)
 	s.app.Post("/system", s.handleSystemPrompt)
+	s.app.Get("/system/presets", s.handleListPresets)
+	s.app.Post("/system/presets", s.handleSavePreset)
+	s.app.Post("/preferences", s.handlePreferences)
 	s.app.Get("/status", s.handleStatus)
+	s.app.Get("/tokens", s.handleTokens)
+	s.app.Get("/usage", s.handleUsage)
+	s.app.Get("/queue", s.handleQueue)
+	s.app.Get("/search", s.handleSearch)
+	s.app.Get("/metrics", s.handleMetrics)
+
+	// Conversation export and read-only sharing
+	s.app.Get("/conversations/:id/export", s.handleExport)
+	s.app.Post("/conversations/:id/share", s.handleShare)
+	s.app.Get("/shared/:id", s.handleShared)
+
+	// Versioned JSON API
+	s.setupAPIRoutes()
+
+	// Side-by-side model comparison
+	s.app.Get("/compare", s.handleComparePage)
+	s.app.Post("/compare", s.handleCompare)
+	s.app.Post("/compare/vote", s.handleCompareVote)
+
+	// Last, to label requests for unknown paths in the request metrics
+	s.app.Use(markUnmatched)
 }
 
 func (s *Server) handleHome(c *fiber.Ctx) error {
+	session, err := s.getOrCreateSession(c)
+	if err != nil {
+		return c.Status(500).SendString("Failed to get session: " + err.Error())
+	}
+
 	c.Set("Content-Type", htmlContentType)
-	return s.renderComponent(c, templates.ChatPage())
+	return s.renderComponent(c, templates.ChatPage(s.prompts.List(), session.Preferences()))
 }
 
-// renderComponent is a helper to render templ components
+// renderComponent is a helper to render templ components in the request's language
 func (s *Server) renderComponent(c *fiber.Ctx, component templ.Component) error {
-	return component.Render(c.Context(), c.Response().BodyWriter())
+	ctx := i18n.WithLanguage(c.Context(), s.requestLanguage(c))
+	return component.Render(ctx, c.Response().BodyWriter())
+}
+
+// renderError shows an error in place of the assistant's reply, with the request ID
+// to look for in the logs
+func (s *Server) renderError(c *fiber.Ctx, err error) error {
+	lang := s.requestLanguage(c)
+	message := i18n.T(lang, "web.error", err.Error())
+	var tooLong *app.MessageTooLongError
+	if errors.As(err, &tooLong) {
+		c.Status(413)
+		message = i18n.T(lang, "web.error", i18n.T(lang, "message_too_long", tooLong.Length, tooLong.Max))
+	}
+	var overCost *app.DailyCostExceededError
+	if errors.As(err, &overCost) {
+		c.Status(429)
+		message = i18n.T(lang, "web.error", i18n.T(lang, "daily_cost_exceeded", overCost.Limit))
+	}
+	var overBudget *app.BudgetExceededError
+	if errors.As(err, &overBudget) {
+		c.Status(429)
+		message = i18n.T(lang, "web.error", i18n.T(lang, "budget_exhausted", overBudget.PromptTokens, overBudget.Remaining))
+	}
+	var inProgress *app.GenerationInProgressError
+	if errors.As(err, &inProgress) {
+		c.Status(409)
+		message = i18n.T(lang, "web.error", i18n.T(lang, "generation_in_progress"))
+	}
+	var queueTimeout *llm.QueueTimeoutError
+	if errors.As(err, &queueTimeout) {
+		c.Status(503)
+	}
+	if id := requestID(c); id != "" {
+		message += " (" + i18n.T(lang, "web.request_id", id) + ")"
+	}
+	return s.renderComponent(c, templates.MessageComponent(string(backend.RoleAssistant), message))
+}
+
+// requestID returns the ID the requestid middleware assigned to this request
+func requestID(c *fiber.Ctx) string {
+	id, _ := c.Locals("requestid").(string)
+	return id
+}
+
+// requestContext returns a context for the model calls made on behalf of this request,
+// carrying its ID into the metrics logs and provider requests
+func requestContext(c *fiber.Ctx) context.Context {
+	return backend.WithRequestID(c.UserContext(), requestID(c))
+}
+
+// requestLanguage picks the UI language from the Accept-Language header, falling
+// back to the configured language
+func (s *Server) requestLanguage(c *fiber.Ctx) string {
+	if lang := i18n.MatchAcceptLanguage(c.Get(fiber.HeaderAcceptLanguage)); lang != "" {
+		return lang
+	}
+	if s.language != "" {
+		return s.language
+	}
+	return i18n.Default
+}
+
+// theme returns the visitor's chosen theme from its cookie, or the configured default
+func (s *Server) theme(c *fiber.Ctx) string {
+	if theme := c.Cookies(themeCookieName); config.IsTheme(theme) {
+		return theme
+	}
+	if config.IsTheme(s.webConfig.Theme) {
+		return s.webConfig.Theme
+	}
+	return config.ThemeDark
 }
 
 func (s *Server) handleFavicon(c *fiber.Ctx) error {
@@ -155,26 +417,134 @@ func (s *Server) handleChat(c *fiber.Ctx) error {
 		return c.Status(400).SendString("Message is required")
 	}
 
+	return s.respond(c, session, userMessage)
+}
+
+// handleEdit replaces a previous user message: it truncates the conversation at that
+// turn and sends the edited text in its place
+func (s *Server) handleEdit(c *fiber.Ctx) error {
+	session, err := s.getOrCreateSession(c)
+	if err != nil {
+		return c.Status(500).SendString("Failed to get session: " + err.Error())
+	}
+
+	userMessage := c.FormValue("message")
+	if userMessage == "" {
+		return c.Status(400).SendString("Message is required")
+	}
+	turn, err := strconv.Atoi(c.FormValue("turn"))
+	if err != nil {
+		return c.Status(400).SendString("turn must be a number")
+	}
+
+	// Check the new text before dropping the old one
+	if err := session.CheckMessage(userMessage); err != nil {
+		return s.renderError(c, err)
+	}
+	if err := session.TruncateAt(turn); err != nil {
+		return s.renderError(c, err)
+	}
+	return s.respond(c, session, userMessage)
+}
+
+// handleRegenerate replaces the last assistant reply with a new one for the same
+// user message. An optional temperature form value (0 to 2) overrides the default.
+func (s *Server) handleRegenerate(c *fiber.Ctx) error {
+	session, err := s.getOrCreateSession(c)
+	if err != nil {
+		return c.Status(500).SendString("Failed to get session: " + err.Error())
+	}
+
+	var temperature *float64
+	if value := c.FormValue("temperature"); value != "" {
+		t, err := strconv.ParseFloat(value, 64)
+		if err != nil || t < 0 || t > 2 {
+			return c.Status(400).SendString("temperature must be a number between 0 and 2")
+		}
+		temperature = &t
+	}
+
+	userMessage := session.LastUserMessage()
+	sentAt := time.Now()
+	response, err := session.Regenerate(requestContext(c), temperature)
+	return s.renderExchange(c, session, userMessage, sentAt, response, err)
+}
+
+// handleContinue asks for the rest of the latest reply, which was cut off at the token
+// limit, and renders the exchange with the whole reply
+func (s *Server) handleContinue(c *fiber.Ctx) error {
+	session, err := s.getOrCreateSession(c)
+	if err != nil {
+		return c.Status(500).SendString("Failed to get session: " + err.Error())
+	}
+
+	userMessage := session.LastUserMessage()
+	sentAt := time.Now()
+	response, err := session.Continue(requestContext(c), nil)
+	return s.renderExchange(c, session, userMessage, sentAt, response, err)
+}
+
+// handleAbort stops the reply the session is generating, whose request then renders
+// what was streamed so far
+func (s *Server) handleAbort(c *fiber.Ctx) error {
+	session, err := s.getOrCreateSession(c)
+	if err != nil {
+		return c.Status(500).JSON(fiber.Map{"error": "Failed to get session: " + err.Error()})
+	}
+	return c.JSON(fiber.Map{"aborted": session.Abort()})
+}
+
+// respond sends a user message to the session and renders the exchange
+func (s *Server) respond(c *fiber.Ctx, session *app.ChatSession, userMessage string) error {
 	// Process the user message using the session
-	response, err := session.ProcessUserMessage(userMessage)
+	sentAt := time.Now()
+	response, err := session.ProcessUserMessageContext(requestContext(c), userMessage, nil)
+	return s.renderExchange(c, session, userMessage, sentAt, response, err)
+}
+
+// renderExchange renders a user message and the assistant's response, or the error
+// that prevented one
+func (s *Server) renderExchange(c *fiber.Ctx, session *app.ChatSession, userMessage string, sentAt time.Time, response *app.ChatResponse, err error) error {
+	c.Set("HX-Trigger", usageChangedEvent)
 	if err != nil {
 		// Show error message
-		return s.renderComponent(c, templates.MessageComponent(string(backend.RoleAssistant), "Error: "+err.Error()))
+		return s.renderError(c, err)
 	}
 
 	// Prepare warning message if any
 	var warningMsg string
-	if len(response.Warnings) > 0 {
+	if response.Truncated() {
+		warningMsg = fmt.Sprintf("⚠️ %s", i18n.T(s.requestLanguage(c), "reply.truncated"))
+	} else if response.Aborted() {
+		warningMsg = fmt.Sprintf("⚠️ %s", i18n.T(s.requestLanguage(c), "reply.aborted"))
+	} else if len(response.Warnings) > 0 {
 		warningMsg = fmt.Sprintf("⚠️ %s", response.Warnings[0])
 	}
 
+	if response.Pruned != nil {
+		if err := s.renderComponent(c, templates.PruneNotice(response.Pruned)); err != nil {
+			return err
+		}
+	}
+
 	// Render everything as a single response
 	return s.renderComponent(c, templates.ChatResponseComponent(
 		userMessage,
 		response.Content,
 		response.Usage,
 		response.ResponseTime.Milliseconds(),
+		response.TTFT.Milliseconds(),
+		response.TokensPerSecond,
 		warningMsg,
+		response.FollowUps,
+		templates.MessageMeta{
+			Show:      session.Preferences().ShowDetails,
+			SentAt:    sentAt,
+			RepliedAt: sentAt.Add(response.ResponseTime),
+			Model:     response.Model,
+			Turn:      session.Turns() - 1,
+			Truncated: response.Truncated() || response.Aborted(),
+		},
 	))
 }
 
@@ -185,12 +555,27 @@ func (s *Server) handleReset(c *fiber.Ctx) error {
 	}
 
 	session.Reset(defaultSystemPrompt)
+	c.Set("HX-Trigger", usageChangedEvent)
 
 	// Return the welcome screen HTML
-	return c.SendString(`<div class="welcome-screen">
-		<h2>How can I help you today?</h2>
-		<p>I'm ChatGBT, your AI assistant. Ask me anything, and I'll do my best to help you with information, analysis, creative tasks, and more.</p>
-	</div>`)
+	return s.renderComponent(c, templates.WelcomeScreen())
+}
+
+// handleSummarize asks the model for a summary of the conversation and pins it to the
+// context; the summary is also saved with the conversation, so downloads include it
+func (s *Server) handleSummarize(c *fiber.Ctx) error {
+	session, err := s.getOrCreateSession(c)
+	if err != nil {
+		return c.Status(500).SendString("Failed to get session: " + err.Error())
+	}
+
+	summary, err := session.Summarize(requestContext(c))
+	c.Set("HX-Trigger", usageChangedEvent)
+	if err != nil {
+		return s.renderError(c, err)
+	}
+	session.PinSummary(summary)
+	return s.renderComponent(c, templates.SummaryNotice(summary))
 }
 
 func (s *Server) handleSystemPrompt(c *fiber.Ctx) error {
@@ -200,16 +585,83 @@ func (s *Server) handleSystemPrompt(c *fiber.Ctx) error {
 	}
 
 	newPrompt := c.FormValue("prompt")
+	if newPrompt == "" {
+		// Fall back to a named preset from the prompt library
+		if preset, ok := s.prompts.Get(c.FormValue("preset")); ok {
+			newPrompt = preset.Prompt
+		}
+	}
 	if newPrompt == "" {
 		return c.Status(400).SendString("System prompt is required")
 	}
 
 	session.UpdateSystemPrompt(newPrompt)
 
-	return c.SendString(`<div class="message system">
-		<div class="message-role">system</div>
-		<div class="message-content">System prompt updated.</div>
-	</div>`)
+	c.Set("HX-Trigger", usageChangedEvent)
+	return s.renderComponent(c, templates.SystemNotice(i18n.T(s.requestLanguage(c), "system_updated")))
+}
+
+// handlePreferences updates the session's UI preferences from form values. Only the
+// settings present in the request change.
+func (s *Server) handlePreferences(c *fiber.Ctx) error {
+	session, err := s.getOrCreateSession(c)
+	if err != nil {
+		return c.Status(500).SendString("Failed to get session: " + err.Error())
+	}
+
+	if value := c.FormValue("show_details"); value != "" {
+		show, err := strconv.ParseBool(value)
+		if err != nil {
+			return c.Status(400).SendString("show_details must be true or false")
+		}
+		session.UpdatePreferences(func(p *app.Preferences) { p.ShowDetails = show })
+	}
+
+	if theme := c.FormValue("theme"); theme != "" {
+		if !config.IsTheme(theme) {
+			return c.Status(400).SendString(fmt.Sprintf("theme must be %q or %q", config.ThemeDark, config.ThemeLight))
+		}
+		session.UpdatePreferences(func(p *app.Preferences) { p.Theme = theme })
+		// The cookie outlives the session, so the choice survives expiry and restarts
+		c.Cookie(&fiber.Cookie{
+			Name:     themeCookieName,
+			Value:    theme,
+			MaxAge:   int(themeCookieMaxAge.Seconds()),
+			HTTPOnly: true,
+			SameSite: "Lax",
+		})
+	}
+
+	return c.SendStatus(204)
+}
+
+// handleListPresets returns the available system prompt presets as JSON
+func (s *Server) handleListPresets(c *fiber.Ctx) error {
+	presets := s.prompts.List()
+	result := make([]fiber.Map, 0, len(presets))
+	for _, p := range presets {
+		result = append(result, fiber.Map{
+			"name":        p.Name,
+			"description": p.Description,
+			"prompt":      p.Prompt,
+			"built_in":    p.BuiltIn,
+		})
+	}
+	return c.JSON(result)
+}
+
+// handleSavePreset saves a system prompt as a new user preset and returns the refreshed dropdown
+func (s *Server) handleSavePreset(c *fiber.Ctx) error {
+	preset := prompts.Preset{
+		Name:        c.FormValue("name"),
+		Description: c.FormValue("description"),
+		Prompt:      c.FormValue("prompt"),
+	}
+	if err := s.prompts.Save(preset); err != nil {
+		return c.Status(400).SendString(err.Error())
+	}
+
+	return s.renderComponent(c, templates.PresetSelect(s.prompts.List(), strings.TrimSpace(preset.Name)))
 }
 
 // handleStatus returns budget and session status as JSON
@@ -234,11 +686,16 @@ func (s *Server) handleStatus(c *fiber.Ctx) error {
 			"should_prune":   budgetStatus.ShouldPrune,
 		},
 		"session": fiber.Map{
-			"total_requests":    sessionSummary.TotalRequests,
-			"success_rate":      sessionSummary.SuccessRate,
-			"estimated_cost":    sessionSummary.EstimatedCost,
-			"duration_seconds":  sessionSummary.Duration.Seconds(),
-			"avg_response_time": sessionSummary.AvgResponseTime,
+			"total_requests":     sessionSummary.TotalRequests,
+			"success_rate":       sessionSummary.SuccessRate,
+			"estimated_cost":     sessionSummary.EstimatedCost,
+			"duration_seconds":   sessionSummary.Duration.Seconds(),
+			"avg_response_time":  sessionSummary.AvgResponseTime,
+			"avg_ttft_ms":        sessionSummary.AvgTTFT,
+			"avg_tokens_per_sec": sessionSummary.AvgTokensPerSec,
+			"latency":            sessionSummary.Latency,
+			"latency_by_model":   sessionSummary.LatencyByModel,
+			"usage_by_model":     sessionSummary.UsageByModel,
 		},
 		"context": fiber.Map{
 			"total_messages":     contextStats.TotalMessages,
@@ -250,17 +707,127 @@ func (s *Server) handleStatus(c *fiber.Ctx) error {
 			"utilization_pct":    contextStats.UtilizationPct,
 			"should_prune":       contextStats.ShouldPrune,
 		},
+		"queue": fiber.Map{
+			"generating": session.Generating(),
+			"queued":     session.Queued(),
+		},
+		"capabilities": session.Capabilities(),
+		"features":     backend.EnabledFeatures(),
 	})
 }
 
-// Run starts the web server with graceful shutdown
+// handleTokens renders the per-message token breakdown of the current context
+func (s *Server) handleTokens(c *fiber.Ctx) error {
+	session, err := s.getOrCreateSession(c)
+	if err != nil {
+		return c.Status(500).SendString("Failed to get session: " + err.Error())
+	}
+
+	counts, exact := session.GetMessageTokens()
+	return s.renderComponent(c, templates.TokenBreakdown(counts, exact, session.GetContextStats().TokenLimit))
+}
+
+// handleUsage renders the header gauge of context utilization, budget, and cost.
+// The page reloads it when a response carries the usage-changed HX-Trigger event.
+func (s *Server) handleUsage(c *fiber.Ctx) error {
+	session, err := s.getOrCreateSession(c)
+	if err != nil {
+		return c.Status(500).SendString("Failed to get session: " + err.Error())
+	}
+
+	return s.renderComponent(c, templates.UsageGauge(session.GetBudgetStatus(), session.GetContextStats()))
+}
+
+// handleQueue renders how many requests of the session are waiting for the reply in
+// progress. The page polls it while a request is pending.
+func (s *Server) handleQueue(c *fiber.Ctx) error {
+	session, err := s.getOrCreateSession(c)
+	if err != nil {
+		return c.Status(500).SendString("Failed to get session: " + err.Error())
+	}
+
+	return s.renderComponent(c, templates.QueueStatus(session.Queued()))
+}
+
+// handleSearch full-text searches the conversations and logs of the caller's session,
+// in its tenant's store when it has one; other visitors' chats never show up
+func (s *Server) handleSearch(c *fiber.Ctx) error {
+	session, err := s.getOrCreateSession(c)
+	if err != nil {
+		return c.Status(500).SendString("Failed to get session: " + err.Error())
+	}
+
+	query := c.Query("q")
+	hits, err := session.SearchHistory(query, searchResultLimit)
+	if err != nil {
+		return c.Status(500).SendString("Search failed: " + err.Error())
+	}
+	return s.renderComponent(c, templates.SearchResults(query, hits))
+}
+
+// handleMetrics exposes process-wide metrics in the Prometheus text format
+func (s *Server) handleMetrics(c *fiber.Ctx) error {
+	c.Set("Content-Type", "text/plain; version=0.0.4; charset=utf-8")
+	if err := backend.DefaultLatencyRegistry.WritePrometheus(c.Response().BodyWriter()); err != nil {
+		return err
+	}
+	return s.metrics.WritePrometheus(c.Response().BodyWriter())
+}
+
+// Run starts the web server with graceful shutdown. address is a TCP address such as
+// ":3000" or a Unix socket path prefixed with "unix:"; a socket passed in by systemd
+// socket activation takes precedence over it.
 func (s *Server) Run(address string) error {
 	if address == "" {
 		address = defaultAddress
 	}
 
-	log.Printf("Starting web server on http://localhost%s", address)
-	log.Printf("Session management: enabled with %v max age", sessionMaxAge)
+	ln, err := listen(address)
+	if err != nil {
+		return fmt.Errorf("failed to listen on %s: %w", address, err)
+	}
+	if s.webConfig.Server == config.WebServerNetHTTP {
+		s.httpServer = s.newHTTPServer(address)
+	}
+
+	log.Printf("Starting web server on %s", describeListener(ln))
+	if addr, ok := ln.Addr().(*net.TCPAddr); ok && addr.IP.IsUnspecified() {
+		log.Printf("Listening on all interfaces: the web UI is reachable from other hosts")
+	}
+	log.Printf("Session management: enabled with %v max age, cleanup every %v",
+		sessionTTL(s.webConfig), cleanupInterval(s.webConfig))
+	if s.webConfig.MaxSessions > 0 {
+		log.Printf("Session limit: %d, evicting the least recently used", s.webConfig.MaxSessions)
+	}
 
-	return s.app.Listen(address)
+	// Save sessions before exiting so a restart or deploy doesn't lose them, and API
+	// token usage once the requests in flight have finished
+	stopped := make(chan struct{})
+	go func() {
+		defer close(stopped)
+		signals := make(chan os.Signal, 1)
+		signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+		<-signals
+		log.Printf("Shutting down")
+		s.saveSessions()
+		if err := s.shutdown(); err != nil {
+			log.Printf("Warning: %v", err)
+		}
+		s.flushAPIUsage()
+	}()
+	go s.reloadOnHangup()
+
+	if s.httpServer != nil {
+		err = s.httpServer.Serve(ln)
+		if errors.Is(err, http.ErrServerClosed) {
+			err = nil
+		}
+	} else {
+		err = s.app.Listener(ln)
+	}
+	if err != nil {
+		return err
+	}
+	<-stopped
+	return nil
 }
@@ -0,0 +1,137 @@
+package web
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"log"
+	"net/url"
+	"strconv"
+	"time"
+
+	"github.com/gofiber/fiber/v2"
+
+	"github.com/nleiva/chatgbt/internal/web/templates"
+	"github.com/nleiva/chatgbt/pkg/config"
+)
+
+const (
+	// maxShareTTL caps the lifetime a caller may request for a share link
+	maxShareTTL = 30 * 24 * time.Hour
+)
+
+// shareSigner signs and verifies expiring conversation links
+type shareSigner struct {
+	key []byte
+	ttl time.Duration
+}
+
+// newShareSigner uses the configured secret, or a random key when none is set
+func newShareSigner(webConfig config.WebConfig) *shareSigner {
+	key := []byte(webConfig.ShareSecret)
+	if len(key) == 0 {
+		key = make([]byte, 32)
+		if _, err := rand.Read(key); err != nil {
+			panic(fmt.Sprintf("failed to generate share key: %v", err))
+		}
+		log.Printf("SHARE_SECRET not set; share links will stop working when the server restarts")
+	}
+
+	ttl := webConfig.ShareTTL
+	if ttl <= 0 {
+		ttl = config.DefaultShareTTL
+	}
+	return &shareSigner{key: key, ttl: ttl}
+}
+
+// sign returns the signature for a link to a conversation of tenant, empty for none,
+// expiring at expires. Links without a tenant are signed as before tenants existed,
+// so those already handed out keep working.
+func (s *shareSigner) sign(id, tenant string, expires int64) string {
+	mac := hmac.New(sha256.New, s.key)
+	fmt.Fprintf(mac, "%s\n%d", id, expires)
+	if tenant != "" {
+		fmt.Fprintf(mac, "\n%s", tenant)
+	}
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// verify checks a link's signature and expiry
+func (s *shareSigner) verify(id, tenant, expiresStr, signature string) error {
+	expires, err := strconv.ParseInt(expiresStr, 10, 64)
+	if err != nil {
+		return errors.New("invalid link")
+	}
+	if !hmac.Equal([]byte(s.sign(id, tenant, expires)), []byte(signature)) {
+		return errors.New("invalid link")
+	}
+	if time.Now().Unix() > expires {
+		return errors.New("link expired")
+	}
+	return nil
+}
+
+// handleShare creates a signed, expiring read-only link to one of the session's own
+// conversations. An optional ttl form value (e.g. "24h") shortens or extends the
+// default lifetime up to maxShareTTL.
+func (s *Server) handleShare(c *fiber.Ctx) error {
+	session, conv, err := s.ownConversation(c)
+	if err != nil {
+		return err
+	}
+
+	ttl := s.share.ttl
+	if ttlStr := c.FormValue("ttl"); ttlStr != "" {
+		ttl, err = time.ParseDuration(ttlStr)
+		if err != nil || ttl <= 0 || ttl > maxShareTTL {
+			return c.Status(400).SendString(fmt.Sprintf("ttl must be a duration up to %v", maxShareTTL))
+		}
+	}
+
+	// The tenant is signed with the link, so it can't be swapped for another's store
+	expiresAt := time.Now().Add(ttl).Truncate(time.Second)
+	link := fmt.Sprintf("%s/shared/%s?expires=%d&sig=%s",
+		c.BaseURL(), conv.ID, expiresAt.Unix(), s.share.sign(conv.ID, session.Tenant, expiresAt.Unix()))
+	if session.Tenant != "" {
+		link += "&tenant=" + url.QueryEscape(session.Tenant)
+	}
+
+	if c.Get("HX-Request") == "true" {
+		return s.renderComponent(c, templates.ShareLink(link, expiresAt))
+	}
+	return c.JSON(fiber.Map{
+		"url":        link,
+		"expires_at": expiresAt,
+	})
+}
+
+// handleShared renders a shared conversation read-only, from the store of the tenant
+// the link was signed for. The page has no forms or HTMX endpoints, so visitors can't
+// make API calls through it.
+func (s *Server) handleShared(c *fiber.Ctx) error {
+	id, tenant := c.Params("id"), c.Query("tenant")
+	if err := s.share.verify(id, tenant, c.Query("expires"), c.Query("sig")); err != nil {
+		return c.Status(403).SendString(err.Error())
+	}
+
+	conversations := s.conversations
+	if tenant != "" {
+		var err error
+		if conversations, err = tenantStore(tenant); err != nil {
+			return c.Status(404).SendString("Conversation not found")
+		}
+	}
+	conv, err := conversations.Load(id)
+	if err != nil {
+		return c.Status(404).SendString("Conversation not found")
+	}
+
+	expires, _ := strconv.ParseInt(c.Query("expires"), 10, 64)
+	c.Set("Content-Type", htmlContentType)
+	c.Set("X-Robots-Tag", "noindex")
+	c.Set("Referrer-Policy", "no-referrer")
+	return s.renderComponent(c, templates.SharedConversationPage(conv, time.Unix(expires, 0), s.theme(c)))
+}
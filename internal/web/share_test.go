@@ -0,0 +1,78 @@
+package web
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/nleiva/chatgbt/pkg/config"
+)
+
+func TestShareSignerVerify(t *testing.T) {
+	signer := newShareSigner(config.WebConfig{ShareSecret: "secret"})
+	other := newShareSigner(config.WebConfig{ShareSecret: "other"})
+	future := time.Now().Add(time.Hour).Unix()
+	past := time.Now().Add(-time.Hour).Unix()
+
+	tests := []struct {
+		name      string
+		id        string // Verified ID; the link is always signed for "conv1"
+		tenant    string // Verified tenant; signedFor is the one the link was signed for
+		signedFor string
+		expires   int64
+		expiresIn string // Overrides the expires query value when set
+		signer    *shareSigner
+		wantErr   string
+	}{
+		{name: "valid", id: "conv1", expires: future},
+		{name: "valid for tenant", id: "conv1", tenant: "acme", signedFor: "acme", expires: future},
+		{name: "other conversation", id: "conv2", expires: future, wantErr: "invalid link"},
+		{name: "other tenant", id: "conv1", tenant: "globex", signedFor: "acme", expires: future, wantErr: "invalid link"},
+		{name: "tenant dropped", id: "conv1", signedFor: "acme", expires: future, wantErr: "invalid link"},
+		{name: "tenant added", id: "conv1", tenant: "acme", expires: future, wantErr: "invalid link"},
+		{name: "extended expiry", id: "conv1", expires: past, expiresIn: strconv.FormatInt(future, 10), wantErr: "invalid link"},
+		{name: "malformed expiry", id: "conv1", expires: future, expiresIn: "tomorrow", wantErr: "invalid link"},
+		{name: "other key", id: "conv1", expires: future, signer: other, wantErr: "invalid link"},
+		{name: "expired", id: "conv1", expires: past, wantErr: "link expired"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			s := signer
+			if tt.signer != nil {
+				s = tt.signer
+			}
+			signature := s.sign("conv1", tt.signedFor, tt.expires)
+			expires := strconv.FormatInt(tt.expires, 10)
+			if tt.expiresIn != "" {
+				expires = tt.expiresIn
+			}
+
+			err := signer.verify(tt.id, tt.tenant, expires, signature)
+			switch {
+			case tt.wantErr == "" && err != nil:
+				t.Errorf("verify = %v, want nil", err)
+			case tt.wantErr != "" && (err == nil || err.Error() != tt.wantErr):
+				t.Errorf("verify = %v, want %q", err, tt.wantErr)
+			}
+		})
+	}
+}
+
+// Links handed out before tenants existed were signed over the ID and expiry alone
+func TestShareSignerAcceptsLinksWithoutTenant(t *testing.T) {
+	signer := newShareSigner(config.WebConfig{ShareSecret: "secret"})
+	expires := time.Now().Add(time.Hour).Unix()
+
+	mac := hmac.New(sha256.New, []byte("secret"))
+	fmt.Fprintf(mac, "%s\n%d", "conv1", expires)
+	signature := hex.EncodeToString(mac.Sum(nil))
+
+	if err := signer.verify("conv1", "", strconv.FormatInt(expires, 10), signature); err != nil {
+		t.Errorf("verify = %v, want nil", err)
+	}
+}
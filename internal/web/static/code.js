@@ -0,0 +1,9 @@
+// Copies the code block containing the clicked button to the clipboard
+function copyCode(button) {
+    const code = button.parentElement.querySelector('code');
+    navigator.clipboard.writeText(code.innerText).then(function () {
+        const label = button.innerHTML;
+        button.textContent = button.dataset.copied;
+        setTimeout(function () { button.innerHTML = label; }, 1500);
+    });
+}
@@ -0,0 +1,7 @@
+//go:build inline_assets
+
+package static
+
+// Inline makes pages carry the assets in <style> and <script> elements instead of
+// linking to /static, so a page needs nothing else from the server
+const Inline = true
@@ -0,0 +1,75 @@
+// Keyboard handling for message inputs rendered with data-submit-on-enter: Enter sends,
+// Shift+Enter inserts a newline, the field grows with its content, and focus returns
+// to it after each response
+
+const maxInputHeight = 200;
+
+// Grows a textarea to fit its content, up to maxInputHeight
+function autoResize(textarea) {
+    textarea.style.height = 'auto';
+    textarea.style.height = Math.min(textarea.scrollHeight, maxInputHeight) + 'px';
+}
+
+// Clears a message form once its request finishes and puts the cursor back in it
+function resetInput(form) {
+    form.reset();
+    const field = form.querySelector('[data-submit-on-enter]');
+    if (field) {
+        autoResize(field);
+        field.focus();
+    }
+}
+
+// Returns the page's main message field
+function messageInput() {
+    return document.querySelector('[data-submit-on-enter]');
+}
+
+// Reports whether keystrokes on el are meant as text
+function isEditable(el) {
+    return el.isContentEditable || ['INPUT', 'TEXTAREA', 'SELECT'].includes(el.tagName);
+}
+
+document.addEventListener('keydown', function (evt) {
+    const target = evt.target;
+
+    if (target.matches('[data-submit-on-enter]')) {
+        // Leave Enter alone while an IME is composing text
+        if (evt.key === 'Enter' && !evt.shiftKey && !evt.isComposing) {
+            evt.preventDefault();
+            // Don't send twice while a response is still on its way
+            if (target.value.trim() !== '' && !target.form.classList.contains('htmx-request')) {
+                target.form.requestSubmit();
+            }
+        }
+        return;
+    }
+
+    // Escape closes an open dialog and returns to the message field
+    if (evt.key === 'Escape') {
+        const modal = document.querySelector('.modal.open');
+        if (modal) {
+            modal.classList.remove('open');
+            const field = messageInput();
+            if (field) {
+                field.focus();
+            }
+        }
+        return;
+    }
+
+    // "/" jumps to the message field from anywhere else on the page
+    if (evt.key === '/' && !isEditable(target) && !evt.ctrlKey && !evt.metaKey && !evt.altKey) {
+        const field = messageInput();
+        if (field) {
+            evt.preventDefault();
+            field.focus();
+        }
+    }
+});
+
+document.addEventListener('input', function (evt) {
+    if (evt.target.matches('[data-submit-on-enter]')) {
+        autoResize(evt.target);
+    }
+});
@@ -0,0 +1,7 @@
+//go:build !inline_assets
+
+package static
+
+// Inline makes pages carry the assets in <style> and <script> elements instead of
+// linking to /static; build with the inline_assets tag to turn it on
+const Inline = false
@@ -0,0 +1,16 @@
+// Package static holds the web UI stylesheet and scripts, compiled into the binary so
+// the server works from any directory
+package static
+
+import "embed"
+
+// Files holds the assets, served under /static or inlined into each page
+//
+//go:embed *.css *.js
+var Files embed.FS
+
+// Stylesheets and Scripts list the assets every page loads, in order
+var (
+	Stylesheets = []string{"style.css"}
+	Scripts     = []string{"code.js", "input.js"}
+)
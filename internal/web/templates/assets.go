@@ -0,0 +1,44 @@
+package templates
+
+import (
+	"context"
+	"fmt"
+	"io"
+
+	"github.com/a-h/templ"
+
+	"github.com/nleiva/chatgbt/internal/web/static"
+)
+
+// assets links the stylesheets and scripts from /static, or writes their contents into
+// the page when built with the inline_assets tag
+func assets() templ.Component {
+	return templ.ComponentFunc(func(ctx context.Context, w io.Writer) error {
+		for _, name := range static.Stylesheets {
+			if err := writeAsset(w, name, `<link href="/static/%s" rel="stylesheet"/>`, "<style>%s</style>"); err != nil {
+				return err
+			}
+		}
+		for _, name := range static.Scripts {
+			if err := writeAsset(w, name, `<script src="/static/%s" defer></script>`, "<script>%s</script>"); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// writeAsset writes the link format with the asset's name, or the inline format with its contents
+func writeAsset(w io.Writer, name, linkFormat, inlineFormat string) error {
+	if !static.Inline {
+		_, err := fmt.Fprintf(w, linkFormat, name)
+		return err
+	}
+
+	data, err := static.Files.ReadFile(name)
+	if err != nil {
+		return fmt.Errorf("failed to read asset %s: %w", name, err)
+	}
+	_, err = fmt.Fprintf(w, inlineFormat, data)
+	return err
+}
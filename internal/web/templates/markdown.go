@@ -0,0 +1,63 @@
+package templates
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"strings"
+
+	"github.com/russross/blackfriday/v2"
+)
+
+// markdownToHTML converts markdown text to HTML
+func markdownToHTML(ctx context.Context, markdown string) string {
+	extensions := blackfriday.CommonExtensions | blackfriday.AutoHeadingIDs | blackfriday.Footnotes
+	flags := blackfriday.CommonHTMLFlags | blackfriday.HrefTargetBlank
+	return renderMarkdown(ctx, markdown, extensions, flags)
+}
+
+// safeMarkdownToHTML converts markdown to HTML, dropping raw HTML and unsafe links, for
+// pages viewed by people other than the author
+func safeMarkdownToHTML(ctx context.Context, markdown string) string {
+	extensions := blackfriday.CommonExtensions | blackfriday.AutoHeadingIDs
+	flags := blackfriday.CommonHTMLFlags | blackfriday.HrefTargetBlank | blackfriday.SkipHTML |
+		blackfriday.Safelink | blackfriday.NofollowLinks | blackfriday.NoreferrerLinks
+	return renderMarkdown(ctx, markdown, extensions, flags)
+}
+
+// renderMarkdown runs blackfriday with fenced code blocks rendered by the CodeBlock component
+func renderMarkdown(ctx context.Context, markdown string, extensions blackfriday.Extensions, flags blackfriday.HTMLFlags) string {
+	renderer := &codeBlockRenderer{
+		HTMLRenderer: blackfriday.NewHTMLRenderer(blackfriday.HTMLRendererParameters{Flags: flags}),
+		ctx:          ctx,
+	}
+
+	html := blackfriday.Run([]byte(markdown), blackfriday.WithRenderer(renderer), blackfriday.WithExtensions(extensions))
+	return string(html)
+}
+
+// codeBlockRenderer adds a language label and copy button to code blocks
+type codeBlockRenderer struct {
+	*blackfriday.HTMLRenderer
+	ctx context.Context
+}
+
+// RenderNode renders code blocks with CodeBlock and everything else as plain HTML
+func (r *codeBlockRenderer) RenderNode(w io.Writer, node *blackfriday.Node, entering bool) blackfriday.WalkStatus {
+	if node.Type != blackfriday.CodeBlock {
+		return r.HTMLRenderer.RenderNode(w, node, entering)
+	}
+
+	// The info string is the text after the opening fence, e.g. "go" or "python title=x"
+	language := ""
+	if fields := strings.Fields(string(node.CodeBlockData.Info)); len(fields) > 0 {
+		language = fields[0]
+	}
+
+	var buf bytes.Buffer
+	if err := CodeBlock(language, string(node.Literal)).Render(r.ctx, &buf); err != nil {
+		return r.HTMLRenderer.RenderNode(w, node, entering)
+	}
+	w.Write(buf.Bytes())
+	return blackfriday.GoToNext
+}
@@ -0,0 +1,40 @@
+package web
+
+import (
+	"github.com/nleiva/chatgbt/internal/app"
+	"github.com/nleiva/chatgbt/pkg/backend"
+	"github.com/nleiva/chatgbt/pkg/config"
+	"github.com/nleiva/chatgbt/pkg/store"
+)
+
+// resolveTenant keeps each tenant's data in the user profile of the same name, as
+// --user does for single-user modes: conversations and logs in its directory, and
+// session budgets and daily cost limit from its budget.yaml on top of budget
+func resolveTenant(name string, budget backend.TokenBudgetConfig) (*app.Tenant, error) {
+	profile, err := config.LoadUserProfile(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := profile.ApplyBudget(&budget); err != nil {
+		return nil, err
+	}
+	dailyCost, err := profile.DailyCost()
+	if err != nil {
+		return nil, err
+	}
+	return &app.Tenant{
+		Name:      profile.Name,
+		Budget:    budget,
+		Store:     store.NewFileStore(profile.ConversationsDir()),
+		DailyCost: dailyCost,
+	}, nil
+}
+
+// tenantStore returns the conversations of the tenant called name, as resolveTenant keeps them
+func tenantStore(name string) (store.Store, error) {
+	profile, err := config.LoadUserProfile(name)
+	if err != nil {
+		return nil, err
+	}
+	return store.NewFileStore(profile.ConversationsDir()), nil
+}
@@ -0,0 +1,381 @@
+// Package apikeys manages the static API tokens that authorize calls to the web
+// server's JSON API. Only a hash of each token is stored; the token itself is shown
+// once, when it is created.
+package apikeys
+
+import (
+	"crypto/rand"
+	"crypto/sha256"
+	"crypto/subtle"
+	"encoding/hex"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// tokenPrefix starts every token, so leaked ones are easy to recognize and scan for
+const tokenPrefix = "cgb_"
+
+// Token describes an API token. The secret itself is never stored.
+type Token struct {
+	ID        string    `json:"id"` // Public part of the token, used to find and revoke it
+	Name      string    `json:"name"`
+	Tenant    string    `json:"tenant,omitempty"` // Whose sessions, data, and budget requests use; empty for the shared ones
+	Hash      string    `json:"hash"`             // SHA-256 of the whole token, hex encoded
+	CreatedAt time.Time `json:"created_at"`
+
+	TokenBudget       int `json:"token_budget,omitempty"`        // Model tokens the API token may spend in all; 0 for no limit
+	RequestsPerMinute int `json:"requests_per_minute,omitempty"` // 0 for no limit
+
+	TokensUsed int       `json:"tokens_used"`
+	Requests   int       `json:"requests"`
+	LastUsed   time.Time `json:"last_used,omitzero"`
+}
+
+// OverBudget reports whether the token has spent its token budget
+func (t Token) OverBudget() bool {
+	return t.TokenBudget > 0 && t.TokensUsed >= t.TokenBudget
+}
+
+// ErrInvalidToken is returned for tokens that are malformed, unknown, or revoked
+var ErrInvalidToken = errors.New("invalid API token")
+
+// RateLimitedError is returned when a token has used up its requests for the minute
+type RateLimitedError struct {
+	Limit      int           // Requests per minute
+	RetryAfter time.Duration // Until the next request is allowed
+}
+
+func (e *RateLimitedError) Error() string {
+	return fmt.Sprintf("API token rate limit of %d requests per minute reached; retry in %v",
+		e.Limit, e.RetryAfter.Round(time.Second))
+}
+
+// Store keeps tokens in a JSON file. The file is reread when another process, such as
+// "chatgbt tokens revoke", changes it, so a running server picks up new and revoked
+// tokens without a restart. Usage is kept in memory until Flush writes it.
+type Store struct {
+	mutex   sync.Mutex // Guards the fields below
+	path    string
+	tokens  map[string]*Token // By ID, as in the file
+	exists  bool              // Whether the file existed when last read or written
+	modTime time.Time         // Of the file when last read or written
+	windows map[string]*window
+	pending map[string]*usage // Usage recorded since the last Flush, by token ID
+}
+
+// window counts a token's requests in the current minute
+type window struct {
+	start time.Time
+	count int
+}
+
+// usage is what a token's requests used since the last Flush
+type usage struct {
+	requests int
+	tokens   int
+	lastUsed time.Time
+}
+
+// apply adds u to token's totals
+func (u *usage) apply(token *Token) {
+	if u == nil {
+		return
+	}
+	token.Requests += u.requests
+	token.TokensUsed += u.tokens
+	if u.lastUsed.After(token.LastUsed) {
+		token.LastUsed = u.lastUsed
+	}
+}
+
+// DefaultPath returns the default location of the token file
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "api_tokens.json"
+	}
+	return filepath.Join(dir, "chatgbt", "api_tokens.json")
+}
+
+// Open loads the tokens in path. A missing file is not an error. The store is returned
+// even on error, rejecting every token until the file can be read.
+func Open(path string) (*Store, error) {
+	s := &Store{
+		path:    path,
+		tokens:  make(map[string]*Token),
+		windows: make(map[string]*window),
+		pending: make(map[string]*usage),
+	}
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	return s, s.reload()
+}
+
+// reload rereads the file if it changed since it was last read; callers must hold the lock
+func (s *Store) reload() error {
+	info, err := os.Stat(s.path)
+	if errors.Is(err, os.ErrNotExist) {
+		s.tokens = make(map[string]*Token)
+		s.exists = false
+		s.modTime = time.Time{}
+		return nil
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read API tokens: %w", err)
+	}
+	if s.exists && info.ModTime().Equal(s.modTime) {
+		return nil
+	}
+
+	data, err := os.ReadFile(s.path)
+	if err != nil {
+		return fmt.Errorf("failed to read API tokens: %w", err)
+	}
+	var tokens []*Token
+	if err := json.Unmarshal(data, &tokens); err != nil {
+		return fmt.Errorf("failed to parse API tokens %s: %w", s.path, err)
+	}
+	s.tokens = make(map[string]*Token, len(tokens))
+	for _, t := range tokens {
+		s.tokens[t.ID] = t
+	}
+	s.exists = true
+	s.modTime = info.ModTime()
+	return nil
+}
+
+// persist writes the tokens to disk; callers must hold the lock
+func (s *Store) persist() error {
+	tokens := make([]*Token, 0, len(s.tokens))
+	for _, t := range s.tokens {
+		tokens = append(tokens, t)
+	}
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].CreatedAt.Before(tokens[j].CreatedAt) })
+
+	data, err := json.MarshalIndent(tokens, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode API tokens: %w", err)
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("failed to create API token directory: %w", err)
+	}
+	// Written to a temporary file first, so a crash never leaves a truncated file
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0600); err != nil {
+		return fmt.Errorf("failed to write API tokens: %w", err)
+	}
+	if err := os.Rename(tmp, s.path); err != nil {
+		return fmt.Errorf("failed to write API tokens: %w", err)
+	}
+	s.exists = true
+	if info, err := os.Stat(s.path); err == nil {
+		s.modTime = info.ModTime()
+	}
+	return nil
+}
+
+// Create adds a token for tenant, which may be empty, and returns it along with its
+// secret, which can't be recovered later
+func (s *Store) Create(name, tenant string, tokenBudget, requestsPerMinute int) (string, Token, error) {
+	name = strings.TrimSpace(name)
+	if name == "" {
+		return "", Token{}, fmt.Errorf("token name is required")
+	}
+	if tokenBudget < 0 || requestsPerMinute < 0 {
+		return "", Token{}, fmt.Errorf("token budget and rate limit can't be negative")
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.reload(); err != nil {
+		return "", Token{}, err
+	}
+	for _, t := range s.tokens {
+		if t.Name == name {
+			return "", Token{}, fmt.Errorf("a token named %q already exists", name)
+		}
+	}
+
+	id, err := randomHex(4)
+	if err != nil {
+		return "", Token{}, err
+	}
+	secret, err := randomHex(24)
+	if err != nil {
+		return "", Token{}, err
+	}
+	value := tokenPrefix + id + "_" + secret
+	token := &Token{
+		ID:                id,
+		Name:              name,
+		Tenant:            tenant,
+		Hash:              hash(value),
+		CreatedAt:         time.Now().UTC(),
+		TokenBudget:       tokenBudget,
+		RequestsPerMinute: requestsPerMinute,
+	}
+	s.tokens[id] = token
+	if err := s.persist(); err != nil {
+		delete(s.tokens, id)
+		return "", Token{}, err
+	}
+	return value, *token, nil
+}
+
+// List returns every token, oldest first, with the usage not flushed yet
+func (s *Store) List() ([]Token, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.reload(); err != nil {
+		return nil, err
+	}
+
+	tokens := make([]Token, 0, len(s.tokens))
+	for id, t := range s.tokens {
+		token := *t
+		s.pending[id].apply(&token)
+		tokens = append(tokens, token)
+	}
+	sort.Slice(tokens, func(i, j int) bool { return tokens[i].CreatedAt.Before(tokens[j].CreatedAt) })
+	return tokens, nil
+}
+
+// Enabled reports whether the API requires tokens: once the token file exists, even
+// with every token revoked, requests without a valid token are refused. Only before
+// the first token is created does the API need none.
+func (s *Store) Enabled() bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.reload(); err != nil {
+		// An unreadable file must not open the API
+		return true
+	}
+	return s.exists
+}
+
+// Revoke deletes the token with the given ID or name
+func (s *Store) Revoke(idOrName string) (Token, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.reload(); err != nil {
+		return Token{}, err
+	}
+
+	for id, t := range s.tokens {
+		if id == idOrName || t.Name == idOrName {
+			delete(s.tokens, id)
+			if err := s.persist(); err != nil {
+				s.tokens[id] = t
+				return Token{}, err
+			}
+			return *t, nil
+		}
+	}
+	return Token{}, fmt.Errorf("no API token with ID or name %q", idOrName)
+}
+
+// Authenticate checks value against the stored tokens and counts a request against
+// its per-minute limit. It returns ErrInvalidToken or a *RateLimitedError on refusal.
+func (s *Store) Authenticate(value string) (Token, error) {
+	rest, ok := strings.CutPrefix(value, tokenPrefix)
+	if !ok {
+		return Token{}, ErrInvalidToken
+	}
+	id, _, ok := strings.Cut(rest, "_")
+	if !ok {
+		return Token{}, ErrInvalidToken
+	}
+
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if err := s.reload(); err != nil {
+		return Token{}, err
+	}
+	token, ok := s.tokens[id]
+	if !ok || subtle.ConstantTimeCompare([]byte(hash(value)), []byte(token.Hash)) != 1 {
+		return Token{}, ErrInvalidToken
+	}
+
+	if token.RequestsPerMinute > 0 {
+		now := time.Now()
+		w := s.windows[id]
+		if w == nil || now.Sub(w.start) >= time.Minute {
+			w = &window{start: now}
+			s.windows[id] = w
+		}
+		if w.count >= token.RequestsPerMinute {
+			return Token{}, &RateLimitedError{Limit: token.RequestsPerMinute, RetryAfter: w.start.Add(time.Minute).Sub(now)}
+		}
+		w.count++
+	}
+	result := *token
+	s.pending[id].apply(&result)
+	return result, nil
+}
+
+// RecordUsage adds a request and the model tokens it used to the token's totals. They
+// count toward its budget at once, and are written to the file by the next Flush.
+func (s *Store) RecordUsage(id string, tokens int) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	u, ok := s.pending[id]
+	if !ok {
+		u = &usage{}
+		s.pending[id] = u
+	}
+	u.requests++
+	u.tokens += tokens
+	u.lastUsed = time.Now().UTC()
+}
+
+// Flush writes the usage recorded since the last Flush to the file. Usage of tokens
+// revoked in the meantime is dropped; on error it is kept for the next Flush.
+func (s *Store) Flush() error {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+	if len(s.pending) == 0 {
+		return nil
+	}
+	if err := s.reload(); err != nil {
+		return err
+	}
+
+	previous := make(map[string]Token, len(s.pending))
+	for id, u := range s.pending {
+		if token, ok := s.tokens[id]; ok {
+			previous[id] = *token
+			u.apply(token)
+		}
+	}
+	if err := s.persist(); err != nil {
+		for id, token := range previous {
+			*s.tokens[id] = token
+		}
+		return err
+	}
+	clear(s.pending)
+	return nil
+}
+
+// hash returns the stored form of a token
+func hash(value string) string {
+	sum := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(sum[:])
+}
+
+// randomHex returns n random bytes, hex encoded
+func randomHex(n int) (string, error) {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		return "", fmt.Errorf("failed to generate API token: %w", err)
+	}
+	return hex.EncodeToString(b), nil
+}
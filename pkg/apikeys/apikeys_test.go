@@ -0,0 +1,188 @@
+package apikeys
+
+import (
+	"errors"
+	"path/filepath"
+	"testing"
+)
+
+func TestAuthenticate(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "tokens.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, created, err := s.Create("ci", "class-a", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	revoked, _, err := s.Create("old", "", 0, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := s.Revoke("old"); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		name    string
+		value   string
+		wantErr error
+	}{
+		{name: "valid", value: value},
+		{name: "wrong secret", value: value[:len(value)-1] + "x", wantErr: ErrInvalidToken},
+		{name: "no prefix", value: value[len(tokenPrefix):], wantErr: ErrInvalidToken},
+		{name: "no secret", value: tokenPrefix + created.ID, wantErr: ErrInvalidToken},
+		{name: "unknown ID", value: tokenPrefix + "00000000_" + "secret", wantErr: ErrInvalidToken},
+		{name: "revoked", value: revoked, wantErr: ErrInvalidToken},
+		{name: "empty", value: "", wantErr: ErrInvalidToken},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			token, err := s.Authenticate(tt.value)
+			if !errors.Is(err, tt.wantErr) {
+				t.Fatalf("Authenticate() error = %v, want %v", err, tt.wantErr)
+			}
+			if err == nil && (token.ID != created.ID || token.Tenant != "class-a") {
+				t.Errorf("Authenticate() = %+v, want token %s of tenant class-a", token, created.ID)
+			}
+		})
+	}
+}
+
+func TestEnabled(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	steps := []struct {
+		name        string
+		do          func() error
+		wantEnabled bool
+	}{
+		{name: "no file", do: func() error { return nil }, wantEnabled: false},
+		{name: "token created", do: func() error { _, _, err := s.Create("ci", "", 0, 0); return err }, wantEnabled: true},
+		{name: "last token revoked", do: func() error { _, err := s.Revoke("ci"); return err }, wantEnabled: true},
+	}
+	for _, step := range steps {
+		if err := step.do(); err != nil {
+			t.Fatalf("%s: %v", step.name, err)
+		}
+		if got := s.Enabled(); got != step.wantEnabled {
+			t.Errorf("%s: Enabled() = %v, want %v", step.name, got, step.wantEnabled)
+		}
+	}
+
+	// Another store, such as the server's, sees the revocation in the file too
+	other, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !other.Enabled() {
+		t.Error("Enabled() = false for a token file with every token revoked")
+	}
+}
+
+func TestRateLimit(t *testing.T) {
+	s, err := Open(filepath.Join(t.TempDir(), "tokens.json"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, _, err := s.Create("ci", "", 0, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i, wantLimited := range []bool{false, false, true} {
+		_, err := s.Authenticate(value)
+		var limited *RateLimitedError
+		if got := errors.As(err, &limited); got != wantLimited {
+			t.Errorf("request %d: rate limited = %v (%v), want %v", i+1, got, err, wantLimited)
+		}
+	}
+}
+
+func TestRecordUsageAndFlush(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "tokens.json")
+	s, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	value, created, err := s.Create("ci", "", 100, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// usedInFile returns the ci token's usage as another process reading the file sees it
+	usedInFile := func() (requests, tokens int) {
+		t.Helper()
+		other, err := Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		list, err := other.List()
+		if err != nil {
+			t.Fatal(err)
+		}
+		for _, token := range list {
+			if token.ID == created.ID {
+				return token.Requests, token.TokensUsed
+			}
+		}
+		t.Fatalf("token %s missing from the file", created.ID)
+		return 0, 0
+	}
+
+	s.RecordUsage(created.ID, 60)
+	s.RecordUsage(created.ID, 50)
+	if requests, tokens := usedInFile(); requests != 0 || tokens != 0 {
+		t.Errorf("before Flush the file has %d requests, %d tokens; want none", requests, tokens)
+	}
+	token, err := s.Authenticate(value)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if token.TokensUsed != 110 || !token.OverBudget() {
+		t.Errorf("Authenticate() TokensUsed = %d, OverBudget = %v; want 110, true", token.TokensUsed, token.OverBudget())
+	}
+
+	// A token created by another process meanwhile is kept
+	other, err := Open(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, _, err := other.Create("other", "", 0, 0); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if requests, tokens := usedInFile(); requests != 2 || tokens != 110 {
+		t.Errorf("after Flush the file has %d requests, %d tokens; want 2, 110", requests, tokens)
+	}
+	if list, _ := other.List(); len(list) != 2 {
+		t.Errorf("after Flush the file has %d tokens, want 2", len(list))
+	}
+
+	// Flushing again adds nothing
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if requests, tokens := usedInFile(); requests != 2 || tokens != 110 {
+		t.Errorf("after a second Flush the file has %d requests, %d tokens; want 2, 110", requests, tokens)
+	}
+
+	// Usage of a token revoked before the flush is dropped
+	s.RecordUsage(created.ID, 5)
+	if _, err := other.Revoke("ci"); err != nil {
+		t.Fatal(err)
+	}
+	if err := s.Flush(); err != nil {
+		t.Fatal(err)
+	}
+	if list, _ := s.List(); len(list) != 1 || list[0].Name != "other" {
+		t.Errorf("after revoking ci the file has %+v, want only other", list)
+	}
+}
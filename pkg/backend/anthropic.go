@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 )
 
@@ -24,6 +25,16 @@ func (p *anthropicProvider) Name() string {
 	return "anthropic"
 }
 
+// Capabilities reports what Anthropic supports. The Messages API has no JSON mode.
+func (p *anthropicProvider) Capabilities() Capabilities {
+	return Capabilities{
+		Streaming:  true,
+		Tools:      true,
+		Vision:     modelSupportsVision(p.config.Model),
+		MaxContext: ModelContextWindow(p.config.Model),
+	}
+}
+
 // handleAnthropicError handles Anthropic-specific API error responses
 func (p *anthropicProvider) handleAnthropicError(statusCode int, body []byte) error {
 	var errorResp struct {
@@ -42,7 +53,8 @@ func (p *anthropicProvider) handleAnthropicError(statusCode int, body []byte) er
 		errorResp.Error.Type)
 }
 
-func (p *anthropicProvider) CreateCompletion(ctx context.Context, req *ChatCompletionRequest) (*ChatCompletionResponse, error) {
+// buildRequest creates the Anthropic request body for a chat completion
+func (p *anthropicProvider) buildRequest(req *ChatCompletionRequest) (map[string]interface{}, error) {
 	// Set up the model from config if not provided in request
 	model := req.Model
 	if model == "" {
@@ -75,7 +87,7 @@ func (p *anthropicProvider) CreateCompletion(ctx context.Context, req *ChatCompl
 	// Create the Anthropic request
 	anthropicReq := map[string]interface{}{
 		"model":    model,
-		"messages": conversationMessages,
+		"messages": anthropicMessages(conversationMessages),
 	}
 
 	if systemMessage != "" {
@@ -93,6 +105,119 @@ func (p *anthropicProvider) CreateCompletion(ctx context.Context, req *ChatCompl
 		anthropicReq["temperature"] = *req.Temperature
 	}
 
+	if len(req.Tools) > 0 {
+		anthropicReq["tools"] = anthropicTools(req.Tools)
+	}
+
+	if req.User != "" {
+		anthropicReq["metadata"] = map[string]string{"user_id": req.User}
+	}
+
+	return anthropicReq, nil
+}
+
+// anthropicMessages converts messages to Anthropic's format. Tool calls become tool_use
+// content blocks on the assistant message, and the results of consecutive tool messages
+// are sent together as tool_result blocks in one user message, as Anthropic expects
+// for parallel tool calls.
+func anthropicMessages(messages []Message) []map[string]interface{} {
+	converted := make([]map[string]interface{}, 0, len(messages))
+	var results []map[string]interface{} // tool_result blocks waiting to be sent
+	flushResults := func() {
+		if len(results) > 0 {
+			converted = append(converted, map[string]interface{}{
+				"role":    string(RoleUser),
+				"content": results,
+			})
+			results = nil
+		}
+	}
+
+	for _, msg := range messages {
+		if msg.Role == RoleTool {
+			results = append(results, map[string]interface{}{
+				"type":        "tool_result",
+				"tool_use_id": msg.ToolCallID,
+				"content":     msg.Content,
+			})
+			continue
+		}
+		flushResults()
+
+		if len(msg.ToolCalls) == 0 {
+			converted = append(converted, map[string]interface{}{
+				"role":    string(msg.Role),
+				"content": msg.Content,
+			})
+			continue
+		}
+
+		var blocks []map[string]interface{}
+		if msg.Content != "" {
+			blocks = append(blocks, map[string]interface{}{"type": "text", "text": msg.Content})
+		}
+		for _, call := range msg.ToolCalls {
+			input := json.RawMessage(call.Function.Arguments)
+			if len(input) == 0 || !json.Valid(input) {
+				input = json.RawMessage(`{}`)
+			}
+			blocks = append(blocks, map[string]interface{}{
+				"type":  "tool_use",
+				"id":    call.ID,
+				"name":  call.Function.Name,
+				"input": input,
+			})
+		}
+		converted = append(converted, map[string]interface{}{
+			"role":    string(RoleAssistant),
+			"content": blocks,
+		})
+	}
+	flushResults()
+
+	return converted
+}
+
+// anthropicTools converts tool definitions to Anthropic's tool format
+func anthropicTools(tools []ToolDefinition) []map[string]interface{} {
+	converted := make([]map[string]interface{}, 0, len(tools))
+	for _, tool := range tools {
+		schema := tool.Parameters
+		if len(schema) == 0 {
+			schema = json.RawMessage(`{"type":"object","properties":{}}`)
+		}
+		converted = append(converted, map[string]interface{}{
+			"name":         tool.Name,
+			"description":  tool.Description,
+			"input_schema": schema,
+		})
+	}
+	return converted
+}
+
+// anthropicContentBlock is a block of an Anthropic response: text, or a tool_use call
+type anthropicContentBlock struct {
+	Type  string          `json:"type"`
+	Text  string          `json:"text"`
+	ID    string          `json:"id"`
+	Name  string          `json:"name"`
+	Input json.RawMessage `json:"input"`
+}
+
+// anthropicToolCall converts a tool_use block to a tool call
+func anthropicToolCall(block anthropicContentBlock, arguments string) ToolCall {
+	if arguments == "" {
+		arguments = "{}"
+	}
+	return ToolCall{
+		ID:       block.ID,
+		Type:     "function",
+		Function: ToolCallFunction{Name: block.Name, Arguments: arguments},
+	}
+}
+
+// send posts the request body and returns the response once a 200 status is received
+func (p *anthropicProvider) send(ctx context.Context, anthropicReq map[string]interface{}) (*http.Response, error) {
 	// Marshal the request
 	reqBody, err := json.Marshal(anthropicReq)
 	if err != nil {
@@ -102,7 +227,7 @@ func (p *anthropicProvider) CreateCompletion(ctx context.Context, req *ChatCompl
 	// Set up URL
 	url := p.config.URL
 	if url == "" {
-		url = "https://api.anthropic.com/v1/messages"
+		url = AnthropicDefaultURL
 	}
 
 	// Create HTTP request
@@ -113,6 +238,7 @@ func (p *anthropicProvider) CreateCompletion(ctx context.Context, req *ChatCompl
 
 	// Set Anthropic-specific headers
 	httpReq.Header.Set("Content-Type", "application/json")
+	setRequestIDHeader(httpReq)
 	httpReq.Header.Set("x-api-key", p.config.APIKey)
 	httpReq.Header.Set("anthropic-version", "2023-06-01")
 
@@ -121,13 +247,37 @@ func (p *anthropicProvider) CreateCompletion(ctx context.Context, req *ChatCompl
 	if timeout <= 0 {
 		timeout = 30 * time.Second
 	}
-	client := &http.Client{Timeout: timeout}
+	client := newHTTPClient(timeout)
 
 	// Make the request
 	resp, err := client.Do(httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
+
+	// Handle errors
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+		return nil, &StatusError{StatusCode: resp.StatusCode, Err: p.handleAnthropicError(resp.StatusCode, body)}
+	}
+
+	return resp, nil
+}
+
+func (p *anthropicProvider) CreateCompletion(ctx context.Context, req *ChatCompletionRequest) (*ChatCompletionResponse, error) {
+	anthropicReq, err := p.buildRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.send(ctx, anthropicReq)
+	if err != nil {
+		return nil, err
+	}
 	defer resp.Body.Close()
 
 	// Read response body
@@ -136,23 +286,15 @@ func (p *anthropicProvider) CreateCompletion(ctx context.Context, req *ChatCompl
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
-	// Handle errors
-	if resp.StatusCode != http.StatusOK {
-		return nil, p.handleAnthropicError(resp.StatusCode, body)
-	}
-
 	// Parse Anthropic response format
 	var anthropicResp struct {
-		ID      string `json:"id"`
-		Type    string `json:"type"`
-		Role    string `json:"role"`
-		Content []struct {
-			Type string `json:"type"`
-			Text string `json:"text"`
-		} `json:"content"`
-		Model        string `json:"model"`
-		StopReason   string `json:"stop_reason"`
-		StopSequence string `json:"stop_sequence"`
+		ID           string                  `json:"id"`
+		Type         string                  `json:"type"`
+		Role         string                  `json:"role"`
+		Content      []anthropicContentBlock `json:"content"`
+		Model        string                  `json:"model"`
+		StopReason   string                  `json:"stop_reason"`
+		StopSequence string                  `json:"stop_sequence"`
 		Usage        struct {
 			InputTokens  int `json:"input_tokens"`
 			OutputTokens int `json:"output_tokens"`
@@ -164,9 +306,15 @@ func (p *anthropicProvider) CreateCompletion(ctx context.Context, req *ChatCompl
 	}
 
 	// Convert to standard format
-	var content string
-	if len(anthropicResp.Content) > 0 && anthropicResp.Content[0].Type == "text" {
-		content = anthropicResp.Content[0].Text
+	var content strings.Builder
+	var toolCalls []ToolCall
+	for _, block := range anthropicResp.Content {
+		switch block.Type {
+		case "text":
+			content.WriteString(block.Text)
+		case "tool_use":
+			toolCalls = append(toolCalls, anthropicToolCall(block, string(block.Input)))
+		}
 	}
 
 	response := &ChatCompletionResponse{
@@ -176,10 +324,11 @@ func (p *anthropicProvider) CreateCompletion(ctx context.Context, req *ChatCompl
 			{
 				Index: 0,
 				Message: Message{
-					Role:    RoleAssistant,
-					Content: content,
+					Role:      RoleAssistant,
+					Content:   content.String(),
+					ToolCalls: toolCalls,
 				},
-				FinishReason: anthropicResp.StopReason,
+				FinishReason: anthropicFinishReason(anthropicResp.StopReason),
 			},
 		},
 		Usage: &Usage{
@@ -191,3 +340,128 @@ func (p *anthropicProvider) CreateCompletion(ctx context.Context, req *ChatCompl
 
 	return response, nil
 }
+
+// CreateCompletionStream streams a chat completion using server-sent events
+func (p *anthropicProvider) CreateCompletionStream(ctx context.Context, req *ChatCompletionRequest, onDelta StreamHandler) (*ChatCompletionResponse, error) {
+	anthropicReq, err := p.buildRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	anthropicReq["stream"] = true
+
+	resp, err := p.send(ctx, anthropicReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	result := &ChatCompletionResponse{}
+	usage := &Usage{}
+	var content strings.Builder
+	var stopReason string
+
+	// tool_use blocks by content block index, with their input JSON assembled from deltas
+	type toolBlock struct {
+		block     anthropicContentBlock
+		arguments strings.Builder
+	}
+	var toolBlocks []*toolBlock
+	blocksByIndex := make(map[int]*toolBlock)
+
+	err = readServerSentEvents(resp.Body, func(_, data string) error {
+		var payload struct {
+			Type         string                `json:"type"`
+			Index        int                   `json:"index"`
+			ContentBlock anthropicContentBlock `json:"content_block"`
+			Message      struct {
+				ID    string `json:"id"`
+				Model string `json:"model"`
+				Usage struct {
+					InputTokens int `json:"input_tokens"`
+				} `json:"usage"`
+			} `json:"message"`
+			Delta struct {
+				Type        string `json:"type"`
+				Text        string `json:"text"`
+				PartialJSON string `json:"partial_json"`
+				StopReason  string `json:"stop_reason"`
+			} `json:"delta"`
+			Usage struct {
+				OutputTokens int `json:"output_tokens"`
+			} `json:"usage"`
+			Error struct {
+				Type    string `json:"type"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal([]byte(data), &payload); err != nil {
+			return fmt.Errorf("failed to decode stream event: %w", err)
+		}
+
+		switch payload.Type {
+		case "message_start":
+			result.ID = payload.Message.ID
+			result.Model = payload.Message.Model
+			usage.PromptTokens = payload.Message.Usage.InputTokens
+		case "content_block_start":
+			if payload.ContentBlock.Type == "tool_use" {
+				tb := &toolBlock{block: payload.ContentBlock}
+				toolBlocks = append(toolBlocks, tb)
+				blocksByIndex[payload.Index] = tb
+			}
+		case "content_block_delta":
+			switch payload.Delta.Type {
+			case "text_delta":
+				if payload.Delta.Text != "" {
+					content.WriteString(payload.Delta.Text)
+					if onDelta != nil {
+						onDelta(payload.Delta.Text)
+					}
+				}
+			case "input_json_delta":
+				if tb, ok := blocksByIndex[payload.Index]; ok {
+					tb.arguments.WriteString(payload.Delta.PartialJSON)
+				}
+			}
+		case "message_delta":
+			stopReason = payload.Delta.StopReason
+			usage.CompletionTokens = payload.Usage.OutputTokens
+		case "message_stop":
+			return io.EOF
+		case "error":
+			return fmt.Errorf("Anthropic stream error: %s (type: %s)", payload.Error.Message, payload.Error.Type)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stream: %w", err)
+	}
+
+	var toolCalls []ToolCall
+	for _, tb := range toolBlocks {
+		toolCalls = append(toolCalls, anthropicToolCall(tb.block, tb.arguments.String()))
+	}
+
+	usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+	result.Usage = usage
+	result.Choices = []Choice{{
+		Index:        0,
+		Message:      Message{Role: RoleAssistant, Content: content.String(), ToolCalls: toolCalls},
+		FinishReason: anthropicFinishReason(stopReason),
+	}}
+
+	return result, nil
+}
+
+// anthropicFinishReason maps an Anthropic stop_reason to the OpenAI finish reason
+func anthropicFinishReason(stopReason string) string {
+	switch stopReason {
+	case "end_turn", "stop_sequence":
+		return FinishStop
+	case "max_tokens":
+		return FinishLength
+	case "tool_use":
+		return FinishToolCalls
+	}
+	return stopReason
+}
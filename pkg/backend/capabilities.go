@@ -0,0 +1,93 @@
+package backend
+
+import "strings"
+
+// Capabilities describes what a provider and its configured model support, so callers
+// can adapt requests up front instead of failing at request time
+type Capabilities struct {
+	Streaming  bool `json:"streaming"`   // Completions can be streamed token by token
+	Tools      bool `json:"tools"`       // The model can call tools
+	Vision     bool `json:"vision"`      // The model accepts images
+	JSONMode   bool `json:"json_mode"`   // The API can constrain replies to a JSON object
+	MaxContext int  `json:"max_context"` // Context window in tokens; 0 when unknown
+}
+
+// modelContextWindows maps model name prefixes to their context windows. Longer
+// prefixes are listed before shorter ones they share a start with.
+var modelContextWindows = []struct {
+	prefix string
+	tokens int
+}{
+	{"gpt-5", 400000},
+	{"gpt-4.1", 1047576},
+	{"gpt-4o", 128000},
+	{"gpt-4-turbo", 128000},
+	{"gpt-4", 8192},
+	{"gpt-3.5-turbo", 16385},
+	{"o1-mini", 128000},
+	{"o1", 200000},
+	{"o3", 200000},
+	{"o4", 200000},
+	{"claude", 200000},
+	{"llama3.1", 128000},
+	{"llama3.2", 128000},
+	{"llama3.3", 128000},
+	{"llama3", 8192},
+	{"llama-3.", 131072},
+	{"mistral-small", 131072},
+	{"mistral-medium", 131072},
+	{"mistral-large", 131072},
+	{"mistral", 32768},
+	{"deepseek", 65536},
+	{"gemini-1.5-pro", 2097152},
+	{"gemini", 1048576},
+	{"grok-4", 256000},
+	{"grok-2-vision", 32768},
+	{"grok", 131072},
+	{"qwen2.5", 32768},
+}
+
+// visionModelPrefixes are models that accept image input
+var visionModelPrefixes = []string{
+	"gpt-5", "gpt-4.1", "gpt-4o", "gpt-4-turbo", "o1", "o3", "o4",
+	"claude-3", "claude-sonnet", "claude-opus", "claude-haiku",
+	"llava", "llama3.2-vision", "gemma3", "pixtral",
+	"gemini", "grok-4", "grok-2-vision",
+}
+
+// ModelContextWindow returns the context window of model in tokens, or 0 when unknown.
+// Vendor prefixes used by routers such as OpenRouter ("openai/gpt-4o") are ignored.
+func ModelContextWindow(model string) int {
+	model = baseModelName(model)
+	for _, w := range modelContextWindows {
+		if strings.HasPrefix(model, w.prefix) {
+			return w.tokens
+		}
+	}
+	return 0
+}
+
+// modelSupportsVision reports whether model is known to accept images
+func modelSupportsVision(model string) bool {
+	model = baseModelName(model)
+	for _, prefix := range visionModelPrefixes {
+		if strings.HasPrefix(model, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// baseModelName lowercases model and drops any vendor prefix
+func baseModelName(model string) string {
+	model = strings.ToLower(model)
+	if i := strings.LastIndex(model, "/"); i >= 0 {
+		model = model[i+1:]
+	}
+	return model
+}
+
+// JSONModeInstruction is added to the prompt in place of JSON mode when a provider
+// can't constrain its replies to JSON
+const JSONModeInstruction = "Respond only with a single valid JSON object. " +
+	"Do not wrap it in a code block or add any text before or after it."
@@ -10,6 +10,15 @@ type ContextManager struct {
 	maxTokens      int
 	keepRecent     int // Number of recent exchanges to always keep
 	summaryEnabled bool
+
+	// keepRecentTokens, when positive, replaces keepRecent: pruning keeps as many recent
+	// messages as fit in this many estimated tokens
+	keepRecentTokens int
+
+	// relevance, when set, rescues up to keepRelevant older exchanges from pruning,
+	// picking those most relevant to the latest user message
+	relevance    RelevanceScorer
+	keepRelevant int
 }
 
 // NewContextManager creates a new context manager
@@ -21,19 +30,55 @@ func NewContextManager(maxTokens, keepRecent int, summaryEnabled bool) *ContextM
 	}
 }
 
+// SetKeepRecentTokens makes pruning keep as many recent messages as fit in a reserve of
+// tokens, however many exchanges that is, instead of a fixed number of exchanges. The
+// latest message is always kept. 0 goes back to keeping exchanges.
+func (cm *ContextManager) SetKeepRecentTokens(tokens int) {
+	cm.keepRecentTokens = tokens
+}
+
+// PruneReport describes what a pruning pass removed from the context
+type PruneReport struct {
+	Dropped      []MessageTokens // Removed messages, indexed by their position before pruning, with estimated tokens
+	Summary      string          // Summary note added in their place; empty when summaries are disabled
+	TokensBefore int             // Estimated context tokens before pruning
+	TokensAfter  int             // Estimated context tokens after pruning
+}
+
+// Reclaimed returns the estimated tokens freed by pruning
+func (r *PruneReport) Reclaimed() int {
+	return r.TokensBefore - r.TokensAfter
+}
+
 // PruneContext reduces message array size when approaching token limits
 func (cm *ContextManager) PruneContext(messages []Message, currentTokens int) ([]Message, bool) {
+	pruned, report := cm.Prune(messages, currentTokens)
+	return pruned, report != nil
+}
+
+// Prune is PruneContext with a report of the messages it dropped; the report is nil
+// when nothing was pruned
+func (cm *ContextManager) Prune(messages []Message, currentTokens int) ([]Message, *PruneReport) {
+	return cm.PruneFor(messages, currentTokens, lastUserMessage(messages))
+}
+
+// PruneFor is Prune for a context about to receive query, against which older
+// exchanges are scored when relevance pruning is on
+func (cm *ContextManager) PruneFor(messages []Message, currentTokens int, query string) ([]Message, *PruneReport) {
 	if currentTokens <= cm.maxTokens {
-		return messages, false
+		return messages, nil
 	}
 
-	// Always keep system message if present
-	systemMessage := Message{}
+	// Always keep the system message and any pinned messages (e.g. few-shot examples)
+	// that directly follow it
 	startIdx := 0
 	if len(messages) > 0 && messages[0].Role == RoleSystem {
-		systemMessage = messages[0]
 		startIdx = 1
 	}
+	for startIdx < len(messages) && messages[startIdx].Pinned {
+		startIdx++
+	}
+	protected := messages[:startIdx]
 
 	// Calculate how many recent exchanges to keep (user + assistant pairs)
 	// Keep at least the last few exchanges for context continuity
@@ -42,39 +87,102 @@ func (cm *ContextManager) PruneContext(messages []Message, currentTokens int) ([
 		userMessages = append(userMessages, messages[i])
 	}
 
-	// If we have fewer messages than keepRecent*2, just return original
-	if len(userMessages) <= cm.keepRecent*2 {
-		return messages, false
+	// Strategy 1: Keep system + recent exchanges, or recent messages within the token reserve
+	recentStart := keepToolCallsWhole(userMessages, cm.recentStart(userMessages))
+	if recentStart == 0 {
+		return messages, nil
 	}
 
-	// Strategy 1: Keep system + recent exchanges
-	recentStart := len(userMessages) - (cm.keepRecent * 2)
-	if recentStart < 0 {
-		recentStart = 0
+	// Strategy 2: Rescue the older exchanges most relevant to the query, within a budget
+	// that leaves a quarter of the limit free for the conversation to grow
+	older := userMessages[:recentStart]
+	budget := cm.maxTokens*3/4 - cm.EstimateTokens(protected) - cm.EstimateTokens(userMessages[recentStart:])
+	rescued := cm.rescueRelevant(older, query, budget)
+
+	var kept, dropped []Message
+	report := &PruneReport{TokensBefore: cm.EstimateTokens(messages)}
+	for i, msg := range older {
+		if rescued[i] {
+			kept = append(kept, msg)
+			continue
+		}
+		dropped = append(dropped, msg)
+		report.Dropped = append(report.Dropped, MessageTokens{
+			Index:   startIdx + i,
+			Role:    msg.Role,
+			Tokens:  cm.EstimateTokens([]Message{msg}),
+			Preview: preview(msg.Content, 60),
+		})
+	}
+	if len(dropped) == 0 {
+		return messages, nil
 	}
 
 	prunedMessages := make([]Message, 0)
 
-	// Add system message back if it existed
-	if systemMessage.Content != "" {
-		prunedMessages = append(prunedMessages, systemMessage)
-	}
+	// Add system and pinned messages back
+	prunedMessages = append(prunedMessages, protected...)
 
 	// Add summary of pruned content if enabled
-	if cm.summaryEnabled && recentStart > 0 {
-		summaryContent := cm.createSummary(userMessages[:recentStart])
+	if cm.summaryEnabled {
+		summaryContent := cm.createSummary(dropped)
 		if summaryContent != "" {
+			report.Summary = fmt.Sprintf("Previous conversation summary: %s", summaryContent)
 			prunedMessages = append(prunedMessages, Message{
 				Role:    RoleSystem,
-				Content: fmt.Sprintf("Previous conversation summary: %s", summaryContent),
+				Content: report.Summary,
 			})
 		}
 	}
 
-	// Add recent messages
+	// Add rescued and recent messages
+	prunedMessages = append(prunedMessages, kept...)
 	prunedMessages = append(prunedMessages, userMessages[recentStart:]...)
+	report.TokensAfter = cm.EstimateTokens(prunedMessages)
+
+	return prunedMessages, report
+}
+
+// recentStart returns the index of the first of messages that pruning keeps
+func (cm *ContextManager) recentStart(messages []Message) int {
+	if cm.keepRecentTokens <= 0 {
+		// If we have fewer messages than keepRecent*2, nothing is dropped
+		return max(len(messages)-cm.keepRecent*2, 0)
+	}
 
-	return prunedMessages, true
+	start, total := len(messages), 0
+	for start > 0 {
+		tokens := cm.EstimateTokens(messages[start-1 : start])
+		if total+tokens > cm.keepRecentTokens && start < len(messages) {
+			break
+		}
+		start--
+		total += tokens
+	}
+	return start
+}
+
+// keepToolCallsWhole moves a pruning boundary so it never falls between an assistant
+// message with tool calls and the tool results answering it, which providers reject.
+// The boundary moves back to keep the whole group when that still drops something,
+// and otherwise past the group, dropping it whole.
+func keepToolCallsWhole(messages []Message, boundary int) int {
+	if boundary >= len(messages) || messages[boundary].Role != RoleTool {
+		return boundary
+	}
+
+	caller := boundary
+	for caller > 0 && messages[caller].Role == RoleTool {
+		caller--
+	}
+	if caller > 0 && len(messages[caller].ToolCalls) > 0 {
+		return caller
+	}
+
+	for boundary < len(messages) && messages[boundary].Role == RoleTool {
+		boundary++
+	}
+	return boundary
 }
 
 // createSummary creates a simple summary of pruned messages
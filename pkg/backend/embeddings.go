@@ -0,0 +1,99 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// DefaultEmbeddingModel is used when no embedding model is configured
+const DefaultEmbeddingModel = "text-embedding-3-small"
+
+// Embedder turns texts into embedding vectors
+type Embedder interface {
+	Embed(ctx context.Context, texts []string) ([][]float64, error)
+}
+
+// EmbeddingClient calls an OpenAI-compatible /v1/embeddings endpoint
+type EmbeddingClient struct {
+	apiKey string
+	url    string
+	model  string
+	client *http.Client
+}
+
+// NewEmbeddingClient creates a client for the embeddings endpoint next to chatURL,
+// e.g. https://api.openai.com/v1/chat/completions -> https://api.openai.com/v1/embeddings
+func NewEmbeddingClient(apiKey, chatURL, model string) *EmbeddingClient {
+	if model == "" {
+		model = DefaultEmbeddingModel
+	}
+	url := strings.TrimSuffix(chatURL, "/chat/completions") + "/embeddings"
+	return &EmbeddingClient{
+		apiKey: apiKey,
+		url:    url,
+		model:  model,
+		client: newHTTPClient(10 * time.Second),
+	}
+}
+
+// Embed returns one vector per text, in order
+func (c *EmbeddingClient) Embed(ctx context.Context, texts []string) ([][]float64, error) {
+	reqBody, err := json.Marshal(map[string]interface{}{
+		"model": c.model,
+		"input": texts,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal request: %w", err)
+	}
+
+	httpReq, err := http.NewRequestWithContext(ctx, "POST", c.url, bytes.NewReader(reqBody))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create request: %w", err)
+	}
+	httpReq.Header.Set("Content-Type", "application/json")
+	if c.apiKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.apiKey)
+	}
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return nil, fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("embeddings API error %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var embeddingResp struct {
+		Data []struct {
+			Index     int       `json:"index"`
+			Embedding []float64 `json:"embedding"`
+		} `json:"data"`
+	}
+	if err := json.Unmarshal(body, &embeddingResp); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	if len(embeddingResp.Data) != len(texts) {
+		return nil, fmt.Errorf("embeddings API returned %d vectors for %d inputs", len(embeddingResp.Data), len(texts))
+	}
+
+	vectors := make([][]float64, len(texts))
+	for _, d := range embeddingResp.Data {
+		if d.Index < 0 || d.Index >= len(vectors) {
+			return nil, fmt.Errorf("embeddings API returned out-of-range index %d", d.Index)
+		}
+		vectors[d.Index] = d.Embedding
+	}
+	return vectors, nil
+}
@@ -0,0 +1,51 @@
+package backend
+
+import "sync"
+
+// Feature names a subsystem operators can turn off for a deployment, such as one still
+// settling in
+type Feature string
+
+const (
+	FeatureStreaming Feature = "streaming" // Replies are streamed from the provider as they are generated
+	FeatureTools     Feature = "tools"     // The model may call tools
+	FeatureRelevance Feature = "relevance" // Pruning keeps the older exchanges most relevant to the message, by embeddings
+	FeatureRouting   Feature = "routing"   // Messages matching the ROUTER_FILE rules go to other models
+)
+
+// Features lists every feature, in the order they are reported
+var Features = []Feature{FeatureStreaming, FeatureTools, FeatureRelevance, FeatureRouting}
+
+// FeatureSet tells which features are on; those it doesn't list are on
+type FeatureSet map[Feature]bool
+
+// featureSwitches holds the features in effect; see SetFeatures
+var featureSwitches struct {
+	mutex sync.RWMutex
+	set   FeatureSet
+}
+
+// SetFeatures replaces the features in effect for the whole process. It can be called
+// at any time; requests already in flight are unaffected.
+func SetFeatures(set FeatureSet) {
+	featureSwitches.mutex.Lock()
+	defer featureSwitches.mutex.Unlock()
+	featureSwitches.set = set
+}
+
+// FeatureEnabled reports whether feature is on
+func FeatureEnabled(feature Feature) bool {
+	featureSwitches.mutex.RLock()
+	defer featureSwitches.mutex.RUnlock()
+	enabled, ok := featureSwitches.set[feature]
+	return enabled || !ok
+}
+
+// EnabledFeatures reports whether each feature is on
+func EnabledFeatures() FeatureSet {
+	set := make(FeatureSet, len(Features))
+	for _, feature := range Features {
+		set[feature] = FeatureEnabled(feature)
+	}
+	return set
+}
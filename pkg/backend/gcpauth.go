@@ -0,0 +1,223 @@
+package backend
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"runtime"
+	"strings"
+	"sync"
+	"time"
+)
+
+const (
+	// gcpScope grants access to Vertex AI and other Google Cloud APIs
+	gcpScope = "https://www.googleapis.com/auth/cloud-platform"
+	// gcpTokenURL exchanges credentials for access tokens
+	gcpTokenURL = "https://oauth2.googleapis.com/token"
+	// gcpMetadataTokenURL serves tokens for the attached service account on Google Cloud
+	gcpMetadataTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+	// gcpTokenRefreshMargin renews tokens this long before they expire
+	gcpTokenRefreshMargin = time.Minute
+)
+
+// gcpCredentials is a service account key or the user credentials written by
+// "gcloud auth application-default login"
+type gcpCredentials struct {
+	Type         string `json:"type"` // "service_account" or "authorized_user"
+	ClientEmail  string `json:"client_email"`
+	PrivateKey   string `json:"private_key"`
+	TokenURI     string `json:"token_uri"`
+	ClientID     string `json:"client_id"`
+	ClientSecret string `json:"client_secret"`
+	RefreshToken string `json:"refresh_token"`
+}
+
+// gcpTokenSource fetches and caches Google Cloud access tokens using Application
+// Default Credentials: GOOGLE_APPLICATION_CREDENTIALS, then gcloud's well-known file,
+// then the metadata server when running on Google Cloud
+type gcpTokenSource struct {
+	mutex   sync.Mutex
+	creds   *gcpCredentials // nil when tokens come from the metadata server
+	token   string
+	expires time.Time
+	client  *http.Client
+}
+
+// newGCPTokenSource finds Application Default Credentials
+func newGCPTokenSource() (*gcpTokenSource, error) {
+	source := &gcpTokenSource{client: &http.Client{Timeout: 30 * time.Second}}
+
+	path := os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	if path == "" {
+		if wellKnown := gcloudCredentialsPath(); wellKnown != "" {
+			if _, err := os.Stat(wellKnown); err == nil {
+				path = wellKnown
+			}
+		}
+	}
+	if path == "" {
+		return source, nil // Fall back to the metadata server
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read Google credentials: %w", err)
+	}
+	var creds gcpCredentials
+	if err := json.Unmarshal(data, &creds); err != nil {
+		return nil, fmt.Errorf("failed to parse Google credentials %s: %w", path, err)
+	}
+	if creds.Type != "service_account" && creds.Type != "authorized_user" {
+		return nil, fmt.Errorf("unsupported Google credentials type %q in %s", creds.Type, path)
+	}
+	if creds.TokenURI == "" {
+		creds.TokenURI = gcpTokenURL
+	}
+	source.creds = &creds
+	return source, nil
+}
+
+// gcloudCredentialsPath is where "gcloud auth application-default login" saves credentials
+func gcloudCredentialsPath() string {
+	if runtime.GOOS == "windows" {
+		return filepath.Join(os.Getenv("APPDATA"), "gcloud", "application_default_credentials.json")
+	}
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return ""
+	}
+	return filepath.Join(home, ".config", "gcloud", "application_default_credentials.json")
+}
+
+// Token returns a valid access token, fetching a new one when the cached one is about to expire
+func (s *gcpTokenSource) Token(ctx context.Context) (string, error) {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if s.token != "" && time.Now().Add(gcpTokenRefreshMargin).Before(s.expires) {
+		return s.token, nil
+	}
+
+	var req *http.Request
+	var err error
+	switch {
+	case s.creds == nil:
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, gcpMetadataTokenURL, nil)
+		if err == nil {
+			req.Header.Set("Metadata-Flavor", "Google")
+		}
+	case s.creds.Type == "service_account":
+		var assertion string
+		assertion, err = s.creds.signedJWT()
+		if err == nil {
+			req, err = tokenRequest(ctx, s.creds.TokenURI, url.Values{
+				"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+				"assertion":  {assertion},
+			})
+		}
+	default:
+		req, err = tokenRequest(ctx, s.creds.TokenURI, url.Values{
+			"grant_type":    {"refresh_token"},
+			"client_id":     {s.creds.ClientID},
+			"client_secret": {s.creds.ClientSecret},
+			"refresh_token": {s.creds.RefreshToken},
+		})
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to create Google token request: %w", err)
+	}
+
+	resp, err := s.client.Do(req)
+	if err != nil {
+		if s.creds == nil {
+			return "", fmt.Errorf("no Google credentials found: set GOOGLE_APPLICATION_CREDENTIALS or run "+
+				"'gcloud auth application-default login' (metadata server: %w)", err)
+		}
+		return "", fmt.Errorf("Google token request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return "", fmt.Errorf("failed to read Google token response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return "", fmt.Errorf("Google token request failed with status %d: %s", resp.StatusCode, strings.TrimSpace(string(body)))
+	}
+
+	var token struct {
+		AccessToken string `json:"access_token"`
+		ExpiresIn   int    `json:"expires_in"`
+	}
+	if err := json.Unmarshal(body, &token); err != nil {
+		return "", fmt.Errorf("failed to decode Google token response: %w", err)
+	}
+	if token.AccessToken == "" {
+		return "", errors.New("Google token response has no access token")
+	}
+
+	s.token = token.AccessToken
+	s.expires = time.Now().Add(time.Duration(token.ExpiresIn) * time.Second)
+	return s.token, nil
+}
+
+// tokenRequest builds a form POST to an OAuth token endpoint
+func tokenRequest(ctx context.Context, tokenURL string, form url.Values) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	return req, nil
+}
+
+// signedJWT creates the RS256-signed assertion a service account exchanges for a token
+func (c *gcpCredentials) signedJWT() (string, error) {
+	block, _ := pem.Decode([]byte(c.PrivateKey))
+	if block == nil {
+		return "", errors.New("service account private key is not PEM encoded")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		parsed, err = x509.ParsePKCS1PrivateKey(block.Bytes)
+	}
+	if err != nil {
+		return "", fmt.Errorf("failed to parse service account private key: %w", err)
+	}
+	key, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return "", errors.New("service account private key is not an RSA key")
+	}
+
+	now := time.Now()
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iss":   c.ClientEmail,
+		"scope": gcpScope,
+		"aud":   c.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, key, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", fmt.Errorf("failed to sign service account assertion: %w", err)
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
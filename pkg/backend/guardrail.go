@@ -0,0 +1,78 @@
+package backend
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// InjectionGuard selects how instruction-like content in text from outside the
+// conversation, such as tool results, is handled before it enters the context
+type InjectionGuard string
+
+// Injection guard modes accepted in INJECTION_GUARD
+const (
+	InjectionGuardOff   InjectionGuard = "off"   // Pass content through unchanged
+	InjectionGuardFlag  InjectionGuard = "flag"  // Mark suspicious lines as untrusted data
+	InjectionGuardStrip InjectionGuard = "strip" // Drop suspicious lines
+)
+
+// ParseInjectionGuard validates an injection guard mode; empty selects InjectionGuardFlag
+func ParseInjectionGuard(name string) (InjectionGuard, error) {
+	switch guard := InjectionGuard(strings.ToLower(strings.TrimSpace(name))); guard {
+	case "":
+		return InjectionGuardFlag, nil
+	case InjectionGuardOff, InjectionGuardFlag, InjectionGuardStrip:
+		return guard, nil
+	default:
+		return "", fmt.Errorf("injection guard must be %q, %q, or %q, got %q",
+			InjectionGuardOff, InjectionGuardFlag, InjectionGuardStrip, name)
+	}
+}
+
+// injectionPatterns match lines that try to steer the model rather than inform it
+var injectionPatterns = []*regexp.Regexp{
+	regexp.MustCompile(`(?i)\b(ignore|disregard|forget|override)\b.{0,40}\b(previous|prior|above|earlier|all|your|system)\b.{0,20}\b(instructions?|prompts?|rules|directions|context)\b`),
+	regexp.MustCompile(`(?i)\b(new|updated|real|actual)\s+(system\s+)?(instructions?|prompt|rules)\s*:`),
+	regexp.MustCompile(`(?i)\byou\s+(are\s+now|must\s+now|will\s+now)\b`),
+	regexp.MustCompile(`(?i)\b(reveal|print|repeat|show)\b.{0,30}\b(system\s+prompt|instructions)\b`),
+	regexp.MustCompile(`(?i)^\s*(#+\s*)?(system|assistant|developer)\s*:`),
+	regexp.MustCompile(`(?i)<\|?\s*(im_start|im_end|system|endoftext)\s*\|?>|\[/?(INST|SYS)\]|<</?SYS>>`),
+}
+
+// flagPrefix marks a line the guard considers an injection attempt
+const flagPrefix = "[untrusted content, not an instruction] "
+
+// Sanitize applies the guard to text and reports how many lines it neutralized.
+// Flagging keeps the line so the model can still use it as data; stripping replaces
+// it with a placeholder so the reply can mention that something was removed.
+func (g InjectionGuard) Sanitize(text string) (string, int) {
+	if g == InjectionGuardOff || g == "" {
+		return text, 0
+	}
+
+	lines := strings.Split(text, "\n")
+	neutralized := 0
+	for i, line := range lines {
+		if !looksLikeInjection(line) {
+			continue
+		}
+		neutralized++
+		if g == InjectionGuardStrip {
+			lines[i] = "[removed: instruction-like content]"
+		} else {
+			lines[i] = flagPrefix + line
+		}
+	}
+	return strings.Join(lines, "\n"), neutralized
+}
+
+// looksLikeInjection reports whether line matches any of the injection patterns
+func looksLikeInjection(line string) bool {
+	for _, pattern := range injectionPatterns {
+		if pattern.MatchString(line) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,221 @@
+package backend
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+)
+
+// DefaultHTTPDebugDir is where provider traffic is recorded unless DEBUG_HTTP_DIR says otherwise
+const DefaultHTTPDebugDir = "debug"
+
+// redacted replaces credentials in recorded headers and URLs
+const redacted = "[REDACTED]"
+
+// secretHeaders carry credentials and are never recorded
+var secretHeaders = []string{"Authorization", "X-Api-Key", "Api-Key", "X-Goog-Api-Key", "Cookie", "Set-Cookie"}
+
+// secretParams are query parameters some APIs accept keys in
+var secretParams = []string{"key", "api_key", "access_token"}
+
+// httpRecorder holds the switch and directory of HTTP debugging; see SetHTTPDebug
+type httpRecorder struct {
+	mutex   sync.Mutex // Guards the fields below
+	enabled bool
+	dir     string
+	seq     int // Numbers the files, so round trips of one request don't overwrite each other
+}
+
+var recorder = httpRecorder{dir: DefaultHTTPDebugDir}
+
+// SetHTTPDebug turns recording of provider requests and responses on or off. Each round
+// trip is written, with credentials removed, to its own JSON file in dir, named after
+// the request ID that also appears in logs and error messages. An empty dir keeps the
+// current one. It can be called at any time; requests already in flight are unaffected.
+func SetHTTPDebug(enabled bool, dir string) {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	recorder.enabled = enabled
+	if dir != "" {
+		recorder.dir = dir
+	}
+}
+
+// HTTPDebug reports whether provider traffic is being recorded, and where
+func HTTPDebug() (enabled bool, dir string) {
+	recorder.mutex.Lock()
+	defer recorder.mutex.Unlock()
+	return recorder.enabled, recorder.dir
+}
+
+// nextRecordPath returns the file the next round trip with correlation ID id is saved to
+func nextRecordPath(dir, id string, start time.Time) string {
+	recorder.mutex.Lock()
+	recorder.seq++
+	seq := recorder.seq
+	recorder.mutex.Unlock()
+	return filepath.Join(dir, fmt.Sprintf("%s_%s_%04d.json", start.Format("20060102T150405.000"), id, seq))
+}
+
+// newHTTPClient returns a client for provider APIs, whose traffic SetHTTPDebug can record
+func newHTTPClient(timeout time.Duration) *http.Client {
+	return &http.Client{Timeout: timeout, Transport: debugTransport{base: http.DefaultTransport}}
+}
+
+// debugTransport records every round trip while HTTP debugging is on
+type debugTransport struct {
+	base http.RoundTripper
+}
+
+// httpRecord is what is saved of one round trip
+type httpRecord struct {
+	ID              string              `json:"id"`
+	Time            time.Time           `json:"time"`
+	Method          string              `json:"method"`
+	URL             string              `json:"url"`
+	RequestHeaders  map[string][]string `json:"request_headers"`
+	RequestBody     any                 `json:"request_body,omitempty"`
+	Status          int                 `json:"status,omitempty"`
+	ResponseHeaders map[string][]string `json:"response_headers,omitempty"`
+	ResponseBody    any                 `json:"response_body,omitempty"`
+	DurationMS      int64               `json:"duration_ms"`
+	Error           string              `json:"error,omitempty"`
+}
+
+func (t debugTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	enabled, dir := HTTPDebug()
+	if !enabled {
+		return t.base.RoundTrip(req)
+	}
+
+	record := &httpRecord{
+		ID:             correlationID(req),
+		Time:           time.Now(),
+		Method:         req.Method,
+		URL:            sanitizeURL(req.URL),
+		RequestHeaders: sanitizeHeaders(req.Header),
+	}
+	if req.GetBody != nil {
+		if body, err := req.GetBody(); err == nil {
+			data, _ := io.ReadAll(body)
+			body.Close()
+			record.RequestBody = bodyValue(data)
+		}
+	}
+	path := nextRecordPath(dir, record.ID, record.Time)
+
+	resp, err := t.base.RoundTrip(req)
+	if err != nil {
+		record.Error = err.Error()
+		record.save(path)
+		return nil, err
+	}
+	record.Status = resp.StatusCode
+	record.ResponseHeaders = sanitizeHeaders(resp.Header)
+	// Saved once the caller has read the body, so streamed replies still arrive as they're sent
+	resp.Body = &recordingBody{ReadCloser: resp.Body, record: record, path: path}
+	return resp, nil
+}
+
+// recordingBody copies a response body as it is read and saves the record on Close
+type recordingBody struct {
+	io.ReadCloser
+	record *httpRecord
+	path   string
+	data   bytes.Buffer
+	once   sync.Once
+}
+
+func (b *recordingBody) Read(p []byte) (int, error) {
+	n, err := b.ReadCloser.Read(p)
+	b.data.Write(p[:n])
+	return n, err
+}
+
+func (b *recordingBody) Close() error {
+	b.once.Do(func() {
+		b.record.ResponseBody = bodyValue(b.data.Bytes())
+		b.record.save(b.path)
+	})
+	return b.ReadCloser.Close()
+}
+
+// save writes the record to path. Failures are logged rather than returned, so
+// debugging never breaks a request.
+func (r *httpRecord) save(path string) {
+	r.DurationMS = time.Since(r.Time).Milliseconds()
+	data, err := json.MarshalIndent(r, "", "  ")
+	if err == nil {
+		// Records hold conversation text, so keep them private to the user
+		if err = os.MkdirAll(filepath.Dir(path), 0700); err == nil {
+			err = os.WriteFile(path, data, 0600)
+		}
+	}
+	if err != nil {
+		log.Printf("Warning: failed to record HTTP exchange %s: %v", r.ID, err)
+	}
+}
+
+// correlationID is the request ID of the request's context, or a new random ID for
+// requests made outside one
+func correlationID(req *http.Request) string {
+	if id := RequestIDFromContext(req.Context()); id != "" {
+		return strings.Map(func(r rune) rune {
+			if strings.ContainsRune(`/\:*?"<>|`, r) {
+				return '_'
+			}
+			return r
+		}, id)
+	}
+	b := make([]byte, 6)
+	rand.Read(b)
+	return hex.EncodeToString(b)
+}
+
+// sanitizeHeaders copies headers with credentials replaced
+func sanitizeHeaders(header http.Header) map[string][]string {
+	clean := header.Clone()
+	for _, name := range secretHeaders {
+		if clean.Get(name) != "" {
+			clean.Set(name, redacted)
+		}
+	}
+	return clean
+}
+
+// sanitizeURL returns u with credentials in its query replaced
+func sanitizeURL(u *url.URL) string {
+	clean := *u
+	clean.User = nil
+	query := clean.Query()
+	for _, name := range secretParams {
+		if query.Has(name) {
+			query.Set(name, redacted)
+		}
+	}
+	clean.RawQuery = query.Encode()
+	return clean.String()
+}
+
+// bodyValue keeps JSON bodies as JSON in the record and anything else, such as a
+// server-sent event stream, as text
+func bodyValue(data []byte) any {
+	if len(data) == 0 {
+		return nil
+	}
+	if json.Valid(data) {
+		return json.RawMessage(bytes.Clone(data))
+	}
+	return string(data)
+}
@@ -0,0 +1,264 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// ProviderNameHuggingFace selects Hugging Face Inference Providers or an Inference Endpoint
+const ProviderNameHuggingFace ProviderName = "huggingface"
+
+// HuggingFaceDefaultURL is the OpenAI-compatible router of Hugging Face Inference Providers
+const HuggingFaceDefaultURL = "https://router.huggingface.co/v1/chat/completions"
+
+// HuggingFaceDefaultModel is used when MODEL isn't set
+const HuggingFaceDefaultModel = "meta-llama/Llama-3.1-8B-Instruct"
+
+const (
+	// coldStartMaxWait is how long a request keeps retrying while the model loads
+	coldStartMaxWait = 3 * time.Minute
+	// coldStartMinDelay and coldStartMaxDelay bound the wait between retries
+	coldStartMinDelay = 2 * time.Second
+	coldStartMaxDelay = 30 * time.Second
+	// coldStartDefaultDelay is used when the server doesn't estimate the load time
+	coldStartDefaultDelay = 10 * time.Second
+)
+
+// ModelLoadingError is returned while a Hugging Face model or scaled-to-zero endpoint
+// is starting up (HTTP 503)
+type ModelLoadingError struct {
+	Message       string
+	EstimatedTime time.Duration // Server's estimate of the remaining load time; 0 when unknown
+}
+
+func (e *ModelLoadingError) Error() string {
+	if e.EstimatedTime > 0 {
+		return fmt.Sprintf("Hugging Face model is loading (about %s left): %s", e.EstimatedTime.Round(time.Second), e.Message)
+	}
+	return "Hugging Face model is loading: " + e.Message
+}
+
+// NewHuggingFaceProvider creates a provider for Hugging Face. URLs ending in /generate
+// are treated as Text Generation Inference (TGI) endpoints; any other URL must serve the
+// OpenAI chat completions API, as the router and recent TGI versions do.
+func NewHuggingFaceProvider(config ProviderConfig) Provider {
+	if config.URL == "" {
+		config.URL = HuggingFaceDefaultURL
+	}
+	if config.Model == "" {
+		config.Model = HuggingFaceDefaultModel
+	}
+
+	p := &huggingFaceProvider{
+		openAIProvider: openAIProvider{config: config, name: string(ProviderNameHuggingFace), label: "Hugging Face"},
+		tgi:            strings.HasSuffix(strings.TrimSuffix(config.URL, "/"), "/generate"),
+	}
+	p.errorHandler = p.handleError
+	return p
+}
+
+// huggingFaceProvider implements Provider for Hugging Face, retrying politely while a
+// cold model loads
+type huggingFaceProvider struct {
+	openAIProvider
+	tgi bool // The URL is a TGI /generate endpoint rather than chat completions
+}
+
+// Capabilities reports what the endpoint supports. TGI's /generate has no tools,
+// JSON mode, or streaming through this provider.
+func (p *huggingFaceProvider) Capabilities() Capabilities {
+	return Capabilities{
+		Streaming:  !p.tgi,
+		Tools:      !p.tgi,
+		Vision:     modelSupportsVision(p.config.Model),
+		MaxContext: ModelContextWindow(p.config.Model),
+	}
+}
+
+func (p *huggingFaceProvider) CreateCompletion(ctx context.Context, req *ChatCompletionRequest) (*ChatCompletionResponse, error) {
+	return retryColdStart(ctx, func() (*ChatCompletionResponse, error) {
+		if p.tgi {
+			return p.generate(ctx, req)
+		}
+		return p.openAIProvider.CreateCompletion(ctx, req)
+	})
+}
+
+// CreateCompletionStream streams from chat completions endpoints. TGI /generate
+// responses are delivered in one piece.
+func (p *huggingFaceProvider) CreateCompletionStream(ctx context.Context, req *ChatCompletionRequest, onDelta StreamHandler) (*ChatCompletionResponse, error) {
+	if p.tgi {
+		resp, err := p.CreateCompletion(ctx, req)
+		if err == nil && onDelta != nil && len(resp.Choices) > 0 {
+			onDelta(resp.Choices[0].Message.Content)
+		}
+		return resp, err
+	}
+	return retryColdStart(ctx, func() (*ChatCompletionResponse, error) {
+		return p.openAIProvider.CreateCompletionStream(ctx, req, onDelta)
+	})
+}
+
+// retryColdStart calls fn until it stops failing with *ModelLoadingError, waiting as
+// long as the server estimates between attempts, for up to coldStartMaxWait or the
+// context's deadline
+func retryColdStart(ctx context.Context, fn func() (*ChatCompletionResponse, error)) (*ChatCompletionResponse, error) {
+	deadline := time.Now().Add(coldStartMaxWait)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	for {
+		resp, err := fn()
+		var loading *ModelLoadingError
+		if !errors.As(err, &loading) {
+			return resp, err
+		}
+
+		delay := loading.EstimatedTime
+		if delay <= 0 {
+			delay = coldStartDefaultDelay
+		}
+		delay = min(max(delay, coldStartMinDelay), coldStartMaxDelay)
+		if time.Now().Add(delay).After(deadline) {
+			return nil, fmt.Errorf("%w; try again shortly", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(delay):
+		}
+	}
+}
+
+// handleError turns 503s into *ModelLoadingError and reads Hugging Face's
+// {"error": "message"} responses
+func (p *huggingFaceProvider) handleError(statusCode int, body []byte) error {
+	var hfErr struct {
+		Error         json.RawMessage `json:"error"`
+		EstimatedTime float64         `json:"estimated_time"`
+	}
+	_ = json.Unmarshal(body, &hfErr)
+
+	// The error is a plain string, except on OpenAI-compatible routes
+	var message string
+	_ = json.Unmarshal(hfErr.Error, &message)
+
+	if statusCode == http.StatusServiceUnavailable {
+		if message == "" {
+			message = strings.TrimSpace(string(body))
+		}
+		return &ModelLoadingError{
+			Message:       message,
+			EstimatedTime: time.Duration(hfErr.EstimatedTime * float64(time.Second)),
+		}
+	}
+	if message != "" {
+		return fmt.Errorf("Hugging Face API error %d: %s", statusCode, message)
+	}
+	return p.handleOpenAIError(statusCode, body)
+}
+
+// generate runs a completion against a TGI /generate endpoint, which takes a single
+// prompt rather than messages
+func (p *huggingFaceProvider) generate(ctx context.Context, req *ChatCompletionRequest) (*ChatCompletionResponse, error) {
+	if len(req.Messages) == 0 {
+		return nil, fmt.Errorf("messages cannot be empty")
+	}
+
+	parameters := map[string]interface{}{
+		"details":          true,
+		"return_full_text": false,
+	}
+	if req.MaxTokens != nil {
+		parameters["max_new_tokens"] = *req.MaxTokens
+	}
+	if req.Temperature != nil && *req.Temperature > 0 {
+		// TGI requires a strictly positive temperature; 0 means greedy decoding, its default
+		parameters["temperature"] = *req.Temperature
+	}
+
+	resp, err := p.send(ctx, map[string]interface{}{
+		"inputs":     tgiPrompt(req.Messages),
+		"parameters": parameters,
+	})
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	// TGI returns an object; the serverless API wraps the same object in an array
+	var result struct {
+		GeneratedText string `json:"generated_text"`
+		Details       *struct {
+			FinishReason    string `json:"finish_reason"`
+			GeneratedTokens int    `json:"generated_tokens"`
+		} `json:"details"`
+	}
+	if strings.HasPrefix(strings.TrimSpace(string(body)), "[") {
+		var results []json.RawMessage
+		if err := json.Unmarshal(body, &results); err != nil || len(results) == 0 {
+			return nil, fmt.Errorf("failed to decode response: %s", string(body))
+		}
+		body = results[0]
+	}
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+
+	finishReason := FinishStop
+	usage := &Usage{}
+	if result.Details != nil {
+		if result.Details.FinishReason == FinishLength {
+			finishReason = FinishLength
+		}
+		usage.CompletionTokens = result.Details.GeneratedTokens
+	}
+	// TGI doesn't report prompt tokens, so they are estimated
+	counts, _ := CountMessageTokens(p.config.Model, req.Messages)
+	for _, mt := range counts {
+		usage.PromptTokens += mt.Tokens
+	}
+	usage.TotalTokens = usage.PromptTokens + usage.CompletionTokens
+
+	return &ChatCompletionResponse{
+		Model: p.config.Model,
+		Choices: []Choice{{
+			Message:      Message{Role: RoleAssistant, Content: strings.TrimSpace(result.GeneratedText)},
+			FinishReason: finishReason,
+		}},
+		Usage: usage,
+	}, nil
+}
+
+// tgiPrompt flattens messages into a plain-text transcript ending with the assistant's
+// turn. Endpoints serving the chat completions API apply the model's own chat template
+// and should be preferred.
+func tgiPrompt(messages []Message) string {
+	var b strings.Builder
+	for _, msg := range messages {
+		switch msg.Role {
+		case RoleSystem:
+			b.WriteString("System: ")
+		case RoleAssistant:
+			b.WriteString("Assistant: ")
+		default:
+			b.WriteString("User: ")
+		}
+		b.WriteString(msg.Content)
+		b.WriteString("\n\n")
+	}
+	b.WriteString("Assistant:")
+	return b.String()
+}
@@ -0,0 +1,215 @@
+package backend
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// latencyBuckets are the upper bounds (in milliseconds) of the response time histogram
+var latencyBuckets = []int64{25, 50, 100, 250, 500, 750, 1000, 1500, 2000, 3000, 5000, 7500, 10000, 15000, 20000, 30000, 60000}
+
+// LatencyPercentiles holds estimated response time percentiles in milliseconds
+type LatencyPercentiles struct {
+	P50   int64 `json:"p50_ms"`
+	P95   int64 `json:"p95_ms"`
+	P99   int64 `json:"p99_ms"`
+	Count int   `json:"count"`
+}
+
+// LatencyHistogram tracks the distribution of response times using fixed buckets,
+// so memory use stays constant regardless of how many requests are observed
+type LatencyHistogram struct {
+	counts []int // counts[i] observations <= latencyBuckets[i]; last entry is +Inf
+	total  int
+	sum    int64
+	min    int64
+	max    int64
+}
+
+// NewLatencyHistogram creates an empty latency histogram
+func NewLatencyHistogram() *LatencyHistogram {
+	return &LatencyHistogram{counts: make([]int, len(latencyBuckets)+1)}
+}
+
+// Observe records a single response time in milliseconds
+func (h *LatencyHistogram) Observe(ms int64) {
+	if ms < 0 {
+		ms = 0
+	}
+	idx := sort.Search(len(latencyBuckets), func(i int) bool { return ms <= latencyBuckets[i] })
+	h.counts[idx]++
+	if h.total == 0 || ms < h.min {
+		h.min = ms
+	}
+	if ms > h.max {
+		h.max = ms
+	}
+	h.total++
+	h.sum += ms
+}
+
+// Percentile estimates the p-th percentile (0-100) by linear interpolation within buckets
+func (h *LatencyHistogram) Percentile(p float64) int64 {
+	if h.total == 0 {
+		return 0
+	}
+
+	rank := p / 100 * float64(h.total)
+	cumulative := 0
+	for i, count := range h.counts {
+		if count == 0 {
+			continue
+		}
+		if float64(cumulative+count) < rank {
+			cumulative += count
+			continue
+		}
+
+		// Clamp bucket bounds to the observed range for better small-sample estimates
+		lower := int64(0)
+		if i > 0 {
+			lower = latencyBuckets[i-1]
+		}
+		upper := h.max
+		if i < len(latencyBuckets) {
+			upper = latencyBuckets[i]
+		}
+		lower = max(lower, h.min)
+		upper = min(upper, h.max)
+
+		fraction := (rank - float64(cumulative)) / float64(count)
+		return lower + int64(math.Round(fraction*float64(upper-lower)))
+	}
+	return h.max
+}
+
+// Percentiles returns the p50/p95/p99 estimates for this histogram
+func (h *LatencyHistogram) Percentiles() LatencyPercentiles {
+	return LatencyPercentiles{
+		P50:   h.Percentile(50),
+		P95:   h.Percentile(95),
+		P99:   h.Percentile(99),
+		Count: h.total,
+	}
+}
+
+// LatencyRegistry keeps one latency histogram per provider/model pair
+type LatencyRegistry struct {
+	mutex      sync.Mutex
+	histograms map[latencyKey]*LatencyHistogram
+}
+
+type latencyKey struct {
+	provider string
+	model    string
+}
+
+func (k latencyKey) String() string {
+	return k.provider + "/" + k.model
+}
+
+// DefaultLatencyRegistry aggregates response times across all sessions in the process.
+// It backs the Prometheus metrics endpoint.
+var DefaultLatencyRegistry = NewLatencyRegistry()
+
+// NewLatencyRegistry creates an empty latency registry
+func NewLatencyRegistry() *LatencyRegistry {
+	return &LatencyRegistry{histograms: make(map[latencyKey]*LatencyHistogram)}
+}
+
+// Observe records a response time for the given provider and model
+func (r *LatencyRegistry) Observe(provider, model string, ms int64) {
+	key := latencyKey{provider: provider, model: model}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	h, exists := r.histograms[key]
+	if !exists {
+		h = NewLatencyHistogram()
+		r.histograms[key] = h
+	}
+	h.Observe(ms)
+}
+
+// Percentiles returns percentile estimates keyed by "provider/model"
+func (r *LatencyRegistry) Percentiles() map[string]LatencyPercentiles {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	result := make(map[string]LatencyPercentiles, len(r.histograms))
+	for key, h := range r.histograms {
+		result[key.String()] = h.Percentiles()
+	}
+	return result
+}
+
+// Overall returns percentile estimates across every provider and model
+func (r *LatencyRegistry) Overall() LatencyPercentiles {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	merged := NewLatencyHistogram()
+	for _, h := range r.histograms {
+		if h.total == 0 {
+			continue
+		}
+		for i, count := range h.counts {
+			merged.counts[i] += count
+		}
+		if merged.total == 0 || h.min < merged.min {
+			merged.min = h.min
+		}
+		merged.max = max(merged.max, h.max)
+		merged.total += h.total
+		merged.sum += h.sum
+	}
+	return merged.Percentiles()
+}
+
+// WritePrometheus writes all histograms in the Prometheus text exposition format
+func (r *LatencyRegistry) WritePrometheus(w io.Writer) error {
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	keys := make([]latencyKey, 0, len(r.histograms))
+	for key := range r.histograms {
+		keys = append(keys, key)
+	}
+	sort.Slice(keys, func(i, j int) bool { return keys[i].String() < keys[j].String() })
+
+	var b strings.Builder
+	b.WriteString("# HELP chatgbt_response_time_seconds LLM provider response time.\n")
+	b.WriteString("# TYPE chatgbt_response_time_seconds histogram\n")
+	for _, key := range keys {
+		h := r.histograms[key]
+		labels := fmt.Sprintf("provider=%q,model=%q", key.provider, key.model)
+
+		cumulative := 0
+		for i, bound := range latencyBuckets {
+			cumulative += h.counts[i]
+			fmt.Fprintf(&b, "chatgbt_response_time_seconds_bucket{%s,le=\"%g\"} %d\n",
+				labels, float64(bound)/1000, cumulative)
+		}
+		fmt.Fprintf(&b, "chatgbt_response_time_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, h.total)
+		fmt.Fprintf(&b, "chatgbt_response_time_seconds_sum{%s} %g\n", labels, float64(h.sum)/1000)
+		fmt.Fprintf(&b, "chatgbt_response_time_seconds_count{%s} %d\n", labels, h.total)
+	}
+
+	b.WriteString("# HELP chatgbt_response_time_quantile_seconds Estimated LLM response time percentiles.\n")
+	b.WriteString("# TYPE chatgbt_response_time_quantile_seconds gauge\n")
+	for _, key := range keys {
+		p := r.histograms[key].Percentiles()
+		labels := fmt.Sprintf("provider=%q,model=%q", key.provider, key.model)
+		fmt.Fprintf(&b, "chatgbt_response_time_quantile_seconds{%s,quantile=\"0.5\"} %g\n", labels, float64(p.P50)/1000)
+		fmt.Fprintf(&b, "chatgbt_response_time_quantile_seconds{%s,quantile=\"0.95\"} %g\n", labels, float64(p.P95)/1000)
+		fmt.Fprintf(&b, "chatgbt_response_time_quantile_seconds{%s,quantile=\"0.99\"} %g\n", labels, float64(p.P99)/1000)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
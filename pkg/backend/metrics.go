@@ -1,14 +1,25 @@
 package backend
 
 import (
+	"bufio"
+	"cmp"
 	"encoding/json"
 	"fmt"
 	"log"
+	"maps"
 	"os"
 	"path/filepath"
+	"slices"
+	"sync"
 	"time"
+
+	"github.com/nleiva/chatgbt/pkg/i18n"
 )
 
+// MaxInteractions is how many of a session's latest interactions are kept in memory.
+// Older ones are only in the session's JSONL log; the totals and breakdowns cover all.
+const MaxInteractions = 200
+
 // SessionMetrics tracks metrics for a single conversation session
 type SessionMetrics struct {
 	SessionID        string              `json:"session_id"`
@@ -20,9 +31,55 @@ type SessionMetrics struct {
 	TotalTokens      int                 `json:"total_tokens"`
 	PromptTokens     int                 `json:"prompt_tokens"`
 	CompletionTokens int                 `json:"completion_tokens"`
-	Interactions     []InteractionMetric `json:"interactions"`
+	Interactions     []InteractionMetric `json:"interactions"`      // The latest MaxInteractions
 	ConversationType string              `json:"conversation_type"` // "quick", "debug", "creative", etc.
 	EstimatedCost    float64             `json:"estimated_cost"`
+
+	// Running totals over every interaction, including those no longer in Interactions
+	TotalResponseTime int64                 `json:"total_response_time_ms"`
+	Streamed          int                   `json:"streamed_requests,omitempty"` // Interactions that reported a time to first token
+	TotalTTFT         int64                 `json:"total_time_to_first_token_ms,omitempty"`
+	TotalTokensPerSec float64               `json:"total_tokens_per_second,omitempty"`
+	PromptTypes       map[string]int        `json:"prompt_types,omitempty"`
+	UsageByModel      map[string]ModelUsage `json:"usage_by_model,omitempty"` // Keyed by "provider/model"
+}
+
+// record folds an interaction into the running totals and keeps it in Interactions,
+// dropping the oldest once there are MaxInteractions
+func (s *SessionMetrics) record(interaction InteractionMetric) {
+	s.TotalResponseTime += interaction.ResponseTime
+	if interaction.TTFT > 0 {
+		s.Streamed++
+		s.TotalTTFT += interaction.TTFT
+		s.TotalTokensPerSec += interaction.TokensPerSec
+	}
+	if interaction.PromptType != "" {
+		if s.PromptTypes == nil {
+			s.PromptTypes = make(map[string]int)
+		}
+		s.PromptTypes[interaction.PromptType]++
+	}
+
+	if s.UsageByModel == nil {
+		s.UsageByModel = make(map[string]ModelUsage)
+	}
+	key := latencyKey{provider: interaction.Provider, model: interaction.Model}.String()
+	u := s.UsageByModel[key]
+	u.Provider, u.Model = interaction.Provider, interaction.Model
+	u.Requests++
+	u.PromptTokens += interaction.RequestTokens
+	u.CompletionTokens += interaction.ResponseTokens
+	u.TotalTokens += interaction.TotalTokens
+	u.Cost += interaction.Cost
+	s.UsageByModel[key] = u
+
+	if len(s.Interactions) >= MaxInteractions {
+		// Shift in place rather than reslice, so the backing array never grows past the cap
+		n := copy(s.Interactions, s.Interactions[len(s.Interactions)-MaxInteractions+1:])
+		clear(s.Interactions[n:])
+		s.Interactions = s.Interactions[:n]
+	}
+	s.Interactions = append(s.Interactions, interaction)
 }
 
 // InteractionMetric tracks a single request/response cycle
@@ -34,14 +91,33 @@ type InteractionMetric struct {
 	ResponseTime   int64     `json:"response_time_ms"`
 	Success        bool      `json:"success"`
 	ErrorType      string    `json:"error_type,omitempty"`
-	PromptType     string    `json:"prompt_type"` // "system", "user", "code_help", etc.
+	PromptType     string    `json:"prompt_type"`        // "system", "user", "code_help", etc.
+	Language       string    `json:"language,omitempty"` // Detected language of the prompt (ISO 639-1)
+	Provider       string    `json:"provider,omitempty"`
+	Model          string    `json:"model,omitempty"`
+	TTFT           int64     `json:"time_to_first_token_ms,omitempty"` // Only set for streamed responses
+	TokensPerSec   float64   `json:"tokens_per_second,omitempty"`      // Completion tokens per second after the first token
+	RequestID      string    `json:"request_id,omitempty"`             // ID of the web request that triggered the interaction
+	Cost           float64   `json:"cost,omitempty"`                   // Estimated USD cost of the tokens
+	Neutralized    int       `json:"neutralized,omitempty"`            // Instruction-like lines the injection guard caught in tool results sent with the request
 }
 
-// MetricsLogger handles session logging and token budget tracking
+// logQueueSize is how many log lines can wait for the writer before LogInteraction blocks
+const logQueueSize = 64
+
+// MetricsLogger handles session logging and token budget tracking. It is safe for
+// concurrent use; log lines are written to the file in the background.
 type MetricsLogger struct {
-	session   *SessionMetrics
-	logFile   *os.File
+	logFile *os.File
+	lines   chan string   // Log lines waiting for the writer
+	written chan struct{} // Closed when the writer has flushed the last line
+
+	mutex     sync.Mutex // Guards the fields below
 	budgetCfg TokenBudgetConfig
+	session   *SessionMetrics
+	latency   *LatencyRegistry // Per provider/model response time histograms for this session
+	language  string           // Language of budget warnings
+	closed    bool
 }
 
 // TokenBudgetConfig defines token usage limits and warnings
@@ -51,12 +127,13 @@ type TokenBudgetConfig struct {
 	WarnThreshold  float64 `json:"warn_threshold"`  // Warn at % of limit (0.8 = 80%)
 	PruneThreshold int     `json:"prune_threshold"` // Prune context when session exceeds this
 	CostPerToken   float64 `json:"cost_per_token"`  // Estimated cost per token
+	LogsDir        string  `json:"-"`               // Directory of session JSONL logs (default: logs)
 }
 
 // NewMetricsLogger creates a new metrics logger with session tracking
 func NewMetricsLogger(sessionID string, conversationType string, budgetCfg TokenBudgetConfig) (*MetricsLogger, error) {
 	// Create logs directory if it doesn't exist
-	logsDir := "logs"
+	logsDir := cmp.Or(budgetCfg.LogsDir, "logs")
 	if err := os.MkdirAll(logsDir, 0755); err != nil {
 		return nil, fmt.Errorf("failed to create logs directory: %w", err)
 	}
@@ -77,11 +154,42 @@ func NewMetricsLogger(sessionID string, conversationType string, budgetCfg Token
 		Interactions:     make([]InteractionMetric, 0),
 	}
 
-	return &MetricsLogger{
+	ml := &MetricsLogger{
 		session:   session,
 		logFile:   logFile,
 		budgetCfg: budgetCfg,
-	}, nil
+		lines:     make(chan string, logQueueSize),
+		written:   make(chan struct{}),
+		latency:   NewLatencyRegistry(),
+		language:  i18n.Default,
+	}
+	go ml.writeLines()
+	return ml, nil
+}
+
+// writeLines appends queued lines to the log file through a buffer, flushing it whenever
+// the queue runs dry so the file stays current without a write per line
+func (ml *MetricsLogger) writeLines() {
+	defer close(ml.written)
+	out := bufio.NewWriter(ml.logFile)
+	for line := range ml.lines {
+		out.WriteString(line)
+		if len(ml.lines) == 0 {
+			if err := out.Flush(); err != nil {
+				log.Printf("Failed to write session log: %v", err)
+			}
+		}
+	}
+	if err := out.Flush(); err != nil {
+		log.Printf("Failed to write session log: %v", err)
+	}
+}
+
+// SetLanguage sets the language budget warnings are written in
+func (ml *MetricsLogger) SetLanguage(lang string) {
+	ml.mutex.Lock()
+	defer ml.mutex.Unlock()
+	ml.language = lang
 }
 
 // InteractionLog represents the details of a single interaction for logging
@@ -91,6 +199,13 @@ type InteractionLog struct {
 	Success      bool          `json:"success"`
 	ErrorType    string        `json:"error_type,omitempty"`
 	PromptType   string        `json:"prompt_type"`
+	Language     string        `json:"language,omitempty"`
+	Provider     string        `json:"provider,omitempty"`
+	Model        string        `json:"model,omitempty"`
+	TTFT         time.Duration `json:"time_to_first_token,omitempty"` // Zero when the response wasn't streamed
+	RequestID    string        `json:"request_id,omitempty"`
+	CostPerToken float64       `json:"cost_per_token,omitempty"` // Price of the model that served it; 0 uses the budget's
+	Neutralized  int           `json:"neutralized,omitempty"`    // Lines of tool results the injection guard flagged or stripped
 }
 
 // LogInteraction records a single API interaction using a structured log
@@ -101,18 +216,32 @@ func (ml *MetricsLogger) LogInteraction(log InteractionLog) {
 		Success:      log.Success,
 		ErrorType:    log.ErrorType,
 		PromptType:   log.PromptType,
+		Language:     log.Language,
+		TTFT:         log.TTFT.Milliseconds(),
+		Provider:     log.Provider,
+		Model:        log.Model,
+		RequestID:    log.RequestID,
+		Neutralized:  log.Neutralized,
 	}
 
+	ml.mutex.Lock()
+	defer ml.mutex.Unlock()
 	if log.Usage != nil {
 		interaction.RequestTokens = log.Usage.PromptTokens
 		interaction.ResponseTokens = log.Usage.CompletionTokens
 		interaction.TotalTokens = log.Usage.TotalTokens
+		interaction.TokensPerSec = TokensPerSecond(log.Usage.CompletionTokens, log.ResponseTime, log.TTFT)
 
 		// Update session totals
 		ml.session.TotalTokens += log.Usage.TotalTokens
 		ml.session.PromptTokens += log.Usage.PromptTokens
 		ml.session.CompletionTokens += log.Usage.CompletionTokens
-		ml.session.EstimatedCost += float64(log.Usage.TotalTokens) * ml.budgetCfg.CostPerToken
+		costPerToken := log.CostPerToken
+		if costPerToken == 0 {
+			costPerToken = ml.budgetCfg.CostPerToken
+		}
+		interaction.Cost = float64(log.Usage.TotalTokens) * costPerToken
+		ml.session.EstimatedCost += interaction.Cost
 	}
 
 	ml.session.TotalRequests++
@@ -122,12 +251,17 @@ func (ml *MetricsLogger) LogInteraction(log InteractionLog) {
 		ml.session.FailedReqs++
 	}
 
-	ml.session.Interactions = append(ml.session.Interactions, interaction)
+	ml.session.record(interaction)
 
-	// Write to log file
-	if logLine, err := json.Marshal(interaction); err == nil {
-		ml.logFile.WriteString(string(logLine) + "\n")
-		ml.logFile.Sync()
+	// Only successful calls are meaningful for latency percentiles
+	if log.Success {
+		ml.latency.Observe(log.Provider, log.Model, interaction.ResponseTime)
+		DefaultLatencyRegistry.Observe(log.Provider, log.Model, interaction.ResponseTime)
+	}
+
+	// Queue for the log file; a closed logger still counts the interaction
+	if logLine, err := json.Marshal(interaction); err == nil && !ml.closed {
+		ml.lines <- string(logLine) + "\n"
 	}
 }
 
@@ -143,8 +277,36 @@ func (ml *MetricsLogger) LogInteractionLegacy(usage *Usage, responseTime time.Du
 	})
 }
 
+// TokensPerSecond computes generation throughput, excluding the time spent waiting
+// for the first token when the response was streamed
+func TokensPerSecond(completionTokens int, responseTime, ttft time.Duration) float64 {
+	generation := responseTime - ttft
+	if completionTokens <= 0 || generation <= 0 {
+		return 0
+	}
+	return float64(completionTokens) / generation.Seconds()
+}
+
+// SetBudget replaces the limits and price usage is checked against from now on. The
+// logs directory stays the one the log file was opened in.
+func (ml *MetricsLogger) SetBudget(budgetCfg TokenBudgetConfig) {
+	ml.mutex.Lock()
+	defer ml.mutex.Unlock()
+	budgetCfg.LogsDir = ml.budgetCfg.LogsDir
+	ml.budgetCfg = budgetCfg
+}
+
+// LogsDir returns the directory the session's log is written to
+func (ml *MetricsLogger) LogsDir() string {
+	ml.mutex.Lock()
+	defer ml.mutex.Unlock()
+	return cmp.Or(ml.budgetCfg.LogsDir, "logs")
+}
+
 // CheckBudgetStatus returns warnings and recommendations based on current usage
 func (ml *MetricsLogger) CheckBudgetStatus() BudgetStatus {
+	ml.mutex.Lock()
+	defer ml.mutex.Unlock()
 	status := BudgetStatus{
 		SessionTokens: ml.session.TotalTokens,
 		SessionCost:   ml.session.EstimatedCost,
@@ -157,8 +319,9 @@ func (ml *MetricsLogger) CheckBudgetStatus() BudgetStatus {
 	if ml.budgetCfg.SessionLimit > 0 {
 		sessionUsage := float64(ml.session.TotalTokens) / float64(ml.budgetCfg.SessionLimit)
 		if sessionUsage > ml.budgetCfg.WarnThreshold {
+			status.NearLimit = true
 			status.Warnings = append(status.Warnings,
-				fmt.Sprintf("Session token usage at %.1f%% of limit (%d/%d tokens)",
+				i18n.T(ml.language, "budget.usage",
 					sessionUsage*100, ml.session.TotalTokens, ml.budgetCfg.SessionLimit))
 		}
 		if sessionUsage > 1.0 {
@@ -170,7 +333,7 @@ func (ml *MetricsLogger) CheckBudgetStatus() BudgetStatus {
 	// For now, just check if we're getting expensive
 	if ml.session.EstimatedCost > 1.0 {
 		status.Warnings = append(status.Warnings,
-			fmt.Sprintf("Session cost: $%.3f", ml.session.EstimatedCost))
+			i18n.T(ml.language, "budget.cost", ml.session.EstimatedCost))
 	}
 
 	return status
@@ -178,15 +341,21 @@ func (ml *MetricsLogger) CheckBudgetStatus() BudgetStatus {
 
 // GetSessionSummary returns a summary of the current session
 func (ml *MetricsLogger) GetSessionSummary() SessionSummary {
+	ml.mutex.Lock()
+	defer ml.mutex.Unlock()
 	duration := time.Since(ml.session.StartTime)
 
 	avgResponseTime := int64(0)
-	if len(ml.session.Interactions) > 0 {
-		var totalTime int64
-		for _, interaction := range ml.session.Interactions {
-			totalTime += interaction.ResponseTime
-		}
-		avgResponseTime = totalTime / int64(len(ml.session.Interactions))
+	if ml.session.TotalRequests > 0 {
+		avgResponseTime = ml.session.TotalResponseTime / int64(ml.session.TotalRequests)
+	}
+
+	// Streaming metrics are averaged only over interactions that reported them
+	var avgTTFT int64
+	var avgTokensPerSec float64
+	if streamed := ml.session.Streamed; streamed > 0 {
+		avgTTFT = ml.session.TotalTTFT / int64(streamed)
+		avgTokensPerSec = ml.session.TotalTokensPerSec / float64(streamed)
 	}
 
 	return SessionSummary{
@@ -196,33 +365,89 @@ func (ml *MetricsLogger) GetSessionSummary() SessionSummary {
 		TotalTokens:      ml.session.TotalTokens,
 		EstimatedCost:    ml.session.EstimatedCost,
 		AvgResponseTime:  avgResponseTime,
+		AvgTTFT:          avgTTFT,
+		AvgTokensPerSec:  avgTokensPerSec,
+		Latency:          ml.latency.Overall(),
+		LatencyByModel:   ml.latency.Percentiles(),
+		UsageByModel:     ml.usageByModel(),
 		ConversationType: ml.session.ConversationType,
 	}
 }
 
+// usageByModel attributes the session's requests, tokens, and cost to the provider and
+// model that served them, keyed by "provider/model"
+func (ml *MetricsLogger) usageByModel() map[string]ModelUsage {
+	usage := make(map[string]ModelUsage, len(ml.session.UsageByModel))
+	maps.Copy(usage, ml.session.UsageByModel)
+	return usage
+}
+
 // GetPromptTypeBreakdown returns a breakdown of prompt types used in this session
 func (ml *MetricsLogger) GetPromptTypeBreakdown() map[string]int {
-	breakdown := make(map[string]int)
+	ml.mutex.Lock()
+	defer ml.mutex.Unlock()
+	breakdown := make(map[string]int, len(ml.session.PromptTypes))
+	maps.Copy(breakdown, ml.session.PromptTypes)
+	return breakdown
+}
+
+// Snapshot returns a copy of the session's metrics, for persisting across restarts
+func (ml *MetricsLogger) Snapshot() SessionMetrics {
+	ml.mutex.Lock()
+	defer ml.mutex.Unlock()
+	snapshot := *ml.session
+	snapshot.Interactions = slices.Clone(ml.session.Interactions)
+	snapshot.PromptTypes = maps.Clone(ml.session.PromptTypes)
+	snapshot.UsageByModel = maps.Clone(ml.session.UsageByModel)
+	return snapshot
+}
 
-	for _, interaction := range ml.session.Interactions {
-		if interaction.PromptType != "" {
-			breakdown[interaction.PromptType]++
+// Restore replaces the session's counters and interactions with a snapshot taken by
+// Snapshot, so budgets and summaries carry over a restart. The log file is kept. Latency
+// percentiles start over from the interactions the snapshot kept.
+func (ml *MetricsLogger) Restore(snapshot SessionMetrics) {
+	ml.mutex.Lock()
+	defer ml.mutex.Unlock()
+	snapshot.SessionID = ml.session.SessionID
+	snapshot.EndTime = nil
+	if snapshot.UsageByModel == nil && len(snapshot.Interactions) > 0 {
+		// Snapshots from before the running totals kept every interaction instead
+		interactions := snapshot.Interactions
+		snapshot.Interactions = nil
+		for _, interaction := range interactions {
+			snapshot.record(interaction)
 		}
 	}
+	*ml.session = snapshot
 
-	return breakdown
+	ml.latency = NewLatencyRegistry()
+	for _, interaction := range snapshot.Interactions {
+		if interaction.Success {
+			ml.latency.Observe(interaction.Provider, interaction.Model, interaction.ResponseTime)
+		}
+	}
 }
 
-// Close finalizes the session and closes log files
+// Close finalizes the session, waits for queued lines to be written, and closes the log
+// file. Closing twice is harmless.
 func (ml *MetricsLogger) Close() error {
+	ml.mutex.Lock()
+	if ml.closed {
+		ml.mutex.Unlock()
+		return nil
+	}
+	ml.closed = true
 	now := time.Now()
 	ml.session.EndTime = &now
 
 	// Write final session summary
 	if sessionData, err := json.Marshal(ml.session); err == nil {
-		ml.logFile.WriteString("SESSION_SUMMARY: " + string(sessionData) + "\n")
+		ml.lines <- "SESSION_SUMMARY: " + string(sessionData) + "\n"
 	}
+	close(ml.lines)
+	ml.mutex.Unlock()
 
+	<-ml.written
 	return ml.logFile.Close()
 }
 
@@ -233,6 +458,7 @@ type BudgetStatus struct {
 	SessionLimit  int
 	DailyLimit    int
 	Warnings      []string
+	NearLimit     bool // Session usage is past the warn threshold
 	OverBudget    bool
 	ShouldPrune   bool
 }
@@ -245,9 +471,25 @@ type SessionSummary struct {
 	TotalTokens      int
 	EstimatedCost    float64
 	AvgResponseTime  int64
+	AvgTTFT          int64                         // Average time to first token in milliseconds (streamed responses)
+	AvgTokensPerSec  float64                       // Average generation throughput (streamed responses)
+	Latency          LatencyPercentiles            // Response time percentiles across all models
+	LatencyByModel   map[string]LatencyPercentiles // Response time percentiles keyed by "provider/model"
+	UsageByModel     map[string]ModelUsage         // Requests, tokens, and cost keyed by "provider/model"
 	ConversationType string
 }
 
+// ModelUsage is the share of a session's usage served by one provider and model
+type ModelUsage struct {
+	Provider         string  `json:"provider"`
+	Model            string  `json:"model"`
+	Requests         int     `json:"requests"`
+	PromptTokens     int     `json:"prompt_tokens"`
+	CompletionTokens int     `json:"completion_tokens"`
+	TotalTokens      int     `json:"total_tokens"`
+	Cost             float64 `json:"estimated_cost"`
+}
+
 // DefaultBudgetConfig returns sensible defaults for token budgeting
 func DefaultBudgetConfig() TokenBudgetConfig {
 	return TokenBudgetConfig{
@@ -0,0 +1,50 @@
+package backend
+
+import "slices"
+
+// ProviderNames returns the providers that can be selected with LLM_PROVIDER, sorted
+func ProviderNames() []ProviderName {
+	names := []ProviderName{
+		ProviderNameOpenAI, ProviderNameAnthropic, ProviderNameOllama,
+		ProviderNameHuggingFace, ProviderNameVertex,
+	}
+	for name := range presets {
+		names = append(names, name)
+	}
+	slices.Sort(names)
+	return names
+}
+
+// knownModels are well-known models of each provider besides its default. They are
+// offered by shell completion; any other model name still works.
+var knownModels = map[ProviderName][]string{
+	ProviderNameOpenAI: {
+		"gpt-3.5-turbo", "gpt-4o", "gpt-4o-mini", "gpt-4.1", "gpt-4.1-mini",
+		"gpt-5", "gpt-5-mini", "o3", "o4-mini",
+	},
+	ProviderNameAnthropic: {
+		"claude-3-5-haiku-latest", "claude-sonnet-4-20250514", "claude-opus-4-1-20250805",
+	},
+	ProviderNameOllama:   {"llama3.1", "llama3.2", "qwen2.5", "mistral"},
+	ProviderNameGroq:     {"llama-3.1-8b-instant"},
+	ProviderNameMistral:  {"mistral-large-latest", "codestral-latest"},
+	ProviderNameDeepSeek: {"deepseek-reasoner"},
+	ProviderNameXAI:      {"grok-4"},
+}
+
+// KnownModels returns "provider:model" for every provider's default model and its
+// well-known models, sorted
+func KnownModels() []string {
+	var models []string
+	for _, provider := range ProviderNames() {
+		names := knownModels[provider]
+		if model := DefaultModelFor(provider); model != "" && !slices.Contains(names, model) {
+			names = append([]string{model}, names...)
+		}
+		for _, model := range names {
+			models = append(models, string(provider)+":"+model)
+		}
+	}
+	slices.Sort(models)
+	return models
+}
@@ -2,42 +2,108 @@ package backend
 
 import (
 	"bytes"
+	"cmp"
 	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"net/http"
+	"strings"
 	"time"
 )
 
 // NewOpenAIProvider creates a new OpenAI provider
 func NewOpenAIProvider(config ProviderConfig) Provider {
-	return &openAIProvider{config: config}
+	return &openAIProvider{config: config, name: string(ProviderNameOpenAI), headers: openAIAccountHeaders(config)}
 }
 
-// openAIProvider implements Provider interface for OpenAI
+// openAIAccountHeaders returns the headers that bill requests to the configured
+// organization and project, or nil when neither is set
+func openAIAccountHeaders(config ProviderConfig) map[string]string {
+	if config.Organization == "" && config.Project == "" {
+		return nil
+	}
+	headers := map[string]string{}
+	if config.Organization != "" {
+		headers["OpenAI-Organization"] = config.Organization
+	}
+	if config.Project != "" {
+		headers["OpenAI-Project"] = config.Project
+	}
+	return headers
+}
+
+// openAIProvider implements Provider interface for OpenAI and OpenAI-compatible APIs
 type openAIProvider struct {
-	config ProviderConfig
+	config        ProviderConfig
+	name          string
+	label         string            // Name of the API in error messages; empty means OpenAI
+	headers       map[string]string // Extra headers required or recommended by the API
+	noStreamUsage bool              // The API rejects stream_options
+
+	// errorHandler replaces handleOpenAIError for APIs with their own error responses
+	errorHandler func(statusCode int, body []byte) error
+
+	// bearerToken supplies the token of each request for APIs with short-lived
+	// OAuth tokens instead of an API key
+	bearerToken func(ctx context.Context) (string, error)
 }
 
 func (p *openAIProvider) Name() string {
-	return "openai"
+	return p.name
+}
+
+// Capabilities reports what OpenAI, or an OpenAI-compatible server such as Ollama, supports
+func (p *openAIProvider) Capabilities() Capabilities {
+	return Capabilities{
+		Streaming:  true,
+		Tools:      true,
+		Vision:     modelSupportsVision(p.config.Model),
+		JSONMode:   true,
+		MaxContext: ModelContextWindow(p.config.Model),
+	}
 }
 
 // handleOpenAIError handles OpenAI-specific API error responses
 func (p *openAIProvider) handleOpenAIError(statusCode int, body []byte) error {
+	label := p.label
+	if label == "" {
+		label = "OpenAI"
+	}
+
 	var apiErr APIErrorResponse
-	if err := json.Unmarshal(body, &apiErr); err != nil {
-		return fmt.Errorf("OpenAI API error %d: unable to parse error response: %s", statusCode, string(body))
-	}
-	return fmt.Errorf("OpenAI API error %d: %s (type: %s, code: %s)",
-		statusCode,
-		apiErr.Error.Message,
-		apiErr.Error.Type,
-		apiErr.Error.Code)
+	if err := json.Unmarshal(body, &apiErr); err == nil && apiErr.Error.Message != "" {
+		return fmt.Errorf("%s API error %d: %s (type: %s, code: %s)",
+			label,
+			statusCode,
+			apiErr.Error.Message,
+			apiErr.Error.Type,
+			apiErr.Error.Code)
+	}
+
+	// Compatible APIs don't always follow OpenAI's error shape: Mistral puts the message
+	// at the top level, OpenRouter uses numeric codes, and xAI sends the error as a string
+	var loose struct {
+		Message string          `json:"message"`
+		Error   json.RawMessage `json:"error"`
+	}
+	if err := json.Unmarshal(body, &loose); err == nil {
+		var nested struct {
+			Message string `json:"message"`
+		}
+		var flat string
+		if json.Unmarshal(loose.Error, &nested) != nil {
+			_ = json.Unmarshal(loose.Error, &flat)
+		}
+		if message := cmp.Or(nested.Message, flat, loose.Message); message != "" {
+			return fmt.Errorf("%s API error %d: %s", label, statusCode, message)
+		}
+	}
+	return fmt.Errorf("%s API error %d: unable to parse error response: %s", label, statusCode, string(body))
 }
 
-func (p *openAIProvider) CreateCompletion(ctx context.Context, req *ChatCompletionRequest) (*ChatCompletionResponse, error) {
+// buildRequest creates the OpenAI request body for a chat completion
+func (p *openAIProvider) buildRequest(req *ChatCompletionRequest) (map[string]interface{}, error) {
 	// Set up the model from config if not provided in request
 	model := req.Model
 	if model == "" {
@@ -59,7 +125,42 @@ func (p *openAIProvider) CreateCompletion(ctx context.Context, req *ChatCompleti
 	if req.Temperature != nil {
 		openAIReq["temperature"] = *req.Temperature
 	}
+	if len(req.Tools) > 0 {
+		openAIReq["tools"] = openAITools(req.Tools)
+	}
+	if req.JSONMode {
+		openAIReq["response_format"] = map[string]string{"type": "json_object"}
+	}
+	// Only OpenAI itself is sent the user; some compatible APIs reject fields they don't know
+	if req.User != "" && p.name == string(ProviderNameOpenAI) {
+		openAIReq["user"] = req.User
+	}
 
+	return openAIReq, nil
+}
+
+// openAITools converts tool definitions to OpenAI's function tool format
+func openAITools(tools []ToolDefinition) []map[string]interface{} {
+	converted := make([]map[string]interface{}, 0, len(tools))
+	for _, tool := range tools {
+		parameters := tool.Parameters
+		if len(parameters) == 0 {
+			parameters = json.RawMessage(`{"type":"object","properties":{}}`)
+		}
+		converted = append(converted, map[string]interface{}{
+			"type": "function",
+			"function": map[string]interface{}{
+				"name":        tool.Name,
+				"description": tool.Description,
+				"parameters":  parameters,
+			},
+		})
+	}
+	return converted
+}
+
+// send posts the request body and returns the response once a 200 status is received
+func (p *openAIProvider) send(ctx context.Context, openAIReq map[string]interface{}) (*http.Response, error) {
 	// Marshal the request
 	reqBody, err := json.Marshal(openAIReq)
 	if err != nil {
@@ -79,20 +180,59 @@ func (p *openAIProvider) CreateCompletion(ctx context.Context, req *ChatCompleti
 	}
 
 	httpReq.Header.Set("Content-Type", "application/json")
-	httpReq.Header.Set("Authorization", "Bearer "+p.config.APIKey)
+	setRequestIDHeader(httpReq)
+	token := p.config.APIKey
+	if p.bearerToken != nil {
+		if token, err = p.bearerToken(ctx); err != nil {
+			return nil, err
+		}
+	}
+	if token != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+token)
+	}
+	for name, value := range p.headers {
+		httpReq.Header.Set(name, value)
+	}
 
 	// Create HTTP client with timeout
 	timeout := time.Duration(p.config.Timeout) * time.Second
 	if timeout <= 0 {
 		timeout = 30 * time.Second
 	}
-	client := &http.Client{Timeout: timeout}
+	client := newHTTPClient(timeout)
 
 	// Make the request
 	resp, err := client.Do(httpReq)
 	if err != nil {
 		return nil, fmt.Errorf("request failed: %w", err)
 	}
+
+	// Handle errors
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, err := io.ReadAll(resp.Body)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read response: %w", err)
+		}
+		if p.errorHandler != nil {
+			return nil, &StatusError{StatusCode: resp.StatusCode, Err: p.errorHandler(resp.StatusCode, body)}
+		}
+		return nil, &StatusError{StatusCode: resp.StatusCode, Err: p.handleOpenAIError(resp.StatusCode, body)}
+	}
+
+	return resp, nil
+}
+
+func (p *openAIProvider) CreateCompletion(ctx context.Context, req *ChatCompletionRequest) (*ChatCompletionResponse, error) {
+	openAIReq, err := p.buildRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.send(ctx, openAIReq)
+	if err != nil {
+		return nil, err
+	}
 	defer resp.Body.Close()
 
 	// Read response body
@@ -101,11 +241,6 @@ func (p *openAIProvider) CreateCompletion(ctx context.Context, req *ChatCompleti
 		return nil, fmt.Errorf("failed to read response: %w", err)
 	}
 
-	// Handle errors
-	if resp.StatusCode != http.StatusOK {
-		return nil, p.handleOpenAIError(resp.StatusCode, body)
-	}
-
 	// Parse successful response
 	var openAIResp ChatCompletionResponse
 	if err := json.Unmarshal(body, &openAIResp); err != nil {
@@ -114,3 +249,99 @@ func (p *openAIProvider) CreateCompletion(ctx context.Context, req *ChatCompleti
 
 	return &openAIResp, nil
 }
+
+// CreateCompletionStream streams a chat completion using server-sent events
+func (p *openAIProvider) CreateCompletionStream(ctx context.Context, req *ChatCompletionRequest, onDelta StreamHandler) (*ChatCompletionResponse, error) {
+	openAIReq, err := p.buildRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	openAIReq["stream"] = true
+	if !p.noStreamUsage {
+		openAIReq["stream_options"] = map[string]interface{}{"include_usage": true}
+	}
+
+	resp, err := p.send(ctx, openAIReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	result := &ChatCompletionResponse{}
+	var content strings.Builder
+	var finishReason string
+	var toolCalls []ToolCall // Assembled from fragments keyed by their index
+
+	err = readServerSentEvents(resp.Body, func(_, data string) error {
+		if data == "[DONE]" {
+			return io.EOF
+		}
+
+		var chunk struct {
+			ID      string `json:"id"`
+			Model   string `json:"model"`
+			Choices []struct {
+				Delta struct {
+					Content   string `json:"content"`
+					ToolCalls []struct {
+						Index    int    `json:"index"`
+						ID       string `json:"id"`
+						Type     string `json:"type"`
+						Function struct {
+							Name      string `json:"name"`
+							Arguments string `json:"arguments"`
+						} `json:"function"`
+					} `json:"tool_calls"`
+				} `json:"delta"`
+				FinishReason *string `json:"finish_reason"`
+			} `json:"choices"`
+			Usage *Usage `json:"usage"`
+		}
+		if err := json.Unmarshal([]byte(data), &chunk); err != nil {
+			return fmt.Errorf("failed to decode stream chunk: %w", err)
+		}
+
+		result.ID = chunk.ID
+		result.Model = chunk.Model
+		if chunk.Usage != nil {
+			result.Usage = chunk.Usage
+		}
+		for _, choice := range chunk.Choices {
+			if choice.Delta.Content != "" {
+				content.WriteString(choice.Delta.Content)
+				if onDelta != nil {
+					onDelta(choice.Delta.Content)
+				}
+			}
+			for _, fragment := range choice.Delta.ToolCalls {
+				for len(toolCalls) <= fragment.Index {
+					toolCalls = append(toolCalls, ToolCall{Type: "function"})
+				}
+				call := &toolCalls[fragment.Index]
+				if fragment.ID != "" {
+					call.ID = fragment.ID
+				}
+				if fragment.Type != "" {
+					call.Type = fragment.Type
+				}
+				call.Function.Name += fragment.Function.Name
+				call.Function.Arguments += fragment.Function.Arguments
+			}
+			if choice.FinishReason != nil {
+				finishReason = *choice.FinishReason
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stream: %w", err)
+	}
+
+	result.Choices = []Choice{{
+		Index:        0,
+		Message:      Message{Role: RoleAssistant, Content: content.String(), ToolCalls: toolCalls},
+		FinishReason: finishReason,
+	}}
+
+	return result, nil
+}
@@ -0,0 +1,298 @@
+package backend
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"mime/multipart"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// OpenAIBatchDiscount is the fraction of the regular price charged for Batch API requests
+const OpenAIBatchDiscount = 0.5
+
+// Batch job statuses reported by the Batch API
+const (
+	BatchStatusCompleted = "completed"
+	BatchStatusFailed    = "failed"
+	BatchStatusExpired   = "expired"
+	BatchStatusCancelled = "cancelled"
+)
+
+// OpenAIBatch is the state of a job submitted to OpenAI's Batch API
+type OpenAIBatch struct {
+	ID            string `json:"id"`
+	Status        string `json:"status"`
+	InputFileID   string `json:"input_file_id"`
+	OutputFileID  string `json:"output_file_id"`
+	ErrorFileID   string `json:"error_file_id"`
+	RequestCounts struct {
+		Total     int `json:"total"`
+		Completed int `json:"completed"`
+		Failed    int `json:"failed"`
+	} `json:"request_counts"`
+	Errors *struct {
+		Data []struct {
+			Code    string `json:"code"`
+			Message string `json:"message"`
+		} `json:"data"`
+	} `json:"errors"`
+}
+
+// Done reports whether the job has reached a final status
+func (b *OpenAIBatch) Done() bool {
+	switch b.Status {
+	case BatchStatusCompleted, BatchStatusFailed, BatchStatusExpired, BatchStatusCancelled:
+		return true
+	}
+	return false
+}
+
+// OpenAIBatchRequest is one chat completion in a batch input file
+type OpenAIBatchRequest struct {
+	CustomID string                 // Matches the result to its request
+	Request  *ChatCompletionRequest // Sent to /v1/chat/completions
+}
+
+// OpenAIBatchResult is one line of a batch output or error file
+type OpenAIBatchResult struct {
+	CustomID string
+	Response *ChatCompletionResponse // Set when the request succeeded
+	Err      error                   // Set when the request failed
+}
+
+// OpenAIBatchClient submits chat completions to OpenAI's Batch API, which runs them
+// within 24 hours at half the regular price
+type OpenAIBatchClient struct {
+	provider openAIProvider
+	baseURL  string // e.g. https://api.openai.com/v1
+}
+
+// NewOpenAIBatchClient creates a Batch API client. The base URL is derived from the
+// configured chat completions or responses URL.
+func NewOpenAIBatchClient(config ProviderConfig) *OpenAIBatchClient {
+	baseURL := config.URL
+	for _, suffix := range []string{"/chat/completions", "/responses"} {
+		baseURL = strings.TrimSuffix(baseURL, suffix)
+	}
+	if baseURL == "" {
+		baseURL = "https://api.openai.com/v1"
+	}
+	return &OpenAIBatchClient{
+		provider: openAIProvider{config: config, name: string(ProviderNameOpenAI), headers: openAIAccountHeaders(config)},
+		baseURL:  strings.TrimSuffix(baseURL, "/"),
+	}
+}
+
+// Submit uploads requests as a JSONL input file and creates a batch job for them
+func (c *OpenAIBatchClient) Submit(ctx context.Context, requests []OpenAIBatchRequest) (*OpenAIBatch, error) {
+	var input bytes.Buffer
+	encoder := json.NewEncoder(&input)
+	for _, r := range requests {
+		body, err := c.provider.buildRequest(r.Request)
+		if err != nil {
+			return nil, fmt.Errorf("failed to build request %s: %w", r.CustomID, err)
+		}
+		line := map[string]interface{}{
+			"custom_id": r.CustomID,
+			"method":    http.MethodPost,
+			"url":       "/v1/chat/completions",
+			"body":      body,
+		}
+		if err := encoder.Encode(line); err != nil {
+			return nil, fmt.Errorf("failed to encode request %s: %w", r.CustomID, err)
+		}
+	}
+
+	fileID, err := c.uploadFile(ctx, input.Bytes())
+	if err != nil {
+		return nil, err
+	}
+
+	payload, err := json.Marshal(map[string]string{
+		"input_file_id":     fileID,
+		"endpoint":          "/v1/chat/completions",
+		"completion_window": "24h",
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal batch request: %w", err)
+	}
+
+	var batch OpenAIBatch
+	if err := c.do(ctx, http.MethodPost, "/batches", "application/json", bytes.NewReader(payload), &batch); err != nil {
+		return nil, fmt.Errorf("failed to create batch: %w", err)
+	}
+	return &batch, nil
+}
+
+// Get retrieves the current state of a batch job
+func (c *OpenAIBatchClient) Get(ctx context.Context, batchID string) (*OpenAIBatch, error) {
+	var batch OpenAIBatch
+	if err := c.do(ctx, http.MethodGet, "/batches/"+batchID, "", nil, &batch); err != nil {
+		return nil, fmt.Errorf("failed to get batch %s: %w", batchID, err)
+	}
+	return &batch, nil
+}
+
+// Wait polls a batch job every interval until it reaches a final status. onPoll, if
+// not nil, is called with every state retrieved.
+func (c *OpenAIBatchClient) Wait(ctx context.Context, batchID string, interval time.Duration, onPoll func(*OpenAIBatch)) (*OpenAIBatch, error) {
+	for {
+		batch, err := c.Get(ctx, batchID)
+		if err != nil {
+			return nil, err
+		}
+		if onPoll != nil {
+			onPoll(batch)
+		}
+		if batch.Done() {
+			return batch, nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return nil, ctx.Err()
+		case <-time.After(interval):
+		}
+	}
+}
+
+// Results downloads the output and error files of a finished batch job
+func (c *OpenAIBatchClient) Results(ctx context.Context, batch *OpenAIBatch) ([]OpenAIBatchResult, error) {
+	var results []OpenAIBatchResult
+	for _, fileID := range []string{batch.OutputFileID, batch.ErrorFileID} {
+		if fileID == "" {
+			continue
+		}
+		var content bytes.Buffer
+		if err := c.do(ctx, http.MethodGet, "/files/"+fileID+"/content", "", nil, &content); err != nil {
+			return nil, fmt.Errorf("failed to download batch file %s: %w", fileID, err)
+		}
+		fileResults, err := parseBatchResults(content.Bytes())
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, fileResults...)
+	}
+	return results, nil
+}
+
+// parseBatchResults decodes a JSONL batch output or error file
+func parseBatchResults(data []byte) ([]OpenAIBatchResult, error) {
+	var results []OpenAIBatchResult
+	for _, line := range bytes.Split(data, []byte("\n")) {
+		if len(bytes.TrimSpace(line)) == 0 {
+			continue
+		}
+
+		var raw struct {
+			CustomID string `json:"custom_id"`
+			Response *struct {
+				StatusCode int             `json:"status_code"`
+				Body       json.RawMessage `json:"body"`
+			} `json:"response"`
+			Error *struct {
+				Code    string `json:"code"`
+				Message string `json:"message"`
+			} `json:"error"`
+		}
+		if err := json.Unmarshal(line, &raw); err != nil {
+			return nil, fmt.Errorf("failed to decode batch result: %w", err)
+		}
+
+		result := OpenAIBatchResult{CustomID: raw.CustomID}
+		switch {
+		case raw.Error != nil:
+			result.Err = fmt.Errorf("OpenAI batch error: %s (code: %s)", raw.Error.Message, raw.Error.Code)
+		case raw.Response == nil:
+			result.Err = fmt.Errorf("OpenAI batch result has no response")
+		case raw.Response.StatusCode != http.StatusOK:
+			result.Err = (&openAIProvider{}).handleOpenAIError(raw.Response.StatusCode, raw.Response.Body)
+		default:
+			var resp ChatCompletionResponse
+			if err := json.Unmarshal(raw.Response.Body, &resp); err != nil {
+				return nil, fmt.Errorf("failed to decode batch response %s: %w", raw.CustomID, err)
+			}
+			result.Response = &resp
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// uploadFile uploads a batch input file and returns its ID
+func (c *OpenAIBatchClient) uploadFile(ctx context.Context, content []byte) (string, error) {
+	var body bytes.Buffer
+	writer := multipart.NewWriter(&body)
+	if err := writer.WriteField("purpose", "batch"); err != nil {
+		return "", fmt.Errorf("failed to build upload: %w", err)
+	}
+	part, err := writer.CreateFormFile("file", "batch.jsonl")
+	if err != nil {
+		return "", fmt.Errorf("failed to build upload: %w", err)
+	}
+	if _, err := part.Write(content); err != nil {
+		return "", fmt.Errorf("failed to build upload: %w", err)
+	}
+	if err := writer.Close(); err != nil {
+		return "", fmt.Errorf("failed to build upload: %w", err)
+	}
+
+	var file struct {
+		ID string `json:"id"`
+	}
+	if err := c.do(ctx, http.MethodPost, "/files", writer.FormDataContentType(), &body, &file); err != nil {
+		return "", fmt.Errorf("failed to upload batch file: %w", err)
+	}
+	return file.ID, nil
+}
+
+// do sends a request to the API and decodes the JSON response into out, or copies
+// the raw body when out is a *bytes.Buffer
+func (c *OpenAIBatchClient) do(ctx context.Context, method, path, contentType string, body io.Reader, out interface{}) error {
+	httpReq, err := http.NewRequestWithContext(ctx, method, c.baseURL+path, body)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	if contentType != "" {
+		httpReq.Header.Set("Content-Type", contentType)
+	}
+	setRequestIDHeader(httpReq)
+	if c.provider.config.APIKey != "" {
+		httpReq.Header.Set("Authorization", "Bearer "+c.provider.config.APIKey)
+	}
+	for name, value := range c.provider.headers {
+		httpReq.Header.Set(name, value)
+	}
+
+	timeout := time.Duration(c.provider.config.Timeout) * time.Second
+	if timeout <= 0 {
+		timeout = 60 * time.Second
+	}
+	resp, err := newHTTPClient(timeout).Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return c.provider.handleOpenAIError(resp.StatusCode, data)
+	}
+
+	if buf, ok := out.(*bytes.Buffer); ok {
+		buf.Write(data)
+		return nil
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
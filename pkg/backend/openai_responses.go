@@ -0,0 +1,274 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// OpenAI transports selectable with ProviderConfig.API
+const (
+	OpenAIAPIChat      = "chat"      // /v1/chat/completions (default)
+	OpenAIAPIResponses = "responses" // /v1/responses, required by some reasoning models
+)
+
+// OpenAIResponsesURL is the endpoint of OpenAI's Responses API
+const OpenAIResponsesURL = "https://api.openai.com/v1/responses"
+
+// NewOpenAIResponsesProvider creates an OpenAI provider that talks to the Responses API
+// and normalizes its output into chat completion responses
+func NewOpenAIResponsesProvider(config ProviderConfig) Provider {
+	if config.URL == "" {
+		config.URL = OpenAIResponsesURL
+	}
+	return &openAIResponsesProvider{openAIProvider{config: config, name: string(ProviderNameOpenAI), headers: openAIAccountHeaders(config)}}
+}
+
+// openAIResponsesProvider implements Provider on top of OpenAI's /v1/responses endpoint.
+// It reuses the chat provider's transport and error handling.
+type openAIResponsesProvider struct {
+	openAIProvider
+}
+
+// buildResponsesRequest creates the Responses API request body. System messages become
+// the instructions; tool calls and results become function_call and function_call_output items.
+func (p *openAIResponsesProvider) buildResponsesRequest(req *ChatCompletionRequest) (map[string]interface{}, error) {
+	model := req.Model
+	if model == "" {
+		model = p.config.Model
+	}
+	if model == "" {
+		return nil, fmt.Errorf("model must be specified")
+	}
+	if len(req.Messages) == 0 {
+		return nil, fmt.Errorf("messages cannot be empty")
+	}
+
+	var instructions []string
+	input := make([]map[string]interface{}, 0, len(req.Messages))
+	for _, msg := range req.Messages {
+		switch msg.Role {
+		case RoleSystem:
+			instructions = append(instructions, msg.Content)
+		case RoleTool:
+			input = append(input, map[string]interface{}{
+				"type":    "function_call_output",
+				"call_id": msg.ToolCallID,
+				"output":  msg.Content,
+			})
+		default:
+			if msg.Content != "" || len(msg.ToolCalls) == 0 {
+				input = append(input, map[string]interface{}{
+					"role":    string(msg.Role),
+					"content": msg.Content,
+				})
+			}
+			for _, call := range msg.ToolCalls {
+				input = append(input, map[string]interface{}{
+					"type":      "function_call",
+					"call_id":   call.ID,
+					"name":      call.Function.Name,
+					"arguments": call.Function.Arguments,
+				})
+			}
+		}
+	}
+
+	responsesReq := map[string]interface{}{
+		"model": model,
+		"input": input,
+		"store": false, // The conversation is kept locally, not on OpenAI's servers
+	}
+	if len(instructions) > 0 {
+		responsesReq["instructions"] = strings.Join(instructions, "\n\n")
+	}
+	if req.MaxTokens != nil {
+		responsesReq["max_output_tokens"] = *req.MaxTokens
+	}
+	if req.Temperature != nil {
+		responsesReq["temperature"] = *req.Temperature
+	}
+	if len(req.Tools) > 0 {
+		responsesReq["tools"] = responsesTools(req.Tools)
+	}
+	if req.JSONMode {
+		responsesReq["text"] = map[string]interface{}{"format": map[string]string{"type": "json_object"}}
+	}
+	if req.User != "" {
+		responsesReq["user"] = req.User
+	}
+
+	return responsesReq, nil
+}
+
+// responsesTools converts tool definitions to the Responses API's flat function tool format
+func responsesTools(tools []ToolDefinition) []map[string]interface{} {
+	converted := make([]map[string]interface{}, 0, len(tools))
+	for _, tool := range tools {
+		parameters := tool.Parameters
+		if len(parameters) == 0 {
+			parameters = json.RawMessage(`{"type":"object","properties":{}}`)
+		}
+		converted = append(converted, map[string]interface{}{
+			"type":        "function",
+			"name":        tool.Name,
+			"description": tool.Description,
+			"parameters":  parameters,
+		})
+	}
+	return converted
+}
+
+// responsesResult is the response object returned by the Responses API
+type responsesResult struct {
+	ID     string `json:"id"`
+	Model  string `json:"model"`
+	Status string `json:"status"` // "completed", "incomplete", or "failed"
+	Output []struct {
+		Type    string `json:"type"` // "message", "function_call", "reasoning", ...
+		Content []struct {
+			Type string `json:"type"`
+			Text string `json:"text"`
+		} `json:"content"`
+		CallID    string `json:"call_id"`
+		Name      string `json:"name"`
+		Arguments string `json:"arguments"`
+	} `json:"output"`
+	Usage *struct {
+		InputTokens  int `json:"input_tokens"`
+		OutputTokens int `json:"output_tokens"`
+		TotalTokens  int `json:"total_tokens"`
+	} `json:"usage"`
+	Error *struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	} `json:"error"`
+}
+
+// toChatCompletion normalizes a Responses API result into a chat completion response
+func (r *responsesResult) toChatCompletion() (*ChatCompletionResponse, error) {
+	if r.Status == "failed" && r.Error != nil {
+		return nil, fmt.Errorf("OpenAI response failed: %s (code: %s)", r.Error.Message, r.Error.Code)
+	}
+
+	var content strings.Builder
+	var toolCalls []ToolCall
+	for _, item := range r.Output {
+		switch item.Type {
+		case "message":
+			for _, part := range item.Content {
+				if part.Type == "output_text" {
+					content.WriteString(part.Text)
+				}
+			}
+		case "function_call":
+			toolCalls = append(toolCalls, ToolCall{
+				ID:       item.CallID,
+				Type:     "function",
+				Function: ToolCallFunction{Name: item.Name, Arguments: item.Arguments},
+			})
+		}
+	}
+
+	finishReason := FinishStop
+	switch {
+	case len(toolCalls) > 0:
+		finishReason = FinishToolCalls
+	case r.Status == "incomplete":
+		finishReason = FinishLength
+	}
+
+	response := &ChatCompletionResponse{
+		ID:    r.ID,
+		Model: r.Model,
+		Choices: []Choice{{
+			Index:        0,
+			Message:      Message{Role: RoleAssistant, Content: content.String(), ToolCalls: toolCalls},
+			FinishReason: finishReason,
+		}},
+	}
+	if r.Usage != nil {
+		response.Usage = &Usage{
+			PromptTokens:     r.Usage.InputTokens,
+			CompletionTokens: r.Usage.OutputTokens,
+			TotalTokens:      r.Usage.TotalTokens,
+		}
+	}
+	return response, nil
+}
+
+func (p *openAIResponsesProvider) CreateCompletion(ctx context.Context, req *ChatCompletionRequest) (*ChatCompletionResponse, error) {
+	responsesReq, err := p.buildResponsesRequest(req)
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := p.send(ctx, responsesReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	body, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read response: %w", err)
+	}
+
+	var result responsesResult
+	if err := json.Unmarshal(body, &result); err != nil {
+		return nil, fmt.Errorf("failed to decode response: %w", err)
+	}
+	return result.toChatCompletion()
+}
+
+// CreateCompletionStream streams a response using server-sent events. Text deltas are
+// passed to onDelta as they arrive; the final response event carries the full output.
+func (p *openAIResponsesProvider) CreateCompletionStream(ctx context.Context, req *ChatCompletionRequest, onDelta StreamHandler) (*ChatCompletionResponse, error) {
+	responsesReq, err := p.buildResponsesRequest(req)
+	if err != nil {
+		return nil, err
+	}
+	responsesReq["stream"] = true
+
+	resp, err := p.send(ctx, responsesReq)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+
+	var final *responsesResult
+	err = readServerSentEvents(resp.Body, func(_, data string) error {
+		var event struct {
+			Type     string           `json:"type"`
+			Delta    string           `json:"delta"`
+			Response *responsesResult `json:"response"`
+			Message  string           `json:"message"`
+			Code     string           `json:"code"`
+		}
+		if err := json.Unmarshal([]byte(data), &event); err != nil {
+			return fmt.Errorf("failed to decode stream event: %w", err)
+		}
+
+		switch event.Type {
+		case "response.output_text.delta":
+			if event.Delta != "" && onDelta != nil {
+				onDelta(event.Delta)
+			}
+		case "response.completed", "response.incomplete", "response.failed":
+			final = event.Response
+			return io.EOF
+		case "error":
+			return fmt.Errorf("OpenAI stream error: %s (code: %s)", event.Message, event.Code)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read stream: %w", err)
+	}
+	if final == nil {
+		return nil, fmt.Errorf("failed to read stream: ended before the response completed")
+	}
+	return final.toChatCompletion()
+}
@@ -0,0 +1,173 @@
+package backend
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OpenAIOrganizationURL is the base of OpenAI's organization usage and costs APIs
+const OpenAIOrganizationURL = "https://api.openai.com/v1/organization"
+
+// snapshotSuffix matches the date OpenAI appends to the models it reports usage for,
+// as in gpt-4o-mini-2024-07-18
+var snapshotSuffix = regexp.MustCompile(`-\d{4}-\d{2}-\d{2}$`)
+
+// OpenAIBaseModel removes the snapshot date from model, so gpt-4o-mini-2024-07-18 and
+// gpt-4o-mini are counted together
+func OpenAIBaseModel(model string) string {
+	return snapshotSuffix.ReplaceAllString(strings.TrimSpace(model), "")
+}
+
+// OpenAIModelUsage is what OpenAI recorded for one model over a period
+type OpenAIModelUsage struct {
+	Model        string  // Snapshot date removed, to match the model requested
+	Requests     int     // Requests to the model
+	InputTokens  int     // Prompt tokens, cached ones included
+	OutputTokens int     // Completion tokens
+	Cost         float64 // Billed USD; 0 when the costs API had no line item for the model
+}
+
+// TotalTokens is the sum of input and output tokens
+func (u OpenAIModelUsage) TotalTokens() int {
+	return u.InputTokens + u.OutputTokens
+}
+
+// OpenAIUsageClient reads what OpenAI recorded and billed for an organization. The
+// usage and costs APIs need an admin key, not a project API key.
+type OpenAIUsageClient struct {
+	adminKey string
+	project  string // Only this project's usage is read when set
+	baseURL  string
+	client   *http.Client
+}
+
+// NewOpenAIUsageClient creates a usage client for the organization adminKey belongs to,
+// limited to project when it isn't empty
+func NewOpenAIUsageClient(adminKey, project string) *OpenAIUsageClient {
+	return &OpenAIUsageClient{
+		adminKey: adminKey,
+		project:  project,
+		baseURL:  OpenAIOrganizationURL,
+		client:   newHTTPClient(60 * time.Second),
+	}
+}
+
+// usagePage is one page of the usage and costs APIs, which group results in time buckets
+type usagePage struct {
+	Data []struct {
+		Results []struct {
+			Model            string `json:"model"`
+			InputTokens      int    `json:"input_tokens"`
+			OutputTokens     int    `json:"output_tokens"`
+			NumModelRequests int    `json:"num_model_requests"`
+			LineItem         string `json:"line_item"`
+			Amount           struct {
+				Value float64 `json:"value"`
+			} `json:"amount"`
+		} `json:"results"`
+	} `json:"data"`
+	HasMore  bool   `json:"has_more"`
+	NextPage string `json:"next_page"`
+}
+
+// Usage returns the tokens and cost OpenAI recorded for each model between start and
+// end. Costs are billed per line item, such as "gpt-4o-2024-08-06, input", so they are
+// folded into the model the item names.
+func (c *OpenAIUsageClient) Usage(ctx context.Context, start, end time.Time) (map[string]*OpenAIModelUsage, error) {
+	usage := make(map[string]*OpenAIModelUsage)
+	entry := func(model string) *OpenAIModelUsage {
+		model = OpenAIBaseModel(model)
+		if usage[model] == nil {
+			usage[model] = &OpenAIModelUsage{Model: model}
+		}
+		return usage[model]
+	}
+
+	err := c.pages(ctx, "/usage/completions", start, end, "model", func(page *usagePage) {
+		for _, bucket := range page.Data {
+			for _, r := range bucket.Results {
+				u := entry(r.Model)
+				u.Requests += r.NumModelRequests
+				u.InputTokens += r.InputTokens
+				u.OutputTokens += r.OutputTokens
+			}
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read usage: %w", err)
+	}
+
+	err = c.pages(ctx, "/costs", start, end, "line_item", func(page *usagePage) {
+		for _, bucket := range page.Data {
+			for _, r := range bucket.Results {
+				model, _, _ := strings.Cut(r.LineItem, ",")
+				entry(model).Cost += r.Amount.Value
+			}
+		}
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to read costs: %w", err)
+	}
+	return usage, nil
+}
+
+// pages reads every page of daily buckets from path, grouped by groupBy
+func (c *OpenAIUsageClient) pages(ctx context.Context, path string, start, end time.Time, groupBy string, onPage func(*usagePage)) error {
+	query := url.Values{}
+	query.Set("start_time", strconv.FormatInt(start.Unix(), 10))
+	query.Set("end_time", strconv.FormatInt(end.Unix(), 10))
+	query.Set("bucket_width", "1d")
+	query.Set("limit", "31")
+	query.Set("group_by", groupBy)
+	if c.project != "" {
+		query.Set("project_ids", c.project)
+	}
+
+	for {
+		var page usagePage
+		if err := c.get(ctx, path+"?"+query.Encode(), &page); err != nil {
+			return err
+		}
+		onPage(&page)
+		if !page.HasMore || page.NextPage == "" {
+			return nil
+		}
+		query.Set("page", page.NextPage)
+	}
+}
+
+// get decodes the JSON response to a GET of path into out
+func (c *OpenAIUsageClient) get(ctx context.Context, path string, out interface{}) error {
+	httpReq, err := http.NewRequestWithContext(ctx, http.MethodGet, c.baseURL+path, nil)
+	if err != nil {
+		return fmt.Errorf("failed to create request: %w", err)
+	}
+	setRequestIDHeader(httpReq)
+	httpReq.Header.Set("Authorization", "Bearer "+c.adminKey)
+
+	resp, err := c.client.Do(httpReq)
+	if err != nil {
+		return fmt.Errorf("request failed: %w", err)
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(resp.Body)
+	if err != nil {
+		return fmt.Errorf("failed to read response: %w", err)
+	}
+	if resp.StatusCode != http.StatusOK {
+		return (&openAIProvider{}).handleOpenAIError(resp.StatusCode, data)
+	}
+	if err := json.Unmarshal(data, out); err != nil {
+		return fmt.Errorf("failed to decode response: %w", err)
+	}
+	return nil
+}
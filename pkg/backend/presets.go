@@ -0,0 +1,89 @@
+package backend
+
+// Hosted providers with OpenAI-compatible chat completions APIs
+const (
+	ProviderNameGroq       ProviderName = "groq"
+	ProviderNameMistral    ProviderName = "mistral"
+	ProviderNameDeepSeek   ProviderName = "deepseek"
+	ProviderNameOpenRouter ProviderName = "openrouter"
+	ProviderNameXAI        ProviderName = "xai"
+)
+
+// Preset holds what it takes to use an OpenAI-compatible provider through the OpenAI
+// provider, so users don't have to find the right URL and model name themselves
+type Preset struct {
+	Label        string            // Name of the API in error messages
+	URL          string            // Chat completions endpoint
+	DefaultModel string            // Used when MODEL isn't set
+	CostPerToken float64           // Approximate USD per token of DefaultModel, input and output blended
+	Headers      map[string]string // Sent with every request, in addition to the bearer token
+	StreamUsage  bool              // Accepts stream_options to report usage at the end of a stream
+}
+
+// presets are keyed by LLM_PROVIDER value
+var presets = map[ProviderName]Preset{
+	ProviderNameGroq: {
+		Label:        "Groq",
+		URL:          "https://api.groq.com/openai/v1/chat/completions",
+		DefaultModel: "llama-3.3-70b-versatile",
+		CostPerToken: 0.0000007,
+		StreamUsage:  true,
+	},
+	ProviderNameMistral: {
+		Label:        "Mistral",
+		URL:          "https://api.mistral.ai/v1/chat/completions",
+		DefaultModel: "mistral-small-latest",
+		CostPerToken: 0.0000002,
+		// Mistral rejects unknown request fields and reports usage in the last chunk anyway
+		StreamUsage: false,
+	},
+	ProviderNameDeepSeek: {
+		Label:        "DeepSeek",
+		URL:          "https://api.deepseek.com/v1/chat/completions",
+		DefaultModel: "deepseek-chat",
+		CostPerToken: 0.0000004,
+		StreamUsage:  true,
+	},
+	ProviderNameOpenRouter: {
+		Label:        "OpenRouter",
+		URL:          "https://openrouter.ai/api/v1/chat/completions",
+		DefaultModel: "openai/gpt-4o-mini",
+		CostPerToken: 0.0000004,
+		// OpenRouter attributes requests to the app named in these optional headers
+		Headers: map[string]string{
+			"HTTP-Referer": "https://github.com/nleiva/chatgbt",
+			"X-Title":      "ChatGBT",
+		},
+		StreamUsage: true,
+	},
+	ProviderNameXAI: {
+		Label:        "xAI",
+		URL:          "https://api.x.ai/v1/chat/completions",
+		DefaultModel: "grok-3-mini",
+		CostPerToken: 0.0000004,
+		StreamUsage:  true,
+	},
+}
+
+// LookupPreset returns the preset for provider, if it has one
+func LookupPreset(provider ProviderName) (Preset, bool) {
+	preset, ok := presets[provider]
+	return preset, ok
+}
+
+// newPresetProvider creates an OpenAI provider for a preset's API
+func newPresetProvider(config ProviderConfig, preset Preset) Provider {
+	if config.URL == "" {
+		config.URL = preset.URL
+	}
+	if config.Model == "" {
+		config.Model = preset.DefaultModel
+	}
+	return &openAIProvider{
+		config:        config,
+		name:          string(config.Name),
+		label:         preset.Label,
+		headers:       preset.Headers,
+		noStreamUsage: !preset.StreamUsage,
+	}
+}
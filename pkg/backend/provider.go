@@ -2,7 +2,9 @@ package backend
 
 import (
 	"context"
+	"encoding/json"
 	"fmt"
+	"strings"
 )
 
 // Provider interface defines the contract for LLM providers
@@ -11,6 +13,8 @@ type Provider interface {
 	CreateCompletion(ctx context.Context, req *ChatCompletionRequest) (*ChatCompletionResponse, error)
 	// Name returns the provider name
 	Name() string
+	// Capabilities reports what the provider and its configured model support
+	Capabilities() Capabilities
 }
 
 // ProviderName represents the different LLM provider names
@@ -20,15 +24,35 @@ const (
 	ProviderNameOpenAI    ProviderName = "openai"
 	ProviderNameAnthropic ProviderName = "anthropic"
 	ProviderNameBedrock   ProviderName = "bedrock"
+	ProviderNameOllama    ProviderName = "ollama"
 )
 
+// OpenAIDefaultURL is the endpoint of OpenAI's Chat Completions API
+const OpenAIDefaultURL = "https://api.openai.com/v1/chat/completions"
+
+// OllamaDefaultURL is the OpenAI-compatible chat endpoint of a local Ollama server
+const OllamaDefaultURL = "http://localhost:11434/v1/chat/completions"
+
+// OllamaDefaultModel is the model Ollama requests use when none is configured
+const OllamaDefaultModel = "llama3.2"
+
+// AnthropicDefaultURL is the endpoint of Anthropic's Messages API
+const AnthropicDefaultURL = "https://api.anthropic.com/v1/messages"
+
+// AnthropicDefaultModel is the model Anthropic requests use when none is configured
+const AnthropicDefaultModel = "claude-sonnet-4-20250514"
+
 // ProviderConfig holds configuration for provider selection and initialization
 type ProviderConfig struct {
-	Name    ProviderName `json:"name"`    // Provider name (openai, anthropic, bedrock)
+	Name    ProviderName `json:"name"`    // Provider name (openai, anthropic, ollama, bedrock, or a preset such as groq)
 	APIKey  string       `json:"api_key"` // API key for authentication
 	URL     string       `json:"url"`     // API endpoint URL
 	Model   string       `json:"model"`   // Model identifier
 	Timeout int          `json:"timeout"` // Request timeout in seconds
+	API     string       `json:"api"`     // OpenAI transport: OpenAIAPIChat (default) or OpenAIAPIResponses
+	// OpenAI organization and project usage is billed to; empty uses the key's defaults
+	Organization string `json:"organization,omitempty"`
+	Project      string `json:"project,omitempty"`
 }
 
 // LLMConfig holds configuration for LLM API interactions (legacy compatibility)
@@ -36,8 +60,12 @@ type LLMConfig struct {
 	APIKey    string       `json:"api_key"`    // API key for authentication
 	URL       string       `json:"url"`        // API endpoint URL
 	Model     string       `json:"model"`      // Model identifier
-	Provider  ProviderName `json:"provider"`   // Provider name (openai, anthropic, bedrock)
+	Provider  ProviderName `json:"provider"`   // Provider name (openai, anthropic, ollama, bedrock)
 	ShowUsage bool         `json:"show_usage"` // Whether to return token usage information in responses
+	API       string       `json:"api"`        // OpenAI transport: OpenAIAPIChat (default) or OpenAIAPIResponses
+	// OpenAI organization and project usage is billed to; empty uses the key's defaults
+	Organization string `json:"organization,omitempty"`
+	Project      string `json:"project,omitempty"`
 }
 
 // Role represents the different message roles in a conversation
@@ -47,12 +75,54 @@ const (
 	RoleSystem    Role = "system"    // System messages help set the behavior of the assistant
 	RoleUser      Role = "user"      // User messages are requests or comments from the end-user
 	RoleAssistant Role = "assistant" // Assistant messages are responses from the AI assistant
+	RoleTool      Role = "tool"      // Tool messages carry the result of a tool call back to the model
 )
 
 // Message represents a single message in the conversation
 type Message struct {
-	Role    Role   `json:"role"`    // The role of the message author
-	Content string `json:"content"` // The contents of the message
+	Role       Role       `json:"role"`                   // The role of the message author
+	Content    string     `json:"content"`                // The contents of the message
+	ToolCalls  []ToolCall `json:"tool_calls,omitempty"`   // Tools the assistant asked to call
+	ToolCallID string     `json:"tool_call_id,omitempty"` // The call a tool message answers
+	Pinned     bool       `json:"-"`                      // Pinned messages at the start of the context are never pruned
+}
+
+// ToolCall is a request from the model to run a tool
+type ToolCall struct {
+	ID       string           `json:"id"`   // Identifier echoed back in the tool result
+	Type     string           `json:"type"` // Always "function"
+	Function ToolCallFunction `json:"function"`
+}
+
+// ToolCallFunction names the tool and carries its JSON-encoded arguments
+type ToolCallFunction struct {
+	Name      string `json:"name"`
+	Arguments string `json:"arguments"`
+}
+
+// ToolDefinition describes a tool the model may call
+type ToolDefinition struct {
+	Name        string          `json:"name"`
+	Description string          `json:"description"`
+	Parameters  json.RawMessage `json:"parameters"` // JSON schema of the arguments object
+}
+
+// FewShotExample is an example exchange injected after the system prompt to steer responses
+type FewShotExample struct {
+	User      string `json:"user" yaml:"user"`
+	Assistant string `json:"assistant" yaml:"assistant"`
+}
+
+// FewShotMessages converts examples into pinned user/assistant message pairs
+func FewShotMessages(examples []FewShotExample) []Message {
+	messages := make([]Message, 0, len(examples)*2)
+	for _, ex := range examples {
+		messages = append(messages,
+			Message{Role: RoleUser, Content: ex.User, Pinned: true},
+			Message{Role: RoleAssistant, Content: ex.Assistant, Pinned: true},
+		)
+	}
+	return messages
 }
 
 // ChatCompletionRequest represents a chat completion request
@@ -61,6 +131,13 @@ type ChatCompletionRequest struct {
 	Messages    []Message `json:"messages"`              // A list of messages comprising the conversation
 	MaxTokens   *int      `json:"max_tokens,omitempty"`  // The maximum number of tokens that can be generated
 	Temperature *float64  `json:"temperature,omitempty"` // Sampling temperature between 0 and 2
+
+	Tools    []ToolDefinition `json:"-"` // Tools the model may call; mapped to each provider's format
+	JSONMode bool             `json:"-"` // Constrain the reply to a JSON object; see Capabilities.JSONMode
+
+	// User is a stable, opaque ID of the person asking, sent to OpenAI as user and to
+	// Anthropic as metadata.user_id for abuse monitoring and per-user analytics
+	User string `json:"-"`
 }
 
 // ChatCompletionResponse represents a chat completion response
@@ -71,6 +148,14 @@ type ChatCompletionResponse struct {
 	Usage   *Usage   `json:"usage"`   // Usage statistics for the completion request
 }
 
+// Finish reasons as OpenAI reports them; the other providers' reasons are mapped to these
+const (
+	FinishStop      = "stop"       // The model ended the reply
+	FinishLength    = "length"     // The reply was cut off at max_tokens
+	FinishToolCalls = "tool_calls" // The model asked to call tools
+	FinishAborted   = "aborted"    // The user stopped the reply while it was streamed
+)
+
 // Choice represents a single completion choice
 type Choice struct {
 	Index        int     `json:"index"`         // Index of the choice in the list
@@ -97,13 +182,113 @@ type APIError struct {
 	Code    string `json:"code"`    // Error code
 }
 
+// StatusError is returned when a provider answers with an HTTP error. It keeps the
+// status so callers can tell rejected keys, rate limits, and outages apart.
+type StatusError struct {
+	StatusCode int
+	Err        error // Provider's error, as parsed from the response body
+}
+
+func (e *StatusError) Error() string {
+	return e.Err.Error()
+}
+
+func (e *StatusError) Unwrap() error {
+	return e.Err
+}
+
+// DefaultModelFor returns the model provider uses when none is configured, or "" when
+// it has no default of its own
+func DefaultModelFor(provider ProviderName) string {
+	if preset, ok := presets[provider]; ok {
+		return preset.DefaultModel
+	}
+	switch provider {
+	case ProviderNameAnthropic:
+		return AnthropicDefaultModel
+	case ProviderNameOllama:
+		return OllamaDefaultModel
+	case ProviderNameHuggingFace:
+		return HuggingFaceDefaultModel
+	case ProviderNameVertex:
+		return VertexDefaultModel
+	}
+	return ""
+}
+
+// DefaultURLFor returns the endpoint provider is called at when none is configured, or
+// "" when it isn't known from the provider alone, as for Vertex AI, whose URL names the
+// project
+func DefaultURLFor(provider ProviderName) string {
+	if preset, ok := presets[provider]; ok {
+		return preset.URL
+	}
+	switch provider {
+	case ProviderNameOpenAI:
+		return OpenAIDefaultURL
+	case ProviderNameAnthropic:
+		return AnthropicDefaultURL
+	case ProviderNameOllama:
+		return OllamaDefaultURL
+	case ProviderNameHuggingFace:
+		return HuggingFaceDefaultURL
+	}
+	return ""
+}
+
+// modelFamilies maps model name prefixes to the provider that serves the family natively
+var modelFamilies = []struct {
+	prefix   string
+	provider ProviderName
+}{
+	{"gpt-", ProviderNameOpenAI},
+	{"chatgpt-", ProviderNameOpenAI},
+	{"o1", ProviderNameOpenAI},
+	{"o3", ProviderNameOpenAI},
+	{"o4", ProviderNameOpenAI},
+	{"claude-", ProviderNameAnthropic},
+	{"google/gemini-", ProviderNameVertex},
+	{"grok-", ProviderNameXAI},
+	{"mistral-", ProviderNameMistral},
+	{"codestral-", ProviderNameMistral},
+	{"deepseek-", ProviderNameDeepSeek},
+}
+
+// ProviderForModel returns the provider that serves model natively, judging by its
+// name, or "" for models it doesn't recognize
+func ProviderForModel(model string) ProviderName {
+	model = strings.ToLower(model)
+	for _, family := range modelFamilies {
+		if strings.HasPrefix(model, family.prefix) {
+			return family.provider
+		}
+	}
+	return ""
+}
+
 // CreateProvider creates a new provider instance based on the configuration
 func CreateProvider(config ProviderConfig) (Provider, error) {
 	switch config.Name {
 	case ProviderNameOpenAI:
+		if config.API == OpenAIAPIResponses {
+			return NewOpenAIResponsesProvider(config), nil
+		}
 		return NewOpenAIProvider(config), nil
 	case ProviderNameAnthropic:
 		return NewAnthropicProvider(config), nil
+	case ProviderNameOllama:
+		// Ollama exposes an OpenAI-compatible API and doesn't require an API key
+		if config.URL == "" {
+			config.URL = OllamaDefaultURL
+		}
+		return &openAIProvider{config: config, name: string(ProviderNameOllama)}, nil
+	case ProviderNameGroq, ProviderNameMistral, ProviderNameDeepSeek, ProviderNameOpenRouter, ProviderNameXAI:
+		preset, _ := LookupPreset(config.Name)
+		return newPresetProvider(config, preset), nil
+	case ProviderNameHuggingFace:
+		return NewHuggingFaceProvider(config), nil
+	case ProviderNameVertex:
+		return NewVertexProvider(config)
 	case ProviderNameBedrock:
 		// TODO: Implement Bedrock provider
 		return nil, fmt.Errorf("bedrock provider not yet implemented")
@@ -0,0 +1,52 @@
+package backend
+
+import (
+	"slices"
+	"strings"
+)
+
+// RateLimit is how much a provider accepts per minute; a zero field is no limit
+type RateLimit struct {
+	RequestsPerMinute int
+	TokensPerMinute   int
+}
+
+// rateLimitTiers hold the published default limits of each provider's usage tiers for
+// its flagship models. Smaller models often allow more, so these err on the safe side;
+// RATE_LIMIT_RPM and RATE_LIMIT_TPM set exact values.
+var rateLimitTiers = map[ProviderName]map[string]RateLimit{
+	ProviderNameOpenAI: {
+		"free":  {RequestsPerMinute: 3, TokensPerMinute: 40000},
+		"tier1": {RequestsPerMinute: 500, TokensPerMinute: 30000},
+		"tier2": {RequestsPerMinute: 5000, TokensPerMinute: 450000},
+		"tier3": {RequestsPerMinute: 5000, TokensPerMinute: 800000},
+		"tier4": {RequestsPerMinute: 10000, TokensPerMinute: 2000000},
+		"tier5": {RequestsPerMinute: 10000, TokensPerMinute: 30000000},
+	},
+	ProviderNameAnthropic: {
+		"tier1": {RequestsPerMinute: 50, TokensPerMinute: 40000},
+		"tier2": {RequestsPerMinute: 1000, TokensPerMinute: 80000},
+		"tier3": {RequestsPerMinute: 2000, TokensPerMinute: 160000},
+		"tier4": {RequestsPerMinute: 4000, TokensPerMinute: 400000},
+	},
+	ProviderNameGroq: {
+		"free":      {RequestsPerMinute: 30, TokensPerMinute: 6000},
+		"developer": {RequestsPerMinute: 1000, TokensPerMinute: 300000},
+	},
+}
+
+// LookupRateLimitTier returns the limits of provider's usage tier, such as "tier1"
+func LookupRateLimitTier(provider ProviderName, tier string) (RateLimit, bool) {
+	limit, ok := rateLimitTiers[provider][strings.ToLower(tier)]
+	return limit, ok
+}
+
+// RateLimitTiers lists the tiers LookupRateLimitTier knows for provider, sorted
+func RateLimitTiers(provider ProviderName) []string {
+	var tiers []string
+	for tier := range rateLimitTiers[provider] {
+		tiers = append(tiers, tier)
+	}
+	slices.Sort(tiers)
+	return tiers
+}
@@ -0,0 +1,101 @@
+package backend
+
+import (
+	"cmp"
+	"fmt"
+	"log"
+	"slices"
+	"strings"
+)
+
+// RelevanceScorer rates how relevant each text is to query; higher scores are more relevant
+type RelevanceScorer interface {
+	Score(query string, texts []string) ([]float64, error)
+}
+
+// SetRelevance makes pruning keep up to keep of the older exchanges it would drop,
+// picking those scorer rates most relevant to the latest user message. A nil scorer
+// or keep of 0 prunes chronologically.
+func (cm *ContextManager) SetRelevance(scorer RelevanceScorer, keep int) {
+	cm.relevance = scorer
+	cm.keepRelevant = keep
+}
+
+// rescueRelevant reports which of the older messages to keep. Whole exchanges, a user
+// message with the replies and tool calls that follow it, are kept or dropped together,
+// most relevant first, while they fit in budget tokens. Scoring failures keep nothing.
+func (cm *ContextManager) rescueRelevant(older []Message, query string, budget int) []bool {
+	rescued := make([]bool, len(older))
+	if cm.relevance == nil || cm.keepRelevant <= 0 || query == "" || budget <= 0 || !FeatureEnabled(FeatureRelevance) {
+		return rescued
+	}
+
+	type exchange struct {
+		start, end int
+		tokens     int
+		score      float64
+	}
+	var exchanges []exchange
+	var texts []string
+	for i, msg := range older {
+		if msg.Role == RoleUser {
+			exchanges = append(exchanges, exchange{start: i})
+		}
+		if len(exchanges) > 0 {
+			exchanges[len(exchanges)-1].end = i + 1
+		}
+	}
+	if len(exchanges) == 0 {
+		return rescued
+	}
+	for i, ex := range exchanges {
+		var b strings.Builder
+		for _, msg := range older[ex.start:ex.end] {
+			b.WriteString(msg.Content)
+			b.WriteString("\n")
+		}
+		texts = append(texts, b.String())
+		exchanges[i].tokens = cm.EstimateTokens(older[ex.start:ex.end])
+	}
+
+	scores, err := cm.relevance.Score(query, texts)
+	if err == nil && len(scores) != len(exchanges) {
+		err = fmt.Errorf("got %d scores for %d exchanges", len(scores), len(exchanges))
+	}
+	if err != nil {
+		log.Printf("Relevance scoring unavailable, pruning chronologically: %v", err)
+		return rescued
+	}
+	for i := range exchanges {
+		exchanges[i].score = scores[i]
+	}
+
+	slices.SortStableFunc(exchanges, func(a, b exchange) int {
+		return cmp.Compare(b.score, a.score)
+	})
+	kept := 0
+	for _, ex := range exchanges {
+		if kept == cm.keepRelevant {
+			break
+		}
+		if ex.tokens > budget {
+			continue
+		}
+		budget -= ex.tokens
+		kept++
+		for i := ex.start; i < ex.end; i++ {
+			rescued[i] = true
+		}
+	}
+	return rescued
+}
+
+// lastUserMessage returns the content of the latest user message, or "" if there is none
+func lastUserMessage(messages []Message) string {
+	for i := len(messages) - 1; i >= 0; i-- {
+		if messages[i].Role == RoleUser {
+			return messages[i].Content
+		}
+	}
+	return ""
+}
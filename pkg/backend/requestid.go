@@ -0,0 +1,31 @@
+package backend
+
+import (
+	"context"
+	"net/http"
+)
+
+// RequestIDHeader carries a request ID to providers and back to web clients
+const RequestIDHeader = "X-Request-ID"
+
+// requestIDKey is the context key under which WithRequestID stores the ID
+type requestIDKey struct{}
+
+// WithRequestID returns a context that carries a request ID for logs, metrics, and
+// provider calls
+func WithRequestID(ctx context.Context, id string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, id)
+}
+
+// RequestIDFromContext returns the request ID stored by WithRequestID, or ""
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// setRequestIDHeader forwards the request ID of an outgoing request's context to the provider
+func setRequestIDHeader(req *http.Request) {
+	if id := RequestIDFromContext(req.Context()); id != "" {
+		req.Header.Set(RequestIDHeader, id)
+	}
+}
@@ -0,0 +1,65 @@
+package backend
+
+import (
+	"bufio"
+	"context"
+	"io"
+	"strings"
+)
+
+// StreamHandler receives incremental assistant content as it is generated
+type StreamHandler func(delta string)
+
+// StreamingProvider is implemented by providers that can stream completions.
+// The returned response contains the full concatenated content and final usage.
+type StreamingProvider interface {
+	Provider
+	CreateCompletionStream(ctx context.Context, req *ChatCompletionRequest, onDelta StreamHandler) (*ChatCompletionResponse, error)
+}
+
+// readServerSentEvents parses a text/event-stream body and calls fn for each event.
+// Returning io.EOF from fn stops reading without an error.
+func readServerSentEvents(r io.Reader, fn func(event, data string) error) error {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 64*1024), 1024*1024)
+
+	var event string
+	var data []string
+	dispatch := func() error {
+		if len(data) == 0 {
+			event = ""
+			return nil
+		}
+		err := fn(event, strings.Join(data, "\n"))
+		event = ""
+		data = data[:0]
+		return err
+	}
+
+	for scanner.Scan() {
+		line := scanner.Text()
+		switch {
+		case line == "":
+			if err := dispatch(); err != nil {
+				if err == io.EOF {
+					return nil
+				}
+				return err
+			}
+		case strings.HasPrefix(line, ":"):
+			// Comment line, used by some servers as keep-alive
+		case strings.HasPrefix(line, "event:"):
+			event = strings.TrimSpace(strings.TrimPrefix(line, "event:"))
+		case strings.HasPrefix(line, "data:"):
+			data = append(data, strings.TrimPrefix(strings.TrimPrefix(line, "data:"), " "))
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return err
+	}
+
+	if err := dispatch(); err != nil && err != io.EOF {
+		return err
+	}
+	return nil
+}
@@ -0,0 +1,92 @@
+package backend
+
+import (
+	"sync"
+
+	"github.com/pkoukk/tiktoken-go"
+)
+
+const (
+	// fallbackEncoding is used for models tiktoken doesn't know, such as Anthropic or Ollama models
+	fallbackEncoding = "cl100k_base"
+
+	// tokensPerMessage is the per-message framing overhead used by OpenAI chat models
+	tokensPerMessage = 3
+)
+
+// encodings caches tiktoken encoders by model; a nil entry means the encoder couldn't be loaded
+var encodings sync.Map
+
+// MessageTokens is the token count of a single message in the context
+type MessageTokens struct {
+	Index   int
+	Role    Role
+	Tokens  int
+	Pinned  bool
+	Preview string
+}
+
+// encodingForModel returns the tiktoken encoder for a model, or nil when it's unavailable
+// (e.g. the BPE files can't be downloaded)
+func encodingForModel(model string) *tiktoken.Tiktoken {
+	if cached, ok := encodings.Load(model); ok {
+		return cached.(*tiktoken.Tiktoken)
+	}
+
+	enc, err := tiktoken.EncodingForModel(model)
+	if err != nil {
+		enc, err = tiktoken.GetEncoding(fallbackEncoding)
+	}
+	if err != nil {
+		enc = nil
+	}
+
+	encodings.Store(model, enc)
+	return enc
+}
+
+// CountTokens counts the tokens in text with the model's tokenizer. The second return value
+// is false when the tokenizer is unavailable and the count is a characters/4 estimate.
+func CountTokens(model, text string) (int, bool) {
+	enc := encodingForModel(model)
+	if enc == nil {
+		return len(text) / 4, false
+	}
+	return len(enc.Encode(text, nil, nil)), true
+}
+
+// CountMessageTokens returns the token count of every message, including role and framing overhead.
+// The second return value is false when counts are estimates.
+func CountMessageTokens(model string, messages []Message) ([]MessageTokens, bool) {
+	counts := make([]MessageTokens, 0, len(messages))
+	exact := true
+	for i, msg := range messages {
+		content, ok := CountTokens(model, msg.Content)
+		role, _ := CountTokens(model, string(msg.Role))
+		exact = exact && ok
+
+		counts = append(counts, MessageTokens{
+			Index:   i,
+			Role:    msg.Role,
+			Tokens:  content + role + tokensPerMessage,
+			Pinned:  msg.Pinned,
+			Preview: preview(msg.Content, 60),
+		})
+	}
+	return counts, exact
+}
+
+// preview returns the first line of s truncated to max runes
+func preview(s string, max int) string {
+	runes := []rune(s)
+	for i, r := range runes {
+		if r == '\n' || r == '\r' {
+			runes = runes[:i]
+			break
+		}
+	}
+	if len(runes) > max {
+		return string(runes[:max-3]) + "..."
+	}
+	return string(runes)
+}
@@ -0,0 +1,79 @@
+package backend
+
+import (
+	"cmp"
+	"fmt"
+	"os"
+)
+
+// ProviderNameVertex selects Gemini models on Google Cloud Vertex AI
+const ProviderNameVertex ProviderName = "vertex"
+
+// VertexDefaultModel is used when MODEL isn't set. Vertex's OpenAI-compatible endpoint
+// expects publisher-qualified model names.
+const VertexDefaultModel = "google/gemini-2.0-flash-001"
+
+// VertexDefaultLocation is the region used when none is configured
+const VertexDefaultLocation = "us-central1"
+
+// VertexURL returns the OpenAI-compatible chat completions endpoint of a project and location
+func VertexURL(project, location string) string {
+	host := location + "-aiplatform.googleapis.com"
+	if location == "global" {
+		host = "aiplatform.googleapis.com"
+	}
+	return fmt.Sprintf("https://%s/v1/projects/%s/locations/%s/endpoints/openapi/chat/completions", host, project, location)
+}
+
+// VertexURLFromEnv returns the endpoint for VERTEX_PROJECT (or GOOGLE_CLOUD_PROJECT)
+// in VERTEX_LOCATION, which defaults to VertexDefaultLocation
+func VertexURLFromEnv() (string, error) {
+	project := cmp.Or(os.Getenv("VERTEX_PROJECT"), os.Getenv("GOOGLE_CLOUD_PROJECT"))
+	if project == "" {
+		return "", fmt.Errorf("missing Google Cloud project: please set VERTEX_PROJECT")
+	}
+	return VertexURL(project, cmp.Or(os.Getenv("VERTEX_LOCATION"), VertexDefaultLocation)), nil
+}
+
+// NewVertexProvider creates a provider for Vertex AI. Vertex authenticates with OAuth
+// access tokens rather than API keys: a configured APIKey is sent as a ready-made token
+// (e.g. from "gcloud auth print-access-token"); otherwise tokens come from Application
+// Default Credentials and are refreshed before they expire.
+func NewVertexProvider(config ProviderConfig) (Provider, error) {
+	if config.URL == "" {
+		url, err := VertexURLFromEnv()
+		if err != nil {
+			return nil, err
+		}
+		config.URL = url
+	}
+	if config.Model == "" {
+		config.Model = VertexDefaultModel
+	}
+
+	p := &vertexProvider{openAIProvider{config: config, name: string(ProviderNameVertex), label: "Vertex AI"}}
+	if config.APIKey == "" {
+		source, err := newGCPTokenSource()
+		if err != nil {
+			return nil, err
+		}
+		p.bearerToken = source.Token
+	}
+	return p, nil
+}
+
+// vertexProvider implements Provider on top of Vertex AI's OpenAI-compatible endpoint
+type vertexProvider struct {
+	openAIProvider
+}
+
+// Capabilities reports what Gemini on Vertex supports
+func (p *vertexProvider) Capabilities() Capabilities {
+	return Capabilities{
+		Streaming:  true,
+		Tools:      true,
+		Vision:     modelSupportsVision(p.config.Model),
+		JSONMode:   true,
+		MaxContext: ModelContextWindow(p.config.Model),
+	}
+}
@@ -0,0 +1,306 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strings"
+	"text/template"
+
+	"github.com/nleiva/chatgbt/internal/cli/render"
+	"github.com/nleiva/chatgbt/pkg/backend"
+	"github.com/nleiva/chatgbt/pkg/config"
+)
+
+// Kinds of value a flag takes, as far as completion is concerned
+const (
+	argNone      = ""          // Boolean flag
+	argText      = "text"      // Free text or a file name
+	argModels    = "models"    // provider:model
+	argProviders = "providers" // LLM_PROVIDER names
+	argUsers     = "users"     // --user profile names
+	argSelect    = "select"    // --best-of selection methods
+	argColor     = "color"     // --color modes
+)
+
+// completionFlag is a flag as the user types it, e.g. "--model" or "-a"
+type completionFlag struct {
+	name string
+	arg  string
+}
+
+// completionShells are the shells `completion` writes scripts for
+var completionShells = []string{"bash", "zsh", "fish", "powershell"}
+
+// completionArg groups the flags that take the same kind of value
+type completionArg struct {
+	Flags []string // Flags taking this kind of value
+	Words []string // Values offered; empty for free text and files
+	Users bool     // Values are the --user profiles, listed by `chatgbt completion users`
+}
+
+// completionScope is what's offered after a mode, or at the top level when Command is empty
+type completionScope struct {
+	Command string
+	Words   []string
+}
+
+// completionData feeds the completion script templates
+type completionData struct {
+	Args   []completionArg
+	Scopes []completionScope
+	Global []string // Global flags taking a value, skipped along with it when looking for the mode
+	Values []string // Every flag taking a value
+}
+
+// CompletionRunner writes a shell completion script, or the --user profile names the
+// scripts offer
+type CompletionRunner struct {
+	shell  string
+	writer io.Writer
+}
+
+// newCompletionRunner parses the completion subcommand arguments
+func newCompletionRunner(args []string, w io.Writer) (*CompletionRunner, error) {
+	if len(args) != 1 || (args[0] != argUsers && !slices.Contains(completionShells, args[0])) {
+		return nil, fmt.Errorf("usage: completion %s", strings.Join(completionShells, "|"))
+	}
+	return &CompletionRunner{shell: args[0], writer: w}, nil
+}
+
+// Run writes the script for the shell
+func (c *CompletionRunner) Run(cfg backend.LLMConfig, budgetCfg backend.TokenBudgetConfig) error {
+	if c.shell == argUsers {
+		users, err := config.UserProfiles()
+		if err != nil {
+			return err
+		}
+		for _, user := range users {
+			fmt.Fprintln(c.writer, user)
+		}
+		return nil
+	}
+	return completionTemplates.ExecuteTemplate(c.writer, c.shell, newCompletionData())
+}
+
+// newCompletionData collects the words offered for each mode and flag
+func newCompletionData() completionData {
+	var providers []string
+	for _, provider := range backend.ProviderNames() {
+		providers = append(providers, string(provider))
+	}
+	words := map[string][]string{
+		argModels:    backend.KnownModels(),
+		argProviders: providers,
+		argSelect:    {"vote", "judge"},
+		argColor:     {render.ColorAuto, render.ColorAlways, render.ColorNever},
+	}
+
+	flagsByArg := map[string][]string{}
+	addFlags := func(flags []completionFlag) []string {
+		var names []string
+		for _, flag := range flags {
+			names = append(names, flag.name)
+			if flag.arg != argNone && !slices.Contains(flagsByArg[flag.arg], flag.name) {
+				flagsByArg[flag.arg] = append(flagsByArg[flag.arg], flag.name)
+			}
+		}
+		return names
+	}
+
+	top := completionScope{}
+	var scopes []completionScope
+	for _, spec := range modeSpecs {
+		top.Words = append(top.Words, spec.name)
+		if words := append(addFlags(spec.flags), spec.args...); len(words) > 0 {
+			scopes = append(scopes, completionScope{Command: spec.name, Words: words})
+		}
+	}
+	top.Words = append(top.Words, addFlags(globalFlags)...)
+
+	var data completionData
+	for _, flag := range globalFlags {
+		if flag.arg != argNone {
+			data.Global = append(data.Global, flag.name)
+		}
+	}
+	for _, arg := range slices.Sorted(maps.Keys(flagsByArg)) {
+		data.Args = append(data.Args, completionArg{
+			Flags: flagsByArg[arg],
+			Words: words[arg],
+			Users: arg == argUsers,
+		})
+		data.Values = append(data.Values, flagsByArg[arg]...)
+	}
+	data.Scopes = append([]completionScope{top}, scopes...)
+	return data
+}
+
+var completionTemplates = template.Must(template.New("completion").Funcs(template.FuncMap{
+	"join": strings.Join,
+	"quote": func(words []string) string {
+		quoted := make([]string, len(words))
+		for i, word := range words {
+			quoted[i] = "'" + word + "'"
+		}
+		return strings.Join(quoted, ", ")
+	},
+}).Parse(`
+{{- define "bash" -}}
+# bash completion for chatgbt; load it with: source <(chatgbt completion bash)
+_chatgbt() {
+    local cur prev
+    if declare -F _get_comp_words_by_ref >/dev/null; then
+        _get_comp_words_by_ref -n : cur prev
+    else
+        cur="${COMP_WORDS[COMP_CWORD]}"
+        prev="${COMP_WORDS[COMP_CWORD-1]}"
+    fi
+
+    local words="" cmd="" i
+    for ((i = 1; i < COMP_CWORD; i++)); do
+        case "${COMP_WORDS[i]}" in
+            {{join .Global "|"}}) ((i++)) ;;
+            -*) ;;
+            *) cmd="${COMP_WORDS[i]}"; break ;;
+        esac
+    done
+
+    case "$prev" in
+{{- range .Args}}
+        {{join .Flags "|"}}) words="{{if .Users}}$(chatgbt completion users 2>/dev/null){{else}}{{join .Words " "}}{{end}}" ;;
+{{- end}}
+        *)
+            case "$cmd" in
+{{- range .Scopes}}
+                {{if .Command}}{{.Command}}{{else}}""{{end}}) words="{{join .Words " "}}" ;;
+{{- end}}
+            esac
+            ;;
+    esac
+
+    COMPREPLY=($(compgen -W "$words" -- "$cur"))
+    if declare -F __ltrim_colon_completions >/dev/null; then
+        __ltrim_colon_completions "$cur"
+    fi
+}
+complete -o default -F _chatgbt chatgbt
+{{end}}
+
+{{- define "zsh" -}}
+#compdef chatgbt
+# zsh completion for chatgbt; load it with: source <(chatgbt completion zsh)
+_chatgbt() {
+    local -a candidates
+    local cmd="" i
+    for ((i = 2; i < CURRENT; i++)); do
+        case "${words[i]}" in
+            {{join .Global "|"}}) ((i++)) ;;
+            -*) ;;
+            *) cmd="${words[i]}"; break ;;
+        esac
+    done
+
+    case "${words[CURRENT-1]}" in
+{{- range .Args}}
+        {{join .Flags "|"}}) candidates=({{if .Users}}${(f)"$(chatgbt completion users 2>/dev/null)"}{{else}}{{join .Words " "}}{{end}}) ;;
+{{- end}}
+        *)
+            case "$cmd" in
+{{- range .Scopes}}
+                {{if .Command}}{{.Command}}{{else}}""{{end}}) candidates=({{join .Words " "}}) ;;
+{{- end}}
+            esac
+            ;;
+    esac
+
+    compadd -- $candidates || _files
+}
+
+if [ "$funcstack[1]" = "_chatgbt" ]; then
+    _chatgbt "$@"
+else
+    compdef _chatgbt chatgbt
+fi
+{{end}}
+
+{{- define "fish" -}}
+# fish completion for chatgbt; load it with: chatgbt completion fish | source
+function __chatgbt_command
+    set -l tokens (commandline -opc)
+    set -e tokens[1]
+    while set -q tokens[1]
+        switch $tokens[1]
+            case {{join .Global " "}}
+                set -e tokens[1]
+            case '-*'
+            case '*'
+                echo $tokens[1]
+                return
+        end
+        set -e tokens[1]
+    end
+end
+
+function __chatgbt_offer
+    set -l tokens (commandline -opc)
+    if contains -- $tokens[-1] {{join .Values " "}}
+        return 1
+    end
+    set -l cmd (__chatgbt_command)
+    test "$cmd" = "$argv[1]"
+end
+
+function __chatgbt_after
+    set -l tokens (commandline -opc)
+    contains -- $tokens[-1] $argv
+end
+{{range .Args}}
+complete -c chatgbt -n '__chatgbt_after {{join .Flags " "}}' {{if .Users}}-x -a '(chatgbt completion users 2>/dev/null)'{{else if .Words}}-x -a '{{join .Words " "}}'{{else}}-F{{end}}
+{{- end}}
+{{range .Scopes}}
+complete -c chatgbt -n '__chatgbt_offer {{if .Command}}{{.Command}}{{else}}""{{end}}' -a '{{join .Words " "}}'
+{{- end}}
+{{end}}
+
+{{- define "powershell" -}}
+# PowerShell completion for chatgbt; load it with:
+#   chatgbt completion powershell | Out-String | Invoke-Expression
+Register-ArgumentCompleter -Native -CommandName chatgbt, chatgbt.exe -ScriptBlock {
+    param($wordToComplete, $commandAst, $cursorPosition)
+
+    $tokens = @($commandAst.CommandElements | Select-Object -Skip 1 | ForEach-Object { $_.ToString() })
+    if ($wordToComplete -ne '') {
+        $tokens = @($tokens | Select-Object -SkipLast 1)
+    }
+    $prev = if ($tokens.Count -gt 0) { $tokens[-1] } else { '' }
+
+    $command = ''
+    for ($i = 0; $i -lt $tokens.Count; $i++) {
+        if ($tokens[$i] -in {{quote .Global}}) { $i++; continue }
+        if ($tokens[$i].StartsWith('-')) { continue }
+        $command = $tokens[$i]
+        break
+    }
+
+    $words = switch ($prev) {
+{{- range .Args}}
+        { $_ -in {{quote .Flags}} } { {{if .Users}}@(chatgbt completion users 2>$null){{else if .Words}}@({{quote .Words}}){{else}}@(){{end}}; break }
+{{- end}}
+        default {
+            switch ($command) {
+{{- range .Scopes}}
+                '{{.Command}}' { @({{quote .Words}}) }
+{{- end}}
+                default { @() }
+            }
+        }
+    }
+
+    $words | Where-Object { $_ -like "$wordToComplete*" } | ForEach-Object {
+        [System.Management.Automation.CompletionResult]::new($_, $_, 'ParameterValue', $_)
+    }
+}
+{{end}}
+`))
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"bytes"
+	"cmp"
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/nleiva/chatgbt/pkg/backend"
+	"github.com/nleiva/chatgbt/pkg/config"
+	"github.com/nleiva/chatgbt/pkg/llm"
+	"github.com/nleiva/chatgbt/pkg/prompts"
+	"github.com/nleiva/chatgbt/pkg/store"
+	"github.com/nleiva/chatgbt/pkg/tools"
+)
+
+// doctorTimeout bounds the test request sent to the provider
+const doctorTimeout = 20 * time.Second
+
+// Outcomes of a doctor check
+const (
+	checkOK   = "ok"
+	checkWarn = "warn"
+	checkFail = "FAIL"
+	checkSkip = "skip"
+)
+
+// doctorCheck is the outcome of one check, with what to do about it when it didn't pass
+type doctorCheck struct {
+	name   string
+	status string
+	detail string
+	fix    string
+}
+
+// doctor checks the setup chatgbt runs with and prints what to fix. It loads the
+// configuration itself so that a broken one is reported instead of stopping it.
+type doctor struct {
+	profile *config.UserProfile // --user profile, if any
+	writer  io.Writer
+	checks  []doctorCheck
+}
+
+// add records the outcome of a check
+func (d *doctor) add(name, status, detail, fix string) {
+	d.checks = append(d.checks, doctorCheck{name: name, status: status, detail: detail, fix: fix})
+}
+
+// Run performs every check and reports an error when any of them failed
+func (d *doctor) Run() error {
+	cfg := d.checkConfig()
+	if cfg == nil {
+		d.add("provider", checkSkip, "needs a valid configuration", "")
+	} else {
+		d.checkModel(cfg)
+		d.checkProvider(cfg)
+	}
+	d.checkDirs(cfg)
+	if cfg != nil {
+		d.checkTools(cfg)
+	}
+
+	d.writeChecks()
+	if failed := d.count(checkFail); failed > 0 {
+		return fmt.Errorf("doctor found %d problem(s)", failed)
+	}
+	fmt.Fprintln(d.writer, "\nEverything looks good.")
+	return nil
+}
+
+// writeChecks prints the outcome of every check, with its fix
+func (d *doctor) writeChecks() {
+	for _, check := range d.checks {
+		fmt.Fprintf(d.writer, "[%-4s] %-14s %s\n", check.status, check.name, check.detail)
+		if check.fix != "" {
+			fmt.Fprintf(d.writer, "       %-14s fix: %s\n", "", check.fix)
+		}
+	}
+}
+
+// count returns how many checks ended with status
+func (d *doctor) count(status string) int {
+	n := 0
+	for _, check := range d.checks {
+		if check.status == status {
+			n++
+		}
+	}
+	return n
+}
+
+// checkConfig loads the configuration from the environment, reporting its warnings;
+// nil when it can't be used
+func (d *doctor) checkConfig() *config.Config {
+	var warnings bytes.Buffer
+	cfg, err := config.LoadFromEnv(&warnings)
+	for _, line := range strings.Split(strings.TrimSpace(warnings.String()), "\n") {
+		if line != "" {
+			d.add("config", checkWarn, strings.TrimPrefix(line, "Warning: "),
+				"Correct or unset the variable; run chatgbt without arguments for the accepted values")
+		}
+	}
+	if err != nil {
+		fix := "Correct the variable named above; run chatgbt without arguments for the accepted values"
+		if strings.Contains(err.Error(), "API key") || strings.Contains(err.Error(), "API_KEY") {
+			fix = "export API_KEY=<your key> (or the provider's own variable, e.g. OPENAI_API_KEY)"
+		}
+		d.add("config", checkFail, err.Error(), fix)
+		return nil
+	}
+
+	if d.profile != nil {
+		if err := d.profile.ApplyBudget(&cfg.Budget); err != nil {
+			d.add("config", checkFail, err.Error(), "Fix or remove budget.yaml in "+d.profile.Dir)
+			return nil
+		}
+	}
+	d.add("config", checkOK, fmt.Sprintf("provider %s, model %s, session budget %d tokens",
+		cfg.LLM.Provider, cfg.LLM.Model, cfg.Budget.SessionLimit), "")
+	return cfg
+}
+
+// checkModel compares the model with the known models and the provider that serves it
+func (d *doctor) checkModel(cfg *config.Config) {
+	provider, model := cfg.LLM.Provider, cfg.LLM.Model
+	if native := backend.ProviderForModel(model); native != "" && native != provider {
+		d.add("model", checkWarn, fmt.Sprintf("%s looks like a model served by %s, but LLM_PROVIDER is %s", model, native, provider),
+			fmt.Sprintf("export LLM_PROVIDER=%s, or pick a model %s serves", native, provider))
+		return
+	}
+	if slices.Contains(backend.KnownModels(), string(provider)+":"+model) {
+		d.add("model", checkOK, model+" is a known model", "")
+		return
+	}
+
+	detail := model + " is not in the list of well-known models"
+	if backend.ModelContextWindow(model) == 0 {
+		detail += ", and its context window is unknown, so the context is pruned by MAX_TOKENS only"
+	}
+	d.add("model", checkWarn, detail,
+		"Check MODEL for typos; `chatgbt completion bash` lists the known names (other models still work)")
+}
+
+// checkProvider sends the smallest possible request to the configured provider
+func (d *doctor) checkProvider(cfg *config.Config) {
+	client, err := llm.NewClient(cfg.LLM, doctorTimeout)
+	if err != nil {
+		d.add("provider", checkFail, err.Error(), "Set LLM_PROVIDER to a supported provider")
+		return
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), doctorTimeout)
+	defer cancel()
+
+	maxTokens := 1
+	start := time.Now()
+	_, err = client.CreateCompletion(ctx, &backend.ChatCompletionRequest{
+		Messages:  []backend.Message{{Role: backend.RoleUser, Content: "ping"}},
+		MaxTokens: &maxTokens,
+	})
+	if err != nil {
+		d.add("provider", checkFail, err.Error(), providerFix(err, cfg.LLM))
+		return
+	}
+	d.add("provider", checkOK, fmt.Sprintf("%s answered in %v", cfg.LLM.URL, time.Since(start).Round(time.Millisecond)), "")
+}
+
+// providerFix suggests what to do about a failed test request
+func providerFix(err error, cfg backend.LLMConfig) string {
+	errStr := strings.ToLower(err.Error())
+	switch {
+	case strings.Contains(errStr, " 401") || strings.Contains(errStr, " 403") ||
+		strings.Contains(errStr, "unauthorized") || strings.Contains(errStr, "invalid api key"):
+		return "The provider rejected the key: check API_KEY belongs to " + string(cfg.Provider) + " and hasn't expired"
+	case strings.Contains(errStr, " 404") || strings.Contains(errStr, "model"):
+		return "Check MODEL is available to your account, and the endpoint URL"
+	case strings.Contains(errStr, " 429") || strings.Contains(errStr, "quota") || strings.Contains(errStr, "rate limit"):
+		return "The account is out of quota or rate limited; check billing or retry later"
+	case strings.Contains(errStr, "deadline") || strings.Contains(errStr, "timeout"):
+		return "No answer within " + doctorTimeout.String() + "; check the network, proxy settings (HTTPS_PROXY), and the endpoint"
+	case strings.Contains(errStr, "connection refused") || strings.Contains(errStr, "no such host"):
+		if cfg.Provider == backend.ProviderNameOllama {
+			return "Start Ollama (ollama serve) or point the URL at the machine running it"
+		}
+		return "Can't reach " + cfg.URL + "; check the network and DNS"
+	default:
+		return "See the error above; `chatgbt -h` lists the provider settings"
+	}
+}
+
+// checkDirs verifies that the directories chatgbt writes to can be written
+func (d *doctor) checkDirs(cfg *config.Config) {
+	logsDir := "logs"
+	conversationsDir := store.DefaultDir
+	if d.profile != nil {
+		logsDir, conversationsDir = d.profile.LogsDir(), d.profile.ConversationsDir()
+	}
+
+	type writableDir struct{ name, dir string }
+	dirs := []writableDir{{"logs dir", logsDir}, {"history dir", conversationsDir}}
+	if cfg != nil {
+		if cfg.Web.SessionFile != "" {
+			dirs = append(dirs, writableDir{"sessions dir", filepath.Dir(cfg.Web.SessionFile)})
+		}
+		dirs = append(dirs, writableDir{"prompts dir", filepath.Dir(cmp.Or(cfg.Web.PromptsFile, prompts.DefaultPath()))})
+	}
+
+	for _, dir := range dirs {
+		if err := checkWritable(dir.dir); err != nil {
+			d.add(dir.name, checkFail, err.Error(),
+				fmt.Sprintf("Create %s and make it writable by %s, or run chatgbt from another directory", dir.dir, currentUser()))
+			continue
+		}
+		d.add(dir.name, checkOK, dir.dir+" is writable", "")
+	}
+}
+
+// checkWritable creates dir if needed and writes a file in it
+func checkWritable(dir string) error {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return err
+	}
+	f, err := os.CreateTemp(dir, ".doctor-*")
+	if err != nil {
+		return err
+	}
+	f.Close()
+	return os.Remove(f.Name())
+}
+
+// currentUser names the account chatgbt runs as, for fix suggestions
+func currentUser() string {
+	return cmp.Or(os.Getenv("USER"), os.Getenv("USERNAME"), "the current user")
+}
+
+// checkTools reports subprocess tools that can't be loaded
+func (d *doctor) checkTools(cfg *config.Config) {
+	registry, errs := tools.Discover(cfg.ToolsDir)
+	for _, err := range errs {
+		d.add("tools", checkWarn, err.Error(), "Fix or remove the tool in "+cfg.ToolsDir)
+	}
+	if len(errs) == 0 {
+		d.add("tools", checkOK, fmt.Sprintf("%d tool(s) in %s", registry.Len(), cfg.ToolsDir), "")
+	}
+}
@@ -2,15 +2,19 @@ package main
 
 import (
 	"fmt"
-	"net"
+	"io"
 	"os"
-	"strconv"
+	"os/user"
 	"strings"
 
+	"github.com/nleiva/chatgbt/internal/app"
 	"github.com/nleiva/chatgbt/internal/cli"
-	"github.com/nleiva/chatgbt/internal/web"
 	"github.com/nleiva/chatgbt/pkg/backend"
 	"github.com/nleiva/chatgbt/pkg/config"
+	"github.com/nleiva/chatgbt/pkg/llm"
+	"github.com/nleiva/chatgbt/pkg/notify"
+	"github.com/nleiva/chatgbt/pkg/store"
+	"github.com/nleiva/chatgbt/pkg/tools"
 )
 
 // Mode represents a runnable application mode
@@ -20,58 +24,270 @@ type Mode interface {
 
 // printUsage displays the usage information
 func printUsage() {
-	fmt.Fprintf(os.Stderr, "Usage: %s <mode> [options]\n", os.Args[0])
-	fmt.Fprintf(os.Stderr, "\nModes:\n")
-	fmt.Fprintf(os.Stderr, "  cli           Start in CLI mode (interactive terminal)\n")
-	fmt.Fprintf(os.Stderr, "  web           Start in web mode (HTTP server)\n")
-	fmt.Fprintf(os.Stderr, "  \"<query>\"     Quick query mode (non-interactive)\n")
-	fmt.Fprintf(os.Stderr, "\nEnvironment Variables:\n")
-	fmt.Fprintf(os.Stderr, "  API_KEY         Required: Your API key for the selected provider\n")
-	fmt.Fprintf(os.Stderr, "  LLM_PROVIDER    Optional: LLM provider (openai, anthropic, bedrock) (default: openai)\n")
-	fmt.Fprintf(os.Stderr, "  MODEL           Optional: Model to use (default: %s)\n", config.DefaultModel)
-	fmt.Fprintf(os.Stderr, "  PORT            Optional: Web server port number (default: %d)\n", config.DefaultPort)
-	fmt.Fprintf(os.Stderr, "  TOKEN_BUDGET    Optional: Session token budget (default: 10000)\n")
-	fmt.Fprintf(os.Stderr, "  COST_BUDGET     Optional: Session cost budget in USD (default: $0.02)\n")
+	writeUsage(os.Stderr, os.Args[0])
 }
 
 func run(args []string) error {
+	args, user, err := userFlag(args)
+	if err != nil {
+		return err
+	}
+	if err := config.LoadEnvFile(os.Getenv("ENV_FILE")); err != nil {
+		return err
+	}
 	if len(args) < 2 {
 		printUsage()
 		return fmt.Errorf("mode argument required")
 	}
 
 	modeArg := args[1]
+	spec, isMode := lookupMode(modeArg)
+
+	var profile *config.UserProfile
+	if user != "" {
+		if isMode && spec.multiUser {
+			return fmt.Errorf("--user is for single-user modes; %s mode serves many users", modeArg)
+		}
+		if profile, err = config.LoadUserProfile(user); err != nil {
+			return err
+		}
+	}
+
+	env := &modeEnv{args: args[2:], profile: profile}
+	if isMode && spec.standalone {
+		mode, err := spec.newMode(env)
+		if err != nil {
+			return err
+		}
+		return mode.Run(backend.LLMConfig{}, backend.DefaultBudgetConfig())
+	}
+
+	// A --quiet quick query prints nothing but the reply, not even configuration warnings
+	warnings := io.Writer(os.Stderr)
+	if strings.HasPrefix(modeArg, "-") && cli.QuietQuery(args[1:]) {
+		warnings = io.Discard
+	}
 
 	// Load configuration from environment
-	cfg, err := config.LoadFromEnv(os.Stderr)
+	cfg, err := config.LoadFromEnv(warnings)
 	if err != nil {
 		return err
 	}
+	llm.SetConcurrencyLimit(cfg.MaxConcurrentRequests, cfg.QueueTimeout)
+	llm.SetRateLimits(cfg.RateLimits)
+	backend.SetHTTPDebug(cfg.DebugHTTP, cfg.DebugHTTPDir)
+	backend.SetFeatures(cfg.Features)
 
-	var mode Mode
+	registry, toolErrs := tools.Discover(cfg.ToolsDir)
+	for _, err := range toolErrs {
+		fmt.Fprintf(warnings, "Warning: skipping tool: %v\n", err)
+	}
 
-	switch modeArg {
-	case "cli":
-		mode = cli.NewCLIRunner()
-	case "web":
-		address := net.JoinHostPort("", strconv.Itoa(cfg.Port))
-		mode = web.NewWebRunner(address)
-	default:
-		// Handle direct query mode
-		query := modeArg
-		if len(args) > 2 {
-			// Join all remaining args as the query
-			query = strings.Join(args[1:], " ")
+	router, err := newRouter(cfg)
+	if err != nil {
+		return err
+	}
+
+	opts := app.SessionOptions{
+		FewShot:          cfg.FewShot,
+		Tools:            registry,
+		Notifier:         notify.NewWebhooks(cfg.Hooks.URLs, cfg.Hooks.SlackURLs),
+		FailureThreshold: cfg.Hooks.FailureThreshold,
+		Classifier:       newClassifier(cfg),
+		Language:         cfg.Language,
+		AutoLanguage:     cfg.AutoLanguage,
+		MaxMessageLength: cfg.MaxMessageLength,
+		MaxQueued:        cfg.SessionQueue,
+		ConversationType: cfg.ConversationType,
+		MaxDailyCost:     cfg.MaxDailyCost,
+		TenantDailyCost:  cfg.TenantDailyCost,
+		Conversations:    conversationDefaults(cfg.Conversations),
+		Router:           router,
+		InjectionGuard:   cfg.InjectionGuard,
+		Embedder:         newEmbedder(cfg),
+		EndUser:          endUser(profile),
+	}
+	if profile != nil {
+		if err := profile.ApplyBudget(&cfg.Budget); err != nil {
+			return err
+		}
+		opts.Store = store.NewFileStore(profile.ConversationsDir())
+	}
+
+	env.cfg, env.opts = cfg, opts
+
+	var mode Mode
+	if isMode {
+		mode, err = spec.newMode(env)
+	} else {
+		// Handle direct query mode, which may start with flags such as --best-of; all
+		// remaining args are joined as the query
+		var query *cli.DirectQueryRunner
+		query, err = cli.ParseDirectQuery(args[1:], cfg.LLM.ShowUsage)
+		if err == nil {
+			query.SetEndUser(opts.EndUser)
+			mode = query
 		}
-		mode = cli.NewDirectQueryRunner(query, cfg.LLM.ShowUsage)
+	}
+	if err != nil {
+		return err
 	}
 
 	return mode.Run(cfg.LLM, cfg.Budget)
 }
 
+// endUser names who is at the terminal, to tell users apart in the provider's abuse
+// monitoring: the --user profile, or else the OS account
+func endUser(profile *config.UserProfile) string {
+	if profile != nil {
+		return profile.Name
+	}
+	if account, err := user.Current(); err == nil {
+		return account.Username
+	}
+	return ""
+}
+
+// userFlag removes a leading --user <name> or --user=<name> from args and returns the name
+func userFlag(args []string) ([]string, string, error) {
+	if len(args) < 2 {
+		return args, "", nil
+	}
+	switch arg := args[1]; {
+	case arg == "--user" || arg == "-user":
+		if len(args) < 3 || args[2] == "" {
+			return nil, "", fmt.Errorf("--user requires a name")
+		}
+		return append([]string{args[0]}, args[3:]...), args[2], nil
+	case strings.HasPrefix(arg, "--user=") || strings.HasPrefix(arg, "-user="):
+		user := arg[strings.Index(arg, "=")+1:]
+		if user == "" {
+			return nil, "", fmt.Errorf("--user requires a name")
+		}
+		return append([]string{args[0]}, args[2:]...), user, nil
+	}
+	return args, "", nil
+}
+
 func main() {
 	if err := run(os.Args); err != nil {
 		fmt.Fprintf(os.Stderr, "Error starting chatGBT: %s\n", err)
-		os.Exit(1)
+		os.Exit(cli.ExitCode(err))
+	}
+}
+
+// reloadSettings rereads ENV_FILE and the environment for a running web server. It
+// returns base with the session options that can change while running updated; the
+// tools, notifier, and daily cost limit stay those the server started with.
+func reloadSettings(base app.SessionOptions) (backend.LLMConfig, backend.TokenBudgetConfig, app.SessionOptions, error) {
+	vars, err := config.ReadEnvFile(os.Getenv("ENV_FILE"))
+	if err != nil {
+		return backend.LLMConfig{}, backend.TokenBudgetConfig{}, base, err
+	}
+	// The file's values are set to be validated, and undone if they turn out invalid
+	restore, err := config.SetEnv(vars)
+	if err != nil {
+		return backend.LLMConfig{}, backend.TokenBudgetConfig{}, base, err
+	}
+	cfg, err := config.LoadFromEnv(os.Stderr)
+	if err != nil {
+		restore()
+		return backend.LLMConfig{}, backend.TokenBudgetConfig{}, base, err
+	}
+	router, err := newRouter(cfg)
+	if err != nil {
+		restore()
+		return backend.LLMConfig{}, backend.TokenBudgetConfig{}, base, err
+	}
+	backend.SetFeatures(cfg.Features)
+
+	opts := base
+	opts.FewShot = cfg.FewShot
+	opts.Language = cfg.Language
+	opts.AutoLanguage = cfg.AutoLanguage
+	opts.MaxMessageLength = cfg.MaxMessageLength
+	opts.MaxQueued = cfg.SessionQueue
+	opts.Conversations = conversationDefaults(cfg.Conversations)
+	opts.Router = router
+	opts.InjectionGuard = cfg.InjectionGuard
+	opts.FollowUps = cfg.FollowUps
+	opts.AutoContinue = cfg.AutoContinue
+	return cfg.LLM, cfg.Budget, opts, nil
+}
+
+// conversationDefaults layers the conversation type overrides from CONVERSATIONS_FILE
+// on top of each type's built-in defaults
+func conversationDefaults(profiles map[string]config.ConversationProfile) map[string]app.ConversationDefaults {
+	if len(profiles) == 0 {
+		return nil
+	}
+
+	defaults := make(map[string]app.ConversationDefaults, len(profiles))
+	for conversationType, profile := range profiles {
+		d := app.ConversationDefaultsFor(conversationType)
+		if profile.SystemPrompt != "" {
+			d.SystemPrompt = profile.SystemPrompt
+		}
+		if profile.MaxTokens > 0 {
+			d.MaxTokens = profile.MaxTokens
+		}
+		if profile.KeepRecent > 0 {
+			d.KeepRecent = profile.KeepRecent
+		}
+		if profile.KeepRecentTokens > 0 {
+			d.KeepRecentTokens = profile.KeepRecentTokens
+		}
+		if profile.KeepRelevant > 0 {
+			d.KeepRelevant = profile.KeepRelevant
+		}
+		if profile.Summary != nil {
+			d.SummaryEnabled = *profile.Summary
+		}
+		if profile.SessionLimit > 0 {
+			d.SessionLimit = profile.SessionLimit
+		}
+		if profile.JSONMode {
+			d.JSONMode = true
+		}
+		defaults[conversationType] = d
+	}
+	return defaults
+}
+
+// newClassifier builds the prompt classifier selected by CLASSIFIER
+func newClassifier(cfg *config.Config) app.Classifier {
+	if cfg.Classifier.Kind != config.ClassifierEmbedding {
+		return app.KeywordClassifier{}
+	}
+	return app.NewEmbeddingClassifier(newEmbedder(cfg))
+}
+
+// newEmbedder builds the client for the embeddings endpoint set by EMBEDDING_URL and
+// EMBEDDING_MODEL, next to the chat endpoint by default
+func newEmbedder(cfg *config.Config) *backend.EmbeddingClient {
+	url := cfg.Classifier.EmbeddingURL
+	if url == "" {
+		url = cfg.LLM.URL
+	}
+	return backend.NewEmbeddingClient(cfg.LLM.APIKey, url, cfg.Classifier.EmbeddingModel)
+}
+
+// newRouter builds the model router from the rules in ROUTER_FILE; nil when there are none
+func newRouter(cfg *config.Config) (*app.Router, error) {
+	if len(cfg.Routes) == 0 {
+		return nil, nil
+	}
+
+	rules := make([]app.RouteRule, 0, len(cfg.Routes))
+	for _, route := range cfg.Routes {
+		rules = append(rules, app.RouteRule{
+			Model:        route.Model,
+			PromptTypes:  route.PromptTypes,
+			MinTokens:    route.MinTokens,
+			MaxTokens:    route.MaxTokens,
+			BudgetBelow:  route.BudgetBelow,
+			CostPerToken: route.CostPerToken,
+		})
 	}
+	return app.NewRouter(cfg.LLM, rules)
 }
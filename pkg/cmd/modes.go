@@ -0,0 +1,248 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"slices"
+	"strings"
+
+	"github.com/nleiva/chatgbt/pkg/apikeys"
+	"github.com/nleiva/chatgbt/pkg/backend"
+	"github.com/nleiva/chatgbt/pkg/config"
+	"github.com/nleiva/chatgbt/pkg/i18n"
+	"github.com/nleiva/chatgbt/pkg/prompts"
+	"github.com/nleiva/chatgbt/pkg/tools"
+)
+
+// helpLine is one entry of the usage text: how to invoke something and what it does
+type helpLine struct {
+	syntax string
+	text   string
+}
+
+// modeSpec describes a mode. run builds the mode with newMode, and the usage text and
+// the completion scripts are generated from modeSpecs, so a new mode only needs an
+// entry here.
+type modeSpec struct {
+	name       string
+	help       []helpLine
+	flags      []completionFlag
+	args       []string                         // Fixed arguments, such as subcommands
+	multiUser  bool                             // Serves many users at once, so --user doesn't apply
+	standalone bool                             // Needs no provider configuration; env.cfg is nil
+	newMode    func(env *modeEnv) (Mode, error) // Builds the mode to run
+}
+
+// modeSpecs lists the modes in the order the usage text shows them. It is set in init,
+// as the completion mode reads it.
+var modeSpecs []modeSpec
+
+func init() {
+	modeSpecs = []modeSpec{
+		{name: "cli", newMode: newCLIMode, help: []helpLine{{"cli", "Start in CLI mode (interactive terminal); --color auto|always|never"}},
+			flags: []completionFlag{{"--color", argColor}}},
+		{name: "tui", newMode: newTUIMode, help: []helpLine{{"tui", "Start in terminal UI mode (chat, history, and live budget panes)"}}},
+		{name: "web", newMode: newWebMode, help: []helpLine{{"web", "Start in web mode (HTTP server)"}}, multiUser: true},
+		{name: "slack", newMode: newSlackMode, help: []helpLine{{"slack", "Run as a Slack bot over Socket Mode"}}, multiUser: true},
+		{name: "daemon", newMode: newDaemonMode, help: []helpLine{{"daemon", "Keep sessions warm behind a local Unix socket (see daemon -h)"}},
+			flags: []completionFlag{{"--socket", argText}, {"--idle", argText}}, multiUser: true},
+		{name: "mcp", newMode: newMCPMode, help: []helpLine{{"mcp", "Serve conversations, prompts, metrics, and a chat tool to MCP clients over stdio"}},
+			flags: []completionFlag{{"--prompts", argText}}, multiUser: true},
+		{name: "ask", newMode: newAskMode, help: []helpLine{
+			{"ask <query>", "Ask through a running daemon, reusing its session context (see ask -h)"},
+			{"ask --fanout <models> <query>", "Ask several models at once and compare or --merge their answers"},
+		}, flags: []completionFlag{
+			{"--session", argText}, {"--new", argNone}, {"--socket", argText},
+			{"--fanout", argModels}, {"--merge", argNone}, {"--raw", argNone},
+		}},
+		{name: "bench", newMode: newBenchMode, help: []helpLine{{"bench", "Compare providers on the same prompts (see bench -h)"}}, flags: []completionFlag{
+			{"--providers", argProviders}, {"--models", argText}, {"--prompt-file", argText},
+			{"--prompt", argText}, {"--runs", argText},
+		}},
+		{name: "eval", newMode: newEvalMode, help: []helpLine{{"eval <file>", "Run an evaluation suite from a YAML file (see eval -h)"}}, flags: []completionFlag{
+			{"--model", argModels}, {"--judge-model", argModels}, {"--min-pass-rate", argText}, {"-v", argNone},
+		}},
+		{name: "batch", newMode: newBatchMode, help: []helpLine{{"batch <file>", "Answer every prompt in a file as JSONL; --openai-batch uses the Batch API (see batch -h)"}}, flags: []completionFlag{
+			{"-o", argText}, {"--model", argModels}, {"--system", argText},
+			{"--openai-batch", argNone}, {"--state", argText}, {"--poll", argText}, {"--transcript", argText},
+		}},
+		{name: "replay", newMode: newReplayMode, help: []helpLine{{"replay <log>", "Re-send a logged session's messages to --model and report how the answers differ"}},
+			flags: []completionFlag{{"--model", argModels}, {"-o", argText}, {"--dir", argText}}},
+		{name: "costs", newMode: newCostsMode, help: []helpLine{{"costs sync", "Compare estimated costs with OpenAI's bill; --calibrate estimates with the billed prices"}},
+			args: []string{"sync"}, flags: []completionFlag{{"--days", argText}, {"--calibrate", argNone}}},
+		{name: "commit", newMode: newCommitMode, help: []helpLine{{"commit", "Write a commit message for the staged diff; -a commits with it (see commit -h)"}},
+			flags: []completionFlag{{"-a", argNone}, {"--model", argModels}, {"--max-diff-bytes", argText}}},
+		{name: "review", newMode: newReviewMode, help: []helpLine{{"review <src>", "Review a diff, file, or GitHub PR URL and print a Markdown report (see review -h)"}},
+			flags: []completionFlag{{"--model", argModels}, {"--chunk-tokens", argText}, {"-o", argText}}},
+		{name: "search", standalone: true, newMode: newSearchMode, help: []helpLine{{"search <text>", "Search past conversations and session logs (see search -h)"}},
+			flags: []completionFlag{{"--limit", argText}, {"--dir", argText}, {"--logs", argText}}},
+		{name: "stats", standalone: true, newMode: newStatsMode, help: []helpLine{
+			{"stats export", "Write the logged interactions as CSV or JSONL; --since 7d limits them (see stats export -h)"},
+			{"stats digest", "Summarize the last day or week of usage as Markdown or HTML; --webhook, --email deliver it"},
+		}, args: []string{"export", "digest"}, flags: []completionFlag{
+			{"--format", argText}, {"--since", argText}, {"-o", argText}, {"--logs", argText},
+			{"--period", argText}, {"--webhook", argNone}, {"--email", argText},
+		}},
+		{name: "tokens", standalone: true, newMode: newTokensMode, help: []helpLine{{"tokens create|list|revoke", "Manage the API tokens /api/v1 requires once any exist (see tokens create -h)"}},
+			args: []string{"create", "list", "revoke"}, flags: []completionFlag{
+				{"--name", argText}, {"--tenant", argText}, {"--budget", argText}, {"--rpm", argText}, {"--file", argText},
+			}},
+		{name: "version", standalone: true, newMode: newVersionMode, help: []helpLine{{"version", "Print the version and build details and check for a newer release (--no-update-check to skip)"}},
+			flags: []completionFlag{{"--no-update-check", argNone}}},
+		{name: "doctor", standalone: true, newMode: newDoctorMode, help: []helpLine{{"doctor", "Check the configuration, provider, model, and data directories and suggest fixes"}}},
+		{name: "config", standalone: true, newMode: newConfigMode, help: []helpLine{{"config validate", "Check the configuration, model, and provider for CI; --format json, --offline, --strict"}},
+			args: []string{"validate"}, flags: []completionFlag{{"--format", argText}, {"--offline", argNone}, {"--strict", argNone}}},
+		{name: "completion", standalone: true, newMode: newCompletionMode, help: []helpLine{{"completion <shell>", "Print the completion script for bash, zsh, fish, or powershell"}},
+			args: completionShells},
+	}
+}
+
+// queryHelp describes quick query mode, which any argument that isn't a mode starts
+var queryHelp = []helpLine{
+	{`"<query>"`, "Quick query mode (non-interactive)"},
+	{`--best-of N [--select vote|judge] "<query>"`, "Sample N answers and print the best one"},
+	{`--quiet | --verbose "<query>"`, "Print the reply only, or also the request details and timing on stderr"},
+	{`--output <file> --transcript <file> "<query>"`, "Write the reply to a file; append the exchange to a Markdown transcript"},
+	{`{{file "path"}} {{env "VAR"}} {{clipboard}} {{sh "cmd"}}`, "Helpers expanded in a query before sending (--raw to skip)"},
+	{`--from-clipboard ["<query>"]`, "Ask about the clipboard text, after the query if one is given"},
+}
+
+// globalFlags come before the mode
+var globalFlags = []completionFlag{
+	{"--user", argUsers}, {"--best-of", argText}, {"--select", argSelect},
+	{"--quiet", argNone}, {"--verbose", argNone}, {"--output", argText}, {"--transcript", argText},
+	{"--raw", argNone}, {"--from-clipboard", argNone},
+}
+
+// optionHelp describes the options that apply to every mode
+var optionHelp = []helpLine{
+	{"--user <name>", "Keep history, session logs, and budget in ~/.local/share/chatgbt/<name> (not for " + strings.Join(multiUserModes(), ", ") + ")"},
+}
+
+// envHelp describes the environment variables config reads. Defaults are looked up when
+// the usage is printed, so they can't drift from the code.
+func envHelp() []helpLine {
+	var providers []string
+	for _, provider := range backend.ProviderNames() {
+		providers = append(providers, string(provider))
+	}
+	var features []string
+	for _, feature := range backend.Features {
+		features = append(features, string(feature))
+	}
+
+	return []helpLine{
+		{"API_KEY", "Required: Your API key for the selected provider; <PROVIDER>_API_KEY, e.g. OPENAI_API_KEY, also works"},
+		{"LLM_PROVIDER", fmt.Sprintf("Optional: LLM provider (%s) (default: %s)", strings.Join(providers, ", "), config.DefaultProvider)},
+		{"OPENAI_API", "Optional: OpenAI endpoint, chat (/v1/chat/completions) or responses (/v1/responses) (default: chat)"},
+		{"OPENAI_ORG_ID", "Optional: OpenAI organization to bill requests to (default: the key's default)"},
+		{"OPENAI_ADMIN_KEY", "Optional: OpenAI admin key costs sync reads the organization's usage and costs with"},
+		{"OPENAI_PROJECT", "Optional: OpenAI project to bill requests to (default: the key's default)"},
+		{"MODEL", fmt.Sprintf("Optional: Model to use (default: %s, or the provider's default)", config.DefaultModel)},
+		{"API_URL", "Optional: Endpoint to call instead of the provider's default, e.g. a proxy or a remote Ollama"},
+		{"ENV_FILE", "Optional: File of KEY=VALUE settings read at startup, and again when the web server reloads"},
+		{"PORT", fmt.Sprintf("Optional: Web server port number (default: %d)", config.DefaultPort)},
+		{"HOST", fmt.Sprintf("Optional: Web server interface, 0.0.0.0 for all (default: %s)", config.DefaultHost)},
+		{"TOKEN_BUDGET", "Optional: Session token budget (default: 10000)"},
+		{"COST_BUDGET", "Optional: Session cost budget in USD (default: $0.02)"},
+		{"MAX_DAILY_COST", "Optional: Combined spend in USD per day across web or Slack sessions (default: no limit)"},
+		{"TENANT_DAILY_COST", "Optional: Spend in USD per day of each tenant without a daily_cost in its budget.yaml (default: no limit but MAX_DAILY_COST)"},
+		{"COMPARE_MODEL", "Optional: Default provider:model for side B of the web compare view"},
+		{"PROMPTS_FILE", fmt.Sprintf("Optional: System prompt preset file (default: %s)", prompts.DefaultPath())},
+		{"SHARE_SECRET", "Optional: Key that signs web share links (default: random per run)"},
+		{"SHARE_TTL", fmt.Sprintf("Optional: Lifetime of web share links (default: %v)", config.DefaultShareTTL)},
+		{"MAX_MESSAGE_LENGTH", fmt.Sprintf("Optional: Longest user message in characters (default: %d)", config.DefaultMaxMessageLength)},
+		{"MAX_CONCURRENT_REQUESTS", "Optional: Most completions in flight at once across all sessions (default: no limit)"},
+		{"QUEUE_TIMEOUT", fmt.Sprintf("Optional: How long a completion waits for a slot under MAX_CONCURRENT_REQUESTS (default: %v)", config.DefaultQueueTimeout)},
+		{"RATE_LIMIT_TIER", "Optional: Provider usage tiers to pace requests to, e.g. tier1 or openai:tier2,anthropic:tier1"},
+		{"RATE_LIMIT_RPM", "Optional: Requests per minute to pace LLM_PROVIDER to, overriding its tier"},
+		{"RATE_LIMIT_TPM", "Optional: Tokens per minute to pace LLM_PROVIDER to, overriding its tier"},
+		{"DEBUG_HTTP", "Optional: true records provider requests and responses, API keys removed (default: false)"},
+		{"DEBUG_HTTP_DIR", fmt.Sprintf("Optional: Directory DEBUG_HTTP records to (default: %s)", backend.DefaultHTTPDebugDir)},
+		{"SESSION_QUEUE", fmt.Sprintf("Optional: Requests that wait while a session generates a reply, 0 to reject them (default: %d)", config.DefaultSessionQueue)},
+		{"MAX_BODY_SIZE", fmt.Sprintf("Optional: Largest web request body in bytes (default: %d)", config.DefaultMaxBodySize)},
+		{"SESSION_TTL", fmt.Sprintf("Optional: Idle time before a web session expires (default: %v)", config.DefaultSessionTTL)},
+		{"SESSION_CLEANUP_INTERVAL", fmt.Sprintf("Optional: How often expired web sessions are removed (default: %v)", config.DefaultSessionCleanupInterval)},
+		{"MAX_SESSIONS", "Optional: Most concurrent web sessions, evicting the least recently used (default: no limit)"},
+		{"SESSION_FILE", fmt.Sprintf("Optional: File that keeps web sessions across restarts, or \"off\" (default: %s)", config.DefaultSessionFile)},
+		{"ADMIN_TOKEN", "Optional: Bearer token for /api/v1/sessions, /api/v1/tenants, and /api/v1/reload (disabled when unset)"},
+		{"WEB_THEME", "Optional: Default web UI theme, dark or light (default: dark)"},
+		{"WEB_SERVER", fmt.Sprintf("Optional: HTTP server for web mode, %s or %s (default: %s)", config.WebServerFiber, config.WebServerNetHTTP, config.WebServerFiber)},
+		{"ACCESS_LOG_FORMAT", "Optional: Web request log format, text, json, or off (default: text)"},
+		{"ACCESS_LOG_FIELDS", fmt.Sprintf("Optional: Comma-separated request log fields (default: %s)", strings.Join(config.DefaultAccessLogFields, ","))},
+		{"ACCESS_LOG_SAMPLE_RATE", "Optional: Fraction of successful requests logged (default: 1)"},
+		{"API_TOKENS_FILE", fmt.Sprintf("Optional: File holding the API tokens of tokens create (default: %s)", apikeys.DefaultPath())},
+		{"WEB_SOCKET", "Optional: Unix socket path for web mode to listen on instead of PORT"},
+		{"CORS_ALLOWED_ORIGINS", "Optional: Comma-separated origins allowed to call /api/v1 (\"*\" for any)"},
+		{"API_MODELS", "Optional: Comma-separated models a /api/v1/chat request may pick, or * for any (default: none)"},
+		{"API_MAX_TOKENS", "Optional: Largest max_tokens a /api/v1/chat request may ask for (default: no limit)"},
+		{"IP_ALLOWLIST", "Optional: Comma-separated addresses or CIDR networks that may reach web mode (default: any)"},
+		{"IP_DENYLIST", "Optional: Comma-separated addresses or CIDR networks refused by web mode"},
+		{"SLACK_APP_TOKEN", "Slack mode: App-level token (xapp-...) for Socket Mode"},
+		{"SLACK_BOT_TOKEN", "Slack mode: Bot token (xoxb-...)"},
+		{"SLACK_WORKSPACE_BUDGET", fmt.Sprintf("Slack mode: Tokens each workspace may spend (default: %d)", config.DefaultSlackWorkspaceBudget)},
+		{"WEBHOOK_URLS", "Optional: Comma-separated URLs that receive budget and failure events as JSON"},
+		{"SLACK_WEBHOOK_URLS", "Optional: Comma-separated Slack incoming webhook URLs for the same events"},
+		{"WEBHOOK_FAILURE_THRESHOLD", fmt.Sprintf("Optional: Consecutive provider failures before notifying (default: %d)", config.DefaultWebhookFailureThreshold)},
+		{"TOOLS_DIR", fmt.Sprintf("Optional: Directory of subprocess tools the model may call (default: %s)", tools.DefaultDir())},
+		{"CLASSIFIER", "Optional: Prompt classifier for metrics (keyword, embedding) (default: keyword)"},
+		{"EMBEDDING_MODEL", fmt.Sprintf("Optional: Embedding model for CLASSIFIER=embedding (default: %s)", backend.DefaultEmbeddingModel)},
+		{"EMBEDDING_URL", "Optional: OpenAI-compatible chat completions URL whose /embeddings endpoint is used (default: API URL)"},
+		{"UI_LANGUAGE", fmt.Sprintf("Optional: Interface language (%s) or auto to follow the user (default: auto)", strings.Join(i18n.Supported(), ", "))},
+		{"FEW_SHOT_FILE", "Optional: YAML file of few-shot examples keyed by conversation type (cli_session, web)"},
+		{"CONVERSATION_TYPE", "Optional: Defaults for CLI and TUI sessions: cli_session, code_review, tutor (default: cli_session)"},
+		{"CONVERSATIONS_FILE", "Optional: YAML file overriding prompt, pruning, and budget defaults per conversation type"},
+		{"ROUTER_FILE", "Optional: YAML rules that send messages to other models by prompt type, size, or budget left"},
+		{"FEATURE_<NAME>", fmt.Sprintf("Optional: false turns a feature off for this deployment (%s) (default: true)", strings.Join(features, ", "))},
+	}
+}
+
+// lookupMode returns the spec of the mode called name
+func lookupMode(name string) (modeSpec, bool) {
+	i := slices.IndexFunc(modeSpecs, func(spec modeSpec) bool { return spec.name == name })
+	if i < 0 {
+		return modeSpec{}, false
+	}
+	return modeSpecs[i], true
+}
+
+// multiUserModes returns the names of the modes that reject --user
+func multiUserModes() []string {
+	var names []string
+	for _, spec := range modeSpecs {
+		if spec.multiUser {
+			names = append(names, spec.name)
+		}
+	}
+	return names
+}
+
+// writeUsage writes the usage text for the program called name
+func writeUsage(w io.Writer, name string) {
+	fmt.Fprintf(w, "Usage: %s [--user <name>] <mode> [options]\n", name)
+
+	fmt.Fprintf(w, "\nModes:\n")
+	var modes []helpLine
+	for _, spec := range modeSpecs {
+		modes = append(modes, spec.help...)
+	}
+	writeHelpLines(w, append(modes, queryHelp...), 13)
+
+	fmt.Fprintf(w, "\nOptions:\n")
+	writeHelpLines(w, optionHelp, 13)
+
+	fmt.Fprintf(w, "\nEnvironment Variables:\n")
+	writeHelpLines(w, envHelp(), 15)
+}
+
+// writeHelpLines writes lines indented, padding each syntax to width so the texts line up;
+// longer ones are followed by two spaces
+func writeHelpLines(w io.Writer, lines []helpLine, width int) {
+	for _, line := range lines {
+		if len(line.syntax) < width {
+			fmt.Fprintf(w, "  %-*s %s\n", width, line.syntax, line.text)
+		} else {
+			fmt.Fprintf(w, "  %s  %s\n", line.syntax, line.text)
+		}
+	}
+}
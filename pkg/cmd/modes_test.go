@@ -0,0 +1,40 @@
+package main
+
+import "testing"
+
+func TestModeSpecs(t *testing.T) {
+	seen := make(map[string]bool)
+	for _, spec := range modeSpecs {
+		if seen[spec.name] {
+			t.Errorf("mode %s is listed twice", spec.name)
+		}
+		seen[spec.name] = true
+		if spec.newMode == nil {
+			t.Errorf("mode %s has no newMode, so run can't start it", spec.name)
+		}
+		if len(spec.help) == 0 {
+			t.Errorf("mode %s has no usage text", spec.name)
+		}
+	}
+}
+
+func TestLookupMode(t *testing.T) {
+	tests := []struct {
+		arg            string
+		wantMode       bool
+		wantStandalone bool
+	}{
+		{arg: "web", wantMode: true},
+		{arg: "cli", wantMode: true},
+		{arg: "version", wantMode: true, wantStandalone: true},
+		{arg: "tokens", wantMode: true, wantStandalone: true},
+		{arg: "what is a goroutine?"},
+		{arg: "--best-of"},
+	}
+	for _, tt := range tests {
+		spec, ok := lookupMode(tt.arg)
+		if ok != tt.wantMode || spec.standalone != tt.wantStandalone {
+			t.Errorf("lookupMode(%q) = standalone %v, found %v; want %v, %v", tt.arg, spec.standalone, ok, tt.wantStandalone, tt.wantMode)
+		}
+	}
+}
@@ -0,0 +1,160 @@
+package main
+
+import (
+	"net"
+	"os"
+	"strconv"
+
+	"github.com/nleiva/chatgbt/internal/app"
+	"github.com/nleiva/chatgbt/internal/cli"
+	"github.com/nleiva/chatgbt/internal/daemon"
+	"github.com/nleiva/chatgbt/internal/mcp"
+	"github.com/nleiva/chatgbt/internal/slackbot"
+	"github.com/nleiva/chatgbt/internal/tui"
+	"github.com/nleiva/chatgbt/internal/web"
+	"github.com/nleiva/chatgbt/pkg/backend"
+	"github.com/nleiva/chatgbt/pkg/config"
+	"github.com/nleiva/chatgbt/pkg/store"
+)
+
+// modeEnv is what a mode is built from: the arguments after its name, the --user
+// profile, and, unless the mode is standalone, the configuration and session options
+type modeEnv struct {
+	args    []string
+	profile *config.UserProfile // nil without --user
+	cfg     *config.Config      // nil for standalone modes
+	opts    app.SessionOptions
+}
+
+// modeFunc adapts a function to Mode
+type modeFunc func(cfg backend.LLMConfig, budgetCfg backend.TokenBudgetConfig) error
+
+func (f modeFunc) Run(cfg backend.LLMConfig, budgetCfg backend.TokenBudgetConfig) error {
+	return f(cfg, budgetCfg)
+}
+
+// Doctor and config validate load the configuration themselves, to report what's
+// wrong with it
+
+func newDoctorMode(env *modeEnv) (Mode, error) {
+	d := &doctor{profile: env.profile, writer: os.Stdout}
+	return modeFunc(func(backend.LLMConfig, backend.TokenBudgetConfig) error { return d.Run() }), nil
+}
+
+func newConfigMode(env *modeEnv) (Mode, error) {
+	return newConfigValidator(env.args, env.profile, os.Stdout)
+}
+
+// Neither version nor completion scripts need provider configuration
+
+func newVersionMode(env *modeEnv) (Mode, error) {
+	return newVersionRunner(env.args, os.Stdout)
+}
+
+func newCompletionMode(env *modeEnv) (Mode, error) {
+	return newCompletionRunner(env.args, os.Stdout)
+}
+
+// Search and stats only read local files, and managing API tokens only touches the
+// token file, so they don't need provider configuration either
+
+func newSearchMode(env *modeEnv) (Mode, error) {
+	args := env.args
+	if env.profile != nil {
+		// Flags given after these still win
+		args = append([]string{"-dir", env.profile.ConversationsDir(), "-logs", env.profile.LogsDir()}, args...)
+	}
+	return cli.NewSearchRunner(args)
+}
+
+func newStatsMode(env *modeEnv) (Mode, error) {
+	logsDir := "logs"
+	if env.profile != nil {
+		logsDir = env.profile.LogsDir()
+	}
+	if len(env.args) > 0 && env.args[0] == "digest" {
+		return cli.NewDigestRunner(env.args[1:], logsDir, config.LoadDigestConfig(os.Stderr))
+	}
+	return cli.NewStatsRunner(env.args, logsDir)
+}
+
+func newTokensMode(env *modeEnv) (Mode, error) {
+	return cli.NewTokensRunner(env.args, config.APITokensFile())
+}
+
+// The modes below talk to a provider
+
+func newCLIMode(env *modeEnv) (Mode, error) {
+	opts := env.opts
+	opts.FollowUps = env.cfg.FollowUps
+	opts.AutoContinue = env.cfg.AutoContinue
+	return cli.NewCLIRunner(env.args, opts)
+}
+
+func newTUIMode(env *modeEnv) (Mode, error) {
+	return tui.NewTUIRunner(env.opts), nil
+}
+
+func newWebMode(env *modeEnv) (Mode, error) {
+	cfg := env.cfg
+	opts := env.opts
+	opts.FollowUps = cfg.FollowUps
+	opts.AutoContinue = cfg.AutoContinue
+	address := net.JoinHostPort(cfg.Host, strconv.Itoa(cfg.Port))
+	if cfg.Web.Socket != "" {
+		address = "unix:" + cfg.Web.Socket
+	}
+	runner := web.NewWebRunner(address, cfg.Web, opts)
+	runner.SetReloader(func() (backend.LLMConfig, backend.TokenBudgetConfig, app.SessionOptions, error) {
+		return reloadSettings(opts)
+	})
+	return runner, nil
+}
+
+func newSlackMode(env *modeEnv) (Mode, error) {
+	return slackbot.NewSlackRunner(env.cfg.Slack, env.opts), nil
+}
+
+func newDaemonMode(env *modeEnv) (Mode, error) {
+	return daemon.NewDaemonRunner(env.args, env.opts)
+}
+
+func newMCPMode(env *modeEnv) (Mode, error) {
+	return mcp.NewMCPRunner(env.args, env.cfg.Web.PromptsFile, env.opts)
+}
+
+func newAskMode(env *modeEnv) (Mode, error) {
+	return daemon.NewAskRunner(env.args, env.cfg.LLM.ShowUsage)
+}
+
+func newBenchMode(env *modeEnv) (Mode, error) {
+	return cli.NewBenchRunner(env.args)
+}
+
+func newEvalMode(env *modeEnv) (Mode, error) {
+	return cli.NewEvalRunner(env.args)
+}
+
+func newBatchMode(env *modeEnv) (Mode, error) {
+	return cli.NewBatchRunner(env.args)
+}
+
+func newReplayMode(env *modeEnv) (Mode, error) {
+	conversationsDir := store.DefaultDir
+	if env.profile != nil {
+		conversationsDir = env.profile.ConversationsDir()
+	}
+	return cli.NewReplayRunner(env.args, conversationsDir)
+}
+
+func newCostsMode(env *modeEnv) (Mode, error) {
+	return cli.NewCostsRunner(env.args, env.cfg.OpenAIAdminKey)
+}
+
+func newCommitMode(env *modeEnv) (Mode, error) {
+	return cli.NewCommitRunner(env.args, env.cfg.LLM.ShowUsage)
+}
+
+func newReviewMode(env *modeEnv) (Mode, error) {
+	return cli.NewReviewRunner(env.args, env.cfg.LLM.ShowUsage)
+}
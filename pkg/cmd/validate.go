@@ -0,0 +1,124 @@
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"strings"
+
+	"github.com/nleiva/chatgbt/pkg/backend"
+	"github.com/nleiva/chatgbt/pkg/config"
+)
+
+// Output formats of config validate
+const (
+	validateText = "text"
+	validateJSON = "json"
+)
+
+// validateUsage describes the config subcommand
+const validateUsage = "usage: config validate [--format text|json] [--offline] [--strict]"
+
+// ConfigValidator checks the configuration in the environment the way a server would
+// load it, for CI pipelines that bake the configuration into an image. Unlike doctor,
+// it leaves the data directories alone and can report as JSON.
+type ConfigValidator struct {
+	doctor  doctor
+	format  string
+	offline bool // Skip the test request to the provider
+	strict  bool // Fail on warnings too
+}
+
+// validateReport is the JSON output of config validate
+type validateReport struct {
+	Valid    bool             `json:"valid"`
+	Provider string           `json:"provider,omitempty"`
+	Model    string           `json:"model,omitempty"`
+	URL      string           `json:"url,omitempty"`
+	Checks   []validateResult `json:"checks"`
+}
+
+// validateResult is one check in the JSON output
+type validateResult struct {
+	Name   string `json:"name"`
+	Status string `json:"status"` // ok, warn, fail, or skip
+	Detail string `json:"detail"`
+	Fix    string `json:"fix,omitempty"`
+}
+
+// newConfigValidator parses the config subcommand arguments
+func newConfigValidator(args []string, profile *config.UserProfile, w io.Writer) (*ConfigValidator, error) {
+	if len(args) == 0 || args[0] != "validate" {
+		return nil, fmt.Errorf(validateUsage)
+	}
+
+	v := &ConfigValidator{doctor: doctor{profile: profile, writer: w}}
+	fs := flag.NewFlagSet("config validate", flag.ContinueOnError)
+	fs.StringVar(&v.format, "format", validateText, "Output format: text or json")
+	fs.BoolVar(&v.offline, "offline", false, "Don't send a test request to the provider")
+	fs.BoolVar(&v.strict, "strict", false, "Treat warnings as failures")
+	if err := fs.Parse(args[1:]); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf(validateUsage)
+	}
+	if v.format != validateText && v.format != validateJSON {
+		return nil, fmt.Errorf("invalid --format %q: must be %s or %s", v.format, validateText, validateJSON)
+	}
+	return v, nil
+}
+
+// Run performs the checks, reports them, and returns an error when the configuration
+// isn't valid, so the exit status tells CI the outcome
+func (v *ConfigValidator) Run(cfg backend.LLMConfig, budgetCfg backend.TokenBudgetConfig) error {
+	d := &v.doctor
+	loaded := d.checkConfig()
+	switch {
+	case loaded == nil:
+		d.add("provider", checkSkip, "needs a valid configuration", "")
+	case v.offline:
+		d.checkModel(loaded)
+		d.add("provider", checkSkip, "--offline", "")
+	default:
+		d.checkModel(loaded)
+		d.checkProvider(loaded)
+	}
+
+	problems := d.count(checkFail)
+	if v.strict {
+		problems += d.count(checkWarn)
+	}
+
+	if v.format == validateJSON {
+		report := validateReport{Valid: problems == 0, Checks: make([]validateResult, 0, len(d.checks))}
+		if loaded != nil {
+			report.Provider, report.Model, report.URL = string(loaded.LLM.Provider), loaded.LLM.Model, loaded.LLM.URL
+		}
+		for _, check := range d.checks {
+			report.Checks = append(report.Checks, validateResult{
+				Name:   check.name,
+				Status: strings.ToLower(check.status),
+				Detail: check.detail,
+				Fix:    check.fix,
+			})
+		}
+		enc := json.NewEncoder(d.writer)
+		enc.SetIndent("", "  ")
+		enc.SetEscapeHTML(false)
+		if err := enc.Encode(report); err != nil {
+			return err
+		}
+	} else {
+		d.writeChecks()
+	}
+
+	if problems > 0 {
+		return fmt.Errorf("configuration is not valid: %d problem(s)", problems)
+	}
+	if v.format == validateText {
+		fmt.Fprintln(d.writer, "\nConfiguration is valid.")
+	}
+	return nil
+}
@@ -0,0 +1,169 @@
+package main
+
+import (
+	"cmp"
+	"context"
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"net/http"
+	"runtime"
+	"runtime/debug"
+	"strings"
+	"time"
+
+	"golang.org/x/mod/semver"
+
+	"github.com/nleiva/chatgbt/pkg/backend"
+)
+
+// Set by the Makefile with -ldflags -X; plain go builds fall back to the module's build info
+var (
+	Version   string
+	BuildTime string
+	GoVersion string
+)
+
+// modulePath locates the GitHub repository whose releases the update check looks at
+const modulePath = "github.com/nleiva/chatgbt"
+
+// updateCheckTimeout bounds the request to GitHub so an offline machine isn't kept waiting
+const updateCheckTimeout = 3 * time.Second
+
+// buildInfo describes the running binary
+type buildInfo struct {
+	version   string
+	commit    string
+	modified  bool // Built from a working tree with uncommitted changes
+	buildTime string
+	goVersion string
+}
+
+// readBuildInfo combines the values set at link time with what the Go toolchain
+// recorded in the binary
+func readBuildInfo() buildInfo {
+	info := buildInfo{version: Version, buildTime: BuildTime, goVersion: GoVersion}
+	if bi, ok := debug.ReadBuildInfo(); ok {
+		if bi.Main.Version != "" && bi.Main.Version != "(devel)" {
+			info.version = cmp.Or(info.version, bi.Main.Version)
+		}
+		info.goVersion = cmp.Or(info.goVersion, bi.GoVersion)
+		for _, setting := range bi.Settings {
+			switch setting.Key {
+			case "vcs.revision":
+				info.commit = setting.Value
+			case "vcs.time":
+				info.buildTime = cmp.Or(info.buildTime, setting.Value)
+			case "vcs.modified":
+				info.modified = setting.Value == "true"
+			}
+		}
+	}
+	info.version = cmp.Or(info.version, "dev")
+	info.goVersion = cmp.Or(info.goVersion, runtime.Version())
+	return info
+}
+
+// VersionRunner prints the version and build details, and whether a newer release exists
+type VersionRunner struct {
+	updateCheck bool
+	releasesURL string
+	writer      io.Writer
+}
+
+// newVersionRunner parses the version subcommand arguments
+func newVersionRunner(args []string, w io.Writer) (*VersionRunner, error) {
+	fs := flag.NewFlagSet("version", flag.ContinueOnError)
+	noUpdateCheck := fs.Bool("no-update-check", false, "Don't ask GitHub whether a newer release exists")
+	if err := fs.Parse(args); err != nil {
+		return nil, err
+	}
+	if fs.NArg() > 0 {
+		return nil, fmt.Errorf("usage: version [--no-update-check]")
+	}
+
+	return &VersionRunner{
+		updateCheck: !*noUpdateCheck,
+		releasesURL: "https://api.github.com/repos/" + strings.TrimPrefix(modulePath, "github.com/") + "/releases/latest",
+		writer:      w,
+	}, nil
+}
+
+// Run prints the build details, then the outcome of the update check. A failed check is
+// reported but isn't an error.
+func (v *VersionRunner) Run(cfg backend.LLMConfig, budgetCfg backend.TokenBudgetConfig) error {
+	info := readBuildInfo()
+	fmt.Fprintf(v.writer, "chatgbt %s\n", info.version)
+	if info.commit != "" {
+		commit := info.commit[:min(len(info.commit), 12)]
+		if info.modified {
+			commit += " (modified)"
+		}
+		fmt.Fprintf(v.writer, "  commit:   %s\n", commit)
+	}
+	if info.buildTime != "" {
+		fmt.Fprintf(v.writer, "  built:    %s\n", info.buildTime)
+	}
+	fmt.Fprintf(v.writer, "  go:       %s\n", info.goVersion)
+	fmt.Fprintf(v.writer, "  platform: %s/%s\n", runtime.GOOS, runtime.GOARCH)
+
+	if !v.updateCheck {
+		return nil
+	}
+	if !isRelease(info.version) {
+		fmt.Fprintln(v.writer, "\nUpdate check skipped: this is a development build.")
+		return nil
+	}
+
+	latest, url, err := v.latestRelease()
+	switch {
+	case err != nil:
+		fmt.Fprintf(v.writer, "\nUpdate check failed: %v\n", err)
+	case semver.Compare(latest, info.version) > 0:
+		fmt.Fprintf(v.writer, "\nA newer release is available: %s\n  %s\n", latest, url)
+	default:
+		fmt.Fprintln(v.writer, "\nYou're running the latest release.")
+	}
+	return nil
+}
+
+// isRelease reports whether version is a tagged release rather than a development
+// build: Go stamps those with a pseudo-version such as v0.0.0-20250101000000-abcdef123456,
+// and the Makefile with git describe output such as v1.2.0-3-gabcdef1-dirty
+func isRelease(version string) bool {
+	return semver.IsValid(version) && semver.Prerelease(version) == "" && semver.Build(version) == ""
+}
+
+// latestRelease returns the tag and page of the newest GitHub release
+func (v *VersionRunner) latestRelease() (tag, url string, err error) {
+	ctx, cancel := context.WithTimeout(context.Background(), updateCheckTimeout)
+	defer cancel()
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, v.releasesURL, nil)
+	if err != nil {
+		return "", "", err
+	}
+	req.Header.Set("Accept", "application/vnd.github+json")
+
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return "", "", err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return "", "", fmt.Errorf("GitHub answered %s", resp.Status)
+	}
+
+	var release struct {
+		TagName string `json:"tag_name"`
+		HTMLURL string `json:"html_url"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&release); err != nil {
+		return "", "", fmt.Errorf("failed to parse the release: %w", err)
+	}
+	if !semver.IsValid(release.TagName) {
+		return "", "", fmt.Errorf("latest release tag %q is not a version", release.TagName)
+	}
+	return release.TagName, release.HTMLURL, nil
+}
@@ -1,20 +1,43 @@
 package config
 
 import (
+	"cmp"
 	"fmt"
 	"io"
+	"net"
+	"net/netip"
+	"net/url"
 	"os"
+	"slices"
 	"strconv"
+	"strings"
+	"time"
 
+	"gopkg.in/yaml.v3"
+
+	"github.com/nleiva/chatgbt/pkg/apikeys"
 	"github.com/nleiva/chatgbt/pkg/backend"
+	"github.com/nleiva/chatgbt/pkg/i18n"
+	"github.com/nleiva/chatgbt/pkg/prompts"
+	"github.com/nleiva/chatgbt/pkg/tools"
 )
 
 const (
 	// Default configuration values
 	DefaultModel    = "gpt-3.5-turbo"
-	DefaultURL      = "https://api.openai.com/v1/chat/completions"
+	DefaultURL      = backend.OpenAIDefaultURL
 	DefaultPort     = 3000
+	DefaultHost     = "127.0.0.1"
 	DefaultProvider = "openai"
+
+	// DefaultMaxMessageLength is the longest user message, in characters, any mode accepts
+	DefaultMaxMessageLength = 32000
+	// DefaultMaxBodySize is the largest web request body, in bytes
+	DefaultMaxBodySize = 1 << 20
+	// DefaultSessionQueue is how many requests may wait for a session's reply in progress
+	DefaultSessionQueue = 3
+	// DefaultQueueTimeout is how long a completion waits for a slot under MAX_CONCURRENT_REQUESTS
+	DefaultQueueTimeout = 30 * time.Second
 )
 
 // Config holds all application configuration for the chatgbt application.
@@ -24,11 +47,262 @@ type Config struct {
 	LLM    backend.LLMConfig         // LLM client configuration
 	Budget backend.TokenBudgetConfig // Token usage and cost limits
 	Port   int                       // HTTP server port for web mode
+	Host   string                    // Interface the web server binds to; "0.0.0.0" for all
+	Web    WebConfig                 // Optional web mode settings
+	Slack  SlackConfig               // Slack bot mode settings
+	Hooks  WebhookConfig             // Budget and provider failure notifications
+
+	// Classifier selects how prompts are classified for metrics
+	Classifier ClassifierConfig
+
+	// Language is the UI language; AutoLanguage switches it to the language the user writes in
+	Language     string
+	AutoLanguage bool
+
+	// ToolsDir is searched for subprocess tools the model may call
+	ToolsDir string
+
+	// MaxMessageLength caps the characters in a single user message
+	MaxMessageLength int
+
+	// InjectionGuard flags or strips instruction-like lines in tool results
+	InjectionGuard backend.InjectionGuard
+
+	// FollowUps suggests follow-up questions after each reply in CLI and web mode
+	FollowUps bool
+
+	// AutoContinue asks for the rest of replies cut off at the token limit in CLI and web mode
+	AutoContinue bool
+
+	// OpenAIAdminKey reads the organization's usage and costs to reconcile estimates with
+	OpenAIAdminKey string
+
+	// SessionQueue is how many requests may wait while a session generates a reply;
+	// 0 rejects overlapping requests instead
+	SessionQueue int
+
+	// MaxConcurrentRequests caps the completions the process sends at once; 0 for no
+	// limit. Requests over it wait up to QueueTimeout for a slot.
+	MaxConcurrentRequests int
+	QueueTimeout          time.Duration
+
+	// DebugHTTP records provider requests and responses, credentials removed, to DebugHTTPDir
+	DebugHTTP    bool
+	DebugHTTPDir string
+
+	// Features turns subsystems off for this deployment, from FEATURE_<NAME>
+	Features backend.FeatureSet
+
+	// RateLimits pace the completions sent to each provider to its per-minute limits
+	RateLimits map[backend.ProviderName]backend.RateLimit
+
+	// MaxDailyCost caps the combined USD spend of web or Slack sessions per UTC day; 0 for no limit
+	MaxDailyCost float64
+
+	// TenantDailyCost caps the USD spend of each tenant's sessions per UTC day, for tenants
+	// whose budget.yaml sets no daily_cost; 0 for no limit but MaxDailyCost
+	TenantDailyCost float64
+
+	// FewShot holds example exchanges keyed by conversation type (e.g. "cli_session", "web")
+	FewShot map[string][]backend.FewShotExample
+
+	// ConversationType picks the defaults of CLI and TUI sessions (e.g. "code_review", "tutor")
+	ConversationType string
+
+	// Conversations overrides the built-in defaults of conversation types
+	Conversations map[string]ConversationProfile
+
+	// Routes pick another model for the messages they match, in order
+	Routes []RouteRule
+}
+
+// ConversationProfile overrides the defaults of one conversation type. Zero fields keep
+// the built-in value.
+type ConversationProfile struct {
+	SystemPrompt     string `yaml:"system_prompt"`
+	MaxTokens        int    `yaml:"max_tokens"`         // Auto-prune conversation context at this limit
+	KeepRecent       int    `yaml:"keep_recent"`        // Recent exchanges kept when pruning
+	KeepRecentTokens int    `yaml:"keep_recent_tokens"` // Tokens of recent messages kept when pruning, instead of exchanges
+	KeepRelevant     int    `yaml:"keep_relevant"`      // Older exchanges kept by relevance to the latest message when pruning
+	Summary          *bool  `yaml:"summary"`            // Summarize pruned content
+	SessionLimit     int    `yaml:"session_limit"`      // Session token budget
+	JSONMode         bool   `yaml:"json_mode"`          // Ask for replies as JSON objects
+}
+
+// RouteRule sends the messages that meet all of its conditions to Model. The first
+// matching rule in ROUTER_FILE wins; unmatched messages use MODEL.
+type RouteRule struct {
+	Model        string   `yaml:"model"`          // "provider:model" or a model of LLM_PROVIDER
+	PromptTypes  []string `yaml:"prompt_types"`   // Classifier categories, e.g. code_help
+	MinTokens    int      `yaml:"min_tokens"`     // Smallest estimated context in tokens
+	MaxTokens    int      `yaml:"max_tokens"`     // Largest estimated context in tokens
+	BudgetBelow  float64  `yaml:"budget_below"`   // Fraction of the session token budget left, e.g. 0.2
+	CostPerToken float64  `yaml:"cost_per_token"` // USD per token of Model
+}
+
+// WebConfig holds settings that only apply to web mode
+type WebConfig struct {
+	CompareModel string // Default "provider:model" for side B of the compare view
+	PromptsFile  string // JSON file holding user-defined system prompt presets
+
+	// ShareSecret signs public conversation links; when empty a random key is generated
+	// at startup and links stop working after a restart
+	ShareSecret string
+	ShareTTL    time.Duration // Default lifetime of a share link
+
+	Theme string // Web UI theme for visitors who haven't picked one: ThemeDark or ThemeLight
+
+	// CORSOrigins are the browser origins allowed to call /api/v1 ("*" allows any);
+	// empty keeps the API same-origin only
+	CORSOrigins []string
+
+	// APIModels are the models a /api/v1/chat request may pick instead of MODEL ("*"
+	// allows any); empty refuses model overrides. APIMaxTokens caps the max_tokens a
+	// request may ask for; 0 leaves it to the session budget.
+	APIModels    []string
+	APIMaxTokens int
+
+	MaxBodySize int // Largest request body in bytes; larger requests get 413
+
+	// SessionFile keeps web sessions across restarts; empty disables persistence
+	SessionFile string
+
+	SessionTTL             time.Duration // Idle time after which a web session expires
+	SessionCleanupInterval time.Duration // How often expired sessions are removed and sessions saved
+	MaxSessions            int           // Most concurrent sessions; the least recently used is evicted beyond it. 0 for no limit
+
+	// AdminToken authorizes the session administration API; empty disables it
+	AdminToken string
+
+	Server string // HTTP server accepting web connections, handed to the Fiber routes either way: WebServerFiber or WebServerNetHTTP
+
+	// Socket is a Unix domain socket path to listen on instead of PORT, such as for a
+	// reverse proxy on the same host
+	Socket string
+
+	AccessLog AccessLogConfig // Format, fields, and sampling of the request log
+
+	// APITokensFile holds the tokens "chatgbt tokens create" issues; once it has any,
+	// the JSON API requires one
+	APITokensFile string
+
+	// AllowedIPs, when not empty, are the only networks whose clients may reach the
+	// server; DeniedIPs are refused even when allowed
+	AllowedIPs []netip.Prefix
+	DeniedIPs  []netip.Prefix
+}
+
+// AccessLogConfig shapes the line the web server logs for each request
+type AccessLogConfig struct {
+	Format     string   // AccessLogText, AccessLogJSON, or AccessLogOff
+	Fields     []string // Fields of AccessLogFields to include, in order
+	SampleRate float64  // Fraction of successful requests logged; server errors always are
+}
+
+// Access log formats accepted in ACCESS_LOG_FORMAT
+const (
+	AccessLogText = "text"
+	AccessLogJSON = "json"
+	AccessLogOff  = "off"
+)
+
+// AccessLogFields are the fields ACCESS_LOG_FIELDS may list. route is the matched route
+// pattern, such as /conversations/:id/export, and bytes the size of the response body.
+var AccessLogFields = []string{"time", "request_id", "status", "latency", "ip", "method", "path", "route", "bytes", "user_agent", "error"}
+
+// DefaultAccessLogFields are logged when ACCESS_LOG_FIELDS is unset
+var DefaultAccessLogFields = []string{"time", "request_id", "status", "latency", "ip", "method", "path", "error"}
+
+// DefaultShareTTL is how long share links stay valid when SHARE_TTL is unset
+const DefaultShareTTL = 7 * 24 * time.Hour
+
+// DefaultSessionFile is where web sessions are saved when SESSION_FILE is unset
+const DefaultSessionFile = "logs/web_sessions.json"
+
+// Web session lifecycle defaults used when SESSION_TTL or SESSION_CLEANUP_INTERVAL is unset
+const (
+	DefaultSessionTTL             = 24 * time.Hour
+	DefaultSessionCleanupInterval = 30 * time.Minute
+)
+
+// Web UI themes accepted in WEB_THEME
+const (
+	ThemeDark  = "dark"
+	ThemeLight = "light"
+)
+
+// HTTP servers accepted in WEB_SERVER
+const (
+	WebServerFiber   = "fiber"
+	WebServerNetHTTP = "nethttp"
+)
+
+// IsTheme reports whether name is a supported web UI theme
+func IsTheme(name string) bool {
+	return name == ThemeDark || name == ThemeLight
+}
+
+// SlackConfig holds settings that only apply to Slack bot mode
+type SlackConfig struct {
+	AppToken             string // App-level token (xapp-...) used to open the Socket Mode connection
+	BotToken             string // Bot token (xoxb-...) used to post messages
+	WorkspaceTokenBudget int    // Tokens each workspace may spend across all of its threads
+}
+
+// WebhookConfig holds the endpoints notified about budget and provider failure events
+type WebhookConfig struct {
+	URLs             []string // Endpoints that receive the event as JSON
+	SlackURLs        []string // Slack incoming webhook URLs
+	FailureThreshold int      // Consecutive provider failures before notifying
+}
+
+// SMTPConfig holds the mail server usage digests are emailed through
+type SMTPConfig struct {
+	Addr     string // host:port of the server
+	Username string // Empty to send without authentication
+	Password string
+	From     string // Sender address
+}
+
+// DigestConfig holds where usage digests can be delivered
+type DigestConfig struct {
+	Hooks WebhookConfig
+	SMTP  SMTPConfig
+}
+
+// Classifier kinds accepted in CLASSIFIER
+const (
+	ClassifierKeyword   = "keyword"
+	ClassifierEmbedding = "embedding"
+)
+
+// ClassifierConfig holds prompt classification settings
+type ClassifierConfig struct {
+	Kind           string // ClassifierKeyword or ClassifierEmbedding
+	EmbeddingModel string // Embedding model, defaults to backend.DefaultEmbeddingModel
+	EmbeddingURL   string // Chat completions URL whose /embeddings sibling is used, defaults to the LLM URL
+}
+
+// DefaultWebhookFailureThreshold is used when WEBHOOK_FAILURE_THRESHOLD is unset
+const DefaultWebhookFailureThreshold = 3
+
+// DefaultSlackWorkspaceBudget is the per-workspace token budget when SLACK_WORKSPACE_BUDGET is unset
+const DefaultSlackWorkspaceBudget = 200000
+
+// Validate checks the Slack settings required to start the bot
+func (s SlackConfig) Validate() error {
+	if s.AppToken == "" {
+		return fmt.Errorf("SLACK_APP_TOKEN is required for slack mode")
+	}
+	if s.BotToken == "" {
+		return fmt.Errorf("SLACK_BOT_TOKEN is required for slack mode")
+	}
+	return nil
 }
 
 // Validate checks the configuration for correctness
 func (c *Config) Validate() error {
-	if c.LLM.APIKey == "" {
+	if c.LLM.APIKey == "" && c.LLM.Provider != backend.ProviderNameVertex {
 		return fmt.Errorf("API_KEY is required")
 	}
 	if c.LLM.URL == "" {
@@ -43,6 +317,9 @@ func (c *Config) Validate() error {
 	if c.Budget.SessionLimit <= 0 {
 		return fmt.Errorf("session limit must be positive, got %d", c.Budget.SessionLimit)
 	}
+	if c.Classifier.Kind != ClassifierKeyword && c.Classifier.Kind != ClassifierEmbedding {
+		return fmt.Errorf("CLASSIFIER must be %q or %q, got %q", ClassifierKeyword, ClassifierEmbedding, c.Classifier.Kind)
+	}
 	return nil
 }
 
@@ -50,18 +327,74 @@ func (c *Config) Validate() error {
 // a fully configured Config struct. It writes warnings to w for any
 // invalid environment variable values encountered.
 func LoadFromEnv(w io.Writer) (*Config, error) {
-	llmCfg, err := loadLLMConfig()
+	llmCfg, err := loadLLMConfig(w)
+	if err != nil {
+		return nil, err
+	}
+
+	fewShot, err := loadFewShot()
+	if err != nil {
+		return nil, err
+	}
+
+	conversations, err := loadConversations()
+	if err != nil {
+		return nil, err
+	}
+
+	routes, err := loadRoutes()
 	if err != nil {
 		return nil, err
 	}
 
-	budgetCfg := loadBudgetConfig(w)
+	rateLimits, err := loadRateLimits(w, llmCfg.Provider)
+	if err != nil {
+		return nil, err
+	}
+
+	budgetCfg := loadBudgetConfig(w, llmCfg.Provider, llmCfg.Model)
 	port := loadPort(w)
+	host := loadHost(w)
 
 	config := &Config{
-		LLM:    llmCfg,
-		Budget: budgetCfg,
-		Port:   port,
+		LLM:      llmCfg,
+		Budget:   budgetCfg,
+		Port:     port,
+		Host:     host,
+		Web:      loadWebConfig(w),
+		Slack:    loadSlackConfig(w),
+		Hooks:    loadWebhookConfig(w),
+		ToolsDir: loadToolsDir(),
+
+		MaxMessageLength: loadLimit(w, "MAX_MESSAGE_LENGTH", DefaultMaxMessageLength),
+		MaxDailyCost:     loadDailyCost(w, "MAX_DAILY_COST"),
+		TenantDailyCost:  loadDailyCost(w, "TENANT_DAILY_COST"),
+		SessionQueue:     loadCount(w, "SESSION_QUEUE", DefaultSessionQueue),
+		FollowUps:        loadFlag(w, "FOLLOW_UPS"),
+		AutoContinue:     loadFlag(w, "AUTO_CONTINUE"),
+		OpenAIAdminKey:   os.Getenv("OPENAI_ADMIN_KEY"),
+
+		MaxConcurrentRequests: loadCount(w, "MAX_CONCURRENT_REQUESTS", 0),
+		QueueTimeout:          loadDuration(w, "QUEUE_TIMEOUT", DefaultQueueTimeout),
+		RateLimits:            rateLimits,
+
+		DebugHTTP:    loadFlag(w, "DEBUG_HTTP"),
+		DebugHTTPDir: cmp.Or(os.Getenv("DEBUG_HTTP_DIR"), backend.DefaultHTTPDebugDir),
+
+		Features: loadFeatures(w),
+
+		Classifier: loadClassifierConfig(),
+
+		FewShot: fewShot,
+
+		ConversationType: os.Getenv("CONVERSATION_TYPE"),
+		Conversations:    conversations,
+		Routes:           routes,
+	}
+	config.Language, config.AutoLanguage = loadLanguage(w)
+	config.InjectionGuard, err = backend.ParseInjectionGuard(os.Getenv("INJECTION_GUARD"))
+	if err != nil {
+		return nil, fmt.Errorf("INJECTION_GUARD: %w", err)
 	}
 
 	// Validate the configuration
@@ -73,35 +406,143 @@ func LoadFromEnv(w io.Writer) (*Config, error) {
 }
 
 // loadLLMConfig creates LLM configuration from environment variables
-func loadLLMConfig() (backend.LLMConfig, error) {
-	apiKey := os.Getenv("API_KEY")
-	if apiKey == "" {
-		return backend.LLMConfig{}, fmt.Errorf("missing API key: please set the API_KEY environment variable")
+func loadLLMConfig(w io.Writer) (backend.LLMConfig, error) {
+	provider := os.Getenv("LLM_PROVIDER")
+	if provider == "" {
+		provider = DefaultProvider
+	}
+
+	apiKey := loadAPIKey(w, backend.ProviderName(provider))
+	// Vertex AI uses Application Default Credentials when no access token is given
+	if apiKey == "" && provider != string(backend.ProviderNameVertex) {
+		return backend.LLMConfig{}, fmt.Errorf("missing API key: please set the %s environment variable",
+			strings.Join(APIKeyVars(backend.ProviderName(provider)), " or "))
 	}
 
 	model := os.Getenv("MODEL")
 	if model == "" {
-		model = DefaultModel
+		model = cmp.Or(backend.DefaultModelFor(backend.ProviderName(provider)), DefaultModel)
 	}
 
-	// For now, we're defaulting to OpenAI, but this can be extended later
-	provider := os.Getenv("LLM_PROVIDER")
-	if provider == "" {
-		provider = DefaultProvider
+	// API_URL points any provider at another endpoint, such as a proxy or a remote Ollama
+	url, err := loadAPIURL()
+	if err != nil {
+		return backend.LLMConfig{}, err
+	}
+	apiURL := url != ""
+	switch {
+	case apiURL:
+	case provider == string(backend.ProviderNameHuggingFace):
+		url = loadHuggingFaceURL()
+	case provider == string(backend.ProviderNameVertex):
+		vertexURL, err := backend.VertexURLFromEnv()
+		if err != nil {
+			return backend.LLMConfig{}, err
+		}
+		url = vertexURL
+	default:
+		url = cmp.Or(backend.DefaultURLFor(backend.ProviderName(provider)), DefaultURL)
 	}
 
-	return backend.LLMConfig{
+	cfg := backend.LLMConfig{
 		APIKey:    apiKey,
-		URL:       DefaultURL,
+		URL:       url,
 		Model:     model,
 		Provider:  backend.ProviderName(provider),
 		ShowUsage: true,
-	}, nil
+	}
+
+	// OPENAI_API=responses switches OpenAI to the Responses API
+	switch api := strings.ToLower(os.Getenv("OPENAI_API")); api {
+	case "", backend.OpenAIAPIChat:
+	case backend.OpenAIAPIResponses:
+		cfg.API = api
+		if !apiURL {
+			cfg.URL = backend.OpenAIResponsesURL
+		}
+	default:
+		return backend.LLMConfig{}, fmt.Errorf("invalid OPENAI_API value '%s': must be %s or %s",
+			api, backend.OpenAIAPIChat, backend.OpenAIAPIResponses)
+	}
+	if cfg.Provider == backend.ProviderNameOpenAI {
+		cfg.Organization = strings.TrimSpace(os.Getenv("OPENAI_ORG_ID"))
+		cfg.Project = strings.TrimSpace(os.Getenv("OPENAI_PROJECT"))
+	}
+
+	return cfg, nil
+}
+
+// APIKeyVars returns the variables the API key of provider is read from, in order of
+// precedence: API_KEY, then the provider's own variable, such as OPENAI_API_KEY or
+// ANTHROPIC_API_KEY, then HF_TOKEN for Hugging Face. Vertex AI reads only API_KEY, as an
+// access token.
+func APIKeyVars(provider backend.ProviderName) []string {
+	vars := []string{"API_KEY"}
+	if provider == backend.ProviderNameVertex {
+		return vars
+	}
+	vars = append(vars, strings.ToUpper(string(provider))+"_API_KEY")
+	if provider == backend.ProviderNameHuggingFace {
+		vars = append(vars, "HF_TOKEN")
+	}
+	return vars
+}
+
+// loadAPIKey returns the first key set among the APIKeyVars of provider, warning when a
+// variable further down holds a different key, since that one is ignored
+func loadAPIKey(w io.Writer, provider backend.ProviderName) string {
+	var key, from string
+	for _, name := range APIKeyVars(provider) {
+		value := strings.TrimSpace(os.Getenv(name))
+		switch {
+		case value == "":
+		case key == "":
+			key, from = value, name
+		case value != key:
+			fmt.Fprintf(w, "Warning: %s and %s hold different keys, using %s\n", from, name, from)
+		}
+	}
+	return key
+}
+
+// loadAPIURL reads the endpoint in API_URL, which replaces the provider's default
+func loadAPIURL() (string, error) {
+	value := strings.TrimSpace(os.Getenv("API_URL"))
+	if value == "" {
+		return "", nil
+	}
+	u, err := url.Parse(value)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return "", fmt.Errorf("invalid API_URL value '%s': must be an http or https URL", value)
+	}
+	return value, nil
 }
 
-// loadBudgetConfig reads budget configuration from environment variables
-func loadBudgetConfig(w io.Writer) backend.TokenBudgetConfig {
+// loadHuggingFaceURL returns the Inference Endpoint in HF_ENDPOINT_URL, or the Inference
+// Providers router when it isn't set. A bare endpoint URL gets TGI's chat completions path.
+func loadHuggingFaceURL() string {
+	endpoint := strings.TrimSuffix(strings.TrimSpace(os.Getenv("HF_ENDPOINT_URL")), "/")
+	switch {
+	case endpoint == "":
+		return backend.HuggingFaceDefaultURL
+	case strings.HasSuffix(endpoint, "/generate"), strings.HasSuffix(endpoint, "/chat/completions"):
+		return endpoint
+	default:
+		return endpoint + "/v1/chat/completions"
+	}
+}
+
+// loadBudgetConfig reads budget configuration from environment variables. Presets
+// bring their own price per token, and a price calibrated against the provider's bill
+// replaces either; COST_BUDGET is converted with the result.
+func loadBudgetConfig(w io.Writer, provider backend.ProviderName, model string) backend.TokenBudgetConfig {
 	cfg := backend.DefaultBudgetConfig()
+	if preset, ok := backend.LookupPreset(provider); ok {
+		cfg.CostPerToken = preset.CostPerToken
+	}
+	if price := loadCalibratedPrice(w, model); price > 0 {
+		cfg.CostPerToken = price
+	}
 
 	if err := loadTokenBudget(&cfg, w); err != nil {
 		fmt.Fprintf(w, "Warning: %v\n", err)
@@ -157,6 +598,75 @@ func loadCostBudget(cfg *backend.TokenBudgetConfig, w io.Writer) error {
 	return nil
 }
 
+// loadDailyCost reads a daily spend cap in USD, such as MAX_DAILY_COST, from the
+// environment variable name
+func loadDailyCost(w io.Writer, name string) float64 {
+	costStr := os.Getenv(name)
+	if costStr == "" {
+		return 0
+	}
+
+	cost, err := strconv.ParseFloat(costStr, 64)
+	if err != nil || cost <= 0 {
+		fmt.Fprintf(w, "Warning: Invalid %s value '%s', using no limit\n", name, costStr)
+		return 0
+	}
+	return cost
+}
+
+// loadHost reads the interface to bind to from HOST. The default keeps the web UI
+// reachable from this machine only.
+func loadHost(w io.Writer) string {
+	host, name := getenv(w, "HOST")
+	if host == "" {
+		return DefaultHost
+	}
+
+	host = strings.Trim(host, "[]")
+	if net.ParseIP(host) == nil && !isHostname(host) {
+		fmt.Fprintf(w, "Warning: Invalid %s value '%s', using default %s\n", name, host, DefaultHost)
+		return DefaultHost
+	}
+	return host
+}
+
+// deprecatedEnv maps variables to the older names they replaced in a release. The old
+// names still work when the new one is unset, with a warning. Only names that shipped
+// belong here; none has been renamed yet.
+var deprecatedEnv = map[string]string{}
+
+// getenv returns the value of the variable name, or else of the deprecated variable it
+// replaced, along with the name it was read from
+func getenv(w io.Writer, name string) (string, string) {
+	if value := os.Getenv(name); value != "" {
+		return value, name
+	}
+	old, ok := deprecatedEnv[name]
+	if !ok {
+		return "", name
+	}
+	value := os.Getenv(old)
+	if value != "" {
+		fmt.Fprintf(w, "Warning: %s is deprecated, use %s instead\n", old, name)
+	}
+	return value, old
+}
+
+// isHostname reports whether name is made of DNS labels, such as "localhost"
+func isHostname(name string) bool {
+	for _, label := range strings.Split(name, ".") {
+		if label == "" || len(label) > 63 || strings.HasPrefix(label, "-") || strings.HasSuffix(label, "-") {
+			return false
+		}
+		for _, r := range label {
+			if !(r == '-' || '0' <= r && r <= '9' || 'a' <= r && r <= 'z' || 'A' <= r && r <= 'Z') {
+				return false
+			}
+		}
+	}
+	return true
+}
+
 // loadPort reads and validates the PORT environment variable
 func loadPort(w io.Writer) int {
 	portStr := os.Getenv("PORT")
@@ -179,3 +689,485 @@ func loadPort(w io.Writer) int {
 
 	return port
 }
+
+// loadLimit reads a positive integer limit from the environment variable name
+func loadLimit(w io.Writer, name string, defaultValue int) int {
+	valueStr := os.Getenv(name)
+	if valueStr == "" {
+		return defaultValue
+	}
+
+	value, err := strconv.Atoi(valueStr)
+	if err != nil || value <= 0 {
+		fmt.Fprintf(w, "Warning: Invalid %s value '%s', using default %d\n", name, valueStr, defaultValue)
+		return defaultValue
+	}
+	return value
+}
+
+// loadCount reads a non-negative integer such as a queue size from the environment variable name
+func loadCount(w io.Writer, name string, defaultValue int) int {
+	valueStr := os.Getenv(name)
+	if valueStr == "" {
+		return defaultValue
+	}
+
+	value, err := strconv.Atoi(valueStr)
+	if err != nil || value < 0 {
+		fmt.Fprintf(w, "Warning: Invalid %s value '%s', using default %d\n", name, valueStr, defaultValue)
+		return defaultValue
+	}
+	return value
+}
+
+// loadFlag reads a boolean such as "true" or "1" from the environment variable name; unset is false
+func loadFlag(w io.Writer, name string) bool {
+	valueStr := os.Getenv(name)
+	if valueStr == "" {
+		return false
+	}
+
+	value, err := strconv.ParseBool(valueStr)
+	if err != nil {
+		fmt.Fprintf(w, "Warning: Invalid %s value '%s', using false\n", name, valueStr)
+		return false
+	}
+	return value
+}
+
+// FeatureVar returns the environment variable that turns feature on or off
+func FeatureVar(feature backend.Feature) string {
+	return "FEATURE_" + strings.ToUpper(string(feature))
+}
+
+// loadFeatures reads FEATURE_<NAME> for each feature; features not set stay on
+func loadFeatures(w io.Writer) backend.FeatureSet {
+	features := make(backend.FeatureSet)
+	for _, feature := range backend.Features {
+		name := FeatureVar(feature)
+		valueStr := os.Getenv(name)
+		if valueStr == "" {
+			continue
+		}
+		value, err := strconv.ParseBool(valueStr)
+		if err != nil {
+			fmt.Fprintf(w, "Warning: Invalid %s value '%s', using true\n", name, valueStr)
+			continue
+		}
+		features[feature] = value
+	}
+	return features
+}
+
+// loadDuration reads a positive duration such as "30m" from the environment variable name
+func loadDuration(w io.Writer, name string, defaultValue time.Duration) time.Duration {
+	valueStr := os.Getenv(name)
+	if valueStr == "" {
+		return defaultValue
+	}
+
+	value, err := time.ParseDuration(valueStr)
+	if err != nil || value <= 0 {
+		fmt.Fprintf(w, "Warning: Invalid %s value '%s', using default %v\n", name, valueStr, defaultValue)
+		return defaultValue
+	}
+	return value
+}
+
+// loadWebConfig reads web mode settings from environment variables
+func loadWebConfig(w io.Writer) WebConfig {
+	promptsFile := os.Getenv("PROMPTS_FILE")
+	if promptsFile == "" {
+		promptsFile = prompts.DefaultPath()
+	}
+
+	cfg := WebConfig{
+		CompareModel:  os.Getenv("COMPARE_MODEL"),
+		PromptsFile:   promptsFile,
+		ShareSecret:   os.Getenv("SHARE_SECRET"),
+		ShareTTL:      loadDuration(w, "SHARE_TTL", DefaultShareTTL),
+		Theme:         ThemeDark,
+		MaxBodySize:   loadLimit(w, "MAX_BODY_SIZE", DefaultMaxBodySize),
+		SessionFile:   DefaultSessionFile,
+		AdminToken:    os.Getenv("ADMIN_TOKEN"),
+		Server:        WebServerFiber,
+		Socket:        os.Getenv("WEB_SOCKET"),
+		AccessLog:     loadAccessLogConfig(w),
+		APITokensFile: APITokensFile(),
+
+		SessionTTL:             loadDuration(w, "SESSION_TTL", DefaultSessionTTL),
+		SessionCleanupInterval: loadDuration(w, "SESSION_CLEANUP_INTERVAL", DefaultSessionCleanupInterval),
+		MaxSessions:            loadLimit(w, "MAX_SESSIONS", 0),
+	}
+
+	// "off" turns persistence off, since an empty value means unset
+	if sessionFile, ok := os.LookupEnv("SESSION_FILE"); ok {
+		if strings.EqualFold(sessionFile, "off") {
+			cfg.SessionFile = ""
+		} else if sessionFile != "" {
+			cfg.SessionFile = sessionFile
+		}
+	}
+
+	cfg.CORSOrigins = parseOrigins(w, os.Getenv("CORS_ALLOWED_ORIGINS"))
+	cfg.APIModels = splitList(os.Getenv("API_MODELS"))
+	cfg.APIMaxTokens = loadLimit(w, "API_MAX_TOKENS", 0)
+	cfg.AllowedIPs = parsePrefixes(w, "IP_ALLOWLIST")
+	cfg.DeniedIPs = parsePrefixes(w, "IP_DENYLIST")
+
+	if theme := os.Getenv("WEB_THEME"); theme != "" {
+		if IsTheme(strings.ToLower(theme)) {
+			cfg.Theme = strings.ToLower(theme)
+		} else {
+			fmt.Fprintf(w, "Warning: Invalid WEB_THEME value '%s', using default %v\n", theme, ThemeDark)
+		}
+	}
+
+	if server := strings.ToLower(os.Getenv("WEB_SERVER")); server != "" {
+		if server == WebServerFiber || server == WebServerNetHTTP {
+			cfg.Server = server
+		} else {
+			fmt.Fprintf(w, "Warning: Invalid WEB_SERVER value '%s', using default %v\n", server, WebServerFiber)
+		}
+	}
+
+	return cfg
+}
+
+// APITokensFile returns the API token file named by API_TOKENS_FILE, or the default one
+func APITokensFile() string {
+	if path := os.Getenv("API_TOKENS_FILE"); path != "" {
+		return path
+	}
+	return apikeys.DefaultPath()
+}
+
+// loadAccessLogConfig reads the request log settings from ACCESS_LOG_FORMAT,
+// ACCESS_LOG_FIELDS, and ACCESS_LOG_SAMPLE_RATE
+func loadAccessLogConfig(w io.Writer) AccessLogConfig {
+	cfg := AccessLogConfig{Format: AccessLogText, Fields: DefaultAccessLogFields, SampleRate: 1}
+
+	if format := strings.ToLower(os.Getenv("ACCESS_LOG_FORMAT")); format != "" {
+		if format == AccessLogText || format == AccessLogJSON || format == AccessLogOff {
+			cfg.Format = format
+		} else {
+			fmt.Fprintf(w, "Warning: Invalid ACCESS_LOG_FORMAT value '%s', using default %s\n", format, AccessLogText)
+		}
+	}
+
+	if fieldsStr := os.Getenv("ACCESS_LOG_FIELDS"); fieldsStr != "" {
+		var fields []string
+		for _, field := range strings.Split(fieldsStr, ",") {
+			field = strings.ToLower(strings.TrimSpace(field))
+			if field == "" {
+				continue
+			}
+			if !slices.Contains(AccessLogFields, field) {
+				fmt.Fprintf(w, "Warning: Unknown ACCESS_LOG_FIELDS entry '%s', ignoring it; known fields: %s\n",
+					field, strings.Join(AccessLogFields, ", "))
+				continue
+			}
+			fields = append(fields, field)
+		}
+		if len(fields) > 0 {
+			cfg.Fields = fields
+		}
+	}
+
+	if rateStr := os.Getenv("ACCESS_LOG_SAMPLE_RATE"); rateStr != "" {
+		rate, err := strconv.ParseFloat(rateStr, 64)
+		if err != nil || rate < 0 || rate > 1 {
+			fmt.Fprintf(w, "Warning: Invalid ACCESS_LOG_SAMPLE_RATE value '%s', logging every request\n", rateStr)
+		} else {
+			cfg.SampleRate = rate
+		}
+	}
+
+	return cfg
+}
+
+// parsePrefixes reads a comma-separated list of networks such as "10.1.2.0/24" from the
+// environment variable name. A bare address stands for just that host.
+func parsePrefixes(w io.Writer, name string) []netip.Prefix {
+	var prefixes []netip.Prefix
+	for _, entry := range strings.Split(os.Getenv(name), ",") {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+		var prefix netip.Prefix
+		var err error
+		if strings.Contains(entry, "/") {
+			prefix, err = netip.ParsePrefix(entry)
+		} else {
+			var addr netip.Addr
+			if addr, err = netip.ParseAddr(entry); err == nil {
+				prefix = netip.PrefixFrom(addr, addr.BitLen())
+			}
+		}
+		if err != nil {
+			fmt.Fprintf(w, "Warning: Invalid %s entry '%s', ignoring it\n", name, entry)
+			continue
+		}
+		prefixes = append(prefixes, prefix.Masked())
+	}
+	return prefixes
+}
+
+// parseOrigins splits a comma-separated list of origins such as "https://app.example.com",
+// skipping entries that aren't "*" or a bare scheme://host[:port]
+func parseOrigins(w io.Writer, value string) []string {
+	var origins []string
+	for _, origin := range strings.Split(value, ",") {
+		origin = strings.TrimSuffix(strings.TrimSpace(origin), "/")
+		if origin == "" {
+			continue
+		}
+		if origin != "*" {
+			u, err := url.Parse(origin)
+			if err != nil || u.Scheme == "" || u.Host == "" || u.Path != "" || u.RawQuery != "" {
+				fmt.Fprintf(w, "Warning: Invalid CORS_ALLOWED_ORIGINS entry '%s', ignoring it\n", origin)
+				continue
+			}
+			origin = strings.ToLower(origin)
+		}
+		origins = append(origins, origin)
+	}
+	return origins
+}
+
+// loadFewShot reads few-shot examples from the YAML file named by FEW_SHOT_FILE.
+// The file maps conversation types to lists of user/assistant pairs.
+func loadFewShot() (map[string][]backend.FewShotExample, error) {
+	path := os.Getenv("FEW_SHOT_FILE")
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read FEW_SHOT_FILE: %w", err)
+	}
+
+	var fewShot map[string][]backend.FewShotExample
+	if err := yaml.Unmarshal(data, &fewShot); err != nil {
+		return nil, fmt.Errorf("failed to parse FEW_SHOT_FILE %s: %w", path, err)
+	}
+
+	for conversationType, examples := range fewShot {
+		for i, ex := range examples {
+			if ex.User == "" || ex.Assistant == "" {
+				return nil, fmt.Errorf("few-shot example %d for %q needs both user and assistant", i+1, conversationType)
+			}
+		}
+	}
+
+	return fewShot, nil
+}
+
+// loadConversations reads per-conversation-type defaults from the YAML file named by
+// CONVERSATIONS_FILE. The file maps conversation types to the settings they override.
+func loadConversations() (map[string]ConversationProfile, error) {
+	path := os.Getenv("CONVERSATIONS_FILE")
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read CONVERSATIONS_FILE: %w", err)
+	}
+
+	var conversations map[string]ConversationProfile
+	if err := yaml.Unmarshal(data, &conversations); err != nil {
+		return nil, fmt.Errorf("failed to parse CONVERSATIONS_FILE %s: %w", path, err)
+	}
+
+	for conversationType, profile := range conversations {
+		if profile.MaxTokens < 0 || profile.KeepRecent < 0 || profile.KeepRecentTokens < 0 || profile.KeepRelevant < 0 || profile.SessionLimit < 0 {
+			return nil, fmt.Errorf("conversation type %q has a negative limit", conversationType)
+		}
+	}
+
+	return conversations, nil
+}
+
+// loadRoutes reads the routing rules from the YAML file named by ROUTER_FILE, a list
+// of rules tried in order
+func loadRoutes() ([]RouteRule, error) {
+	path := os.Getenv("ROUTER_FILE")
+	if path == "" {
+		return nil, nil
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ROUTER_FILE: %w", err)
+	}
+
+	var routes []RouteRule
+	if err := yaml.Unmarshal(data, &routes); err != nil {
+		return nil, fmt.Errorf("failed to parse ROUTER_FILE %s: %w", path, err)
+	}
+
+	for i, route := range routes {
+		switch {
+		case strings.TrimSpace(route.Model) == "":
+			return nil, fmt.Errorf("route %d needs a model", i+1)
+		case route.MinTokens < 0 || route.MaxTokens < 0 || route.CostPerToken < 0:
+			return nil, fmt.Errorf("route %d has a negative limit", i+1)
+		case route.MaxTokens > 0 && route.MaxTokens < route.MinTokens:
+			return nil, fmt.Errorf("route %d has max_tokens below min_tokens", i+1)
+		case route.BudgetBelow < 0 || route.BudgetBelow > 1:
+			return nil, fmt.Errorf("route %d: budget_below must be between 0 and 1, got %v", i+1, route.BudgetBelow)
+		}
+	}
+
+	return routes, nil
+}
+
+// loadRateLimits reads the per-minute limits to pace each provider to. RATE_LIMIT_TIER
+// lists usage tiers as provider:tier, or just the tier of provider, and
+// RATE_LIMIT_RPM and RATE_LIMIT_TPM set or override provider's limits.
+func loadRateLimits(w io.Writer, provider backend.ProviderName) (map[backend.ProviderName]backend.RateLimit, error) {
+	limits := make(map[backend.ProviderName]backend.RateLimit)
+	for _, entry := range splitList(os.Getenv("RATE_LIMIT_TIER")) {
+		name, tier, ok := strings.Cut(entry, ":")
+		if !ok {
+			name, tier = string(provider), entry
+		}
+		limit, ok := backend.LookupRateLimitTier(backend.ProviderName(name), tier)
+		if !ok {
+			known := backend.RateLimitTiers(backend.ProviderName(name))
+			if len(known) == 0 {
+				return nil, fmt.Errorf("RATE_LIMIT_TIER: no known tiers for provider %q; set RATE_LIMIT_RPM and RATE_LIMIT_TPM instead", name)
+			}
+			return nil, fmt.Errorf("RATE_LIMIT_TIER: unknown %s tier %q, expected one of %s", name, tier, strings.Join(known, ", "))
+		}
+		limits[backend.ProviderName(name)] = limit
+	}
+
+	limit := limits[provider]
+	if rpm := loadCount(w, "RATE_LIMIT_RPM", 0); rpm > 0 {
+		limit.RequestsPerMinute = rpm
+	}
+	if tpm := loadCount(w, "RATE_LIMIT_TPM", 0); tpm > 0 {
+		limit.TokensPerMinute = tpm
+	}
+	if limit != (backend.RateLimit{}) {
+		limits[provider] = limit
+	}
+	return limits, nil
+}
+
+// loadSlackConfig reads Slack bot settings from environment variables
+func loadSlackConfig(w io.Writer) SlackConfig {
+	cfg := SlackConfig{
+		AppToken:             os.Getenv("SLACK_APP_TOKEN"),
+		BotToken:             os.Getenv("SLACK_BOT_TOKEN"),
+		WorkspaceTokenBudget: DefaultSlackWorkspaceBudget,
+	}
+
+	if budgetStr := os.Getenv("SLACK_WORKSPACE_BUDGET"); budgetStr != "" {
+		budget, err := strconv.Atoi(budgetStr)
+		if err != nil || budget <= 0 {
+			fmt.Fprintf(w, "Warning: Invalid SLACK_WORKSPACE_BUDGET value '%s', using default %d\n",
+				budgetStr, DefaultSlackWorkspaceBudget)
+		} else {
+			cfg.WorkspaceTokenBudget = budget
+		}
+	}
+
+	return cfg
+}
+
+// loadToolsDir returns TOOLS_DIR, defaulting to the per-user tools directory
+func loadToolsDir() string {
+	if dir := os.Getenv("TOOLS_DIR"); dir != "" {
+		return dir
+	}
+	return tools.DefaultDir()
+}
+
+// loadLanguage reads UI_LANGUAGE, which is a language code or "auto" (the default).
+// In auto mode the UI starts in the language of the system locale and then follows
+// the language the user writes in.
+func loadLanguage(w io.Writer) (lang string, auto bool) {
+	setting := strings.TrimSpace(os.Getenv("UI_LANGUAGE"))
+	if setting != "" && !strings.EqualFold(setting, "auto") {
+		if lang := i18n.Normalize(setting); i18n.IsSupported(lang) {
+			return lang, false
+		}
+		fmt.Fprintf(w, "Warning: Unsupported UI_LANGUAGE value '%s' (supported: %s), using auto\n",
+			setting, strings.Join(i18n.Supported(), ", "))
+	}
+
+	for _, name := range []string{"LC_ALL", "LC_MESSAGES", "LANG"} {
+		if value := os.Getenv(name); value != "" {
+			if lang := i18n.FromLocale(value); lang != "" {
+				return lang, true
+			}
+			break // The first locale variable set takes precedence
+		}
+	}
+	return i18n.Default, true
+}
+
+// loadClassifierConfig reads CLASSIFIER, EMBEDDING_MODEL, and EMBEDDING_URL
+func loadClassifierConfig() ClassifierConfig {
+	cfg := ClassifierConfig{
+		Kind:           strings.ToLower(strings.TrimSpace(os.Getenv("CLASSIFIER"))),
+		EmbeddingModel: os.Getenv("EMBEDDING_MODEL"),
+		EmbeddingURL:   os.Getenv("EMBEDDING_URL"),
+	}
+	if cfg.Kind == "" {
+		cfg.Kind = ClassifierKeyword
+	}
+	return cfg
+}
+
+// LoadDigestConfig reads where usage digests are delivered. Digests only read the
+// session logs, so unlike LoadFromEnv it needs no provider configuration.
+func LoadDigestConfig(w io.Writer) DigestConfig {
+	return DigestConfig{
+		Hooks: loadWebhookConfig(w),
+		SMTP: SMTPConfig{
+			Addr:     os.Getenv("SMTP_ADDR"),
+			Username: os.Getenv("SMTP_USERNAME"),
+			Password: os.Getenv("SMTP_PASSWORD"),
+			From:     os.Getenv("SMTP_FROM"),
+		},
+	}
+}
+
+// loadWebhookConfig reads webhook endpoints from comma-separated environment variables
+func loadWebhookConfig(w io.Writer) WebhookConfig {
+	cfg := WebhookConfig{
+		URLs:             splitList(os.Getenv("WEBHOOK_URLS")),
+		SlackURLs:        splitList(os.Getenv("SLACK_WEBHOOK_URLS")),
+		FailureThreshold: DefaultWebhookFailureThreshold,
+	}
+
+	if thresholdStr := os.Getenv("WEBHOOK_FAILURE_THRESHOLD"); thresholdStr != "" {
+		threshold, err := strconv.Atoi(thresholdStr)
+		if err != nil || threshold <= 0 {
+			fmt.Fprintf(w, "Warning: Invalid WEBHOOK_FAILURE_THRESHOLD value '%s', using default %d\n",
+				thresholdStr, DefaultWebhookFailureThreshold)
+		} else {
+			cfg.FailureThreshold = threshold
+		}
+	}
+
+	return cfg
+}
+
+// splitList splits a comma-separated value, dropping empty entries
+func splitList(value string) []string {
+	var items []string
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+	return items
+}
@@ -0,0 +1,68 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// CostCalibration maps models to the USD per token OpenAI billed for them, as measured
+// by `chatgbt costs sync --calibrate`. It replaces the estimated price of those models.
+type CostCalibration map[string]float64
+
+// CostCalibrationPath returns where calibrated prices are kept
+func CostCalibrationPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "costs.yaml"
+	}
+	return filepath.Join(dir, "chatgbt", "costs.yaml")
+}
+
+// LoadCostCalibration reads calibrated prices from path. A missing file is not an error.
+func LoadCostCalibration(path string) (CostCalibration, error) {
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return CostCalibration{}, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	calibration := CostCalibration{}
+	if err := yaml.Unmarshal(data, &calibration); err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	for model, price := range calibration {
+		if price <= 0 {
+			return nil, fmt.Errorf("%s: price of %s must be positive, got %g", path, model, price)
+		}
+	}
+	return calibration, nil
+}
+
+// Save writes the calibrated prices to path, creating its directory if needed
+func (c CostCalibration) Save(path string) error {
+	data, err := yaml.Marshal(map[string]float64(c))
+	if err != nil {
+		return err
+	}
+	if err := os.MkdirAll(filepath.Dir(path), 0755); err != nil {
+		return fmt.Errorf("failed to create %s: %w", filepath.Dir(path), err)
+	}
+	return os.WriteFile(path, data, 0644)
+}
+
+// loadCalibratedPrice returns the calibrated price of model, or 0 when it has none
+func loadCalibratedPrice(w io.Writer, model string) float64 {
+	calibration, err := LoadCostCalibration(CostCalibrationPath())
+	if err != nil {
+		fmt.Fprintf(w, "Warning: ignoring calibrated prices: %v\n", err)
+		return 0
+	}
+	return calibration[model]
+}
@@ -0,0 +1,73 @@
+package config
+
+import (
+	"bytes"
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestGetenvDeprecated(t *testing.T) {
+	deprecatedEnv["NEW_NAME"] = "OLD_NAME"
+	t.Cleanup(func() { delete(deprecatedEnv, "NEW_NAME") })
+
+	tests := []struct {
+		name        string
+		newValue    string
+		oldValue    string
+		wantValue   string
+		wantFrom    string
+		wantWarning bool
+	}{
+		{name: "new name", newValue: "a", wantValue: "a", wantFrom: "NEW_NAME"},
+		{name: "old name", oldValue: "b", wantValue: "b", wantFrom: "OLD_NAME", wantWarning: true},
+		{name: "new name wins", newValue: "a", oldValue: "b", wantValue: "a", wantFrom: "NEW_NAME"},
+		{name: "neither", wantFrom: "OLD_NAME"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("NEW_NAME", tt.newValue)
+			t.Setenv("OLD_NAME", tt.oldValue)
+			var w bytes.Buffer
+			value, from := getenv(&w, "NEW_NAME")
+			if value != tt.wantValue || from != tt.wantFrom {
+				t.Errorf("getenv() = %q, %q; want %q, %q", value, from, tt.wantValue, tt.wantFrom)
+			}
+			if warned := strings.Contains(w.String(), "deprecated"); warned != tt.wantWarning {
+				t.Errorf("warning %q, want one: %v", w.String(), tt.wantWarning)
+			}
+		})
+	}
+}
+
+func TestParsePrefixes(t *testing.T) {
+	tests := []struct {
+		name        string
+		value       string
+		want        []string
+		wantWarning bool
+	}{
+		{name: "empty"},
+		{name: "networks", value: "10.1.2.0/24, fd00::/8", want: []string{"10.1.2.0/24", "fd00::/8"}},
+		{name: "bare addresses", value: "203.0.113.5,2001:db8::1", want: []string{"203.0.113.5/32", "2001:db8::1/128"}},
+		{name: "host bits masked", value: "10.1.2.3/24", want: []string{"10.1.2.0/24"}},
+		{name: "blank entries", value: ",10.0.0.0/8,,", want: []string{"10.0.0.0/8"}},
+		{name: "invalid entries skipped", value: "10.0.0.0/8,nope,10.0.0.0/33", want: []string{"10.0.0.0/8"}, wantWarning: true},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			t.Setenv("IP_ALLOWLIST", tt.value)
+			var w bytes.Buffer
+			var got []string
+			for _, prefix := range parsePrefixes(&w, "IP_ALLOWLIST") {
+				got = append(got, prefix.String())
+			}
+			if !slices.Equal(got, tt.want) {
+				t.Errorf("parsePrefixes() = %v, want %v", got, tt.want)
+			}
+			if warned := strings.Contains(w.String(), "Invalid IP_ALLOWLIST"); warned != tt.wantWarning {
+				t.Errorf("warning %q, want one: %v", w.String(), tt.wantWarning)
+			}
+		})
+	}
+}
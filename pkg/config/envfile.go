@@ -0,0 +1,94 @@
+package config
+
+import (
+	"bufio"
+	"fmt"
+	"os"
+	"strings"
+)
+
+// LoadEnvFile sets the environment variables assigned in the file at path (see
+// ReadEnvFile). Values in the file win over those already set; a variable removed from
+// the file keeps its value. An empty path does nothing.
+func LoadEnvFile(path string) error {
+	vars, err := ReadEnvFile(path)
+	if err != nil {
+		return err
+	}
+	_, err = SetEnv(vars)
+	return err
+}
+
+// ReadEnvFile returns the variables assigned in the file at path, one KEY=VALUE per
+// line as in a shell or Docker env file, without setting them. Blank lines and lines
+// starting with # are skipped, an "export " prefix is allowed, and matching quotes
+// around a value are removed. An empty path returns none.
+func ReadEnvFile(path string) (map[string]string, error) {
+	if path == "" {
+		return nil, nil
+	}
+
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ENV_FILE: %w", err)
+	}
+	defer f.Close()
+
+	vars := make(map[string]string)
+	scanner := bufio.NewScanner(f)
+	for n := 1; scanner.Scan(); n++ {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		line = strings.TrimPrefix(line, "export ")
+
+		key, value, found := strings.Cut(line, "=")
+		key = strings.TrimSpace(key)
+		if !found || key == "" || strings.ContainsAny(key, " \t\x00") {
+			return nil, fmt.Errorf("%s:%d: expected KEY=VALUE", path, n)
+		}
+		vars[key] = unquote(strings.TrimSpace(value))
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, fmt.Errorf("failed to read ENV_FILE: %w", err)
+	}
+	return vars, nil
+}
+
+// SetEnv sets the environment variables in vars, all or none, and returns a function
+// that puts back the values they had, unsetting those that weren't set, so settings
+// that turn out to be invalid can be undone
+func SetEnv(vars map[string]string) (restore func(), err error) {
+	previous := make(map[string]*string, len(vars))
+	restore = func() {
+		for key, value := range previous {
+			if value == nil {
+				os.Unsetenv(key)
+			} else {
+				os.Setenv(key, *value)
+			}
+		}
+	}
+
+	for key, value := range vars {
+		if old, ok := os.LookupEnv(key); ok {
+			previous[key] = &old
+		} else {
+			previous[key] = nil
+		}
+		if err := os.Setenv(key, value); err != nil {
+			restore()
+			return func() {}, fmt.Errorf("failed to set %s: %w", key, err)
+		}
+	}
+	return restore, nil
+}
+
+// unquote removes a pair of matching single or double quotes around value
+func unquote(value string) string {
+	if len(value) >= 2 && (value[0] == '"' || value[0] == '\'') && value[len(value)-1] == value[0] {
+		return value[1 : len(value)-1]
+	}
+	return value
+}
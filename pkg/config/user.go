@@ -0,0 +1,151 @@
+package config
+
+import (
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"regexp"
+	"runtime"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/nleiva/chatgbt/pkg/backend"
+)
+
+// userNamePattern limits --user names to ones that are safe as a directory name
+var userNamePattern = regexp.MustCompile(`^[A-Za-z0-9][A-Za-z0-9._-]*$`)
+
+// UserProfile keeps one person's history, session logs, and budget apart from the
+// other users of a shared machine
+type UserProfile struct {
+	Name string
+	Dir  string // Root of the user's data, e.g. ~/.local/share/chatgbt/alice
+}
+
+// userBudgetFile is the optional file in a profile's directory that overrides the budget
+const userBudgetFile = "budget.yaml"
+
+// userBudget is the content of userBudgetFile. Zero fields keep the environment's value.
+type userBudget struct {
+	TokenBudget int     `yaml:"token_budget"` // Session token budget, like TOKEN_BUDGET
+	CostBudget  float64 `yaml:"cost_budget"`  // Session budget in USD, like COST_BUDGET
+	DailyLimit  int     `yaml:"daily_limit"`  // Tokens per day
+	DailyCost   float64 `yaml:"daily_cost"`   // USD per UTC day of all the user's sessions as a tenant, like TENANT_DAILY_COST
+}
+
+// LoadUserProfile resolves the data directory of user and creates it
+func LoadUserProfile(user string) (*UserProfile, error) {
+	if !userNamePattern.MatchString(user) {
+		return nil, fmt.Errorf("invalid user name %q: use letters, digits, '.', '_', and '-'", user)
+	}
+
+	base, err := usersDir()
+	if err != nil {
+		return nil, err
+	}
+	dir := filepath.Join(base, user)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return nil, fmt.Errorf("failed to create data directory for user %s: %w", user, err)
+	}
+	return &UserProfile{Name: user, Dir: dir}, nil
+}
+
+// UserProfiles returns the names of the users that have a profile, sorted
+func UserProfiles() ([]string, error) {
+	base, err := usersDir()
+	if err != nil {
+		return nil, err
+	}
+	entries, err := os.ReadDir(base)
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	var users []string
+	for _, entry := range entries {
+		if entry.IsDir() && userNamePattern.MatchString(entry.Name()) {
+			users = append(users, entry.Name())
+		}
+	}
+	return users, nil
+}
+
+// usersDir is where user profiles live: $XDG_DATA_HOME/chatgbt, ~/.local/share/chatgbt
+// by default (%LocalAppData%\chatgbt on Windows)
+func usersDir() (string, error) {
+	base := os.Getenv("XDG_DATA_HOME")
+	if base == "" && runtime.GOOS == "windows" {
+		base = os.Getenv("LocalAppData")
+	}
+	if base == "" {
+		home, err := os.UserHomeDir()
+		if err != nil {
+			return "", fmt.Errorf("failed to find the home directory: %w", err)
+		}
+		base = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(base, "chatgbt"), nil
+}
+
+// LogsDir holds the user's session JSONL logs
+func (p *UserProfile) LogsDir() string {
+	return filepath.Join(p.Dir, "logs")
+}
+
+// ConversationsDir holds the user's persisted conversations
+func (p *UserProfile) ConversationsDir() string {
+	return filepath.Join(p.Dir, "conversations")
+}
+
+// ApplyBudget points cfg at the user's logs and applies the overrides in the user's
+// budget.yaml, if there is one
+func (p *UserProfile) ApplyBudget(cfg *backend.TokenBudgetConfig) error {
+	cfg.LogsDir = p.LogsDir()
+
+	budget, err := p.budget()
+	if err != nil {
+		return err
+	}
+	if budget.TokenBudget > 0 {
+		cfg.SessionLimit = budget.TokenBudget
+	}
+	if budget.CostBudget > 0 {
+		cfg.SessionLimit = int(budget.CostBudget / cfg.CostPerToken)
+	}
+	if budget.DailyLimit > 0 {
+		cfg.DailyLimit = budget.DailyLimit
+	}
+	return nil
+}
+
+// DailyCost returns the daily_cost of the user's budget.yaml, the USD the user's
+// sessions may spend per UTC day as a tenant; 0 when it sets none
+func (p *UserProfile) DailyCost() (float64, error) {
+	budget, err := p.budget()
+	return budget.DailyCost, err
+}
+
+// budget reads the user's budget.yaml; a missing file is an empty budget
+func (p *UserProfile) budget() (userBudget, error) {
+	var budget userBudget
+	path := filepath.Join(p.Dir, userBudgetFile)
+	data, err := os.ReadFile(path)
+	if errors.Is(err, os.ErrNotExist) {
+		return budget, nil
+	}
+	if err != nil {
+		return budget, fmt.Errorf("failed to read %s: %w", path, err)
+	}
+
+	if err := yaml.Unmarshal(data, &budget); err != nil {
+		return budget, fmt.Errorf("failed to parse %s: %w", path, err)
+	}
+	if budget.TokenBudget < 0 || budget.CostBudget < 0 || budget.DailyLimit < 0 || budget.DailyCost < 0 {
+		return budget, fmt.Errorf("%s has a negative budget", path)
+	}
+	return budget, nil
+}
@@ -0,0 +1,115 @@
+package i18n
+
+import (
+	"strings"
+	"unicode"
+)
+
+// minStopwordHits is how many common words a Latin-script text must contain before
+// Detect trusts the guess; short prompts like "hi" stay undetected
+const minStopwordHits = 2
+
+// scripts maps writing systems that identify a language on their own
+var scripts = []struct {
+	table *unicode.RangeTable
+	lang  string
+}{
+	{unicode.Hiragana, "ja"},
+	{unicode.Katakana, "ja"},
+	{unicode.Hangul, "ko"},
+	{unicode.Han, "zh"},
+	{unicode.Cyrillic, "ru"},
+	{unicode.Greek, "el"},
+	{unicode.Arabic, "ar"},
+	{unicode.Hebrew, "he"},
+	{unicode.Devanagari, "hi"},
+	{unicode.Thai, "th"},
+}
+
+// stopwords are frequent function words that tell Latin-script languages apart
+var stopwords = map[string][]string{
+	"en": {"the", "and", "is", "are", "what", "how", "you", "this", "that", "with", "for", "of", "to", "can", "please", "my", "it", "do", "does", "why", "in"},
+	"es": {"el", "la", "los", "las", "es", "que", "qué", "cómo", "como", "por", "para", "una", "un", "del", "con", "puedes", "mi", "y", "en", "está", "son", "favor"},
+	"fr": {"le", "la", "les", "est", "que", "qu'est-ce", "comment", "pour", "une", "un", "des", "du", "avec", "vous", "je", "et", "en", "dans", "pourquoi", "mon", "ce"},
+	"de": {"der", "die", "das", "ist", "und", "wie", "was", "ich", "nicht", "mit", "für", "ein", "eine", "du", "sie", "warum", "kannst", "bitte", "mein", "zu", "den"},
+	"pt": {"o", "a", "os", "as", "é", "que", "como", "por", "para", "uma", "um", "do", "da", "com", "você", "meu", "e", "em", "não", "são", "porque"},
+	"it": {"il", "lo", "la", "gli", "le", "è", "che", "come", "per", "una", "un", "del", "della", "con", "sono", "mio", "e", "in", "non", "perché"},
+	"nl": {"de", "het", "een", "is", "en", "wat", "hoe", "ik", "niet", "met", "voor", "van", "je", "jij", "waarom", "kun", "mijn", "zijn"},
+}
+
+// stopwordIndex maps each stopword to the languages that use it
+var stopwordIndex = func() map[string][]string {
+	index := make(map[string][]string)
+	for lang, words := range stopwords {
+		for _, word := range words {
+			index[word] = append(index[word], lang)
+		}
+	}
+	return index
+}()
+
+// Detect guesses the language of text and returns its ISO 639-1 code, or "" when the
+// text is too short or ambiguous to tell. Non-Latin scripts are identified by their
+// characters; Latin-script languages by counting common words.
+func Detect(text string) string {
+	if lang := detectScript(text); lang != "" {
+		return lang
+	}
+
+	scores := make(map[string]int)
+	words := strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && r != '\''
+	})
+	for _, word := range words {
+		for _, lang := range stopwordIndex[word] {
+			scores[lang]++
+		}
+	}
+
+	best, bestScore, tied := "", 0, false
+	for lang, score := range scores {
+		switch {
+		case score > bestScore:
+			best, bestScore, tied = lang, score, false
+		case score == bestScore:
+			tied = true
+		}
+	}
+	if bestScore < minStopwordHits || tied {
+		return ""
+	}
+	return best
+}
+
+// detectScript returns the language of the dominant non-Latin script, if any
+func detectScript(text string) string {
+	counts := make(map[string]int)
+	letters := 0
+	for _, r := range text {
+		if !unicode.IsLetter(r) {
+			continue
+		}
+		letters++
+		for _, s := range scripts {
+			if unicode.Is(s.table, r) {
+				counts[s.lang]++
+				break
+			}
+		}
+	}
+
+	// Japanese mixes kana with Han characters, so any kana decides it
+	if counts["ja"] > 0 {
+		return "ja"
+	}
+	best, bestCount := "", 0
+	for lang, count := range counts {
+		if count > bestCount {
+			best, bestCount = lang, count
+		}
+	}
+	if bestCount*2 < letters {
+		return ""
+	}
+	return best
+}
@@ -0,0 +1,105 @@
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// Default is the language used when nothing better is known
+const Default = "en"
+
+// Supported returns the languages with translated UI strings
+func Supported() []string {
+	languages := make([]string, 0, len(messages))
+	for lang := range messages {
+		languages = append(languages, lang)
+	}
+	sort.Strings(languages)
+	return languages
+}
+
+// IsSupported reports whether lang has translated UI strings
+func IsSupported(lang string) bool {
+	_, ok := messages[lang]
+	return ok
+}
+
+// T returns the message for key in lang, formatted with args. Missing translations
+// fall back to English, and unknown keys are returned as-is.
+func T(lang, key string, args ...any) string {
+	format, ok := messages[lang][key]
+	if !ok {
+		format, ok = messages[Default][key]
+	}
+	if !ok {
+		format = key
+	}
+	if len(args) == 0 {
+		return format
+	}
+	return fmt.Sprintf(format, args...)
+}
+
+// Normalize reduces a language tag or POSIX locale (e.g. "es-AR", "pt_BR.UTF-8") to its
+// base language code. It returns "" when the tag is empty or names no language.
+func Normalize(tag string) string {
+	tag = strings.TrimSpace(tag)
+	if i := strings.IndexAny(tag, ".@"); i >= 0 {
+		tag = tag[:i]
+	}
+	if i := strings.IndexAny(tag, "-_"); i >= 0 {
+		tag = tag[:i]
+	}
+	tag = strings.ToLower(tag)
+	if tag == "c" || tag == "posix" || tag == "*" {
+		return ""
+	}
+	return tag
+}
+
+// FromLocale returns the supported language named by a POSIX locale, or ""
+func FromLocale(locale string) string {
+	if lang := Normalize(locale); IsSupported(lang) {
+		return lang
+	}
+	return ""
+}
+
+// MatchAcceptLanguage returns the supported language the client prefers most in an
+// Accept-Language header, or "" if it prefers none of them
+func MatchAcceptLanguage(header string) string {
+	best, bestQ := "", 0.0
+	for _, part := range strings.Split(header, ",") {
+		tag, params, _ := strings.Cut(part, ";")
+		q := 1.0
+		if value, ok := strings.CutPrefix(strings.TrimSpace(params), "q="); ok {
+			parsed, err := strconv.ParseFloat(value, 64)
+			if err != nil {
+				continue
+			}
+			q = parsed
+		}
+		if lang := Normalize(tag); IsSupported(lang) && q > bestQ {
+			best, bestQ = lang, q
+		}
+	}
+	return best
+}
+
+type contextKey struct{}
+
+// WithLanguage returns a copy of ctx carrying the UI language
+func WithLanguage(ctx context.Context, lang string) context.Context {
+	return context.WithValue(ctx, contextKey{}, lang)
+}
+
+// FromContext returns the UI language carried by ctx, or Default
+func FromContext(ctx context.Context) string {
+	if lang, ok := ctx.Value(contextKey{}).(string); ok && lang != "" {
+		return lang
+	}
+	return Default
+}
@@ -0,0 +1,554 @@
+package i18n
+
+// messages holds the UI strings for each supported language, keyed by message ID.
+// English is the reference; other languages may omit keys and fall back to it.
+var messages = map[string]map[string]string{
+	"en": {
+		// CLI
+		"cli.welcome":        "Welcome to the interactive LLM chat!",
+		"cli.commands":       "Commands: %s",
+		"cli.input":          "You (end with empty line):",
+		"cli.goodbye":        "Thanks for using chatGBT! Goodbye!",
+		"cli.interrupted":    "Reply interrupted.",
+		"cli.reset":          "Conversation reset.",
+		"cli.system_input":   "Enter new system prompt: ",
+		"cli.system_error":   "Error reading system prompt:",
+		"cli.read_error":     "Error reading input:",
+		"cli.error":          "Error:",
+		"cli.budget_title":   "Budget Status:",
+		"cli.session_tokens": "Session Tokens",
+		"cli.estimated_cost": "Estimated Cost",
+		"cli.warnings":       "Warnings:",
+		"cli.prune_hint":     "Recommendation: Consider pruning context (/prune)",
+		"cli.continue_hint":  "The reply was cut off at the token limit; type %s for the rest",
+		"cli.aborted_hint":   "Reply stopped; type %s for the rest",
+		"cli.budget":         "Budget",
+		"cli.pruned":         "Context pruned: %d -> %d messages, ~%d -> ~%d tokens",
+		"cli.no_prune":       "No pruning needed - context within limits",
+		"system_updated":     "System prompt updated.",
+
+		// Budget warnings
+		"budget.usage": "Session token usage at %.1f%% of limit (%d/%d tokens)",
+		"budget.cost":  "Session cost: $%.3f",
+
+		// Web
+		"web.title":                "ChatGBT - AI Assistant",
+		"web.new_chat":             "New Chat",
+		"web.search":               "Search conversations...",
+		"web.current_conversation": "Current Conversation",
+		"web.compare":              "Compare",
+		"web.tokens":               "Tokens",
+		"web.settings":             "Settings",
+		"web.welcome_title":        "How can I help you today?",
+		"web.welcome_body":         "I'm ChatGBT, your AI assistant. Ask me anything, and I'll do my best to help you with information, analysis, creative tasks, and more.",
+		"web.input":                "Message ChatGBT...",
+		"web.system_prompt":        "System Prompt",
+		"web.preset":               "Preset",
+		"web.prompt":               "Prompt",
+		"web.preset_name":          "Preset name",
+		"web.save_preset":          "Save as preset",
+		"web.apply":                "Apply",
+		"web.custom":               "Custom",
+		"web.saved_preset":         "%s (saved)",
+		"web.you":                  "You",
+		"web.error":                "Error: %s",
+		"web.token_count":          "%d tokens",
+		"web.ttft":                 "Time to first token",
+		"web.throughput":           "Generation throughput",
+		"web.role":                 "Role",
+		"web.message":              "Message",
+		"web.pinned":               "Pinned, never pruned",
+		"web.token_total":          "Total: %d tokens (prune limit %d)",
+		"web.token_estimates":      "— tokenizer unavailable, counts are estimates",
+		"web.no_matches":           "No matches",
+		"web.thinking":             "Thinking...",
+		"web.share":                "Share",
+		"web.share_link":           "Anyone with this link can read this conversation until %s:",
+		"web.shared_title":         "ChatGBT - Shared Conversation",
+		"web.shared_notice":        "Read-only copy of a ChatGBT conversation. This link expires %s.",
+		"web.user":                 "User",
+		"web.download":             "Download",
+		"web.download_title":       "Download this conversation as Markdown",
+		"web.copy":                 "Copy",
+		"web.copied":               "Copied!",
+		"web.show_details":         "Show timestamps, model, and response time on messages",
+		"web.edit":                 "Edit message",
+		"web.save":                 "Save & submit",
+		"web.cancel":               "Cancel",
+		"web.regenerate":           "Regenerate response",
+		"web.continue":             "Continue response",
+		"web.stop":                 "Stop response",
+		"web.context":              "Context",
+		"web.context_usage":        "Context: %d of %d tokens",
+		"web.budget":               "Budget",
+		"web.budget_usage":         "Session budget: %d of %d tokens",
+		"web.cost_title":           "Estimated session cost",
+		"web.input_hint":           "Enter to send, Shift+Enter for a new line, / to focus",
+		"web.theme":                "Theme",
+		"web.theme_dark":           "Dark",
+		"web.theme_light":          "Light",
+		"web.request_id":           "request %s",
+		"message_too_long":         "message is %d characters long; the limit is %d. Shorten it or split it into parts",
+		"daily_cost_exceeded":      "the daily cost limit of $%.2f has been reached; try again tomorrow",
+		"cli.usage_by_model":       "By model:",
+		"budget.trimmed":           "Reply limited to %d tokens to stay within the session budget",
+		"reply.truncated":          "The reply was cut off at the token limit; ask to continue for the rest",
+		"reply.aborted":            "The reply was stopped before it finished; ask to continue for the rest",
+		"budget_exhausted":         "only %[2]d tokens remain in the session budget, not enough for the %[1]d-token prompt and a reply. Prune or reset the conversation to continue",
+		"generation_in_progress":   "a reply is already being generated in this session; wait for it to finish before sending another request",
+		"web.queued":               "Waiting for %d earlier request(s) in this session...",
+		"cli.summarizing":          "Summarizing the conversation...",
+		"cli.summary_pinned":       "Summary pinned to the context; pruning keeps it.",
+		"cli.summary_saved":        "Summary written to %s",
+		"web.summarize":            "Summarize",
+		"web.summarize_title":      "Summarize the conversation and pin the summary",
+		"web.summary":              "Summary (pinned)",
+		"cli.follow_ups":           "Follow-up questions (type a number to ask one):",
+		"web.follow_ups":           "Suggested follow-up questions",
+		"cli.auto_pruned":          "Context pruned to make room: %d messages dropped, ~%d tokens reclaimed",
+		"cli.prune_dropped":        "Dropped:",
+		"cli.prune_summary":        "Replaced by: %s",
+		"web.pruned":               "Context pruned: %d messages dropped, ~%d tokens reclaimed",
+		"web.prune_summary":        "Replaced by: %s",
+		"cli.copied":               "Copied the last reply to the clipboard.",
+		"cli.copied_code":          "Copied the last code block to the clipboard.",
+		"cli.nothing_to_copy":      "Nothing to copy yet.",
+		"cli.no_code_block":        "No reply has a code block to copy.",
+	},
+	"es": {
+		"cli.welcome":        "¡Bienvenido al chat interactivo con el LLM!",
+		"cli.commands":       "Comandos: %s",
+		"cli.input":          "Tú (termina con una línea vacía):",
+		"cli.goodbye":        "¡Gracias por usar chatGBT! ¡Hasta luego!",
+		"cli.interrupted":    "Respuesta interrumpida.",
+		"cli.reset":          "Conversación reiniciada.",
+		"cli.system_input":   "Escribe el nuevo prompt de sistema: ",
+		"cli.system_error":   "Error al leer el prompt de sistema:",
+		"cli.read_error":     "Error al leer la entrada:",
+		"cli.error":          "Error:",
+		"cli.budget_title":   "Estado del presupuesto:",
+		"cli.session_tokens": "Tokens de la sesión",
+		"cli.estimated_cost": "Costo estimado",
+		"cli.warnings":       "Avisos:",
+		"cli.prune_hint":     "Recomendación: considera recortar el contexto (/prune)",
+		"cli.continue_hint":  "La respuesta se cortó al alcanzar el límite de tokens; escribe %s para ver el resto",
+		"cli.aborted_hint":   "Respuesta detenida; escribe %s para ver el resto",
+		"cli.budget":         "Presupuesto",
+		"cli.pruned":         "Contexto recortado: %d -> %d mensajes, ~%d -> ~%d tokens",
+		"cli.no_prune":       "No hace falta recortar: el contexto está dentro de los límites",
+		"system_updated":     "Prompt de sistema actualizado.",
+
+		"budget.usage": "Uso de tokens de la sesión al %.1f%% del límite (%d/%d tokens)",
+		"budget.cost":  "Costo de la sesión: $%.3f",
+
+		"web.title":                "ChatGBT - Asistente de IA",
+		"web.new_chat":             "Nuevo chat",
+		"web.search":               "Buscar conversaciones...",
+		"web.current_conversation": "Conversación actual",
+		"web.compare":              "Comparar",
+		"web.tokens":               "Tokens",
+		"web.settings":             "Ajustes",
+		"web.welcome_title":        "¿En qué puedo ayudarte hoy?",
+		"web.welcome_body":         "Soy ChatGBT, tu asistente de IA. Pregúntame lo que quieras y haré lo posible por ayudarte con información, análisis, tareas creativas y más.",
+		"web.input":                "Escribe un mensaje a ChatGBT...",
+		"web.system_prompt":        "Prompt de sistema",
+		"web.preset":               "Predefinido",
+		"web.prompt":               "Prompt",
+		"web.preset_name":          "Nombre del predefinido",
+		"web.save_preset":          "Guardar como predefinido",
+		"web.apply":                "Aplicar",
+		"web.custom":               "Personalizado",
+		"web.saved_preset":         "%s (guardado)",
+		"web.you":                  "Tú",
+		"web.error":                "Error: %s",
+		"web.token_count":          "%d tokens",
+		"web.ttft":                 "Tiempo hasta el primer token",
+		"web.throughput":           "Velocidad de generación",
+		"web.role":                 "Rol",
+		"web.message":              "Mensaje",
+		"web.pinned":               "Fijado, nunca se recorta",
+		"web.token_total":          "Total: %d tokens (límite de recorte %d)",
+		"web.token_estimates":      "— tokenizador no disponible, los valores son estimaciones",
+		"web.no_matches":           "Sin resultados",
+		"web.thinking":             "Pensando...",
+		"web.share":                "Compartir",
+		"web.share_link":           "Cualquiera con este enlace puede leer esta conversación hasta el %s:",
+		"web.shared_title":         "ChatGBT - Conversación compartida",
+		"web.shared_notice":        "Copia de solo lectura de una conversación de ChatGBT. Este enlace vence el %s.",
+		"web.user":                 "Usuario",
+		"web.download":             "Descargar",
+		"web.download_title":       "Descargar esta conversación en Markdown",
+		"web.copy":                 "Copiar",
+		"web.copied":               "¡Copiado!",
+		"web.show_details":         "Mostrar hora, modelo y tiempo de respuesta en los mensajes",
+		"web.edit":                 "Editar mensaje",
+		"web.save":                 "Guardar y enviar",
+		"web.cancel":               "Cancelar",
+		"web.regenerate":           "Regenerar respuesta",
+		"web.continue":             "Continuar respuesta",
+		"web.stop":                 "Detener respuesta",
+		"web.context":              "Contexto",
+		"web.context_usage":        "Contexto: %d de %d tokens",
+		"web.budget":               "Presupuesto",
+		"web.budget_usage":         "Presupuesto de la sesión: %d de %d tokens",
+		"web.cost_title":           "Costo estimado de la sesión",
+		"web.input_hint":           "Enter para enviar, Mayús+Enter para una nueva línea, / para enfocar",
+		"web.theme":                "Tema",
+		"web.theme_dark":           "Oscuro",
+		"web.theme_light":          "Claro",
+		"web.request_id":           "solicitud %s",
+		"message_too_long":         "el mensaje tiene %d caracteres; el límite es %d. Acórtalo o divídelo en partes",
+		"daily_cost_exceeded":      "se alcanzó el límite de gasto diario de $%.2f; vuelve a intentarlo mañana",
+		"cli.usage_by_model":       "Por modelo:",
+		"budget.trimmed":           "Respuesta limitada a %d tokens para no superar el presupuesto de la sesión",
+		"reply.truncated":          "La respuesta se cortó al alcanzar el límite de tokens; pide que continúe para ver el resto",
+		"reply.aborted":            "La respuesta se detuvo antes de terminar; pide que continúe para ver el resto",
+		"budget_exhausted":         "solo quedan %[2]d tokens en el presupuesto de la sesión, no alcanzan para el mensaje de %[1]d tokens y una respuesta. Poda o reinicia la conversación para continuar",
+		"generation_in_progress":   "ya se está generando una respuesta en esta sesión; espera a que termine antes de enviar otra solicitud",
+		"web.queued":               "Esperando %d solicitud(es) anterior(es) de esta sesión...",
+		"cli.summarizing":          "Resumiendo la conversación...",
+		"cli.summary_pinned":       "Resumen fijado en el contexto; no se recorta.",
+		"cli.summary_saved":        "Resumen guardado en %s",
+		"web.summarize":            "Resumir",
+		"web.summarize_title":      "Resumir la conversación y fijar el resumen",
+		"web.summary":              "Resumen (fijado)",
+		"cli.follow_ups":           "Preguntas de seguimiento (escribe un número para hacer una):",
+		"web.follow_ups":           "Preguntas de seguimiento sugeridas",
+		"cli.auto_pruned":          "Contexto recortado para hacer sitio: %d mensajes eliminados, ~%d tokens recuperados",
+		"cli.prune_dropped":        "Eliminados:",
+		"cli.prune_summary":        "Sustituidos por: %s",
+		"web.pruned":               "Contexto recortado: %d mensajes eliminados, ~%d tokens recuperados",
+		"web.prune_summary":        "Sustituidos por: %s",
+		"cli.copied":               "Última respuesta copiada al portapapeles.",
+		"cli.copied_code":          "Último bloque de código copiado al portapapeles.",
+		"cli.nothing_to_copy":      "Todavía no hay nada que copiar.",
+		"cli.no_code_block":        "Ninguna respuesta tiene un bloque de código que copiar.",
+	},
+	"fr": {
+		"cli.welcome":        "Bienvenue dans le chat interactif avec le LLM !",
+		"cli.commands":       "Commandes : %s",
+		"cli.input":          "Vous (terminez par une ligne vide) :",
+		"cli.goodbye":        "Merci d'avoir utilisé chatGBT ! Au revoir !",
+		"cli.interrupted":    "Réponse interrompue.",
+		"cli.reset":          "Conversation réinitialisée.",
+		"cli.system_input":   "Saisissez le nouveau prompt système : ",
+		"cli.system_error":   "Erreur de lecture du prompt système :",
+		"cli.read_error":     "Erreur de lecture de la saisie :",
+		"cli.error":          "Erreur :",
+		"cli.budget_title":   "État du budget :",
+		"cli.session_tokens": "Tokens de la session",
+		"cli.estimated_cost": "Coût estimé",
+		"cli.warnings":       "Avertissements :",
+		"cli.prune_hint":     "Recommandation : pensez à élaguer le contexte (/prune)",
+		"cli.continue_hint":  "La réponse a été coupée à la limite de tokens ; tapez %s pour la suite",
+		"cli.aborted_hint":   "Réponse arrêtée ; tapez %s pour la suite",
+		"cli.budget":         "Budget",
+		"cli.pruned":         "Contexte élagué : %d -> %d messages, ~%d -> ~%d tokens",
+		"cli.no_prune":       "Aucun élagage nécessaire : le contexte respecte les limites",
+		"system_updated":     "Prompt système mis à jour.",
+
+		"budget.usage": "Utilisation des tokens de la session à %.1f%% de la limite (%d/%d tokens)",
+		"budget.cost":  "Coût de la session : %.3f $",
+
+		"web.title":                "ChatGBT - Assistant IA",
+		"web.new_chat":             "Nouvelle discussion",
+		"web.search":               "Rechercher des conversations...",
+		"web.current_conversation": "Conversation en cours",
+		"web.compare":              "Comparer",
+		"web.tokens":               "Tokens",
+		"web.settings":             "Paramètres",
+		"web.welcome_title":        "Comment puis-je vous aider aujourd'hui ?",
+		"web.welcome_body":         "Je suis ChatGBT, votre assistant IA. Posez-moi n'importe quelle question et je ferai de mon mieux pour vous aider : informations, analyses, tâches créatives et plus encore.",
+		"web.input":                "Envoyer un message à ChatGBT...",
+		"web.system_prompt":        "Prompt système",
+		"web.preset":               "Préréglage",
+		"web.prompt":               "Prompt",
+		"web.preset_name":          "Nom du préréglage",
+		"web.save_preset":          "Enregistrer comme préréglage",
+		"web.apply":                "Appliquer",
+		"web.custom":               "Personnalisé",
+		"web.saved_preset":         "%s (enregistré)",
+		"web.you":                  "Vous",
+		"web.error":                "Erreur : %s",
+		"web.token_count":          "%d tokens",
+		"web.ttft":                 "Délai avant le premier token",
+		"web.throughput":           "Débit de génération",
+		"web.role":                 "Rôle",
+		"web.message":              "Message",
+		"web.pinned":               "Épinglé, jamais élagué",
+		"web.token_total":          "Total : %d tokens (limite d'élagage %d)",
+		"web.token_estimates":      "— tokenizer indisponible, les valeurs sont des estimations",
+		"web.no_matches":           "Aucun résultat",
+		"web.thinking":             "Réflexion...",
+		"web.share":                "Partager",
+		"web.share_link":           "Toute personne disposant de ce lien peut lire cette conversation jusqu'au %s :",
+		"web.shared_title":         "ChatGBT - Conversation partagée",
+		"web.shared_notice":        "Copie en lecture seule d'une conversation ChatGBT. Ce lien expire le %s.",
+		"web.user":                 "Utilisateur",
+		"web.download":             "Télécharger",
+		"web.download_title":       "Télécharger cette conversation en Markdown",
+		"web.copy":                 "Copier",
+		"web.copied":               "Copié !",
+		"web.show_details":         "Afficher l'heure, le modèle et le temps de réponse des messages",
+		"web.edit":                 "Modifier le message",
+		"web.save":                 "Enregistrer et envoyer",
+		"web.cancel":               "Annuler",
+		"web.regenerate":           "Régénérer la réponse",
+		"web.continue":             "Continuer la réponse",
+		"web.stop":                 "Arrêter la réponse",
+		"web.context":              "Contexte",
+		"web.context_usage":        "Contexte : %d sur %d jetons",
+		"web.budget":               "Budget",
+		"web.budget_usage":         "Budget de la session : %d sur %d jetons",
+		"web.cost_title":           "Coût estimé de la session",
+		"web.input_hint":           "Entrée pour envoyer, Maj+Entrée pour un saut de ligne, / pour revenir ici",
+		"web.theme":                "Thème",
+		"web.theme_dark":           "Sombre",
+		"web.theme_light":          "Clair",
+		"web.request_id":           "requête %s",
+		"message_too_long":         "le message fait %d caractères ; la limite est de %d. Raccourcissez-le ou découpez-le",
+		"daily_cost_exceeded":      "la limite de coût quotidienne de %.2f $ est atteinte ; réessayez demain",
+		"cli.usage_by_model":       "Par modèle :",
+		"budget.trimmed":           "Réponse limitée à %d tokens pour respecter le budget de la session",
+		"reply.truncated":          "La réponse a été coupée à la limite de tokens ; demandez la suite pour lire le reste",
+		"reply.aborted":            "La réponse a été arrêtée avant la fin ; demandez la suite pour lire le reste",
+		"budget_exhausted":         "il ne reste que %[2]d tokens dans le budget de la session, pas assez pour la requête de %[1]d tokens et une réponse. Élaguez ou réinitialisez la conversation pour continuer",
+		"generation_in_progress":   "une réponse est déjà en cours de génération dans cette session ; attendez qu'elle se termine avant d'envoyer une autre requête",
+		"web.queued":               "En attente de %d requête(s) précédente(s) de cette session...",
+		"cli.summarizing":          "Résumé de la conversation en cours...",
+		"cli.summary_pinned":       "Résumé épinglé dans le contexte ; il n'est jamais élagué.",
+		"cli.summary_saved":        "Résumé écrit dans %s",
+		"web.summarize":            "Résumer",
+		"web.summarize_title":      "Résumer la conversation et épingler le résumé",
+		"web.summary":              "Résumé (épinglé)",
+		"cli.follow_ups":           "Questions de suivi (tapez un numéro pour en poser une) :",
+		"web.follow_ups":           "Questions de suivi suggérées",
+		"cli.auto_pruned":          "Contexte élagué pour faire de la place : %d messages supprimés, ~%d tokens récupérés",
+		"cli.prune_dropped":        "Supprimés :",
+		"cli.prune_summary":        "Remplacés par : %s",
+		"web.pruned":               "Contexte élagué : %d messages supprimés, ~%d tokens récupérés",
+		"web.prune_summary":        "Remplacés par : %s",
+		"cli.copied":               "Dernière réponse copiée dans le presse-papiers.",
+		"cli.copied_code":          "Dernier bloc de code copié dans le presse-papiers.",
+		"cli.nothing_to_copy":      "Rien à copier pour l'instant.",
+		"cli.no_code_block":        "Aucune réponse ne contient de bloc de code à copier.",
+	},
+	"de": {
+		"cli.welcome":        "Willkommen im interaktiven LLM-Chat!",
+		"cli.commands":       "Befehle: %s",
+		"cli.input":          "Du (mit einer Leerzeile beenden):",
+		"cli.goodbye":        "Danke, dass du chatGBT benutzt hast! Tschüss!",
+		"cli.interrupted":    "Antwort abgebrochen.",
+		"cli.reset":          "Unterhaltung zurückgesetzt.",
+		"cli.system_input":   "Neuen System-Prompt eingeben: ",
+		"cli.system_error":   "Fehler beim Lesen des System-Prompts:",
+		"cli.read_error":     "Fehler beim Lesen der Eingabe:",
+		"cli.error":          "Fehler:",
+		"cli.budget_title":   "Budgetstatus:",
+		"cli.session_tokens": "Sitzungs-Tokens",
+		"cli.estimated_cost": "Geschätzte Kosten",
+		"cli.warnings":       "Warnungen:",
+		"cli.prune_hint":     "Empfehlung: Kontext kürzen (/prune)",
+		"cli.continue_hint":  "Die Antwort wurde am Token-Limit abgeschnitten; gib %s ein für den Rest",
+		"cli.aborted_hint":   "Antwort gestoppt; gib %s ein für den Rest",
+		"cli.budget":         "Budget",
+		"cli.pruned":         "Kontext gekürzt: %d -> %d Nachrichten, ~%d -> ~%d Tokens",
+		"cli.no_prune":       "Kein Kürzen nötig - Kontext innerhalb der Grenzen",
+		"system_updated":     "System-Prompt aktualisiert.",
+
+		"budget.usage": "Token-Verbrauch der Sitzung bei %.1f%% des Limits (%d/%d Tokens)",
+		"budget.cost":  "Kosten der Sitzung: %.3f $",
+
+		"web.title":                "ChatGBT - KI-Assistent",
+		"web.new_chat":             "Neuer Chat",
+		"web.search":               "Unterhaltungen durchsuchen...",
+		"web.current_conversation": "Aktuelle Unterhaltung",
+		"web.compare":              "Vergleichen",
+		"web.tokens":               "Tokens",
+		"web.settings":             "Einstellungen",
+		"web.welcome_title":        "Wie kann ich dir heute helfen?",
+		"web.welcome_body":         "Ich bin ChatGBT, dein KI-Assistent. Frag mich alles, und ich helfe dir so gut ich kann mit Informationen, Analysen, kreativen Aufgaben und mehr.",
+		"web.input":                "Nachricht an ChatGBT...",
+		"web.system_prompt":        "System-Prompt",
+		"web.preset":               "Vorlage",
+		"web.prompt":               "Prompt",
+		"web.preset_name":          "Name der Vorlage",
+		"web.save_preset":          "Als Vorlage speichern",
+		"web.apply":                "Übernehmen",
+		"web.custom":               "Benutzerdefiniert",
+		"web.saved_preset":         "%s (gespeichert)",
+		"web.you":                  "Du",
+		"web.error":                "Fehler: %s",
+		"web.token_count":          "%d Tokens",
+		"web.ttft":                 "Zeit bis zum ersten Token",
+		"web.throughput":           "Generierungsrate",
+		"web.role":                 "Rolle",
+		"web.message":              "Nachricht",
+		"web.pinned":               "Angeheftet, wird nie gekürzt",
+		"web.token_total":          "Gesamt: %d Tokens (Kürzungslimit %d)",
+		"web.token_estimates":      "— Tokenizer nicht verfügbar, Werte sind Schätzungen",
+		"web.no_matches":           "Keine Treffer",
+		"web.thinking":             "Denke nach...",
+		"web.share":                "Teilen",
+		"web.share_link":           "Jeder mit diesem Link kann diese Unterhaltung bis %s lesen:",
+		"web.shared_title":         "ChatGBT - Geteilte Unterhaltung",
+		"web.shared_notice":        "Schreibgeschützte Kopie einer ChatGBT-Unterhaltung. Dieser Link läuft am %s ab.",
+		"web.user":                 "Benutzer",
+		"web.download":             "Herunterladen",
+		"web.download_title":       "Diese Unterhaltung als Markdown herunterladen",
+		"web.copy":                 "Kopieren",
+		"web.copied":               "Kopiert!",
+		"web.show_details":         "Uhrzeit, Modell und Antwortzeit bei Nachrichten anzeigen",
+		"web.edit":                 "Nachricht bearbeiten",
+		"web.save":                 "Speichern und senden",
+		"web.cancel":               "Abbrechen",
+		"web.regenerate":           "Antwort neu generieren",
+		"web.continue":             "Antwort fortsetzen",
+		"web.stop":                 "Antwort stoppen",
+		"web.context":              "Kontext",
+		"web.context_usage":        "Kontext: %d von %d Tokens",
+		"web.budget":               "Budget",
+		"web.budget_usage":         "Sitzungsbudget: %d von %d Tokens",
+		"web.cost_title":           "Geschätzte Sitzungskosten",
+		"web.input_hint":           "Enter zum Senden, Umschalt+Enter für eine neue Zeile, / zum Fokussieren",
+		"web.theme":                "Design",
+		"web.theme_dark":           "Dunkel",
+		"web.theme_light":          "Hell",
+		"web.request_id":           "Anfrage %s",
+		"message_too_long":         "die Nachricht ist %d Zeichen lang; das Limit ist %d. Kürze sie oder teile sie auf",
+		"daily_cost_exceeded":      "das tägliche Kostenlimit von %.2f $ ist erreicht; versuche es morgen erneut",
+		"cli.usage_by_model":       "Nach Modell:",
+		"budget.trimmed":           "Antwort auf %d Tokens begrenzt, um im Sitzungsbudget zu bleiben",
+		"reply.truncated":          "Die Antwort wurde am Token-Limit abgeschnitten; bitte um die Fortsetzung für den Rest",
+		"reply.aborted":            "Die Antwort wurde vor dem Ende gestoppt; bitte um die Fortsetzung für den Rest",
+		"budget_exhausted":         "im Sitzungsbudget sind nur noch %[2]d Tokens übrig, zu wenig für die Anfrage mit %[1]d Tokens und eine Antwort. Kürze oder setze die Unterhaltung zurück, um fortzufahren",
+		"generation_in_progress":   "in dieser Sitzung wird bereits eine Antwort erzeugt; warte, bis sie fertig ist, bevor du eine weitere Anfrage sendest",
+		"web.queued":               "Warte auf %d frühere Anfrage(n) dieser Sitzung...",
+		"cli.summarizing":          "Unterhaltung wird zusammengefasst...",
+		"cli.summary_pinned":       "Zusammenfassung im Kontext angeheftet; sie wird beim Kürzen behalten.",
+		"cli.summary_saved":        "Zusammenfassung in %s gespeichert",
+		"web.summarize":            "Zusammenfassen",
+		"web.summarize_title":      "Unterhaltung zusammenfassen und die Zusammenfassung anheften",
+		"web.summary":              "Zusammenfassung (angeheftet)",
+		"cli.follow_ups":           "Anschlussfragen (gib eine Nummer ein, um sie zu stellen):",
+		"web.follow_ups":           "Vorgeschlagene Anschlussfragen",
+		"cli.auto_pruned":          "Kontext gekürzt, um Platz zu schaffen: %d Nachrichten entfernt, ~%d Tokens frei",
+		"cli.prune_dropped":        "Entfernt:",
+		"cli.prune_summary":        "Ersetzt durch: %s",
+		"web.pruned":               "Kontext gekürzt: %d Nachrichten entfernt, ~%d Tokens frei",
+		"web.prune_summary":        "Ersetzt durch: %s",
+		"cli.copied":               "Letzte Antwort in die Zwischenablage kopiert.",
+		"cli.copied_code":          "Letzten Codeblock in die Zwischenablage kopiert.",
+		"cli.nothing_to_copy":      "Noch nichts zum Kopieren.",
+		"cli.no_code_block":        "Keine Antwort enthält einen Codeblock zum Kopieren.",
+	},
+	"pt": {
+		"cli.welcome":        "Bem-vindo ao chat interativo com o LLM!",
+		"cli.commands":       "Comandos: %s",
+		"cli.input":          "Você (termine com uma linha vazia):",
+		"cli.goodbye":        "Obrigado por usar o chatGBT! Até logo!",
+		"cli.interrupted":    "Resposta interrompida.",
+		"cli.reset":          "Conversa reiniciada.",
+		"cli.system_input":   "Digite o novo prompt de sistema: ",
+		"cli.system_error":   "Erro ao ler o prompt de sistema:",
+		"cli.read_error":     "Erro ao ler a entrada:",
+		"cli.error":          "Erro:",
+		"cli.budget_title":   "Status do orçamento:",
+		"cli.session_tokens": "Tokens da sessão",
+		"cli.estimated_cost": "Custo estimado",
+		"cli.warnings":       "Avisos:",
+		"cli.prune_hint":     "Recomendação: considere reduzir o contexto (/prune)",
+		"cli.continue_hint":  "A resposta foi cortada no limite de tokens; digite %s para ver o resto",
+		"cli.aborted_hint":   "Resposta interrompida; digite %s para ver o resto",
+		"cli.budget":         "Orçamento",
+		"cli.pruned":         "Contexto reduzido: %d -> %d mensagens, ~%d -> ~%d tokens",
+		"cli.no_prune":       "Não é preciso reduzir: o contexto está dentro dos limites",
+		"system_updated":     "Prompt de sistema atualizado.",
+
+		"budget.usage": "Uso de tokens da sessão em %.1f%% do limite (%d/%d tokens)",
+		"budget.cost":  "Custo da sessão: $%.3f",
+
+		"web.title":                "ChatGBT - Assistente de IA",
+		"web.new_chat":             "Novo chat",
+		"web.search":               "Pesquisar conversas...",
+		"web.current_conversation": "Conversa atual",
+		"web.compare":              "Comparar",
+		"web.tokens":               "Tokens",
+		"web.settings":             "Configurações",
+		"web.welcome_title":        "Como posso ajudar você hoje?",
+		"web.welcome_body":         "Sou o ChatGBT, seu assistente de IA. Pergunte qualquer coisa e farei o possível para ajudar com informações, análises, tarefas criativas e muito mais.",
+		"web.input":                "Envie uma mensagem ao ChatGBT...",
+		"web.system_prompt":        "Prompt de sistema",
+		"web.preset":               "Predefinição",
+		"web.prompt":               "Prompt",
+		"web.preset_name":          "Nome da predefinição",
+		"web.save_preset":          "Salvar como predefinição",
+		"web.apply":                "Aplicar",
+		"web.custom":               "Personalizado",
+		"web.saved_preset":         "%s (salvo)",
+		"web.you":                  "Você",
+		"web.error":                "Erro: %s",
+		"web.token_count":          "%d tokens",
+		"web.ttft":                 "Tempo até o primeiro token",
+		"web.throughput":           "Velocidade de geração",
+		"web.role":                 "Papel",
+		"web.message":              "Mensagem",
+		"web.pinned":               "Fixada, nunca é removida",
+		"web.token_total":          "Total: %d tokens (limite de redução %d)",
+		"web.token_estimates":      "— tokenizador indisponível, os valores são estimativas",
+		"web.no_matches":           "Nenhum resultado",
+		"web.thinking":             "Pensando...",
+		"web.share":                "Compartilhar",
+		"web.share_link":           "Qualquer pessoa com este link pode ler esta conversa até %s:",
+		"web.shared_title":         "ChatGBT - Conversa compartilhada",
+		"web.shared_notice":        "Cópia somente leitura de uma conversa do ChatGBT. Este link expira em %s.",
+		"web.user":                 "Usuário",
+		"web.download":             "Baixar",
+		"web.download_title":       "Baixar esta conversa em Markdown",
+		"web.copy":                 "Copiar",
+		"web.copied":               "Copiado!",
+		"web.show_details":         "Mostrar horário, modelo e tempo de resposta nas mensagens",
+		"web.edit":                 "Editar mensagem",
+		"web.save":                 "Salvar e enviar",
+		"web.cancel":               "Cancelar",
+		"web.regenerate":           "Gerar resposta novamente",
+		"web.continue":             "Continuar resposta",
+		"web.stop":                 "Parar resposta",
+		"web.context":              "Contexto",
+		"web.context_usage":        "Contexto: %d de %d tokens",
+		"web.budget":               "Orçamento",
+		"web.budget_usage":         "Orçamento da sessão: %d de %d tokens",
+		"web.cost_title":           "Custo estimado da sessão",
+		"web.input_hint":           "Enter para enviar, Shift+Enter para nova linha, / para focar",
+		"web.theme":                "Tema",
+		"web.theme_dark":           "Escuro",
+		"web.theme_light":          "Claro",
+		"web.request_id":           "requisição %s",
+		"message_too_long":         "a mensagem tem %d caracteres; o limite é %d. Encurte-a ou divida-a em partes",
+		"daily_cost_exceeded":      "o limite de custo diário de US$ %.2f foi atingido; tente novamente amanhã",
+		"cli.usage_by_model":       "Por modelo:",
+		"budget.trimmed":           "Resposta limitada a %d tokens para não ultrapassar o orçamento da sessão",
+		"reply.truncated":          "A resposta foi cortada no limite de tokens; peça para continuar para ver o resto",
+		"reply.aborted":            "A resposta foi interrompida antes de terminar; peça para continuar para ver o resto",
+		"budget_exhausted":         "restam apenas %[2]d tokens no orçamento da sessão, o que não basta para a mensagem de %[1]d tokens e uma resposta. Reduza ou reinicie a conversa para continuar",
+		"generation_in_progress":   "uma resposta já está sendo gerada nesta sessão; aguarde até que termine antes de enviar outra solicitação",
+		"web.queued":               "Aguardando %d solicitação(ões) anterior(es) desta sessão...",
+		"cli.summarizing":          "Resumindo a conversa...",
+		"cli.summary_pinned":       "Resumo fixado no contexto; ele não é podado.",
+		"cli.summary_saved":        "Resumo salvo em %s",
+		"web.summarize":            "Resumir",
+		"web.summarize_title":      "Resumir a conversa e fixar o resumo",
+		"web.summary":              "Resumo (fixado)",
+		"cli.follow_ups":           "Perguntas de acompanhamento (digite um número para fazer uma):",
+		"web.follow_ups":           "Perguntas de acompanhamento sugeridas",
+		"cli.auto_pruned":          "Contexto reduzido para abrir espaço: %d mensagens removidas, ~%d tokens recuperados",
+		"cli.prune_dropped":        "Removidas:",
+		"cli.prune_summary":        "Substituídas por: %s",
+		"web.pruned":               "Contexto reduzido: %d mensagens removidas, ~%d tokens recuperados",
+		"web.prune_summary":        "Substituídas por: %s",
+		"cli.copied":               "Última resposta copiada para a área de transferência.",
+		"cli.copied_code":          "Último bloco de código copiado para a área de transferência.",
+		"cli.nothing_to_copy":      "Ainda não há nada para copiar.",
+		"cli.no_code_block":        "Nenhuma resposta tem um bloco de código para copiar.",
+	},
+}
@@ -11,6 +11,7 @@ import (
 // Client provides LLM interactions with proper context support using the new provider system
 type Client struct {
 	provider backend.Provider
+	model    string
 }
 
 // NewClient creates a new LLM client with configurable timeout
@@ -22,6 +23,10 @@ func NewClient(config backend.LLMConfig, timeout time.Duration) (*Client, error)
 		URL:     config.URL,
 		Model:   config.Model,
 		Timeout: int(timeout.Seconds()),
+		API:     config.API,
+
+		Organization: config.Organization,
+		Project:      config.Project,
 	}
 
 	// Create the provider
@@ -32,10 +37,79 @@ func NewClient(config backend.LLMConfig, timeout time.Duration) (*Client, error)
 
 	return &Client{
 		provider: provider,
+		model:    config.Model,
 	}, nil
 }
 
-// CreateCompletion creates a chat completion using the configured provider
+// CreateCompletion creates a chat completion using the configured provider, once the
+// provider's rate limits and the process's concurrency limit allow it
 func (c *Client) CreateCompletion(ctx context.Context, req *backend.ChatCompletionRequest) (*backend.ChatCompletionResponse, error) {
-	return c.provider.CreateCompletion(ctx, req)
+	done, err := c.admit(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := c.provider.CreateCompletion(ctx, req)
+	done(resp)
+	return resp, err
+}
+
+// CreateCompletionStream creates a chat completion, calling onDelta as content arrives.
+// Providers without streaming support fall back to a regular completion delivered in one piece.
+func (c *Client) CreateCompletionStream(ctx context.Context, req *backend.ChatCompletionRequest, onDelta backend.StreamHandler) (*backend.ChatCompletionResponse, error) {
+	done, err := c.admit(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	streamer, ok := c.provider.(backend.StreamingProvider)
+	if !ok {
+		resp, err := c.provider.CreateCompletion(ctx, req)
+		done(resp)
+		if err == nil && onDelta != nil && len(resp.Choices) > 0 {
+			onDelta(resp.Choices[0].Message.Content)
+		}
+		return resp, err
+	}
+	resp, err := streamer.CreateCompletionStream(ctx, req, onDelta)
+	done(resp)
+	return resp, err
+}
+
+// admit waits until the provider's rate limits have room for req, then for a slot
+// under the concurrency limit. The returned done gives the slot back and corrects the
+// tokens reserved with the usage in the completion, which may be nil.
+func (c *Client) admit(ctx context.Context, req *backend.ChatCompletionRequest) (done func(*backend.ChatCompletionResponse), err error) {
+	limiter := rateLimiterFor(c.provider.Name())
+	estimated := estimateTokens(req)
+	if limiter != nil {
+		if err := limiter.wait(ctx, estimated); err != nil {
+			return nil, err
+		}
+	}
+
+	release, err := acquire(ctx)
+	if err != nil {
+		return nil, err
+	}
+	return func(resp *backend.ChatCompletionResponse) {
+		release()
+		if limiter != nil && resp != nil && resp.Usage != nil {
+			limiter.adjust(estimated, resp.Usage.TotalTokens)
+		}
+	}, nil
+}
+
+// Capabilities reports what the underlying provider and model support
+func (c *Client) Capabilities() backend.Capabilities {
+	return c.provider.Capabilities()
+}
+
+// ProviderName returns the name of the underlying provider
+func (c *Client) ProviderName() string {
+	return c.provider.Name()
+}
+
+// Model returns the default model used when a request doesn't specify one
+func (c *Client) Model() string {
+	return c.model
 }
@@ -0,0 +1,74 @@
+package llm
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// QueueTimeoutError is returned instead of a completion that waited longer than the
+// queue timeout for a free slot under the SetConcurrencyLimit limit
+type QueueTimeoutError struct {
+	Limit   int
+	Timeout time.Duration
+}
+
+func (e *QueueTimeoutError) Error() string {
+	return fmt.Sprintf("waited %v for one of the %d request slots to the model; too many requests are in progress, try again shortly", e.Timeout, e.Limit)
+}
+
+// limiter bounds the completions in flight across every Client in the process
+type limiter struct {
+	slots   chan struct{} // One element per completion in flight; nil for no limit
+	timeout time.Duration // Longest wait for a slot; 0 waits as long as the request's context
+}
+
+var (
+	limiterMutex sync.RWMutex // Guards the field below
+	current      limiter
+)
+
+// SetConcurrencyLimit caps the completions all Clients in the process send at once at
+// max, so overlapping web, Slack, and batch traffic can't exceed provider rate limits or
+// memory. Requests over the cap wait in line for up to queueTimeout, 0 for as long as
+// their context allows. max <= 0 removes the cap.
+func SetConcurrencyLimit(max int, queueTimeout time.Duration) {
+	next := limiter{timeout: queueTimeout}
+	if max > 0 {
+		next.slots = make(chan struct{}, max)
+	}
+
+	limiterMutex.Lock()
+	current = next
+	limiterMutex.Unlock()
+}
+
+// acquire waits for a slot under the concurrency limit and returns the function that
+// gives it back. Requests already in flight when the limit changes keep their slot in
+// the old limiter.
+func acquire(ctx context.Context) (release func(), err error) {
+	limiterMutex.RLock()
+	l := current
+	limiterMutex.RUnlock()
+
+	if l.slots == nil {
+		return func() {}, nil
+	}
+
+	var timeout <-chan time.Time
+	if l.timeout > 0 {
+		timer := time.NewTimer(l.timeout)
+		defer timer.Stop()
+		timeout = timer.C
+	}
+
+	select {
+	case l.slots <- struct{}{}:
+		return func() { <-l.slots }, nil
+	case <-timeout:
+		return nil, &QueueTimeoutError{Limit: cap(l.slots), Timeout: l.timeout}
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
@@ -0,0 +1,127 @@
+package llm
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/nleiva/chatgbt/pkg/backend"
+)
+
+// bucket is a token bucket refilled at capacity per minute. Its level may go negative:
+// a reservation bigger than what's left is granted but pushes later ones back.
+type bucket struct {
+	capacity float64 // Most the bucket holds, also its refill per minute; 0 for no limit
+	level    float64
+}
+
+// rateLimiter shapes the requests and tokens sent to one provider to its per-minute limits
+type rateLimiter struct {
+	mutex    sync.Mutex // Guards the fields below
+	requests bucket
+	tokens   bucket
+	updated  time.Time
+}
+
+var (
+	rateLimitersMutex sync.RWMutex // Guards the field below
+	rateLimiters      map[backend.ProviderName]*rateLimiter
+)
+
+// SetRateLimits paces the completions all Clients in the process send to each provider
+// in limits, so bursts wait for the provider's per-minute allowance to refill instead of
+// being rejected with 429s. Providers not in limits aren't paced.
+func SetRateLimits(limits map[backend.ProviderName]backend.RateLimit) {
+	limiters := make(map[backend.ProviderName]*rateLimiter, len(limits))
+	for provider, limit := range limits {
+		limiters[provider] = newRateLimiter(limit, time.Now())
+	}
+
+	rateLimitersMutex.Lock()
+	rateLimiters = limiters
+	rateLimitersMutex.Unlock()
+}
+
+// newRateLimiter starts with full buckets, allowing a minute's worth of traffic at once
+func newRateLimiter(limit backend.RateLimit, now time.Time) *rateLimiter {
+	rpm, tpm := float64(limit.RequestsPerMinute), float64(limit.TokensPerMinute)
+	return &rateLimiter{
+		requests: bucket{capacity: rpm, level: rpm},
+		tokens:   bucket{capacity: tpm, level: tpm},
+		updated:  now,
+	}
+}
+
+// rateLimiterFor returns the limiter of provider, or nil when it isn't paced
+func rateLimiterFor(provider string) *rateLimiter {
+	rateLimitersMutex.RLock()
+	defer rateLimitersMutex.RUnlock()
+	return rateLimiters[backend.ProviderName(provider)]
+}
+
+// wait reserves one request and tokens, then waits until both buckets have refilled
+// enough to cover them. The reservation is returned when ctx ends first.
+func (l *rateLimiter) wait(ctx context.Context, tokens int) error {
+	delay := l.reserve(1, float64(tokens), time.Now())
+	if delay <= 0 {
+		return nil
+	}
+
+	timer := time.NewTimer(delay)
+	defer timer.Stop()
+	select {
+	case <-timer.C:
+		return nil
+	case <-ctx.Done():
+		l.reserve(-1, -float64(tokens), time.Now())
+		return ctx.Err()
+	}
+}
+
+// adjust corrects the tokens reserved for a completion by the difference between its
+// estimate and the usage the provider reported
+func (l *rateLimiter) adjust(estimated, actual int) {
+	if actual > 0 {
+		l.reserve(0, float64(actual-estimated), time.Now())
+	}
+}
+
+// reserve takes requests and tokens out of the buckets and returns how long until they
+// are covered. Negative amounts give back an earlier reservation.
+func (l *rateLimiter) reserve(requests, tokens float64, now time.Time) time.Duration {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	elapsed := now.Sub(l.updated)
+	l.updated = now
+	return max(l.requests.take(requests, elapsed), l.tokens.take(tokens, elapsed))
+}
+
+// take refills the bucket for elapsed, removes n, and returns how long until the level
+// is back to zero. n is capped at the capacity so oversized requests still get through.
+func (b *bucket) take(n float64, elapsed time.Duration) time.Duration {
+	if b.capacity == 0 {
+		return 0
+	}
+	perSecond := b.capacity / 60
+	b.level = min(b.capacity, b.level+elapsed.Seconds()*perSecond)
+	b.level -= min(n, b.capacity)
+	if b.level >= 0 {
+		return 0
+	}
+	return time.Duration(-b.level / perSecond * float64(time.Second))
+}
+
+// estimateTokens approximates the tokens a request counts against a tokens per minute
+// limit: its prompt at about 4 characters per token, plus the reply it asks room for
+func estimateTokens(req *backend.ChatCompletionRequest) int {
+	chars := 0
+	for _, msg := range req.Messages {
+		chars += len(msg.Role) + len(msg.Content) + 20 // Framing overhead per message
+	}
+	tokens := chars / 4
+	if req.MaxTokens != nil {
+		tokens += *req.MaxTokens
+	}
+	return tokens
+}
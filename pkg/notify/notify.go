@@ -0,0 +1,151 @@
+package notify
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+)
+
+// EventType identifies what triggered a notification
+type EventType string
+
+const (
+	// EventBudgetWarning fires once when a session crosses the budget warn threshold
+	EventBudgetWarning EventType = "budget_warning"
+	// EventOverBudget fires once when a session exceeds its token budget
+	EventOverBudget EventType = "over_budget"
+	// EventProviderFailures fires when a session sees repeated consecutive provider failures
+	EventProviderFailures EventType = "provider_failures"
+	// EventDailyCostLimit fires once a day when the combined spend of all sessions reaches MAX_DAILY_COST
+	EventDailyCostLimit EventType = "daily_cost_limit"
+	// EventUsageDigest carries a usage digest written by `stats digest`, in Markdown
+	EventUsageDigest EventType = "usage_digest"
+)
+
+// Event is the payload delivered to webhooks
+type Event struct {
+	Type             EventType `json:"type"`
+	Timestamp        time.Time `json:"timestamp"`
+	SessionID        string    `json:"session_id"`
+	ConversationType string    `json:"conversation_type,omitempty"`
+	Model            string    `json:"model,omitempty"`
+	Message          string    `json:"message"`
+	Tokens           int       `json:"tokens,omitempty"`
+	Limit            int       `json:"limit,omitempty"`
+	Cost             float64   `json:"cost,omitempty"`
+	Failures         int       `json:"failures,omitempty"`
+	Error            string    `json:"error,omitempty"`
+}
+
+// Notifier delivers events. Implementations must not block the caller.
+type Notifier interface {
+	Notify(event Event)
+}
+
+// Format selects the webhook payload shape
+type Format string
+
+const (
+	// FormatJSON posts the Event as-is
+	FormatJSON Format = "json"
+	// FormatSlack posts a Slack incoming-webhook message
+	FormatSlack Format = "slack"
+)
+
+// Webhook posts events to a URL in the background
+type Webhook struct {
+	url    string
+	format Format
+	client *http.Client
+}
+
+// NewWebhook creates a webhook notifier for url
+func NewWebhook(url string, format Format) *Webhook {
+	return &Webhook{
+		url:    url,
+		format: format,
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Notify sends the event without waiting for the response; delivery failures are logged
+func (w *Webhook) Notify(event Event) {
+	go func() {
+		if err := w.Send(event); err != nil {
+			log.Printf("Webhook delivery failed: %v", err)
+		}
+	}()
+}
+
+// Send posts a single event and waits for the response
+func (w *Webhook) Send(event Event) error {
+	var payload interface{} = event
+	if w.format == FormatSlack {
+		payload = map[string]interface{}{"text": slackText(event)}
+	}
+
+	body, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to encode webhook payload: %w", err)
+	}
+
+	resp, err := w.client.Post(w.url, "application/json", bytes.NewReader(body))
+	if err != nil {
+		return fmt.Errorf("failed to post webhook: %w", err)
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook returned status %d", resp.StatusCode)
+	}
+	return nil
+}
+
+// slackText renders an event as a Slack message
+func slackText(event Event) string {
+	icon := ":warning:"
+	switch event.Type {
+	case EventOverBudget:
+		icon = ":no_entry:"
+	case EventProviderFailures:
+		icon = ":rotating_light:"
+	case EventUsageDigest:
+		// Digests cover every session rather than one, and their tables read fine as plain text
+		return event.Message
+	}
+
+	text := fmt.Sprintf("%s *chatgbt %s* (session `%s`)\n%s", icon, event.Type, event.SessionID, event.Message)
+	if event.Error != "" {
+		text += fmt.Sprintf("\nLast error: `%s`", event.Error)
+	}
+	return text
+}
+
+// Multi fans an event out to several notifiers
+type Multi []Notifier
+
+// Notify sends the event to every notifier
+func (m Multi) Notify(event Event) {
+	for _, n := range m {
+		n.Notify(event)
+	}
+}
+
+// NewWebhooks builds a notifier for the given generic JSON and Slack webhook URLs.
+// It returns nil when no URLs are configured.
+func NewWebhooks(jsonURLs, slackURLs []string) Notifier {
+	var notifiers Multi
+	for _, url := range jsonURLs {
+		notifiers = append(notifiers, NewWebhook(url, FormatJSON))
+	}
+	for _, url := range slackURLs {
+		notifiers = append(notifiers, NewWebhook(url, FormatSlack))
+	}
+	if len(notifiers) == 0 {
+		return nil
+	}
+	return notifiers
+}
@@ -0,0 +1,194 @@
+package prompts
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Preset is a named, reusable system prompt
+type Preset struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+	Prompt      string `json:"prompt"`
+	BuiltIn     bool   `json:"-"`
+}
+
+// builtInPresets ship with the binary and can't be overwritten
+var builtInPresets = []Preset{
+	{
+		Name:        "assistant",
+		Description: "General-purpose helpful assistant",
+		Prompt:      "You are ChatGBT, a helpful AI assistant.",
+	},
+	{
+		Name:        "tutor",
+		Description: "Patient tutor that guides instead of giving answers away",
+		Prompt: "You are a patient tutor. Guide the student toward the answer with questions and hints, " +
+			"explain concepts step by step, and check their understanding before moving on. " +
+			"Only give the full solution if they explicitly ask for it.",
+	},
+	{
+		Name:        "code_reviewer",
+		Description: "Thorough code reviewer focused on correctness and clarity",
+		Prompt: "You are an experienced code reviewer. Point out bugs, edge cases, security issues, " +
+			"and unclear code first, then style nits. Be specific, reference the relevant lines, " +
+			"and suggest concrete fixes.",
+	},
+	{
+		Name:        "translator",
+		Description: "Translates text while preserving tone and formatting",
+		Prompt: "You are a professional translator. Translate the user's text into the requested language " +
+			"(English if none is given), preserving tone, meaning, and formatting. Reply with the translation only.",
+	},
+	{
+		Name:        "concise",
+		Description: "Short, direct answers",
+		Prompt:      "You are a concise assistant. Answer in as few words as possible without losing accuracy.",
+	},
+}
+
+// Library holds built-in presets plus user-defined presets persisted to a JSON file
+type Library struct {
+	mutex sync.RWMutex
+	path  string
+	user  map[string]Preset
+}
+
+// DefaultPath returns the default location of the user preset file
+func DefaultPath() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "prompts.json"
+	}
+	return filepath.Join(dir, "chatgbt", "prompts.json")
+}
+
+// NewLibrary loads user presets from path. A missing file is not an error.
+// An empty path keeps user presets in memory only.
+func NewLibrary(path string) (*Library, error) {
+	lib := &Library{
+		path: path,
+		user: make(map[string]Preset),
+	}
+	return lib, lib.Reload()
+}
+
+// Reload rereads the user presets from the library's file, such as after it was edited
+// by hand. On error the presets loaded before are kept.
+func (l *Library) Reload() error {
+	if l.path == "" {
+		return nil
+	}
+
+	data, err := os.ReadFile(l.path)
+	if errors.Is(err, os.ErrNotExist) {
+		data = []byte("[]")
+	} else if err != nil {
+		return fmt.Errorf("failed to read prompt library: %w", err)
+	}
+
+	var presets []Preset
+	if err := json.Unmarshal(data, &presets); err != nil {
+		return fmt.Errorf("failed to parse prompt library %s: %w", l.path, err)
+	}
+	user := make(map[string]Preset, len(presets))
+	for _, p := range presets {
+		user[p.Name] = p
+	}
+
+	l.mutex.Lock()
+	l.user = user
+	l.mutex.Unlock()
+	return nil
+}
+
+// List returns built-in presets followed by user presets sorted by name
+func (l *Library) List() []Preset {
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+
+	presets := make([]Preset, 0, len(builtInPresets)+len(l.user))
+	for _, p := range builtInPresets {
+		p.BuiltIn = true
+		presets = append(presets, p)
+	}
+
+	user := make([]Preset, 0, len(l.user))
+	for _, p := range l.user {
+		user = append(user, p)
+	}
+	sort.Slice(user, func(i, j int) bool { return user[i].Name < user[j].Name })
+
+	return append(presets, user...)
+}
+
+// Get looks up a preset by name
+func (l *Library) Get(name string) (Preset, bool) {
+	for _, p := range builtInPresets {
+		if p.Name == name {
+			p.BuiltIn = true
+			return p, true
+		}
+	}
+
+	l.mutex.RLock()
+	defer l.mutex.RUnlock()
+	p, ok := l.user[name]
+	return p, ok
+}
+
+// Save adds or replaces a user preset and persists the library
+func (l *Library) Save(preset Preset) error {
+	preset.Name = strings.TrimSpace(preset.Name)
+	preset.Prompt = strings.TrimSpace(preset.Prompt)
+	if preset.Name == "" {
+		return fmt.Errorf("preset name is required")
+	}
+	if preset.Prompt == "" {
+		return fmt.Errorf("preset prompt is required")
+	}
+	for _, p := range builtInPresets {
+		if p.Name == preset.Name {
+			return fmt.Errorf("preset %q is built in and can't be replaced", preset.Name)
+		}
+	}
+	preset.BuiltIn = false
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	l.user[preset.Name] = preset
+	return l.persist()
+}
+
+// persist writes user presets to disk; callers must hold the write lock
+func (l *Library) persist() error {
+	if l.path == "" {
+		return nil
+	}
+
+	presets := make([]Preset, 0, len(l.user))
+	for _, p := range l.user {
+		presets = append(presets, p)
+	}
+	sort.Slice(presets, func(i, j int) bool { return presets[i].Name < presets[j].Name })
+
+	data, err := json.MarshalIndent(presets, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode prompt library: %w", err)
+	}
+
+	if err := os.MkdirAll(filepath.Dir(l.path), 0755); err != nil {
+		return fmt.Errorf("failed to create prompt library directory: %w", err)
+	}
+	if err := os.WriteFile(l.path, data, 0644); err != nil {
+		return fmt.Errorf("failed to write prompt library: %w", err)
+	}
+	return nil
+}
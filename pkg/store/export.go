@@ -0,0 +1,150 @@
+package store
+
+import (
+	"encoding/json"
+	"fmt"
+	"html"
+	"io"
+	"strings"
+
+	"github.com/nleiva/chatgbt/pkg/backend"
+)
+
+// Format is a conversation export format
+type Format string
+
+const (
+	FormatMarkdown Format = "md"
+	FormatJSON     Format = "json"
+	FormatHTML     Format = "html"
+)
+
+// ParseFormat validates an export format name
+func ParseFormat(name string) (Format, error) {
+	switch Format(strings.ToLower(name)) {
+	case FormatMarkdown, "markdown":
+		return FormatMarkdown, nil
+	case FormatJSON:
+		return FormatJSON, nil
+	case FormatHTML, "htm":
+		return FormatHTML, nil
+	default:
+		return "", fmt.Errorf("unsupported export format %q (use md, json, or html)", name)
+	}
+}
+
+// ContentType returns the MIME type of the format
+func (f Format) ContentType() string {
+	switch f {
+	case FormatJSON:
+		return "application/json"
+	case FormatHTML:
+		return "text/html; charset=utf-8"
+	default:
+		return "text/markdown; charset=utf-8"
+	}
+}
+
+// Export writes the conversation to w in the given format
+func Export(w io.Writer, conv *Conversation, format Format) error {
+	switch format {
+	case FormatJSON:
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(conv)
+	case FormatHTML:
+		return exportHTML(w, conv)
+	case FormatMarkdown:
+		return exportMarkdown(w, conv)
+	default:
+		return fmt.Errorf("unsupported export format %q", format)
+	}
+}
+
+// exportMarkdown writes a readable transcript with one section per message
+func exportMarkdown(w io.Writer, conv *Conversation) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# Conversation %s\n\n", conv.ID)
+	fmt.Fprintf(&b, "- Started: %s\n", conv.CreatedAt.Format("2006-01-02 15:04:05"))
+	if conv.Model != "" {
+		fmt.Fprintf(&b, "- Model: %s\n", conv.Model)
+	}
+	if conv.SystemPrompt != "" {
+		fmt.Fprintf(&b, "- System prompt: %s\n", strings.Join(strings.Fields(conv.SystemPrompt), " "))
+	}
+	if conv.Summary != "" {
+		fmt.Fprintf(&b, "\n## Summary\n\n%s\n", demoteHeadings(conv.Summary))
+	}
+
+	for _, msg := range conv.Messages {
+		fmt.Fprintf(&b, "\n## %s (%s)\n\n%s\n", roleTitle(msg.Role), msg.Timestamp.Format("15:04:05"), strings.TrimSpace(msg.Content))
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// exportHTML writes a standalone page. Every message is escaped and shown as preformatted
+// text, so content from the model can't inject markup or scripts.
+func exportHTML(w io.Writer, conv *Conversation) error {
+	var b strings.Builder
+	b.WriteString("<!DOCTYPE html>\n<html>\n<head>\n<meta charset=\"UTF-8\">\n")
+	fmt.Fprintf(&b, "<title>Conversation %s</title>\n", html.EscapeString(conv.ID))
+	b.WriteString(`<style>
+body { font-family: sans-serif; max-width: 48rem; margin: 2rem auto; padding: 0 1rem; color: #222; }
+.meta { color: #666; font-size: 0.9rem; }
+.message { border-top: 1px solid #ddd; padding: 0.75rem 0; }
+.role { font-weight: bold; }
+.time { color: #888; font-size: 0.8rem; margin-left: 0.5rem; }
+pre { white-space: pre-wrap; word-wrap: break-word; font-family: inherit; margin: 0.5rem 0 0; }
+</style>
+</head>
+<body>
+`)
+	fmt.Fprintf(&b, "<h1>Conversation %s</h1>\n", html.EscapeString(conv.ID))
+	fmt.Fprintf(&b, "<p class=\"meta\">Started %s", conv.CreatedAt.Format("2006-01-02 15:04:05"))
+	if conv.Model != "" {
+		fmt.Fprintf(&b, " &middot; %s", html.EscapeString(conv.Model))
+	}
+	b.WriteString("</p>\n")
+	if conv.SystemPrompt != "" {
+		fmt.Fprintf(&b, "<p class=\"meta\">System prompt: %s</p>\n", html.EscapeString(conv.SystemPrompt))
+	}
+	if conv.Summary != "" {
+		fmt.Fprintf(&b, "<div class=\"message summary\"><span class=\"role\">Summary</span><pre>%s</pre></div>\n", html.EscapeString(conv.Summary))
+	}
+
+	for _, msg := range conv.Messages {
+		fmt.Fprintf(&b, "<div class=\"message %s\"><span class=\"role\">%s</span><span class=\"time\">%s</span><pre>%s</pre></div>\n",
+			html.EscapeString(string(msg.Role)), html.EscapeString(roleTitle(msg.Role)), msg.Timestamp.Format("15:04:05"), html.EscapeString(msg.Content))
+	}
+	b.WriteString("</body>\n</html>\n")
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// demoteHeadings nests the Markdown headings of text one level deeper, under a section heading
+func demoteHeadings(text string) string {
+	lines := strings.Split(text, "\n")
+	for i, line := range lines {
+		if strings.HasPrefix(line, "#") {
+			lines[i] = "#" + line
+		}
+	}
+	return strings.Join(lines, "\n")
+}
+
+// roleTitle labels a message author in exports
+func roleTitle(role backend.Role) string {
+	switch role {
+	case backend.RoleUser:
+		return "User"
+	case backend.RoleAssistant:
+		return "Assistant"
+	case backend.RoleSystem:
+		return "System"
+	default:
+		return string(role)
+	}
+}
@@ -0,0 +1,184 @@
+package store
+
+import (
+	"bufio"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+)
+
+const (
+	// SourceConversation and SourceLog identify where a search hit was found
+	SourceConversation = "conversation"
+	SourceLog          = "log"
+
+	snippetRadius = 60
+)
+
+// SearchHit is a single match returned by Search
+type SearchHit struct {
+	ID        string    `json:"id"`         // Conversation ID, or session ID for log hits
+	SessionID string    `json:"session_id"` // Session the match belongs to
+	Source    string    `json:"source"`     // "conversation" or "log"
+	Role      string    `json:"role,omitempty"`
+	Timestamp time.Time `json:"timestamp"`
+	Snippet   string    `json:"snippet"`
+}
+
+// Search runs a case-insensitive full-text search over stored conversations and the
+// session JSONL logs in logsDir. Results are newest first; limit <= 0 means no limit.
+func Search(st Store, logsDir, query string, limit int) ([]SearchHit, error) {
+	return search(st, logsDir, "", query, limit)
+}
+
+// SearchSession is Search limited to the conversations and logs of one session
+func SearchSession(st Store, logsDir, sessionID, query string, limit int) ([]SearchHit, error) {
+	return search(st, logsDir, sessionID, query, limit)
+}
+
+// search runs Search over the session with sessionID, or over every session when it is empty
+func search(st Store, logsDir, sessionID, query string, limit int) ([]SearchHit, error) {
+	query = strings.TrimSpace(query)
+	if query == "" {
+		return nil, nil
+	}
+	needle := strings.ToLower(query)
+
+	var hits []SearchHit
+	conversations, err := st.List()
+	if err != nil {
+		return nil, err
+	}
+	for _, conv := range conversations {
+		if sessionID != "" && conv.SessionID != sessionID {
+			continue
+		}
+		for _, msg := range conv.Messages {
+			if snippet, ok := matchSnippet(msg.Content, needle); ok {
+				hits = append(hits, SearchHit{
+					ID:        conv.ID,
+					SessionID: conv.SessionID,
+					Source:    SourceConversation,
+					Role:      string(msg.Role),
+					Timestamp: msg.Timestamp,
+					Snippet:   snippet,
+				})
+			}
+		}
+	}
+
+	if logsDir != "" {
+		logHits, err := searchLogs(logsDir, sessionID, needle)
+		if err != nil {
+			return nil, err
+		}
+		hits = append(hits, logHits...)
+	}
+
+	sort.SliceStable(hits, func(i, j int) bool {
+		return hits[i].Timestamp.After(hits[j].Timestamp)
+	})
+	if limit > 0 && len(hits) > limit {
+		hits = hits[:limit]
+	}
+	return hits, nil
+}
+
+// searchLogs scans the session metrics logs line by line, only those of the session
+// with sessionID when it isn't empty
+func searchLogs(logsDir, sessionID, needle string) ([]SearchHit, error) {
+	paths, err := filepath.Glob(filepath.Join(logsDir, "*.jsonl"))
+	if err != nil {
+		return nil, err
+	}
+
+	var hits []SearchHit
+	for _, path := range paths {
+		if sessionID != "" && sessionIDFromLogName(filepath.Base(path)) != sessionID {
+			continue
+		}
+		f, err := os.Open(path)
+		if err != nil {
+			continue
+		}
+
+		scanner := bufio.NewScanner(f)
+		scanner.Buffer(make([]byte, 0, 64*1024), 4*1024*1024)
+		for scanner.Scan() {
+			line := scanner.Text()
+			snippet, ok := matchSnippet(line, needle)
+			if !ok {
+				continue
+			}
+
+			var record struct {
+				SessionID string    `json:"session_id"`
+				Timestamp time.Time `json:"timestamp"`
+				StartTime time.Time `json:"start_time"`
+			}
+			_ = json.Unmarshal([]byte(line), &record)
+			if record.Timestamp.IsZero() {
+				record.Timestamp = record.StartTime
+			}
+			if record.SessionID == "" {
+				record.SessionID = sessionIDFromLogName(filepath.Base(path))
+			}
+
+			hits = append(hits, SearchHit{
+				ID:        record.SessionID,
+				SessionID: record.SessionID,
+				Source:    SourceLog,
+				Timestamp: record.Timestamp,
+				Snippet:   snippet,
+			})
+		}
+		f.Close()
+	}
+	return hits, nil
+}
+
+// sessionIDFromLogName extracts the session ID from "session_<date>_<id>.jsonl"
+func sessionIDFromLogName(name string) string {
+	name = strings.TrimSuffix(strings.TrimPrefix(name, "session_"), ".jsonl")
+	if _, id, ok := strings.Cut(name, "_"); ok {
+		return id
+	}
+	return name
+}
+
+// matchSnippet returns the text surrounding the first case-insensitive match of needle
+func matchSnippet(text, needle string) (string, bool) {
+	idx := strings.Index(strings.ToLower(text), needle)
+	if idx < 0 {
+		return "", false
+	}
+	// Lowercasing can change byte lengths for some scripts, keep the index in range
+	idx = min(idx, len(text))
+
+	start := max(idx-snippetRadius, 0)
+	end := min(idx+len(needle)+snippetRadius, len(text))
+	// Avoid cutting multi-byte characters in half
+	for start > 0 && !isRuneStart(text[start]) {
+		start--
+	}
+	for end < len(text) && !isRuneStart(text[end]) {
+		end++
+	}
+
+	snippet := strings.Join(strings.Fields(text[start:end]), " ")
+	if start > 0 {
+		snippet = "..." + snippet
+	}
+	if end < len(text) {
+		snippet += "..."
+	}
+	return snippet, true
+}
+
+// isRuneStart reports whether b is the first byte of a UTF-8 sequence
+func isRuneStart(b byte) bool {
+	return b&0xC0 != 0x80
+}
@@ -0,0 +1,148 @@
+package store
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nleiva/chatgbt/pkg/backend"
+)
+
+// DefaultDir is where conversations are persisted, next to the session metrics logs
+const DefaultDir = "logs/conversations"
+
+// ErrNotFound is returned when a conversation doesn't exist
+var ErrNotFound = errors.New("conversation not found")
+
+// Conversation is a persisted chat transcript. Unlike the session context it is never pruned.
+type Conversation struct {
+	ID               string    `json:"id"`
+	SessionID        string    `json:"session_id"`
+	ConversationType string    `json:"conversation_type"`
+	SystemPrompt     string    `json:"system_prompt"`
+	Model            string    `json:"model,omitempty"`
+	CreatedAt        time.Time `json:"created_at"`
+	UpdatedAt        time.Time `json:"updated_at"`
+	Messages         []Message `json:"messages"`
+	Summary          string    `json:"summary,omitempty"` // Markdown summary requested during the conversation
+}
+
+// Message is a single timestamped message in a conversation
+type Message struct {
+	Role      backend.Role `json:"role"`
+	Content   string       `json:"content"`
+	Timestamp time.Time    `json:"timestamp"`
+	Truncated bool         `json:"truncated,omitempty"` // The reply was stopped before it finished
+}
+
+// Store persists conversations
+type Store interface {
+	Save(conv *Conversation) error
+	Load(id string) (*Conversation, error)
+	List() ([]*Conversation, error)
+}
+
+// FileStore keeps one JSON file per conversation in a directory
+type FileStore struct {
+	dir   string
+	mutex sync.Mutex
+}
+
+// NewFileStore creates a file-backed store rooted at dir
+func NewFileStore(dir string) *FileStore {
+	return &FileStore{dir: dir}
+}
+
+// Dir returns the directory conversations are stored in
+func (fs *FileStore) Dir() string {
+	return fs.dir
+}
+
+// Save writes the conversation, replacing any previous version
+func (fs *FileStore) Save(conv *Conversation) error {
+	if !validID(conv.ID) {
+		return fmt.Errorf("invalid conversation ID %q", conv.ID)
+	}
+
+	data, err := json.MarshalIndent(conv, "", "  ")
+	if err != nil {
+		return fmt.Errorf("failed to encode conversation: %w", err)
+	}
+
+	fs.mutex.Lock()
+	defer fs.mutex.Unlock()
+
+	if err := os.MkdirAll(fs.dir, 0755); err != nil {
+		return fmt.Errorf("failed to create conversation directory: %w", err)
+	}
+
+	// Write to a temporary file first so readers never see a partial conversation
+	path := fs.path(conv.ID)
+	tmp := path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0644); err != nil {
+		return fmt.Errorf("failed to write conversation: %w", err)
+	}
+	if err := os.Rename(tmp, path); err != nil {
+		return fmt.Errorf("failed to write conversation: %w", err)
+	}
+	return nil
+}
+
+// Load reads a conversation by ID
+func (fs *FileStore) Load(id string) (*Conversation, error) {
+	if !validID(id) {
+		return nil, ErrNotFound
+	}
+
+	data, err := os.ReadFile(fs.path(id))
+	if errors.Is(err, os.ErrNotExist) {
+		return nil, ErrNotFound
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to read conversation: %w", err)
+	}
+
+	var conv Conversation
+	if err := json.Unmarshal(data, &conv); err != nil {
+		return nil, fmt.Errorf("failed to parse conversation %s: %w", id, err)
+	}
+	return &conv, nil
+}
+
+// List returns every stored conversation, most recently updated first
+func (fs *FileStore) List() ([]*Conversation, error) {
+	paths, err := filepath.Glob(filepath.Join(fs.dir, "*.json"))
+	if err != nil {
+		return nil, err
+	}
+
+	conversations := make([]*Conversation, 0, len(paths))
+	for _, path := range paths {
+		conv, err := fs.Load(strings.TrimSuffix(filepath.Base(path), ".json"))
+		if err != nil {
+			continue // Skip unreadable files rather than failing the whole listing
+		}
+		conversations = append(conversations, conv)
+	}
+
+	sort.Slice(conversations, func(i, j int) bool {
+		return conversations[i].UpdatedAt.After(conversations[j].UpdatedAt)
+	})
+	return conversations, nil
+}
+
+// path returns the file holding a conversation
+func (fs *FileStore) path(id string) string {
+	return filepath.Join(fs.dir, id+".json")
+}
+
+// validID rejects IDs that could escape the store directory
+func validID(id string) bool {
+	return id != "" && !strings.ContainsAny(id, `/\`) && !strings.HasPrefix(id, ".")
+}
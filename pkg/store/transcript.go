@@ -0,0 +1,62 @@
+package store
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/nleiva/chatgbt/pkg/backend"
+)
+
+// Transcript appends exchanges to a Markdown file. Each exchange is written with a
+// single append to a file opened with O_APPEND, so processes sharing the file, such as
+// quick queries run in parallel, don't interleave their entries.
+type Transcript struct {
+	mutex sync.Mutex
+	path  string
+}
+
+// NewTranscript creates a transcript that appends to path, creating it when needed
+func NewTranscript(path string) *Transcript {
+	return &Transcript{path: path}
+}
+
+// Path returns the file the transcript appends to
+func (t *Transcript) Path() string {
+	return t.path
+}
+
+// Append adds an exchange: a timestamp and the model, when known, followed by messages
+func (t *Transcript) Append(model string, messages ...backend.Message) error {
+	var b strings.Builder
+	fmt.Fprintf(&b, "_%s", time.Now().Format("2006-01-02 15:04:05"))
+	if model != "" {
+		fmt.Fprintf(&b, " · %s", model)
+	}
+	b.WriteString("_\n")
+	for _, msg := range messages {
+		fmt.Fprintf(&b, "\n## %s\n\n%s\n", roleTitle(msg.Role), strings.TrimSpace(msg.Content))
+	}
+
+	t.mutex.Lock()
+	defer t.mutex.Unlock()
+
+	f, err := os.OpenFile(t.path, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0644)
+	if err != nil {
+		return fmt.Errorf("failed to open transcript: %w", err)
+	}
+	entry := b.String()
+	if info, err := f.Stat(); err == nil && info.Size() > 0 {
+		entry = "\n---\n\n" + entry
+	}
+	_, err = f.WriteString(entry)
+	if closeErr := f.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		return fmt.Errorf("failed to write transcript: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,88 @@
+package tools
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"sync"
+
+	"github.com/nleiva/chatgbt/pkg/backend"
+)
+
+// Tool is a function the model can call during a conversation
+type Tool interface {
+	// Definition describes the tool to the model
+	Definition() backend.ToolDefinition
+	// Call runs the tool with JSON-encoded arguments and returns text for the model
+	Call(ctx context.Context, arguments json.RawMessage) (string, error)
+}
+
+// Registry holds the tools available to chat sessions
+type Registry struct {
+	mutex sync.RWMutex
+	tools map[string]Tool
+}
+
+// NewRegistry creates an empty registry
+func NewRegistry() *Registry {
+	return &Registry{tools: make(map[string]Tool)}
+}
+
+// Register adds a tool; names must be unique
+func (r *Registry) Register(tool Tool) error {
+	name := tool.Definition().Name
+	if name == "" {
+		return fmt.Errorf("tool has no name")
+	}
+
+	r.mutex.Lock()
+	defer r.mutex.Unlock()
+
+	if _, exists := r.tools[name]; exists {
+		return fmt.Errorf("tool %q is already registered", name)
+	}
+	r.tools[name] = tool
+	return nil
+}
+
+// Get looks up a tool by name
+func (r *Registry) Get(name string) (Tool, bool) {
+	if r == nil {
+		return nil, false
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	tool, ok := r.tools[name]
+	return tool, ok
+}
+
+// Definitions returns the definitions of every registered tool sorted by name.
+// A nil registry has no tools.
+func (r *Registry) Definitions() []backend.ToolDefinition {
+	if r == nil {
+		return nil
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+
+	definitions := make([]backend.ToolDefinition, 0, len(r.tools))
+	for _, tool := range r.tools {
+		definitions = append(definitions, tool.Definition())
+	}
+	sort.Slice(definitions, func(i, j int) bool { return definitions[i].Name < definitions[j].Name })
+	return definitions
+}
+
+// Len returns the number of registered tools
+func (r *Registry) Len() int {
+	if r == nil {
+		return 0
+	}
+
+	r.mutex.RLock()
+	defer r.mutex.RUnlock()
+	return len(r.tools)
+}
@@ -0,0 +1,180 @@
+package tools
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"regexp"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/nleiva/chatgbt/pkg/backend"
+)
+
+// Subprocess tools are executables that speak JSON over stdin/stdout. Each invocation
+// starts the executable, writes one request object to stdin, and reads one response
+// object from stdout:
+//
+//	{"type": "describe"}                  -> {"name": "...", "description": "...", "parameters": {...}}
+//	{"type": "call", "arguments": {...}}  -> {"content": "..."} or {"error": "..."}
+//
+// Anything the tool writes to stderr is included in error messages.
+
+const (
+	// describeTimeout bounds tool discovery so a broken executable can't stall startup
+	describeTimeout = 5 * time.Second
+	// DefaultCallTimeout bounds a single tool call
+	DefaultCallTimeout = 30 * time.Second
+	// maxOutputBytes caps how much tool output is passed back to the model
+	maxOutputBytes = 64 * 1024
+)
+
+// namePattern is the set of names the providers accept for functions
+var namePattern = regexp.MustCompile(`^[a-zA-Z0-9_-]{1,64}$`)
+
+// DefaultDir returns the directory searched for subprocess tools
+func DefaultDir() string {
+	dir, err := os.UserConfigDir()
+	if err != nil {
+		return "tools"
+	}
+	return filepath.Join(dir, "chatgbt", "tools")
+}
+
+// subprocessRequest is written to the tool's stdin
+type subprocessRequest struct {
+	Type      string          `json:"type"`
+	Arguments json.RawMessage `json:"arguments,omitempty"`
+}
+
+// subprocessResponse is read from the tool's stdout
+type subprocessResponse struct {
+	Content string `json:"content"`
+	Error   string `json:"error"`
+}
+
+// Subprocess is a tool implemented by an external executable
+type Subprocess struct {
+	path       string
+	definition backend.ToolDefinition
+	timeout    time.Duration
+}
+
+// NewSubprocess asks the executable at path to describe itself
+func NewSubprocess(path string) (*Subprocess, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), describeTimeout)
+	defer cancel()
+
+	out, err := run(ctx, path, subprocessRequest{Type: "describe"})
+	if err != nil {
+		return nil, err
+	}
+
+	var definition backend.ToolDefinition
+	if err := json.Unmarshal(out, &definition); err != nil {
+		return nil, fmt.Errorf("%s: invalid describe response: %w", filepath.Base(path), err)
+	}
+	if !namePattern.MatchString(definition.Name) {
+		return nil, fmt.Errorf("%s: invalid tool name %q", filepath.Base(path), definition.Name)
+	}
+
+	return &Subprocess{path: path, definition: definition, timeout: DefaultCallTimeout}, nil
+}
+
+// Definition implements Tool
+func (s *Subprocess) Definition() backend.ToolDefinition {
+	return s.definition
+}
+
+// Call implements Tool by running the executable with the arguments
+func (s *Subprocess) Call(ctx context.Context, arguments json.RawMessage) (string, error) {
+	if len(arguments) == 0 {
+		arguments = json.RawMessage("{}")
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, s.timeout)
+	defer cancel()
+
+	out, err := run(ctx, s.path, subprocessRequest{Type: "call", Arguments: arguments})
+	if err != nil {
+		return "", err
+	}
+
+	var resp subprocessResponse
+	if err := json.Unmarshal(out, &resp); err != nil {
+		return "", fmt.Errorf("%s: invalid call response: %w", s.definition.Name, err)
+	}
+	if resp.Error != "" {
+		return "", errors.New(resp.Error)
+	}
+	if len(resp.Content) > maxOutputBytes {
+		resp.Content = resp.Content[:maxOutputBytes] + "\n[output truncated]"
+	}
+	return resp.Content, nil
+}
+
+// run starts the executable, sends req, and returns its stdout
+func run(ctx context.Context, path string, req subprocessRequest) ([]byte, error) {
+	input, err := json.Marshal(req)
+	if err != nil {
+		return nil, err
+	}
+
+	var stdout, stderr bytes.Buffer
+	cmd := exec.CommandContext(ctx, path)
+	cmd.Stdin = bytes.NewReader(append(input, '\n'))
+	cmd.Stdout = &stdout
+	cmd.Stderr = &stderr
+
+	if err := cmd.Run(); err != nil {
+		if ctx.Err() != nil {
+			return nil, fmt.Errorf("%s timed out", filepath.Base(path))
+		}
+		if msg := strings.TrimSpace(stderr.String()); msg != "" {
+			return nil, fmt.Errorf("%s failed: %w: %s", filepath.Base(path), err, msg)
+		}
+		return nil, fmt.Errorf("%s failed: %w", filepath.Base(path), err)
+	}
+	return stdout.Bytes(), nil
+}
+
+// Discover registers every executable in dir as a subprocess tool. A missing directory
+// yields an empty registry. Tools that fail to describe themselves are skipped and
+// reported in the returned errors.
+func Discover(dir string) (*Registry, []error) {
+	registry := NewRegistry()
+
+	entries, err := os.ReadDir(dir)
+	if errors.Is(err, os.ErrNotExist) {
+		return registry, nil
+	}
+	if err != nil {
+		return registry, []error{fmt.Errorf("failed to read tools directory: %w", err)}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Name() < entries[j].Name() })
+
+	var errs []error
+	for _, entry := range entries {
+		path := filepath.Join(dir, entry.Name())
+		info, err := os.Stat(path) // Follows symlinks
+		if err != nil || info.IsDir() || info.Mode()&0111 == 0 || strings.HasPrefix(entry.Name(), ".") {
+			continue
+		}
+
+		tool, err := NewSubprocess(path)
+		if err != nil {
+			errs = append(errs, err)
+			continue
+		}
+		if err := registry.Register(tool); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", entry.Name(), err))
+		}
+	}
+	return registry, errs
+}